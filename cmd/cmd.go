@@ -14,38 +14,156 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"gopkg.in/yaml.v3"
 
+	"github.com/craftslab/lintflow/codeclimate"
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/eventlog"
+	"github.com/craftslab/lintflow/export"
+	"github.com/craftslab/lintflow/feedback"
 	"github.com/craftslab/lintflow/flow"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/leader"
 	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/metrics"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/progress"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/report"
 	"github.com/craftslab/lintflow/review"
+	"github.com/craftslab/lintflow/server"
+	"github.com/craftslab/lintflow/sla"
+	"github.com/craftslab/lintflow/storage"
+	"github.com/craftslab/lintflow/telemetry"
 	"github.com/craftslab/lintflow/writer"
 )
 
+const (
+	envConfigFile = "LINTFLOW_CONFIG_FILE"
+	envCodeReview = "LINTFLOW_CODE_REVIEW"
+	envListenUrl  = "LINTFLOW_LISTEN_URL"
+)
+
 var (
 	app        = kingpin.New("lintflow", "Lint Flow").Version(config.Version + "-build-" + config.Build)
-	codeReview = app.Flag("code-review", "Code review (bitbucket|gerrit|gitee|github|gitlab)").Required().String()
-	commitHash = app.Flag("commit-hash", "Commit hash (SHA-1)").Required().String()
-	configFile = app.Flag("config-file", "Config file (.yml)").Required().String()
-	outputFile = app.Flag("output-file", "Output file (.json|.txt|.xlsx)").Default().String()
+	configFile = app.Flag("config-file", "Config file (.yml)").Envar(envConfigFile).String()
+
+	runCmd     = app.Command("run", "Run flow on commit").Default()
+	codeReview = runCmd.Flag("code-review", "Code review (bitbucket|gerrit|gitee|github|gitlab)").Required().String()
+	commitHash = runCmd.Flag("commit-hash", "Commit hash (SHA-1)").Required().String()
+	deadline   = runCmd.Flag("deadline", "Deadline of run, instead of the one in config").Duration()
+	outputFile = runCmd.Flag("output-file", "Output file (.codeclimate.json|.html|.json|.txt|.xlsx)").Default().String()
+	keepWork   = runCmd.Flag("keep-workspace", "Keep workspace and print its path for inspection").Bool()
+	jsonReport = runCmd.Flag("json-progress", "Report progress in JSON lines on stdout").Bool()
+	noColor    = runCmd.Flag("no-color", "Print report without colors").Bool()
+	noReport   = runCmd.Flag("no-report", "Hide report of findings on stdout").Bool()
+	quietRun   = runCmd.Flag("quiet", "Hide progress").Bool()
+	recordDir  = runCmd.Flag("record", "Record responses of code review into directory").String()
+	replayDir  = runCmd.Flag("replay", "Replay responses of code review from directory").String()
+
+	cleanCmd  = app.Command("clean", "Clean leftover workspaces and cache entries")
+	olderThan = cleanCmd.Flag("older-than", "Clean ones older than duration").Default("24h").Duration()
+
+	configCmd     = app.Command("config", "Manage config")
+	doctorCmd     = configCmd.Command("doctor", "Check readiness of lints in config")
+	doctorTimeout = doctorCmd.Flag("timeout", "Timeout of each check").Default("10s").Duration()
+
+	diffCmd  = app.Command("diff", "Compare findings between two runs")
+	diffBase = diffCmd.Arg("job-a", "ID of base run").Required().String()
+	diffHead = diffCmd.Arg("job-b", "ID of run compared with base").Required().String()
+
+	jobsCmd    = app.Command("jobs", "Manage jobs of serving flow")
+	jobsUrl    = jobsCmd.Flag("listen-url", "Listen URL of server (host:port)").Envar(envListenUrl).Default(":8080").String()
+	deadCmd    = jobsCmd.Command("dead", "List jobs dead-lettered after retries")
+	requeueCmd = jobsCmd.Command("requeue", "Requeue dead job")
+	requeueId  = requeueCmd.Arg("job-id", "ID of dead job").Required().String()
+
+	logsCmd    = app.Command("logs", "Print execution logs of lints in run")
+	logsId     = logsCmd.Arg("job-id", "ID of run").Required().String()
+	logsLinter = logsCmd.Flag("linter", "Name of lint").String()
+
+	reproduceCmd = app.Command("reproduce", "Reproduce run from its capsule")
+	reproduceId  = reproduceCmd.Arg("job-id", "ID of run").Required().String()
+
+	serveCmd    = app.Command("serve", "Serve flow over HTTP")
+	serveReview = serveCmd.Flag("code-review", "Code review (bitbucket|gerrit|gitee|github|gitlab)").Envar(envCodeReview).
+			Default("gerrit").String()
+	healthCheck = serveCmd.Flag("healthcheck", "Check health of serving flow and exit").Bool()
+	listenUrl   = serveCmd.Flag("listen-url", "Listen URL (host:port)").Envar(envListenUrl).Default(":8080").String()
+
+	simulateCmd  = app.Command("simulate", "Simulate policy on findings")
+	findingsFile = simulateCmd.Flag("findings", "Findings file (.json)").Required().String()
+	policyFile   = simulateCmd.Flag("policy", "Policy file (.yml)").Required().String()
+
+	slaCmd    = app.Command("sla", "Report findings open across patchsets against SLA")
+	slaBreach = slaCmd.Flag("breach", "Report findings breaching SLA only").Bool()
+
+	workerCmd      = app.Command("worker", "Manage lint workers")
+	verifyCmd      = workerCmd.Command("verify", "Verify conformance of lint worker to protocol")
+	workerEndpoint = verifyCmd.Flag("endpoint", "Endpoint of worker (host:port)").Required().String()
+	workerTimeout  = verifyCmd.Flag("timeout", "Timeout of each case").Default("60s").Duration()
+)
+
+var (
+	configPaths = []string{
+		"config.yml",
+		filepath.Join("etc", "config.yml"),
+		filepath.Join("config", "config.yml"),
+		"/etc/lintflow/config.yml",
+	}
 )
 
 func Run() error {
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case cleanCmd.FullCommand():
+		return cleanFlow()
+	case deadCmd.FullCommand():
+		return listDead()
+	case doctorCmd.FullCommand():
+		return checkConfig()
+	case diffCmd.FullCommand():
+		return diffRuns()
+	case logsCmd.FullCommand():
+		return printRunLogs()
+	case reproduceCmd.FullCommand():
+		return reproduceRun()
+	case requeueCmd.FullCommand():
+		return requeueDead()
+	case serveCmd.FullCommand():
+		return serveFlow()
+	case simulateCmd.FullCommand():
+		return simulatePolicy()
+	case slaCmd.FullCommand():
+		return reportSla()
+	case verifyCmd.FullCommand():
+		return verifyWorker()
+	default:
+		return runCommit()
+	}
+}
 
+func runCommit() error {
 	c, err := initConfig(*configFile)
 	if err != nil {
 		return errors.Wrap(err, "failed to init config")
 	}
 
-	r, err := initReview(c)
+	r, err := initReview(c, *codeReview, *recordDir, *replayDir)
 	if err != nil {
 		return errors.Wrap(err, "failed to init review")
 	}
@@ -55,28 +173,374 @@ func Run() error {
 		return errors.Wrap(err, "failed to init lint")
 	}
 
-	w, err := initWriter(c)
+	s := &snippetHook{snippets: report.Snippets{}, storage: initStorage(c)}
+
+	w, err := initWriter(c, s.snippets)
 	if err != nil {
 		return errors.Wrap(err, "failed to init writer")
 	}
 
+	h, err := initHistory(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init history")
+	}
+
+	f, err := initFlow(context.Background(), c, r, l, h, *deadline, *keepWork, s)
+	if err != nil {
+		return errors.Wrap(err, "failed to init flow")
+	}
+
+	defer f.Flush()
+
+	p := initProgress(*quietRun, *jsonReport)
+	log.SetOutput(p)
+
+	defer func() {
+		_ = p.Close()
+		log.SetOutput(os.Stderr)
+	}()
+
 	log.Println("flow running")
 
-	if err := runFlow(c, r, l, w); err != nil {
+	buf, err := runFlow(progress.With(context.Background(), p), f, w)
+	if err != nil {
 		return errors.Wrap(err, "failed to run flow")
 	}
 
+	if !*noReport && !*jsonReport {
+		if err := initReport(*noColor, s.snippets).Print(buf); err != nil {
+			return errors.Wrap(err, "failed to print report")
+		}
+	}
+
 	log.Println("flow exiting")
 
 	return nil
 }
 
+func serveFlow() error {
+	if *healthCheck {
+		if err := server.Probe(*listenUrl); err != nil {
+			return errors.Wrap(err, "failed to check health")
+		}
+		return nil
+	}
+
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	r, err := initReview(c, *serveReview, "", "")
+	if err != nil {
+		return errors.Wrap(err, "failed to init review")
+	}
+
+	l, err := initLint(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init lint")
+	}
+
+	h, err := initHistory(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init history")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	f, err := initFlow(ctx, c, r, l, h, 0, false)
+	if err != nil {
+		return errors.Wrap(err, "failed to init flow")
+	}
+
+	defer f.Flush()
+
+	s, err := initServer(ctx, c, f, h, r)
+	if err != nil {
+		return errors.Wrap(err, "failed to init server")
+	}
+
+	log.Println("server running on " + *listenUrl)
+
+	if err := s.Run(ctx); err != nil {
+		return errors.Wrap(err, "failed to run server")
+	}
+
+	log.Println("server exiting")
+
+	return nil
+}
+
+func cleanFlow() error {
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	s := initStorage(c)
+
+	workspaces, caches, err := initLeftover(c, s, *olderThan)
+	if err != nil {
+		return errors.Wrap(err, "failed to init leftover")
+	}
+
+	for _, val := range workspaces {
+		if err := s.Remove(val); err != nil {
+			return errors.Wrap(err, "failed to remove")
+		}
+		fmt.Println("removed " + val)
+	}
+
+	for _, val := range caches {
+		if err := os.RemoveAll(val); err != nil {
+			return errors.Wrap(err, "failed to remove")
+		}
+		fmt.Println("removed " + val)
+	}
+
+	return nil
+}
+
+func diffRuns() error {
+	runs, err := loadRuns(*diffBase, *diffHead)
+	if err != nil {
+		return errors.Wrap(err, "failed to load runs")
+	}
+
+	printDiff(os.Stdout, runs[0], runs[1])
+
+	return nil
+}
+
+func printRunLogs() error {
+	runs, err := loadRuns(*logsId)
+	if err != nil {
+		return errors.Wrap(err, "failed to load runs")
+	}
+
+	return printLogs(os.Stdout, runs[0], *logsLinter)
+}
+
+// loadRuns loads runs of IDs in order from history.
+func loadRuns(ids ...string) ([]*proto.Run, error) {
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init config")
+	}
+
+	h, err := initHistory(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init history")
+	}
+
+	if h == nil {
+		return nil, errors.New("invalid history path")
+	}
+
+	buf, err := h.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list history")
+	}
+
+	runs := map[string]*proto.Run{}
+
+	for index := range buf {
+		runs[buf[index].ID] = &buf[index]
+	}
+
+	var ret []*proto.Run
+
+	for _, val := range ids {
+		r, ok := runs[val]
+		if !ok {
+			return nil, errors.New("failed to find run " + val)
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nil
+}
+
+func reproduceRun() error {
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	if c.Spec.Capsule.Path == "" {
+		return errors.New("invalid capsule path")
+	}
+
+	capsule, err := flow.LoadCapsule(c.Spec.Capsule.Path, *reproduceId)
+	if err != nil {
+		return errors.Wrap(err, "failed to load capsule")
+	}
+
+	p, err := initPolicy(&capsule.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to init policy")
+	}
+
+	// Files of capsule are on local disk regardless of storage of workspaces
+	l := lint.New(&lint.Config{Lints: capsule.Config.Spec.Lint})
+
+	cfg := flow.DefaultConfig()
+	cfg.Lint = l
+	cfg.Policy = p
+
+	buf, versions, err := flow.Reproduce(context.Background(), cfg, capsule)
+	if err != nil {
+		return errors.Wrap(err, "failed to reproduce")
+	}
+
+	printReproduce(os.Stdout, &capsule.Run, buf, versions)
+
+	return nil
+}
+
+func simulatePolicy() error {
+	current, err := currentPolicy(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to current policy")
+	}
+
+	proposed, err := initPolicyFile(*policyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init policy")
+	}
+
+	buf, err := initFindings(*findingsFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init findings")
+	}
+
+	r := policy.Simulate(policy.New(&policy.Config{Policy: current}), policy.New(&policy.Config{Policy: *proposed}), buf)
+	printSimulate(os.Stdout, r)
+
+	return nil
+}
+
+// currentPolicy returns policy in config, which is empty if no config is found since findings could be simulated
+// without config, while config set but missing or invalid fails.
+func currentPolicy(name string) (config.Policy, error) {
+	if _, err := findConfig(name); err != nil {
+		return config.Policy{}, nil
+	}
+
+	c, err := initConfig(name)
+	if err != nil {
+		return config.Policy{}, errors.Wrap(err, "failed to init config")
+	}
+
+	return c.Spec.Policy, nil
+}
+
+func reportSla() error {
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	h, err := initHistory(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init history")
+	}
+
+	if h == nil {
+		return errors.New("invalid history path")
+	}
+
+	runs, err := h.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list history")
+	}
+
+	buf := sla.New(&sla.Config{Rules: c.Spec.Sla.Rule}).Report(runs, time.Now())
+	if *slaBreach {
+		buf = sla.Breaches(buf)
+	}
+
+	printSla(os.Stdout, buf)
+
+	return nil
+}
+
+func listDead() error {
+	buf, err := server.Dead(*jobsUrl)
+	if err != nil {
+		return errors.Wrap(err, "failed to list dead jobs")
+	}
+
+	printDead(os.Stdout, buf)
+
+	return nil
+}
+
+func requeueDead() error {
+	job, err := server.Requeue(*jobsUrl, *requeueId)
+	if err != nil {
+		return errors.Wrap(err, "failed to requeue")
+	}
+
+	fmt.Printf("requeued %s as %s\n", *requeueId, job.ID)
+
+	return nil
+}
+
+func checkConfig() error {
+	c, err := initConfig(*configFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to init config")
+	}
+
+	buf, err := lint.Doctor(context.Background(), c.Spec.Lint, *doctorTimeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to doctor")
+	}
+
+	if !printDoctor(os.Stdout, buf) {
+		return errors.New("failed to be ready")
+	}
+
+	return nil
+}
+
+func verifyWorker() error {
+	buf := lint.Conform(context.Background(), *workerEndpoint, *workerTimeout)
+	if !printVerify(os.Stdout, buf) {
+		return errors.New("failed to conform")
+	}
+
+	return nil
+}
+
+// findConfig discovers config file in flag, environment variable and well-known paths in order.
+func findConfig(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	for _, val := range configPaths {
+		if _, err := os.Stat(val); err == nil {
+			return val, nil
+		}
+	}
+
+	return "", errors.New("failed to find config, set --config-file or " + envConfigFile)
+}
+
 func initConfig(name string) (*config.Config, error) {
 	c := config.New()
 	if c == nil {
 		return &config.Config{}, errors.New("failed to new")
 	}
 
+	name, err := findConfig(name)
+	if err != nil {
+		return c, errors.Wrap(err, "failed to find")
+	}
+
 	fi, err := os.Open(name)
 	if err != nil {
 		return c, errors.Wrap(err, "failed to open")
@@ -98,14 +562,83 @@ func initConfig(name string) (*config.Config, error) {
 	return c, nil
 }
 
-func initReview(cfg *config.Config) (review.Review, error) {
+func initPolicyFile(name string) (*config.Policy, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	p := config.Policy{}
+
+	if err := yaml.Unmarshal(buf, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return &p, nil
+}
+
+// initFindings loads findings in output of writer, or in plain list.
+func initFindings(name string) ([]proto.Format, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	if codeclimate.Match(buf) {
+		list, err := codeclimate.Unmarshal(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		return list, nil
+	}
+
+	var list []proto.Format
+
+	if err := json.Unmarshal(buf, &list); err == nil {
+		return list, nil
+	}
+
+	sheet := map[string][]proto.Format{}
+
+	if err := json.Unmarshal(buf, &sheet); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	for _, val := range sheet {
+		list = append(list, val...)
+	}
+
+	return list, nil
+}
+
+func initPolicy(cfg *config.Config) (policy.Policy, error) {
+	c := policy.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
+	}
+
+	c.Policy = cfg.Spec.Policy
+
+	return policy.New(c), nil
+}
+
+func initReview(cfg *config.Config, name, record, replay string) (review.Review, error) {
+	p, err := initPolicy(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init policy")
+	}
+
 	c := review.DefaultConfig()
 	if c == nil {
 		return nil, errors.New("failed to config")
 	}
 
-	c.Name = *codeReview
+	c.Name = name
+	c.Policy = p
+	c.Record = record
+	c.Replay = replay
 	c.Reviews = cfg.Spec.Review
+	c.Storage = initStorage(cfg)
 
 	return review.New(c), nil
 }
@@ -117,51 +650,466 @@ func initLint(cfg *config.Config) (lint.Lint, error) {
 	}
 
 	c.Lints = cfg.Spec.Lint
+	c.Snapshot = cfg.Spec.Snapshot
+	c.Storage = initStorage(cfg)
 
 	return lint.New(c), nil
 }
 
-func initWriter(_ *config.Config) (writer.Writer, error) {
+func initStorage(cfg *config.Config) storage.Storage {
+	c := storage.DefaultConfig()
+	c.Storage = cfg.Spec.Workspace.Storage
+
+	return storage.New(c)
+}
+
+func initWriter(_ *config.Config, snippets report.Snippets) (writer.Writer, error) {
 	c := writer.DefaultConfig()
 	if c == nil {
 		return nil, errors.New("failed to config")
 	}
 
+	c.Snippets = snippets
+
 	return writer.New(c), nil
 }
 
-func runFlow(c *config.Config, r review.Review, l lint.Lint, w writer.Writer) error {
-	cfg := flow.DefaultConfig()
-	if cfg == nil {
-		return errors.New("failed to config flow")
+func initExport(cfg *config.Config) (export.Export, error) {
+	c := export.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
 	}
 
-	cfg.Config = *c
-	cfg.Lint = l
-	cfg.Review = r
+	c.Exports = cfg.Spec.Export
+
+	return export.New(c), nil
+}
+
+func initHistory(cfg *config.Config) (history.History, error) {
+	if cfg.Spec.History.Path == "" {
+		return nil, nil
+	}
+
+	c := history.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
+	}
+
+	c.Path = cfg.Spec.History.Path
+
+	return history.New(c), nil
+}
+
+func initTelemetry(cfg *config.Config) (telemetry.Telemetry, error) {
+	if !cfg.Spec.Telemetry.Enabled {
+		return nil, nil
+	}
+
+	c := telemetry.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
+	}
+
+	for _, val := range cfg.Spec.Lint {
+		c.Lints = append(c.Lints, val.Name)
+	}
+
+	c.Url = cfg.Spec.Telemetry.Url
+	c.Version = config.Version
+
+	return telemetry.New(c), nil
+}
+
+func initFlow(ctx context.Context, cfg *config.Config, r review.Review, l lint.Lint, h history.History,
+	deadline time.Duration, keep bool, hooks ...flow.Hook) (flow.Flow, error) {
+	e, err := initExport(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init export")
+	}
+
+	t, err := initTelemetry(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init telemetry")
+	}
+
+	p, err := initPolicy(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init policy")
+	}
+
+	if err := flow.Check("", &cfg.Spec.Workspace); err != nil {
+		return nil, errors.Wrap(err, "failed to check workspace")
+	}
 
-	f := flow.New(context.Background(), cfg)
+	c := flow.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
+	}
+
+	c.Config = *cfg
+	c.Deadline = deadline
+	c.Export = e
+	c.History = h
+	c.Hooks = hooks
+	c.Keep = keep
+	c.Lint = l
+	c.Policy = p
+	c.Review = r
+	c.Storage = initStorage(cfg)
+	c.Telemetry = t
+
+	f := flow.New(ctx, c)
 	if f == nil {
-		return errors.New("failed to new flow")
+		return nil, errors.New("failed to new")
 	}
 
-	buf, err := f.Run(*commitHash)
+	return f, nil
+}
+
+// initLeftover lists workspaces under root and cache entries of reviews, which are older than age.
+// initLeftover lists workspaces in storage, and cache entries of reviews on disk, which are older than age.
+func initLeftover(cfg *config.Config, s storage.Storage, age time.Duration) (workspaces, caches []string, emsg error) {
+	workspaces, err := flow.Leftover(flow.Root("", &cfg.Spec.Workspace), &cfg.Spec.Workspace, s, age)
 	if err != nil {
-		return errors.Wrap(err, "failed to run flow")
+		return nil, nil, errors.Wrap(err, "failed to list workspaces")
+	}
+
+	for _, val := range cfg.Spec.Review {
+		if val.Cache.Path == "" {
+			continue
+		}
+		buf, err := review.Stale(val.Cache.Path, age)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to list cache")
+		}
+		caches = append(caches, buf...)
+	}
+
+	return workspaces, caches, nil
+}
+
+func initServer(ctx context.Context, cfg *config.Config, f flow.Flow, h history.History,
+	r review.Review) (server.Server, error) {
+	c := server.DefaultConfig()
+	if c == nil {
+		return nil, errors.New("failed to config")
+	}
+
+	c.Addr = *listenUrl
+	c.Config = *cfg
+	c.Flow = f
+	c.History = h
+	c.Leader = initLeader(cfg)
+
+	if cfg.Spec.EventLog.Path != "" {
+		ec := eventlog.DefaultConfig()
+		ec.Path = cfg.Spec.EventLog.Path
+		c.EventLog = eventlog.New(ec)
+	}
+
+	if h != nil {
+		mc := metrics.DefaultConfig()
+		if mc == nil {
+			return nil, errors.New("failed to config metrics")
+		}
+		mc.History = h
+		c.Metrics = metrics.New(mc)
+	}
+
+	if h != nil && cfg.Spec.Feedback.Keyword != "" {
+		fc := feedback.DefaultConfig()
+		if fc == nil {
+			return nil, errors.New("failed to config feedback")
+		}
+		fc.Feedback = cfg.Spec.Feedback
+		fc.History = h
+		fc.Review = r
+		c.Feedback = feedback.New(fc)
+	}
+
+	return server.New(ctx, c), nil
+}
+
+// initLeader elects leader by hostname, which is name of pod in Kubernetes, along with process ID.
+func initLeader(cfg *config.Config) leader.Leader {
+	host, _ := os.Hostname()
+
+	c := leader.DefaultConfig()
+	c.Identity = host + "-" + strconv.Itoa(os.Getpid())
+	c.Leader = cfg.Spec.Leader
+
+	return leader.New(c)
+}
+
+// initProgress reports progress in spinner on terminal, or in lines otherwise.
+func initProgress(quiet, json bool) progress.Progress {
+	if quiet {
+		return progress.New(&progress.Config{Mode: progress.ModeQuiet, Writer: os.Stderr})
+	}
+
+	// Lines of JSON are kept apart from logs on stderr
+	if json {
+		return progress.New(&progress.Config{Mode: progress.ModeJSON, Writer: os.Stdout})
+	}
+
+	return progress.New(&progress.Config{Mode: progress.Mode(os.Stderr), Writer: os.Stderr})
+}
+
+func runFlow(ctx context.Context, f flow.Flow, w writer.Writer) ([]proto.Format, error) {
+	buf, err := f.RunContext(flow.WithSource(ctx, flow.SourceCli), *commitHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run flow")
 	}
 
 	if *outputFile != "" {
 		if _, err = os.Stat(*outputFile); err == nil {
-			return errors.New("file already exists")
+			return nil, errors.New("file already exists")
 		}
 		if len(buf) != 0 {
 			if err = w.Run(*outputFile, buf); err != nil {
-				return errors.Wrap(err, "failed to run writer")
+				return nil, errors.Wrap(err, "failed to run writer")
 			}
-		} else {
-			return nil
 		}
 	}
 
+	return buf, nil
+}
+
+func initReport(noColor bool, snippets report.Snippets) report.Report {
+	c := report.DefaultConfig()
+	c.Color = c.Color && !noColor
+	c.Snippets = snippets
+
+	return report.New(c)
+}
+
+// snippetHook captures snippets of findings at postLint, before workspace is cleaned, into map shared with report and writer.
+type snippetHook struct {
+	snippets report.Snippets
+	storage  storage.Storage
+}
+
+func (h *snippetHook) Run(stage string, data *flow.HookData) error {
+	if stage == flow.HookPostLint {
+		for key, val := range report.Capture(h.storage, data.Dir, data.Findings) {
+			h.snippets[key] = val
+		}
+	}
+
+	return nil
+}
+
+// printLogs prints execution logs of lint in run, or of all lints under headers of their names.
+func printLogs(w io.Writer, run *proto.Run, name string) error {
+	if name != "" {
+		buf, ok := run.Logs[name]
+		if !ok {
+			return errors.New("failed to find logs of " + name)
+		}
+		_, _ = fmt.Fprintln(w, buf)
+		return nil
+	}
+
+	var names []string
+
+	for key := range run.Logs {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	for _, val := range names {
+		_, _ = fmt.Fprintf(w, "==> %s <==\n%s\n", val, run.Logs[val])
+	}
+
 	return nil
 }
+
+// printReproduce prints differences of findings and versions of lints between run and its reproduction.
+func printReproduce(w io.Writer, run *proto.Run, data []proto.Format, versions map[string]string) {
+	key := func(f proto.Format) string {
+		return fmt.Sprintf("%s:%d:%s:%s", f.File, f.Line, f.Type, f.Details)
+	}
+
+	_, _ = fmt.Fprintf(w, "findings: %d -> %d\n", len(run.Findings), len(data))
+
+	var names []string
+
+	for name := range versions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if run.Versions[name] != versions[name] {
+			_, _ = fmt.Fprintf(w, "version of %s: %s -> %s\n", name, run.Versions[name], versions[name])
+		}
+	}
+
+	added, removed := changed(run.Findings, data)
+
+	for _, val := range removed {
+		_, _ = fmt.Fprintf(w, "- %s\n", key(val))
+	}
+
+	for _, val := range added {
+		_, _ = fmt.Fprintf(w, "+ %s\n", key(val))
+	}
+}
+
+// printDiff prints findings added and removed from base to head run, grouped by rule, or by lint if without rule.
+func printDiff(w io.Writer, base, head *proto.Run) {
+	key := func(f proto.Format) string {
+		return fmt.Sprintf("%s:%d:%s:%s", f.File, f.Line, f.Type, f.Details)
+	}
+
+	group := func(f proto.Format) string {
+		if f.Rule != "" {
+			return f.Rule
+		}
+		return f.Lint
+	}
+
+	_, _ = fmt.Fprintf(w, "findings: %d -> %d\n", len(base.Findings), len(head.Findings))
+
+	added, removed := changed(base.Findings, head.Findings)
+
+	buf := map[string][]string{}
+
+	for _, val := range removed {
+		buf[group(val)] = append(buf[group(val)], "- "+key(val))
+	}
+
+	for _, val := range added {
+		buf[group(val)] = append(buf[group(val)], "+ "+key(val))
+	}
+
+	var names []string
+
+	for name := range buf {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "%s:\n", name)
+		for _, val := range buf[name] {
+			_, _ = fmt.Fprintf(w, "  %s\n", val)
+		}
+	}
+}
+
+// changed returns findings added and removed from before to after, regardless of lints and their versions.
+func changed(before, after []proto.Format) (added, removed []proto.Format) {
+	key := func(f proto.Format) string {
+		return fmt.Sprintf("%s:%d:%s:%s:%s", f.File, f.Line, f.Type, f.Rule, f.Details)
+	}
+
+	count := map[string]int{}
+
+	for _, val := range after {
+		count[key(val)]++
+	}
+
+	for _, val := range before {
+		if count[key(val)] > 0 {
+			count[key(val)]--
+			continue
+		}
+		removed = append(removed, val)
+	}
+
+	for _, val := range after {
+		if count[key(val)] > 0 {
+			count[key(val)]--
+			added = append(added, val)
+		}
+	}
+
+	return added, removed
+}
+
+func printSimulate(w io.Writer, r *policy.Result) {
+	vote := func(approve bool) string {
+		if approve {
+			return "approve"
+		}
+		return "disapprove"
+	}
+
+	_, _ = fmt.Fprintf(w, "findings: %d -> %d\n", len(r.Before), len(r.After))
+	_, _ = fmt.Fprintf(w, "vote: %s -> %s\n", vote(r.ApproveBefore), vote(r.ApproveAfter))
+
+	for _, val := range r.Removed {
+		_, _ = fmt.Fprintf(w, "- %s:%d:%s:%s\n", val.File, val.Line, val.Type, val.Details)
+	}
+
+	for _, val := range r.Added {
+		_, _ = fmt.Fprintf(w, "+ %s:%d:%s:%s\n", val.File, val.Line, val.Type, val.Details)
+	}
+}
+
+func printSla(w io.Writer, data []sla.Entry) {
+	for _, val := range data {
+		breach := ""
+		if val.Breach {
+			breach = " breach"
+		}
+		_, _ = fmt.Fprintf(w, "%s %d %s:%d:%s:%s patchsets=%d age=%s%s\n", val.Repo, val.Change, val.Finding.File,
+			val.Finding.Line, val.Finding.Type, val.Finding.Details, val.Patchsets, val.Age.Round(time.Second), breach)
+	}
+}
+
+func printDead(w io.Writer, data []server.Job) {
+	for _, val := range data {
+		_, _ = fmt.Fprintf(w, "%s %s source=%s attempts=%d: %s\n", val.ID, val.Commit, val.Source, val.Attempts, val.Error)
+	}
+}
+
+func printDoctor(w io.Writer, data []lint.Readiness) bool {
+	ready := true
+
+	for _, val := range data {
+		state := "READY"
+		if !val.Ready() {
+			ready, state = false, "NOT READY"
+		}
+		line := state + " " + val.Lint
+		if val.Endpoint != "" {
+			line += " " + val.Endpoint
+		}
+		if val.Version != "" {
+			line += " version " + val.Version
+		}
+		if len(val.Checks) == 0 {
+			line += " (built-in)"
+		}
+		_, _ = fmt.Fprintln(w, line)
+		for _, c := range val.Checks {
+			if c.Err != nil {
+				_, _ = fmt.Fprintf(w, "  FAIL %s (%s): %s\n", c.Name, c.Duration.Round(time.Millisecond), c.Err.Error())
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "  PASS %s (%s)\n", c.Name, c.Duration.Round(time.Millisecond))
+		}
+	}
+
+	return ready
+}
+
+func printVerify(w io.Writer, data []lint.Check) bool {
+	pass := true
+
+	for _, val := range data {
+		if val.Err != nil {
+			pass = false
+			_, _ = fmt.Fprintf(w, "FAIL %s (%s): %s\n", val.Name, val.Duration.Round(time.Millisecond), val.Err.Error())
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "PASS %s (%s)\n", val.Name, val.Duration.Round(time.Millisecond))
+	}
+
+	return pass
+}
@@ -13,9 +13,23 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/server"
+	"github.com/craftslab/lintflow/sla"
 )
 
 func TestInitConfig(t *testing.T) {
@@ -35,10 +49,19 @@ func TestInitReview(t *testing.T) {
 	c, err := initConfig("../tests/config.yml")
 	assert.Equal(t, nil, err)
 
-	_, err = initReview(c)
+	_, err = initReview(c, "gerrit", "", "")
 	assert.Equal(t, nil, err)
 }
 
+func TestFindConfig(t *testing.T) {
+	name, err := findConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "../tests/config.yml", name)
+
+	_, err = findConfig("")
+	assert.NotEqual(t, nil, err)
+}
+
 func TestInitLint(t *testing.T) {
 	c, err := initConfig("../tests/config.yml")
 	assert.Equal(t, nil, err)
@@ -47,10 +70,240 @@ func TestInitLint(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestInitServer(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	r, err := initReview(c, "gerrit", "", "")
+	assert.Equal(t, nil, err)
+
+	l, err := initLint(c)
+	assert.Equal(t, nil, err)
+
+	f, err := initFlow(context.Background(), c, r, l, nil, 0, false)
+	assert.Equal(t, nil, err)
+
+	_, err = initServer(context.Background(), c, f, nil, r)
+	assert.Equal(t, nil, err)
+}
+
+func TestInitTelemetry(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	tm, err := initTelemetry(c)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, tm)
+
+	c.Spec.Telemetry.Enabled = true
+
+	tm, err = initTelemetry(c)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, nil, tm)
+}
+
+func TestInitHistory(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	h, err := initHistory(c)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, h)
+
+	c.Spec.History.Path = "history.jsonl"
+
+	h, err = initHistory(c)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, nil, h)
+}
+
+func TestInitExport(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	_, err = initExport(c)
+	assert.Equal(t, nil, err)
+}
+
 func TestInitWriter(t *testing.T) {
 	c, err := initConfig("../tests/config.yml")
 	assert.Equal(t, nil, err)
 
-	_, err = initWriter(c)
+	_, err = initWriter(c, nil)
+	assert.Equal(t, nil, err)
+}
+
+func TestSimulatePolicy(t *testing.T) {
+	d, err := ioutil.TempDir("", "simulate")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	findings := filepath.Join(d, "report.json")
+	err = ioutil.WriteFile(findings, []byte(`{"lintflow":[{"file":"main.go","line":1,"type":"Error","details":"error"}]}`), 0600)
+	assert.Equal(t, nil, err)
+
+	buf, err := initFindings(findings)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+
+	quality := filepath.Join(d, "gl-code-quality-report.json")
+	err = ioutil.WriteFile(quality, []byte(`[{"check_name":"G101","description":"secret","location":{"path":"main.go"}}]`), 0600)
+	assert.Equal(t, nil, err)
+
+	q, err := initFindings(quality)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "G101", q[0].Rule)
+
+	name := filepath.Join(d, "policy.yml")
+	err = ioutil.WriteFile(name, []byte("threshold:\n  Error: 2\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	p, err := initPolicyFile(name)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, p.Threshold["Error"])
+
+	r := policy.Simulate(policy.New(policy.DefaultConfig()), policy.New(&policy.Config{Policy: *p}), buf)
+
+	var b bytes.Buffer
+	printSimulate(&b, r)
+	assert.Equal(t, "findings: 1 -> 1\nvote: disapprove -> approve\n", b.String())
+
+	_, err = currentPolicy(filepath.Join(d, "missing.yml"))
+	assert.NotEqual(t, nil, err)
+
+	invalid := filepath.Join(d, "invalid.yml")
+	err = ioutil.WriteFile(invalid, []byte("spec: ["), 0600)
+	assert.Equal(t, nil, err)
+
+	_, err = currentPolicy(invalid)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestPrintReproduce(t *testing.T) {
+	run := proto.Run{
+		Findings: []proto.Format{{File: "main.go", Line: 1, Type: "Error", Details: "error"}},
+		Versions: map[string]string{"lintgo": "1.0"},
+	}
+
+	var b bytes.Buffer
+	printReproduce(&b, &run, []proto.Format{{File: "main.go", Line: 2, Type: "Error", Details: "error"}},
+		map[string]string{"lintgo": "1.1"})
+	assert.Equal(t, "findings: 1 -> 1\nversion of lintgo: 1.0 -> 1.1\n- main.go:1:Error:error\n+ main.go:2:Error:error\n", b.String())
+}
+
+func TestPrintDiff(t *testing.T) {
+	base := proto.Run{Findings: []proto.Format{
+		{File: "main.go", Line: 1, Type: "Error", Details: "error", Rule: "errcheck", Version: "1.0"},
+		{File: "main.go", Line: 2, Type: "Warn", Details: "unused", Rule: "unused"},
+		{File: "main.c", Line: 3, Type: "Error", Details: "leak", Lint: "lintcpp"},
+	}}
+	head := proto.Run{Findings: []proto.Format{
+		{File: "main.go", Line: 1, Type: "Error", Details: "error", Rule: "errcheck", Version: "1.1"},
+		{File: "main.go", Line: 5, Type: "Error", Details: "error", Rule: "errcheck"},
+		{File: "main.c", Line: 4, Type: "Error", Details: "leak", Lint: "lintcpp"},
+	}}
+
+	var b bytes.Buffer
+	printDiff(&b, &base, &head)
+	assert.Equal(t, "findings: 3 -> 3\n"+
+		"errcheck:\n  + main.go:5:Error:error\n"+
+		"lintcpp:\n  - main.c:3:Error:leak\n  + main.c:4:Error:leak\n"+
+		"unused:\n  - main.go:2:Warn:unused\n", b.String())
+}
+
+func TestPrintLogs(t *testing.T) {
+	run := proto.Run{Logs: map[string]string{"eslint": "1 file", "lintcpp": "text"}}
+
+	var b bytes.Buffer
+
+	err := printLogs(&b, &run, "eslint")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1 file\n", b.String())
+
+	b.Reset()
+	err = printLogs(&b, &run, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "==> eslint <==\n1 file\n==> lintcpp <==\ntext\n", b.String())
+
+	err = printLogs(&b, &run, "invalid")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestPrintDead(t *testing.T) {
+	var b bytes.Buffer
+
+	printDead(&b, []server.Job{{ID: "a1", Commit: "foo", Source: "webhook", Attempts: 3, Error: "refused"}})
+	assert.Equal(t, "a1 foo source=webhook attempts=3: refused\n", b.String())
+}
+
+func TestPrintSla(t *testing.T) {
+	var b bytes.Buffer
+
+	printSla(&b, []sla.Entry{
+		{Age: time.Hour, Breach: true, Change: 12, Finding: proto.Format{File: "main.go", Line: 3, Type: "Error", Details: "error"},
+			Patchsets: 3, Repo: "foo"},
+		{Age: time.Minute, Change: 13, Finding: proto.Format{File: "main.c", Line: 1, Type: "Warn", Details: "leak"},
+			Patchsets: 1, Repo: "bar"},
+	})
+	assert.Equal(t, "foo 12 main.go:3:Error:error patchsets=3 age=1h0m0s breach\n"+
+		"bar 13 main.c:1:Warn:leak patchsets=1 age=1m0s\n", b.String())
+}
+
+func TestPrintVerify(t *testing.T) {
+	var b bytes.Buffer
+
+	assert.Equal(t, true, printVerify(&b, []lint.Check{{Name: lint.CheckConnect, Duration: time.Millisecond}}))
+	assert.Equal(t, "PASS connect (1ms)\n", b.String())
+
+	b.Reset()
+	assert.Equal(t, false, printVerify(&b, []lint.Check{{Name: lint.CheckConnect, Err: errors.New("refused")}}))
+	assert.Equal(t, "FAIL connect (0s): refused\n", b.String())
+}
+
+func TestPrintDoctor(t *testing.T) {
+	var b bytes.Buffer
+
+	assert.Equal(t, true, printDoctor(&b, []lint.Readiness{
+		{Checks: []lint.Check{{Name: lint.CheckConnect, Duration: time.Millisecond}}, Endpoint: "127.0.0.1:9090",
+			Lint: "lintcpp", Version: "1.2.3"},
+		{Lint: "markdown"},
+	}))
+	assert.Equal(t, "READY lintcpp 127.0.0.1:9090 version 1.2.3\n  PASS connect (1ms)\nREADY markdown (built-in)\n",
+		b.String())
+
+	b.Reset()
+	assert.Equal(t, false, printDoctor(&b, []lint.Readiness{
+		{Checks: []lint.Check{{Name: lint.CheckCommand, Err: errors.New("not found")}}, Lint: "lintsh"},
+	}))
+	assert.Equal(t, "NOT READY lintsh\n  FAIL command (0s): not found\n", b.String())
+}
+
+func TestInitLeftover(t *testing.T) {
+	d, err := ioutil.TempDir("", "cmd")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = os.MkdirAll(filepath.Join(d, "root", "lintflow-1234"), os.ModePerm)
+	assert.Equal(t, nil, err)
+
+	err = os.MkdirAll(filepath.Join(d, "cache"), os.ModePerm)
+	assert.Equal(t, nil, err)
+
+	err = ioutil.WriteFile(filepath.Join(d, "cache", "1234.json"), []byte("{}"), os.ModePerm)
+	assert.Equal(t, nil, err)
+
+	c := &config.Config{}
+	c.Spec.Workspace.Root = filepath.Join(d, "root")
+	c.Spec.Review = []config.Review{{Cache: config.Cache{Path: filepath.Join(d, "cache")}}}
+
+	workspaces, caches, err := initLeftover(c, initStorage(c), 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{filepath.Join(d, "root", "lintflow-1234")}, workspaces)
+	assert.Equal(t, []string{filepath.Join(d, "cache", "1234.json")}, caches)
+
+	workspaces, caches, err = initLeftover(c, initStorage(c), time.Hour)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(workspaces)+len(caches))
 }
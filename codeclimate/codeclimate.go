@@ -0,0 +1,241 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeclimate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	Ext = ".codeclimate.json"
+)
+
+const (
+	categorySecurity = "Security"
+	categoryStyle    = "Style"
+	checkName        = "lintflow"
+	issueType        = "issue"
+	pathChange       = "."
+)
+
+const (
+	severityBlocker  = "blocker"
+	severityCritical = "critical"
+	severityInfo     = "info"
+	severityMajor    = "major"
+	severityMinor    = "minor"
+)
+
+// Issue is issue in Code Climate format, see https://github.com/codeclimate/platform/blob/master/spec/analyzers/SPEC.md
+type Issue struct {
+	Categories  []string `json:"categories"`
+	CheckName   string   `json:"check_name"`
+	Description string   `json:"description"`
+	Fingerprint string   `json:"fingerprint"`
+	Location    Location `json:"location"`
+	Severity    string   `json:"severity"`
+	Type        string   `json:"type"`
+}
+
+type Location struct {
+	Lines     *Lines     `json:"lines,omitempty"`
+	Path      string     `json:"path"`
+	Positions *Positions `json:"positions,omitempty"`
+}
+
+type Lines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+type Positions struct {
+	Begin Position `json:"begin"`
+	End   Position `json:"end"`
+}
+
+type Position struct {
+	Column int `json:"column,omitempty"`
+	Line   int `json:"line"`
+}
+
+var (
+	severities = map[string]string{
+		proto.TypeError: severityMajor,
+		proto.TypeInfo:  severityInfo,
+		proto.TypeWarn:  severityMinor,
+	}
+
+	types = map[string]string{
+		severityBlocker:  proto.TypeError,
+		severityCritical: proto.TypeError,
+		severityInfo:     proto.TypeInfo,
+		severityMajor:    proto.TypeError,
+		severityMinor:    proto.TypeWarn,
+	}
+)
+
+// Marshal marshals findings into issues in Code Climate format, e.g. for Code Quality of GitLab.
+func Marshal(data []proto.Format) ([]byte, error) {
+	ret := []Issue{}
+
+	for _, val := range data {
+		ret = append(ret, issue(val))
+	}
+
+	buf, err := json.Marshal(ret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	return buf, nil
+}
+
+// Unmarshal unmarshals issues in Code Climate format into findings, in which issues are required to have description
+// and path in location.
+func Unmarshal(data []byte) ([]proto.Format, error) {
+	var buf []Issue
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	ret := []proto.Format{}
+
+	for index, val := range buf {
+		f, err := format(val)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("invalid issue %d", index))
+		}
+		ret = append(ret, f)
+	}
+
+	return ret, nil
+}
+
+// Match reports whether data is list of issues in Code Climate format, instead of findings in Errorformat.
+func Match(data []byte) bool {
+	var buf []map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &buf); err != nil || len(buf) == 0 {
+		return false
+	}
+
+	_, name := buf[0]["check_name"]
+	_, location := buf[0]["location"]
+
+	return name || location
+}
+
+func issue(data proto.Format) Issue {
+	name := data.Rule
+	if name == "" {
+		name = data.Lint
+	}
+
+	if name == "" {
+		name = checkName
+	}
+
+	category := categoryStyle
+	if data.Category == proto.CategorySecurity {
+		category = categorySecurity
+	}
+
+	severity, ok := severities[data.Type]
+	if !ok {
+		severity = severityInfo
+	}
+
+	path, line := data.File, data.Line
+
+	// Begin of lines is required, which is the first line for finding on file or change
+	if data.Scope == proto.ScopeChange || path == "" {
+		path = pathChange
+	}
+
+	if line <= 0 {
+		line = 1
+	}
+
+	return Issue{
+		Categories:  []string{category},
+		CheckName:   name,
+		Description: data.Details,
+		Fingerprint: fingerprint(data),
+		Location:    Location{Lines: &Lines{Begin: line, End: line}, Path: path},
+		Severity:    severity,
+		Type:        issueType,
+	}
+}
+
+func format(data Issue) (proto.Format, error) {
+	if data.Type != "" && !strings.EqualFold(data.Type, issueType) {
+		return proto.Format{}, errors.New("invalid type: " + data.Type)
+	}
+
+	if data.Description == "" {
+		return proto.Format{}, errors.New("missing description")
+	}
+
+	if data.Location.Path == "" {
+		return proto.Format{}, errors.New("missing path")
+	}
+
+	t, ok := types[data.Severity]
+	if !ok {
+		t = proto.TypeWarn
+	}
+
+	ret := proto.Format{
+		Details: data.Description,
+		File:    data.Location.Path,
+		Rule:    data.CheckName,
+		Type:    t,
+	}
+
+	if data.Location.Lines != nil {
+		ret.Line = data.Location.Lines.Begin
+	} else if data.Location.Positions != nil {
+		ret.Line = data.Location.Positions.Begin.Line
+	}
+
+	for _, val := range data.Categories {
+		if val == categorySecurity {
+			ret.Category = proto.CategorySecurity
+		}
+	}
+
+	if ret.File == pathChange {
+		ret.File, ret.Line, ret.Scope = "", 0, proto.ScopeChange
+	} else if ret.Line <= 0 {
+		ret.Line, ret.Scope = 0, proto.ScopeFile
+	}
+
+	return ret, nil
+}
+
+// fingerprint identifies finding regardless of version of lint, for issues to be tracked across runs.
+func fingerprint(data proto.Format) string {
+	h := sha256.Sum256([]byte(strings.Join([]string{data.Lint, data.Rule, data.File, fmt.Sprint(data.Line), data.Details},
+		"\x00")))
+
+	return hex.EncodeToString(h[:16])
+}
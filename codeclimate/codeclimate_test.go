@@ -0,0 +1,89 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeclimate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestMarshal(t *testing.T) {
+	buf, err := Marshal(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[]", string(buf))
+
+	buf, err = Marshal([]proto.Format{
+		{Details: "text", File: "main.go", Line: 3, Lint: "lintgo", Rule: "errcheck", Type: proto.TypeError},
+		{Category: proto.CategorySecurity, Details: "secret", File: "main.go", Lint: "lintgo", Scope: proto.ScopeFile,
+			Type: proto.TypeWarn},
+		{Details: "missing CODEOWNERS entry", Scope: proto.ScopeChange, Type: proto.TypeInfo},
+	})
+	assert.Equal(t, nil, err)
+
+	data, err := Unmarshal(buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{Details: "text", File: "main.go", Line: 3, Rule: "errcheck", Type: proto.TypeError},
+		{Category: proto.CategorySecurity, Details: "secret", File: "main.go", Line: 1, Rule: "lintgo", Type: proto.TypeWarn},
+		{Details: "missing CODEOWNERS entry", Rule: checkName, Scope: proto.ScopeChange, Type: proto.TypeInfo},
+	}, data)
+}
+
+func TestIssue(t *testing.T) {
+	i := issue(proto.Format{Details: "text", File: "main.go", Line: 3, Lint: "lintgo", Type: proto.TypeWarn})
+	assert.Equal(t, []string{categoryStyle}, i.Categories)
+	assert.Equal(t, "lintgo", i.CheckName)
+	assert.Equal(t, &Lines{Begin: 3, End: 3}, i.Location.Lines)
+	assert.Equal(t, severityMinor, i.Severity)
+	assert.Equal(t, 32, len(i.Fingerprint))
+
+	// Fingerprint is stable across versions of lint
+	assert.Equal(t, i.Fingerprint, issue(proto.Format{Details: "text", File: "main.go", Line: 3, Lint: "lintgo",
+		Type: proto.TypeWarn, Version: "1.1"}).Fingerprint)
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := `[{"type":"issue","check_name":"no-unused-vars","description":"unused","categories":["Bug Risk"],` +
+		`"location":{"path":"src/a.js","positions":{"begin":{"line":7,"column":3},"end":{"line":7,"column":9}}},` +
+		`"severity":"critical","fingerprint":"abc"},` +
+		`{"check_name":"G101","description":"secret","categories":["Security"],"location":{"path":"main.go","lines":{"begin":2}}}]`
+
+	buf, err := Unmarshal([]byte(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{Details: "unused", File: "src/a.js", Line: 7, Rule: "no-unused-vars", Type: proto.TypeError},
+		{Category: proto.CategorySecurity, Details: "secret", File: "main.go", Line: 2, Rule: "G101", Type: proto.TypeWarn},
+	}, buf)
+
+	_, err = Unmarshal([]byte(`[{"description":"text","location":{}}]`))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Unmarshal([]byte(`[{"location":{"path":"main.go"}}]`))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Unmarshal([]byte(`[{"type":"measurement","description":"text","location":{"path":"main.go"}}]`))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Unmarshal([]byte(`{}`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, true, Match([]byte(`[{"check_name":"G101"}]`)))
+	assert.Equal(t, false, Match([]byte(`[{"file":"main.go","line":1}]`)))
+	assert.Equal(t, false, Match([]byte(`[]`)))
+	assert.Equal(t, false, Match([]byte(`{"lint":[]}`)))
+}
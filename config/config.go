@@ -24,20 +24,213 @@ type MetaData struct {
 }
 
 type Spec struct {
-	Lint   []Lint   `yaml:"lint"`
-	Review []Review `yaml:"review"`
+	Batch     Batch               `yaml:"batch"`
+	Capsule   Capsule             `yaml:"capsule"`
+	Carry     Carry               `yaml:"carry"`
+	Command   Command             `yaml:"command"`
+	Deadline  int                 `yaml:"deadline"`
+	Docs      map[string]string   `yaml:"docs"`
+	EventLog  EventLog            `yaml:"eventLog"`
+	Export    []Export            `yaml:"export"`
+	Feedback  Feedback            `yaml:"feedback"`
+	Freeze    []Freeze            `yaml:"freeze"`
+	Group     map[string][]string `yaml:"group"`
+	History   History             `yaml:"history"`
+	Hook      []Hook              `yaml:"hook"`
+	Kind      Kind                `yaml:"kind"`
+	Leader    Leader              `yaml:"leader"`
+	Lint      []Lint              `yaml:"lint"`
+	Metrics   Metrics             `yaml:"metrics"`
+	Mode      Mode                `yaml:"mode"`
+	Policy    Policy              `yaml:"policy"`
+	Queue     Queue               `yaml:"queue"`
+	Review    []Review            `yaml:"review"`
+	Size      Size                `yaml:"size"`
+	Snapshot  Snapshot            `yaml:"snapshot"`
+	Sla       Sla                 `yaml:"sla"`
+	Telemetry Telemetry           `yaml:"telemetry"`
+	Workspace Workspace           `yaml:"workspace"`
+}
+
+type Affinity struct {
+	Host string   `yaml:"host"`
+	Port int      `yaml:"port"`
+	Repo []string `yaml:"repo"`
+}
+
+type Batch struct {
+	Author []string `yaml:"author"`
+	Window int      `yaml:"window"`
+}
+
+type Buf struct {
+	Lint bool `yaml:"lint"`
+}
+
+type Bundle struct {
+	Override bool   `yaml:"override"`
+	Path     string `yaml:"path"`
+}
+
+type Cache struct {
+	Path       string `yaml:"path"`
+	Revalidate bool   `yaml:"revalidate"`
+}
+
+type Canary struct {
+	Enabled bool `yaml:"enabled"`
+	Period  int  `yaml:"period"`
+}
+
+type Capsule struct {
+	Path string `yaml:"path"`
+}
+
+type Carry struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type Command struct {
+	Group   []string            `yaml:"group"`
+	Profile map[string][]string `yaml:"profile"`
+}
+
+type Dco struct {
+	Cla string `yaml:"cla"`
+}
+
+type Dedup struct {
+	Enabled    bool    `yaml:"enabled"`
+	Range      int     `yaml:"range"`
+	Similarity float64 `yaml:"similarity"`
+}
+
+type Demote struct {
+	Action   string   `yaml:"action"`
+	Findings int      `yaml:"findings"`
+	Notify   string   `yaml:"notify"`
+	Override []string `yaml:"override"`
+	Ratio    float64  `yaml:"ratio"`
+}
+
+type Description struct {
+	Forbidden []string `yaml:"forbidden"`
+	Length    int      `yaml:"length"`
+	Links     bool     `yaml:"links"`
+	Sections  []string `yaml:"sections"`
+}
+
+type Exclude struct {
+	File []string `yaml:"file"`
+	Rule []string `yaml:"rule"`
+	Type []string `yaml:"type"`
+}
+
+type EventLog struct {
+	Path string `yaml:"path"`
+}
+
+type Export struct {
+	Batch    int    `yaml:"batch"`
+	Create   bool   `yaml:"create"`
+	Database string `yaml:"database"`
+	Name     string `yaml:"name"`
+	Pass     string `yaml:"pass"`
+	Project  string `yaml:"project"`
+	Retry    int    `yaml:"retry"`
+	Token    string `yaml:"token"`
+	Url      string `yaml:"url"`
+	User     string `yaml:"user"`
+}
+
+type Feedback struct {
+	Demote   Demote `yaml:"demote"`
+	Interval int    `yaml:"interval"`
+	Keyword  string `yaml:"keyword"`
+	Window   int    `yaml:"window"`
+}
+
+type Freeze struct {
+	Day      []string `yaml:"day"`
+	End      string   `yaml:"end"`
+	Project  string   `yaml:"project"`
+	Start    string   `yaml:"start"`
+	Timezone string   `yaml:"timezone"`
+}
+
+type History struct {
+	Path string `yaml:"path"`
+}
+
+type Hook struct {
+	Command []string `yaml:"command"`
+	Stage   string   `yaml:"stage"`
+	Timeout int      `yaml:"timeout"`
+}
+
+type Metrics struct {
+	Interval int `yaml:"interval"`
+}
+
+type Label struct {
+	Approval    string   `yaml:"approval"`
+	Disapproval string   `yaml:"disapproval"`
+	Lint        []string `yaml:"lint"`
+	Name        string   `yaml:"name"`
+}
+
+type Kind struct {
+	Skip []string `yaml:"skip"`
+}
+
+type Leader struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	Ttl       int    `yaml:"ttl"`
+	Url       string `yaml:"url"`
+}
+
+type Lfs struct {
+	Pass string `yaml:"pass"`
+	Size int64  `yaml:"size"`
+	Url  string `yaml:"url"`
+	User string `yaml:"user"`
 }
 
 type Lint struct {
-	Filter  Filter `yaml:"filter"`
-	Host    string `yaml:"host"`
-	Name    string `yaml:"name"`
-	Port    int    `yaml:"port"`
-	Timeout int    `yaml:"timeout"`
+	Affinity    []Affinity        `yaml:"affinity"`
+	Binary      bool              `yaml:"binary"`
+	Buf         Buf               `yaml:"buf"`
+	Builtin     string            `yaml:"builtin"`
+	Bundle      Bundle            `yaml:"bundle"`
+	Canary      Canary            `yaml:"canary"`
+	Command     []string          `yaml:"command"`
+	Dco         Dco               `yaml:"dco"`
+	Description Description       `yaml:"description"`
+	Depends     []string          `yaml:"depends"`
+	Env         map[string]string `yaml:"env"`
+	Failure     string            `yaml:"failure"`
+	Files       map[string]string `yaml:"files"`
+	Filter      Filter            `yaml:"filter"`
+	Host        string            `yaml:"host"`
+	Markdown    Markdown          `yaml:"markdown"`
+	Migration   Migration         `yaml:"migration"`
+	Name        string            `yaml:"name"`
+	Parent      bool              `yaml:"parent"`
+	Port        int               `yaml:"port"`
+	Sandbox     Sandbox           `yaml:"sandbox"`
+	Shadow      Shadow            `yaml:"shadow"`
+	Signature   Signature         `yaml:"signature"`
+	Submodules  []Submodule       `yaml:"submodules"`
+	Timeout     int               `yaml:"timeout"`
+	Ui          Ui                `yaml:"ui"`
+	Workdir     string            `yaml:"workdir"`
 }
 
 type Filter struct {
 	Include Include `yaml:"include"`
+	When    string  `yaml:"when"`
 }
 
 type Include struct {
@@ -46,20 +239,172 @@ type Include struct {
 	Repo      []string `yaml:"repo"`
 }
 
+type Markdown struct {
+	Cache int  `yaml:"cache"`
+	Links bool `yaml:"links"`
+}
+
+type Migration struct {
+	Path []string `yaml:"path"`
+}
+
+type Mode struct {
+	Default string     `yaml:"default"`
+	Rule    []ModeRule `yaml:"rule"`
+}
+
+type ModeRule struct {
+	Author  string `yaml:"author"`
+	Mode    string `yaml:"mode"`
+	Project string `yaml:"project"`
+	Source  string `yaml:"source"`
+}
+
+type Policy struct {
+	Coverage  string         `yaml:"coverage"`
+	Exclude   Exclude        `yaml:"exclude"`
+	Notify    bool           `yaml:"notify"`
+	Severity  []Severity     `yaml:"severity"`
+	Threshold map[string]int `yaml:"threshold"`
+}
+
+type Queue struct {
+	Backoff     int           `yaml:"backoff"`
+	Concurrency int           `yaml:"concurrency"`
+	Debounce    int           `yaml:"debounce"`
+	Retry       int           `yaml:"retry"`
+	Size        int           `yaml:"size"`
+	Source      []QueueSource `yaml:"source"`
+	Supersede   bool          `yaml:"supersede"`
+}
+
+type QueueSource struct {
+	Concurrency int    `yaml:"concurrency"`
+	Name        string `yaml:"name"`
+	Size        int    `yaml:"size"`
+}
+
 type Review struct {
+	Auth      string            `yaml:"auth"`
+	Cache     Cache             `yaml:"cache"`
+	Header    map[string]string `yaml:"header"`
+	Host      string            `yaml:"host"`
+	Name      string            `yaml:"name"`
+	Pass      string            `yaml:"pass"`
+	Path      string            `yaml:"path"`
+	Port      int               `yaml:"port"`
+	Replica   []string          `yaml:"replica"`
+	Repo      string            `yaml:"repo"`
+	Security  Security          `yaml:"security"`
+	Trace     bool              `yaml:"trace"`
+	Transport Transport         `yaml:"transport"`
+	Url       string            `yaml:"url"`
+	User      string            `yaml:"user"`
+	UserAgent string            `yaml:"userAgent"`
+	Vote      Vote              `yaml:"vote"`
+}
+
+type Sandbox struct {
+	Kind     string   `yaml:"kind"`
+	Mount    []string `yaml:"mount"`
+	Network  bool     `yaml:"network"`
+	Seccomp  string   `yaml:"seccomp"`
+	Writable bool     `yaml:"writable"`
+}
+
+type Security struct {
+	Hashtag   string   `yaml:"hashtag"`
+	Reviewers []string `yaml:"reviewers"`
+	Rule      []string `yaml:"rule"`
+}
+
+type Severity struct {
+	File    []string `yaml:"file"`
+	Project string   `yaml:"project"`
+	Rule    string   `yaml:"rule"`
+	Type    string   `yaml:"type"`
+}
+
+type Shadow struct {
 	Host string `yaml:"host"`
-	Name string `yaml:"name"`
-	Pass string `yaml:"pass"`
 	Port int    `yaml:"port"`
-	User string `yaml:"user"`
-	Vote Vote   `yaml:"vote"`
+}
+
+type Signature struct {
+	Certificate bool `yaml:"certificate"`
+	Signoff     bool `yaml:"signoff"`
+}
+
+type Submodule struct {
+	Lockfile string `yaml:"lockfile"`
+	Path     string `yaml:"path"`
+	Project  string `yaml:"project"`
+}
+
+type Size struct {
+	Files []int `yaml:"files"`
+	Lines []int `yaml:"lines"`
+}
+
+type Sla struct {
+	Escalate bool      `yaml:"escalate"`
+	Rule     []SlaRule `yaml:"rule"`
+}
+
+type SlaRule struct {
+	Age       int    `yaml:"age"`
+	Patchsets int    `yaml:"patchsets"`
+	Type      string `yaml:"type"`
+}
+
+type Snapshot struct {
+	Path string `yaml:"path"`
+}
+
+type Storage struct {
+	Bucket   string `yaml:"bucket"`
+	Endpoint string `yaml:"endpoint"`
+	Key      string `yaml:"key"`
+	Kind     string `yaml:"kind"`
+	Region   string `yaml:"region"`
+	Secret   string `yaml:"secret"`
+}
+
+type Telemetry struct {
+	Enabled bool   `yaml:"enabled"`
+	Url     string `yaml:"url"`
+}
+
+type Transport struct {
+	Http2               *bool `yaml:"http2"`
+	IdleTimeout         int   `yaml:"idleTimeout"`
+	MaxIdleConns        int   `yaml:"maxIdleConns"`
+	MaxIdleConnsPerHost int   `yaml:"maxIdleConnsPerHost"`
+	RateLimit           int   `yaml:"rateLimit"`
+}
+
+type Ui struct {
+	Attributes []string `yaml:"attributes"`
+	Ignore     []string `yaml:"ignore"`
+	Images     []string `yaml:"images"`
+	Wrappers   []string `yaml:"wrappers"`
 }
 
 type Vote struct {
-	Approval    string `yaml:"approval"`
-	Disapproval string `yaml:"disapproval"`
-	Label       string `yaml:"label"`
-	Message     string `yaml:"message"`
+	Approval    string  `yaml:"approval"`
+	Attention   bool    `yaml:"attention"`
+	CommentSize int     `yaml:"commentSize"`
+	Dedup       Dedup   `yaml:"dedup"`
+	Disapproval string  `yaml:"disapproval"`
+	Draft       bool    `yaml:"draft"`
+	Label       string  `yaml:"label"`
+	Labels      []Label `yaml:"labels"`
+	Link        string  `yaml:"link"`
+	Message     string  `yaml:"message"`
+	Pack        string  `yaml:"pack"`
+	PayloadSize int     `yaml:"payloadSize"`
+	Range       int     `yaml:"range"`
+	Robot       bool    `yaml:"robot"`
 }
 
 var (
@@ -70,3 +415,9 @@ var (
 func New() *Config {
 	return &Config{}
 }
+
+type Workspace struct {
+	Lfs     Lfs     `yaml:"lfs"`
+	Root    string  `yaml:"root"`
+	Storage Storage `yaml:"storage"`
+}
@@ -0,0 +1,207 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package demote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	ActionDemote  = "demote"
+	ActionDisable = "disable"
+)
+
+const (
+	findings = 10
+)
+
+type Demote interface {
+	Apply([]proto.Format, []Entry) []proto.Format
+	Notify([]Entry) error
+	Rules([]proto.Run, []proto.Feedback) []Entry
+}
+
+type Config struct {
+	Client *http.Client
+	Demote config.Demote
+}
+
+// Entry is rule whose ratio of false positives to distinct findings exceeds threshold.
+type Entry struct {
+	Action         string  `json:"action"`
+	FalsePositives int     `json:"falsePositives"`
+	Findings       int     `json:"findings"`
+	Ratio          float64 `json:"ratio"`
+	Rule           string  `json:"rule"`
+}
+
+type demote struct {
+	cfg *Config
+}
+
+func New(cfg *Config) Demote {
+	return &demote{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Apply demotes findings of rules in entries to Info, or drops them if disabled.
+func (d *demote) Apply(data []proto.Format, entries []Entry) []proto.Format {
+	if len(entries) == 0 || data == nil {
+		return data
+	}
+
+	actions := map[string]string{}
+
+	for _, val := range entries {
+		actions[val.Rule] = val.Action
+	}
+
+	ret := []proto.Format{}
+
+	for _, val := range data {
+		switch actions[val.Rule] {
+		case ActionDisable:
+			continue
+		case ActionDemote:
+			val.Type = proto.TypeInfo
+		}
+		ret = append(ret, val)
+	}
+
+	return ret
+}
+
+// Notify posts entries to admins by JSON to notify, in which text is for chats, e.g., incoming webhooks of Slack.
+func (d *demote) Notify(entries []Entry) error {
+	if d.cfg.Demote.Notify == "" || len(entries) == 0 {
+		return nil
+	}
+
+	var buf []string
+
+	for _, val := range entries {
+		buf = append(buf, fmt.Sprintf("- %s: %d false positives of %d findings (%.3f), %sd", val.Rule,
+			val.FalsePositives, val.Findings, val.Ratio, val.Action))
+	}
+
+	text := fmt.Sprintf("lintflow found %d rules beyond false positive ratio %.3f:\n\n%s", len(entries),
+		d.cfg.Demote.Ratio, strings.Join(buf, "\n"))
+
+	b, err := json.Marshal(map[string]interface{}{"rules": entries, "text": text})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	client := d.cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	rsp, err := client.Post(d.cfg.Demote.Notify, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to post")
+	}
+
+	_ = rsp.Body.Close()
+
+	if rsp.StatusCode >= http.StatusBadRequest {
+		return errors.New("invalid status " + rsp.Status)
+	}
+
+	return nil
+}
+
+// Rules returns rules in order whose ratio of false positives to distinct findings in runs exceeds ratio, once they
+// have findings at least, except overridden ones. No rule is returned if ratio is not set.
+func (d *demote) Rules(runs []proto.Run, feedback []proto.Feedback) []Entry {
+	cfg := &d.cfg.Demote
+	if cfg.Ratio <= 0 {
+		return nil
+	}
+
+	count := cfg.Findings
+	if count <= 0 {
+		count = findings
+	}
+
+	action := cfg.Action
+	if action == "" {
+		action = ActionDemote
+	}
+
+	distinct := map[string]map[string]bool{}
+
+	for _, run := range runs {
+		for _, val := range run.Findings {
+			if val.Rule == "" {
+				continue
+			}
+			if distinct[val.Rule] == nil {
+				distinct[val.Rule] = map[string]bool{}
+			}
+			distinct[val.Rule][strings.Join([]string{run.Repo, val.File, val.Type, val.Details}, "\x00")] = true
+		}
+	}
+
+	positives := map[string]int{}
+
+	for _, val := range feedback {
+		if val.Rule != "" {
+			positives[val.Rule]++
+		}
+	}
+
+	var ret []Entry
+
+	for rule, n := range positives {
+		total := len(distinct[rule])
+		if total < count || d.overridden(rule) {
+			continue
+		}
+		if ratio := float64(n) / float64(total); ratio > cfg.Ratio {
+			ret = append(ret, Entry{Action: action, FalsePositives: n, Findings: total, Ratio: ratio, Rule: rule})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Rule < ret[j].Rule
+	})
+
+	return ret
+}
+
+func (d *demote) overridden(rule string) bool {
+	for _, val := range d.cfg.Demote.Override {
+		if ok, err := path.Match(val, rule); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package demote
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestRules(t *testing.T) {
+	runs := []proto.Run{
+		{Repo: "a", Findings: []proto.Format{{File: "main.go", Line: 1, Rule: "errcheck", Details: "foo"},
+			{File: "main.go", Line: 2, Rule: "errcheck", Details: "bar"}, {File: "main.go", Rule: "golint"}}},
+		{Repo: "a", Findings: []proto.Format{{File: "main.go", Line: 1, Rule: "errcheck", Details: "foo"}}},
+	}
+	feedback := []proto.Feedback{{Rule: "errcheck"}, {Rule: "errcheck"}, {Rule: "golint"}, {}}
+
+	d := New(&Config{Demote: config.Demote{Findings: 1, Ratio: 0.5}})
+	assert.Equal(t, []Entry{
+		{Action: ActionDemote, FalsePositives: 2, Findings: 2, Ratio: 1, Rule: "errcheck"},
+		{Action: ActionDemote, FalsePositives: 1, Findings: 1, Ratio: 1, Rule: "golint"},
+	}, d.Rules(runs, feedback))
+
+	d = New(&Config{Demote: config.Demote{Action: ActionDisable, Findings: 2, Override: []string{"err*"}, Ratio: 0.5}})
+	assert.Equal(t, 0, len(d.Rules(runs, feedback)))
+
+	d = New(DefaultConfig())
+	assert.Equal(t, 0, len(d.Rules(runs, feedback)))
+}
+
+func TestApply(t *testing.T) {
+	data := []proto.Format{{File: "main.go", Type: proto.TypeError, Rule: "errcheck"},
+		{File: "main.go", Type: proto.TypeError, Rule: "golint"}, {File: "main.go", Type: proto.TypeWarn}}
+
+	d := New(DefaultConfig())
+	assert.Equal(t, data, d.Apply(data, nil))
+
+	assert.Equal(t, []proto.Format{{File: "main.go", Type: proto.TypeInfo, Rule: "errcheck"},
+		{File: "main.go", Type: proto.TypeWarn}}, d.Apply(data, []Entry{{Action: ActionDemote, Rule: "errcheck"},
+		{Action: ActionDisable, Rule: "golint"}}))
+	assert.Equal(t, proto.TypeError, data[0].Type)
+}
+
+func TestNotify(t *testing.T) {
+	var body map[string]interface{}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+
+	entries := []Entry{{Action: ActionDisable, FalsePositives: 3, Findings: 4, Ratio: 0.75, Rule: "errcheck"}}
+
+	d := New(&Config{Demote: config.Demote{Ratio: 0.5}})
+	assert.Equal(t, nil, d.Notify(entries))
+
+	d = New(&Config{Demote: config.Demote{Notify: s.URL, Ratio: 0.5}})
+	assert.Equal(t, nil, d.Notify(entries))
+	assert.Equal(t, "lintflow found 1 rules beyond false positive ratio 0.500:\n\n"+
+		"- errcheck: 3 false positives of 4 findings (0.750), disabled", body["text"])
+
+	d = New(&Config{Demote: config.Demote{Notify: s.URL + "/fail", Ratio: 0.5}})
+	assert.NotEqual(t, nil, d.Notify(entries))
+}
@@ -0,0 +1,391 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DevNull = "/dev/null"
+)
+
+const (
+	StatusAdded    = "A"
+	StatusCopied   = "C"
+	StatusDeleted  = "D"
+	StatusModified = "M"
+	StatusRenamed  = "R"
+)
+
+const (
+	LineAdded   = '+'
+	LineContext = ' '
+	LineDeleted = '-'
+)
+
+const (
+	headerBinary  = "Binary files "
+	headerDiff    = "diff --git "
+	headerGitBin  = "GIT binary patch"
+	headerHunk    = "@@ "
+	headerNoEOL   = `\ No newline at end of file`
+	headerNew     = "+++ "
+	headerOld     = "--- "
+	prefixNew     = "b/"
+	prefixOld     = "a/"
+	maxHunkLength = 1 << 30
+)
+
+// File is diff of one file, where paths are without prefixes of a/ and b/, and are empty if absent in revision.
+type File struct {
+	Binary  bool
+	Hunks   []*Hunk
+	ModeNew string
+	ModeOld string
+	New     string
+	Old     string
+	Status  string
+}
+
+// Hunk is range of lines in diff, with section heading after @@ if any.
+type Hunk struct {
+	Lines    []Line
+	NewLines int
+	NewStart int
+	OldLines int
+	OldStart int
+	Section  string
+}
+
+// Line is one line of hunk, with its numbers in old and new revisions which are 0 if absent.
+type Line struct {
+	Content string
+	New     int
+	Old     int
+	Type    byte
+}
+
+type parser struct {
+	lines [][]byte
+	pos   int
+}
+
+// Parse parses unified diff in format of git, e.g. patch of Gerrit, in which content before the first diff,
+// e.g. headers of email and commit message, is skipped.
+func Parse(data []byte) ([]*File, error) {
+	p := parser{lines: bytes.SplitAfter(data, []byte("\n"))}
+
+	var ret []*File
+
+	for p.pos < len(p.lines) {
+		if !strings.HasPrefix(p.line(), headerDiff) {
+			p.pos++
+			continue
+		}
+		f, err := p.file()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse line %d", p.pos+1)
+		}
+		ret = append(ret, f)
+	}
+
+	return ret, nil
+}
+
+// Added reports whether line of new revision is added in diff.
+func (f *File) Added(line int) bool {
+	for _, h := range f.Hunks {
+		if line < h.NewStart || line >= h.NewStart+h.NewLines {
+			continue
+		}
+		for _, l := range h.Lines {
+			if l.Type == LineAdded && l.New == line {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Map maps line of old revision to the one of new revision, and returns 0 if the line is deleted.
+func (f *File) Map(line int) int {
+	if f.Status == StatusDeleted || line <= 0 {
+		return 0
+	}
+
+	offset := 0
+
+	for _, h := range f.Hunks {
+		if line < h.OldStart {
+			break
+		}
+		if line >= h.OldStart+h.OldLines {
+			offset += h.NewLines - h.OldLines
+			continue
+		}
+		for _, l := range h.Lines {
+			if l.Old == line {
+				return l.New
+			}
+		}
+		return 0
+	}
+
+	return line + offset
+}
+
+func (p *parser) line() string {
+	return strings.TrimRight(string(p.lines[p.pos]), "\r\n")
+}
+
+// nolint:gocyclo
+func (p *parser) file() (*File, error) {
+	f := &File{Status: StatusModified}
+
+	f.Old, f.New = paths(strings.TrimPrefix(p.line(), headerDiff))
+	p.pos++
+
+	for ; p.pos < len(p.lines) && !strings.HasPrefix(p.line(), headerDiff); p.pos++ {
+		line := p.line()
+		switch {
+		case strings.HasPrefix(line, "old mode "):
+			f.ModeOld = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			f.ModeNew = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			f.ModeOld, f.Status = strings.TrimPrefix(line, "deleted file mode "), StatusDeleted
+		case strings.HasPrefix(line, "new file mode "):
+			f.ModeNew, f.Status = strings.TrimPrefix(line, "new file mode "), StatusAdded
+		case strings.HasPrefix(line, "rename from "):
+			f.Old, f.Status = unquote(strings.TrimPrefix(line, "rename from ")), StatusRenamed
+		case strings.HasPrefix(line, "rename to "):
+			f.New, f.Status = unquote(strings.TrimPrefix(line, "rename to ")), StatusRenamed
+		case strings.HasPrefix(line, "copy from "):
+			f.Old, f.Status = unquote(strings.TrimPrefix(line, "copy from ")), StatusCopied
+		case strings.HasPrefix(line, "copy to "):
+			f.New, f.Status = unquote(strings.TrimPrefix(line, "copy to ")), StatusCopied
+		case strings.HasPrefix(line, headerBinary) || line == headerGitBin:
+			f.Binary = true
+		case strings.HasPrefix(line, headerOld):
+			if f.Old = strip(strings.TrimPrefix(line, headerOld), prefixOld); f.Old == "" {
+				f.Status = StatusAdded
+			}
+		case strings.HasPrefix(line, headerNew):
+			if f.New = strip(strings.TrimPrefix(line, headerNew), prefixNew); f.New == "" {
+				f.Status = StatusDeleted
+			}
+		case strings.HasPrefix(line, headerHunk):
+			if f.Binary {
+				continue
+			}
+			h, err := p.hunk()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse hunk")
+			}
+			f.Hunks = append(f.Hunks, h)
+		}
+	}
+
+	if f.Status == StatusDeleted {
+		f.New = ""
+	} else if f.Status == StatusAdded {
+		f.Old = ""
+	}
+
+	return f, nil
+}
+
+// hunk parses hunk at header, and leaves position at its last line.
+func (p *parser) hunk() (*Hunk, error) {
+	h, err := header(p.line())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse header")
+	}
+
+	lnOld, lnNew := h.OldStart, h.NewStart
+	remainOld, remainNew := h.OldLines, h.NewLines
+
+	for remainOld > 0 || remainNew > 0 {
+		p.pos++
+		if p.pos >= len(p.lines) {
+			return nil, errors.New("unexpected end of hunk")
+		}
+		if len(p.lines[p.pos]) == 0 {
+			return nil, errors.New("unexpected end of hunk")
+		}
+		line := p.line()
+		if line == headerNoEOL {
+			continue
+		}
+		if line == "" {
+			// Context of empty line whose leading space is trimmed, e.g., by editors
+			line = string(LineContext)
+		}
+		switch line[0] {
+		case LineAdded:
+			if remainNew <= 0 {
+				return nil, errors.New("invalid line: more added lines than header")
+			}
+			h.Lines = append(h.Lines, Line{Content: line[1:], New: lnNew, Type: LineAdded})
+			lnNew, remainNew = lnNew+1, remainNew-1
+		case LineDeleted:
+			if remainOld <= 0 {
+				return nil, errors.New("invalid line: more deleted lines than header")
+			}
+			h.Lines = append(h.Lines, Line{Content: line[1:], Old: lnOld, Type: LineDeleted})
+			lnOld, remainOld = lnOld+1, remainOld-1
+		case LineContext:
+			if remainOld <= 0 || remainNew <= 0 {
+				return nil, errors.New("invalid line: more context lines than header")
+			}
+			h.Lines = append(h.Lines, Line{Content: line[1:], New: lnNew, Old: lnOld, Type: LineContext})
+			lnNew, lnOld, remainNew, remainOld = lnNew+1, lnOld+1, remainNew-1, remainOld-1
+		default:
+			return nil, errors.New("invalid line: " + strconv.Quote(line))
+		}
+	}
+
+	// Trailing marker of missing newline at end of file
+	if p.pos+1 < len(p.lines) && strings.TrimRight(string(p.lines[p.pos+1]), "\r\n") == headerNoEOL {
+		p.pos++
+	}
+
+	return h, nil
+}
+
+// header parses header of hunk, e.g. @@ -1,2 +1,3 @@ func main() {
+func header(line string) (*Hunk, error) {
+	buf := strings.SplitN(strings.TrimPrefix(line, headerHunk), " @@", 2)
+	if len(buf) != 2 {
+		return nil, errors.New("invalid header: " + strconv.Quote(line))
+	}
+
+	ranges := strings.Fields(buf[0])
+	if len(ranges) != 2 || !strings.HasPrefix(ranges[0], "-") || !strings.HasPrefix(ranges[1], "+") {
+		return nil, errors.New("invalid header: " + strconv.Quote(line))
+	}
+
+	h := &Hunk{Section: strings.TrimSpace(buf[1])}
+
+	var err error
+
+	if h.OldStart, h.OldLines, err = span(ranges[0][1:]); err != nil {
+		return nil, errors.Wrap(err, "invalid old range")
+	}
+
+	if h.NewStart, h.NewLines, err = span(ranges[1][1:]); err != nil {
+		return nil, errors.Wrap(err, "invalid new range")
+	}
+
+	return h, nil
+}
+
+// span parses range of hunk in start,lines where lines is 1 if omitted.
+func span(data string) (start, lines int, err error) {
+	buf := strings.SplitN(data, ",", 2)
+
+	if start, err = strconv.Atoi(buf[0]); err != nil || start < 0 || start > maxHunkLength {
+		return 0, 0, errors.New("invalid start: " + data)
+	}
+
+	lines = 1
+
+	if len(buf) == 2 {
+		if lines, err = strconv.Atoi(buf[1]); err != nil || lines < 0 || lines > maxHunkLength {
+			return 0, 0, errors.New("invalid lines: " + data)
+		}
+	}
+
+	// Start of empty range is the line before it, e.g. -0,0 for added file
+	if lines == 0 {
+		start++
+	}
+
+	return start, lines, nil
+}
+
+// paths parses paths in header of diff, e.g. a/main.go b/main.go, in which paths could be quoted or contain spaces.
+func paths(data string) (string, string) {
+	if strings.HasPrefix(data, `"`) {
+		if index := quoted(data); index > 0 {
+			return strip(data[:index], prefixOld), strip(strings.TrimSpace(data[index:]), prefixNew)
+		}
+	}
+
+	// Paths are the same in halves if not renamed, e.g. a/foo bar b/foo bar
+	if n := len(data); n%2 == 1 {
+		if l, r := data[:n/2], data[n/2+1:]; strings.TrimPrefix(l, prefixOld) == strings.TrimPrefix(r, prefixNew) {
+			return strip(l, prefixOld), strip(r, prefixNew)
+		}
+	}
+
+	if index := strings.Index(data, " "+prefixNew); index >= 0 {
+		return strip(data[:index], prefixOld), strip(data[index+1:], prefixNew)
+	}
+
+	return "", ""
+}
+
+// quoted returns the end of quoted string at the beginning of data, or -1 if not terminated.
+func quoted(data string) int {
+	for i := 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+func strip(data, prefix string) string {
+	data = strings.TrimSpace(data)
+
+	// Timestamp after tab in traditional diff
+	if strings.HasPrefix(data, `"`) {
+		if index := quoted(data); index > 0 {
+			data = data[:index]
+		}
+	} else if index := strings.Index(data, "\t"); index >= 0 {
+		data = data[:index]
+	}
+
+	data = unquote(data)
+
+	if data == DevNull {
+		return ""
+	}
+
+	return strings.TrimPrefix(data, prefix)
+}
+
+func unquote(data string) string {
+	if len(data) < 2 || !strings.HasPrefix(data, `"`) || !strings.HasSuffix(data, `"`) {
+		return data
+	}
+
+	buf, err := strconv.Unquote(data)
+	if err != nil {
+		return data
+	}
+
+	return buf
+}
@@ -0,0 +1,172 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	patchGerrit = "From 8f71e42dbcd8c68d849e483c04670f58621aab9c Mon Sep 17 00:00:00 2001\n" +
+		"From: dev <dev@example.com>\nSubject: [PATCH] Update\n\n" +
+		"Update main\n---\n" +
+		" main.go | 3 ++-\n\n" +
+		"diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n" +
+		"@@ -1,4 +1,5 @@ package main\n package main\n-var a = 1\n+var a = 2\n+var b = 3\n \n func main() {}\n" +
+		"diff --git a/old.go b/old.go\ndeleted file mode 100644\nindex 1..0\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n" +
+		"diff --git a/new.go b/new.go\nnew file mode 100755\nindex 0..1\n--- /dev/null\n+++ b/new.go\n" +
+		"@@ -0,0 +1,2 @@\n+a\n+b\n\\ No newline at end of file\n" +
+		"diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files differ\n" +
+		"diff --git a/icon.png b/icon.png\nnew file mode 100644\nindex 0..1\nGIT binary patch\nliteral 5\nMcmZ?wbhEHbNC5\n\nliteral 0\nHcmV?d00001\n\n" +
+		"diff --git a/src/a.go b/src/b.go\nsimilarity index 100%\nrename from src/a.go\nrename to src/b.go\n" +
+		"diff --git a/run.sh b/run.sh\nold mode 100644\nnew mode 100755\n" +
+		"diff --git \"a/foo\\tbar.go\" \"b/foo\\tbar.go\"\nindex 1..2 100644\n--- \"a/foo\\tbar.go\"\n+++ \"b/foo\\tbar.go\"\n" +
+		"@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/foo bar.go b/foo bar.go\nindex 1..2 100644\n--- a/foo bar.go\n+++ b/foo bar.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"-- \n2.30.0\n"
+)
+
+func TestParse(t *testing.T) {
+	buf, err := Parse([]byte(patchGerrit))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 9, len(buf))
+
+	assert.Equal(t, "main.go", buf[0].Old)
+	assert.Equal(t, "main.go", buf[0].New)
+	assert.Equal(t, StatusModified, buf[0].Status)
+	assert.Equal(t, 1, len(buf[0].Hunks))
+	assert.Equal(t, &Hunk{
+		Lines: []Line{
+			{Content: "package main", New: 1, Old: 1, Type: LineContext},
+			{Content: "var a = 1", Old: 2, Type: LineDeleted},
+			{Content: "var a = 2", New: 2, Type: LineAdded},
+			{Content: "var b = 3", New: 3, Type: LineAdded},
+			{Content: "", New: 4, Old: 3, Type: LineContext},
+			{Content: "func main() {}", New: 5, Old: 4, Type: LineContext},
+		},
+		NewLines: 5,
+		NewStart: 1,
+		OldLines: 4,
+		OldStart: 1,
+		Section:  "package main",
+	}, buf[0].Hunks[0])
+
+	assert.Equal(t, &File{Hunks: []*Hunk{{Lines: []Line{{Content: "a", Old: 1, Type: LineDeleted}}, NewStart: 1, OldLines: 1,
+		OldStart: 1}}, ModeOld: "100644", Old: "old.go", Status: StatusDeleted}, buf[1])
+
+	assert.Equal(t, "", buf[2].Old)
+	assert.Equal(t, "new.go", buf[2].New)
+	assert.Equal(t, "100755", buf[2].ModeNew)
+	assert.Equal(t, StatusAdded, buf[2].Status)
+	assert.Equal(t, 2, len(buf[2].Hunks[0].Lines))
+
+	assert.Equal(t, &File{Binary: true, New: "logo.png", Old: "logo.png", Status: StatusModified}, buf[3])
+	assert.Equal(t, &File{Binary: true, ModeNew: "100644", New: "icon.png", Status: StatusAdded}, buf[4])
+	assert.Equal(t, &File{New: "src/b.go", Old: "src/a.go", Status: StatusRenamed}, buf[5])
+	assert.Equal(t, &File{ModeNew: "100755", ModeOld: "100644", New: "run.sh", Old: "run.sh", Status: StatusModified}, buf[6])
+	assert.Equal(t, "foo\tbar.go", buf[7].New)
+	assert.Equal(t, "foo bar.go", buf[8].Old)
+	assert.Equal(t, "foo bar.go", buf[8].New)
+}
+
+func TestParseInvalid(t *testing.T) {
+	buf, err := Parse(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	_, err = Parse([]byte("diff --git a/a b/a\n@@ -1 +1 @@\n"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Parse([]byte("diff --git a/a b/a\n@@ -1 +1 @@\n+a\n+b\n"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Parse([]byte("diff --git a/a b/a\n@@ -x +1 @@\n+a\n"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Parse([]byte("diff --git a/a b/a\n@@ -1 +1\n"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Parse([]byte("diff --git a/a b/a\n@@ -1 +1 @@\n?a\n"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestAdded(t *testing.T) {
+	buf, err := Parse([]byte(patchGerrit))
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, false, buf[0].Added(1))
+	assert.Equal(t, true, buf[0].Added(2))
+	assert.Equal(t, true, buf[0].Added(3))
+	assert.Equal(t, false, buf[0].Added(4))
+	assert.Equal(t, true, buf[2].Added(1))
+	assert.Equal(t, false, buf[3].Added(1))
+}
+
+func TestMap(t *testing.T) {
+	buf, err := Parse([]byte("diff --git a/a b/a\n--- a/a\n+++ b/a\n@@ -2,2 +2,3 @@\n a\n-b\n+c\n+d\n@@ -10 +10,0 @@\n-e\n"))
+	assert.Equal(t, nil, err)
+
+	f := buf[0]
+	assert.Equal(t, 1, f.Map(1))
+	assert.Equal(t, 2, f.Map(2))
+	assert.Equal(t, 0, f.Map(3))
+	assert.Equal(t, 5, f.Map(4))
+	assert.Equal(t, 10, f.Map(9))
+	assert.Equal(t, 0, f.Map(10))
+	assert.Equal(t, 11, f.Map(11))
+	assert.Equal(t, 0, f.Map(0))
+}
+
+// TestParseFuzz mutates patches randomly, and checks parsing never panics and yields consistent line numbers.
+func TestParseFuzz(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tokens := []string{"\n", " ", "+", "-", "@@ ", " @@", ",", "0", "1", "9", "diff --git ", "a/", "b/", "\"", "\\",
+		"/dev/null", "--- ", "+++ ", "rename to ", "Binary files differ"}
+
+	for i := 0; i < 20000; i++ {
+		data := []byte(patchGerrit)
+		for n := r.Intn(8) + 1; n > 0; n-- {
+			pos := r.Intn(len(data) + 1)
+			switch r.Intn(3) {
+			case 0:
+				data = append(data[:pos:pos], append([]byte(tokens[r.Intn(len(tokens))]), data[pos:]...)...)
+			case 1:
+				if end := pos + r.Intn(16); end <= len(data) {
+					data = append(data[:pos:pos], data[end:]...)
+				}
+			default:
+				if pos < len(data) {
+					data[pos] = byte(r.Intn(256))
+				}
+			}
+		}
+		buf, err := Parse(data)
+		if err != nil {
+			continue
+		}
+		for _, f := range buf {
+			for _, h := range f.Hunks {
+				for _, l := range h.Lines {
+					if l.New < 0 || l.Old < 0 || (l.Type == LineAdded && l.New == 0) || (l.Type == LineDeleted && l.Old == 0) {
+						t.Fatalf("invalid line %+v in %q", l, data)
+					}
+				}
+				_ = f.Added(h.NewStart)
+				_ = f.Map(h.OldStart)
+			}
+		}
+	}
+}
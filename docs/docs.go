@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docs
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	placeLower = "{lower}"
+	placeRule  = "{rule}"
+)
+
+type Docs interface {
+	Link(string) string
+}
+
+type Config struct {
+	Docs map[string]string
+}
+
+type docs struct {
+	cfg  *Config
+	keys []string
+}
+
+type builtin struct {
+	pattern *regexp.Regexp
+	url     string
+}
+
+var (
+	builtins = []builtin{
+		{regexp.MustCompile(`^DL\d{4}$`), "https://github.com/hadolint/hadolint/wiki/{rule}"},
+		{regexp.MustCompile(`^G\d{3}$`), "https://securego.io/docs/rules/{lower}.html"},
+		{regexp.MustCompile(`^(QF|S|SA|ST)\d{4}$`), "https://staticcheck.dev/docs/checks/#{rule}"},
+		{regexp.MustCompile(`^SC\d{4}$`), "https://www.shellcheck.net/wiki/{rule}"},
+		{regexp.MustCompile(`^(RUF|UP)\d{3}$`), "https://docs.astral.sh/ruff/rules/#{rule}"},
+	}
+)
+
+func New(cfg *Config) Docs {
+	var keys []string
+
+	for key := range cfg.Docs {
+		keys = append(keys, key)
+	}
+
+	// Match more specific patterns first
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	return &docs{
+		cfg:  cfg,
+		keys: keys,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Link returns documentation URL of rule from config, or built-in registry for common linters.
+func (d *docs) Link(rule string) string {
+	if rule == "" {
+		return ""
+	}
+
+	for _, key := range d.keys {
+		if ok, err := path.Match(key, rule); err == nil && ok {
+			return expand(d.cfg.Docs[key], rule)
+		}
+	}
+
+	for _, val := range builtins {
+		if val.pattern.MatchString(rule) {
+			return expand(val.url, rule)
+		}
+	}
+
+	return ""
+}
+
+func expand(url, rule string) string {
+	return strings.NewReplacer(placeRule, rule, placeLower, strings.ToLower(rule)).Replace(url)
+}
@@ -0,0 +1,38 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLink(t *testing.T) {
+	d := New(DefaultConfig())
+
+	assert.Equal(t, "", d.Link(""))
+	assert.Equal(t, "", d.Link("unknown"))
+	assert.Equal(t, "https://www.shellcheck.net/wiki/SC2086", d.Link("SC2086"))
+	assert.Equal(t, "https://securego.io/docs/rules/g101.html", d.Link("G101"))
+	assert.Equal(t, "https://staticcheck.dev/docs/checks/#SA1019", d.Link("SA1019"))
+
+	d = New(&Config{Docs: map[string]string{
+		"*":      "https://example.com/lint/{rule}",
+		"SC2086": "https://example.com/shell/{lower}",
+	}})
+
+	assert.Equal(t, "https://example.com/shell/sc2086", d.Link("SC2086"))
+	assert.Equal(t, "https://example.com/lint/SC2046", d.Link("SC2046"))
+	assert.Equal(t, "https://example.com/lint/unknown", d.Link("unknown"))
+}
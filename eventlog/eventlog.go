@@ -0,0 +1,246 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	bufSize = 64 * 1024 * 1024
+	perm    = 0600
+)
+
+// EventLog keeps consumed events by offsets in order of consumption, in which duplicates by key are dropped and events
+// are pending till done, e.g., to be resumed after restart.
+type EventLog interface {
+	Append(key string, data []byte) (int64, bool, error)
+	Done(int64) error
+	Pending() ([]Entry, error)
+	Remove(int64) error
+}
+
+type Config struct {
+	Path string
+}
+
+// Entry is line of log, which is either event consumed at offset or mark of done or removal of offset.
+type Entry struct {
+	Data    json.RawMessage `json:"data,omitempty"`
+	Done    bool            `json:"done,omitempty"`
+	Key     string          `json:"key,omitempty"`
+	Offset  int64           `json:"offset"`
+	Removed bool            `json:"removed,omitempty"`
+}
+
+// eventlog stores entries in file of JSON lines, which is loaded on first use.
+type eventlog struct {
+	cfg     *Config
+	keys    map[string]bool
+	loaded  bool
+	mutex   sync.Mutex
+	offset  int64
+	partial bool
+	pending map[int64]Entry
+}
+
+func New(cfg *Config) EventLog {
+	return &eventlog{
+		cfg:     cfg,
+		keys:    map[string]bool{},
+		pending: map[int64]Entry{},
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Append appends event of key at the next offset, which is false if key was consumed before.
+func (e *eventlog) Append(key string, data []byte) (int64, bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err := e.load(); err != nil {
+		return 0, false, errors.Wrap(err, "failed to load")
+	}
+
+	if e.keys[key] {
+		return 0, false, nil
+	}
+
+	entry := Entry{Data: data, Key: key, Offset: e.offset + 1}
+
+	if err := e.write(entry); err != nil {
+		return 0, false, errors.Wrap(err, "failed to write")
+	}
+
+	e.keys[key] = true
+	e.offset = entry.Offset
+	e.pending[entry.Offset] = entry
+
+	return entry.Offset, true, nil
+}
+
+func (e *eventlog) Done(offset int64) error {
+	return e.mark(Entry{Done: true, Offset: offset})
+}
+
+// Remove removes pending event along with its key, e.g., to accept redelivery of event rejected for now.
+func (e *eventlog) Remove(offset int64) error {
+	return e.mark(Entry{Offset: offset, Removed: true})
+}
+
+func (e *eventlog) mark(entry Entry) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err := e.load(); err != nil {
+		return errors.Wrap(err, "failed to load")
+	}
+
+	p, ok := e.pending[entry.Offset]
+	if !ok {
+		return nil
+	}
+
+	if err := e.write(entry); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	delete(e.pending, entry.Offset)
+
+	if entry.Removed {
+		delete(e.keys, p.Key)
+	}
+
+	return nil
+}
+
+// Pending returns events which are not done, in order of offsets.
+func (e *eventlog) Pending() ([]Entry, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err := e.load(); err != nil {
+		return nil, errors.Wrap(err, "failed to load")
+	}
+
+	ret := make([]Entry, 0, len(e.pending))
+	for _, val := range e.pending {
+		ret = append(ret, val)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Offset < ret[j].Offset
+	})
+
+	return ret, nil
+}
+
+func (e *eventlog) load() error {
+	if e.loaded {
+		return nil
+	}
+
+	f, err := os.Open(e.cfg.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			e.loaded = true
+			return nil
+		}
+		return errors.Wrap(err, "failed to open")
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), bufSize)
+
+	for scanner.Scan() {
+		var entry Entry
+		// Skip partial line written by interrupted server
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Done || entry.Removed {
+			if p, ok := e.pending[entry.Offset]; ok && entry.Removed {
+				delete(e.keys, p.Key)
+			}
+			delete(e.pending, entry.Offset)
+			continue
+		}
+		e.keys[entry.Key] = true
+		e.pending[entry.Offset] = entry
+		if entry.Offset > e.offset {
+			e.offset = entry.Offset
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to scan")
+	}
+
+	// Partial line is terminated for entries appended next
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		b := make([]byte, 1)
+		if _, err := f.ReadAt(b, fi.Size()-1); err == nil && b[0] != '\n' {
+			e.partial = true
+		}
+	}
+
+	e.loaded = true
+
+	return nil
+}
+
+func (e *eventlog) write(entry Entry) error {
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	f, err := os.OpenFile(e.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return errors.Wrap(err, "failed to open")
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if e.partial {
+		buf = append([]byte{'\n'}, buf...)
+	}
+
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	// Events are acknowledged after entries are durable
+	if err := f.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync")
+	}
+
+	e.partial = false
+
+	return nil
+}
@@ -0,0 +1,99 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventlog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLog(t *testing.T) {
+	d, err := ioutil.TempDir("", "eventlog")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	cfg := DefaultConfig()
+	cfg.Path = filepath.Join(d, "events.jsonl")
+
+	e := New(cfg)
+
+	offset, ok, err := e.Append("foo", []byte(`{"type":"comment-added"}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, int64(1), offset)
+
+	_, ok, err = e.Append("foo", []byte(`{"type":"comment-added"}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	offset, ok, err = e.Append("bar", []byte(`{"type":"comment-added"}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, int64(2), offset)
+
+	err = e.Done(1)
+	assert.Equal(t, nil, err)
+
+	err = e.Done(1)
+	assert.Equal(t, nil, err)
+
+	// Partial line of interrupted write
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_WRONLY, perm)
+	assert.Equal(t, nil, err)
+	_, _ = f.WriteString(`{"data":`)
+	_ = f.Close()
+
+	e = New(cfg)
+
+	buf, err := e.Pending()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, int64(2), buf[0].Offset)
+	assert.Equal(t, json.RawMessage(`{"type":"comment-added"}`), buf[0].Data)
+
+	_, ok, err = e.Append("foo", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	offset, ok, err = e.Append("baz", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, int64(3), offset)
+
+	e = New(cfg)
+
+	buf, err = e.Pending()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, "baz", buf[1].Key)
+
+	err = e.Remove(3)
+	assert.Equal(t, nil, err)
+
+	e = New(cfg)
+
+	buf, err = e.Pending()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+
+	offset, ok, err = e.Append("baz", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, int64(4), offset)
+}
@@ -0,0 +1,171 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	bigqueryUrl = "https://bigquery.googleapis.com"
+)
+
+var (
+	bigquerySchemas = map[string][]map[string]string{
+		tableFindings: {
+			{"name": "id", "type": "STRING"},
+			{"name": "run", "type": "STRING"},
+			{"name": "file", "type": "STRING"},
+			{"name": "line", "type": "INTEGER"},
+			{"name": "type", "type": "STRING"},
+			{"name": "details", "type": "STRING"},
+		},
+		tableRuns: {
+			{"name": "id", "type": "STRING"},
+			{"name": "commit", "type": "STRING"},
+			{"name": "repo", "type": "STRING"},
+			{"name": "start", "type": "TIMESTAMP"},
+			{"name": "duration", "type": "INTEGER"},
+			{"name": "status", "type": "STRING"},
+			{"name": "error", "type": "STRING"},
+			{"name": "findings", "type": "INTEGER"},
+		},
+	}
+)
+
+type bigquery struct {
+	e       config.Export
+	created bool
+	mutex   sync.Mutex
+}
+
+func (b *bigquery) Run(runs []proto.Run) error {
+	if err := b.ensure(); err != nil {
+		return errors.Wrap(err, "failed to ensure")
+	}
+
+	r, f := rows(runs)
+
+	if err := b.insert(tableRuns, r); err != nil {
+		return errors.Wrap(err, "failed to insert runs")
+	}
+
+	if err := b.insert(tableFindings, f); err != nil {
+		return errors.Wrap(err, "failed to insert findings")
+	}
+
+	return nil
+}
+
+// ensure creates tables once if enabled, which is retried by later runs if failed.
+func (b *bigquery) ensure() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.e.Create || b.created {
+		return nil
+	}
+
+	if err := b.create(); err != nil {
+		return errors.Wrap(err, "failed to create")
+	}
+
+	b.created = true
+
+	return nil
+}
+
+func (b *bigquery) create() error {
+	dataset := map[string]interface{}{
+		"datasetReference": map[string]string{"datasetId": b.e.Database, "projectId": b.e.Project},
+	}
+
+	if _, err := b.post(b.url("/datasets"), dataset, http.StatusConflict); err != nil {
+		return errors.Wrap(err, "failed to create dataset")
+	}
+
+	for _, name := range []string{tableRuns, tableFindings} {
+		table := map[string]interface{}{
+			"tableReference": map[string]string{"datasetId": b.e.Database, "projectId": b.e.Project, "tableId": name},
+			"schema":         map[string]interface{}{"fields": bigquerySchemas[name]},
+		}
+		if _, err := b.post(b.url("/datasets/"+b.e.Database+"/tables"), table, http.StatusConflict); err != nil {
+			return errors.Wrap(err, "failed to create table")
+		}
+	}
+
+	return nil
+}
+
+func (b *bigquery) insert(table string, data []map[string]interface{}) error {
+	for _, batch := range batches(data, b.e.Batch) {
+		var r []map[string]interface{}
+		for _, val := range batch {
+			r = append(r, map[string]interface{}{"insertId": val["id"], "json": val})
+		}
+		buf, err := b.post(b.url("/datasets/"+b.e.Database+"/tables/"+table+"/insertAll"), map[string]interface{}{"rows": r})
+		if err != nil {
+			return errors.Wrap(err, "failed to post")
+		}
+		var ret struct {
+			InsertErrors []interface{} `json:"insertErrors"`
+		}
+		if err := json.Unmarshal(buf, &ret); err != nil {
+			return errors.Wrap(err, "failed to unmarshal")
+		}
+		if len(ret.InsertErrors) != 0 {
+			return errors.New("failed to insert rows")
+		}
+	}
+
+	return nil
+}
+
+func (b *bigquery) post(_url string, data interface{}, accept ...int) ([]byte, error) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	req := func() (*http.Request, error) {
+		r, err := http.NewRequest(http.MethodPost, _url, bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json;charset=utf-8")
+		if b.e.Token != "" {
+			r.Header.Set("Authorization", "Bearer "+b.e.Token)
+		}
+		return r, nil
+	}
+
+	return request(b.e.Retry, req, accept...)
+}
+
+func (b *bigquery) url(name string) string {
+	base := bigqueryUrl
+	if b.e.Url != "" {
+		base = strings.TrimSuffix(b.e.Url, "/")
+	}
+
+	return base + "/bigquery/v2/projects/" + b.e.Project + name
+}
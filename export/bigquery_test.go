@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestBigQuery(t *testing.T) {
+	var paths []string
+	var reject bool
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.Path)
+		if reject {
+			_, _ = w.Write([]byte(`{"insertErrors":[{"index":0}]}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/datasets") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	b := &bigquery{
+		e: config.Export{
+			Create:   true,
+			Database: "lintflow",
+			Name:     exportBigQuery,
+			Project:  "project",
+			Token:    "token",
+			Url:      ts.URL,
+		},
+	}
+
+	err := b.Run(runs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 5, len(paths))
+	assert.Equal(t, "/bigquery/v2/projects/project/datasets/lintflow/tables/findings/insertAll", paths[4])
+
+	reject = true
+
+	err = b.Run(runs)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,133 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	clickhouseFindings = `CREATE TABLE IF NOT EXISTS %s.findings (
+id String, run String, file String, line Int64, type String, details String
+) ENGINE = MergeTree ORDER BY (run, id)`
+	clickhouseRuns = `CREATE TABLE IF NOT EXISTS %s.runs (
+id String, commit String, repo String, start DateTime, duration Int64, status String, error String, findings Int64
+) ENGINE = MergeTree ORDER BY (start, id)`
+)
+
+type clickhouse struct {
+	e       config.Export
+	created bool
+	mutex   sync.Mutex
+}
+
+func (c *clickhouse) Run(runs []proto.Run) error {
+	if err := c.ensure(); err != nil {
+		return errors.Wrap(err, "failed to ensure")
+	}
+
+	r, f := rows(runs)
+
+	if err := c.insert(tableRuns, r); err != nil {
+		return errors.Wrap(err, "failed to insert runs")
+	}
+
+	if err := c.insert(tableFindings, f); err != nil {
+		return errors.Wrap(err, "failed to insert findings")
+	}
+
+	return nil
+}
+
+// ensure creates tables once if enabled, which is retried by later runs if failed.
+func (c *clickhouse) ensure() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.e.Create || c.created {
+		return nil
+	}
+
+	if err := c.create(); err != nil {
+		return errors.Wrap(err, "failed to create")
+	}
+
+	c.created = true
+
+	return nil
+}
+
+func (c *clickhouse) create() error {
+	queries := []string{
+		"CREATE DATABASE IF NOT EXISTS " + c.e.Database,
+		strings.Replace(clickhouseRuns, "%s", c.e.Database, 1),
+		strings.Replace(clickhouseFindings, "%s", c.e.Database, 1),
+	}
+
+	for _, val := range queries {
+		if err := c.query(val, nil); err != nil {
+			return errors.Wrap(err, "failed to query")
+		}
+	}
+
+	return nil
+}
+
+func (c *clickhouse) insert(table string, data []map[string]interface{}) error {
+	for _, batch := range batches(data, c.e.Batch) {
+		var buf bytes.Buffer
+		for _, val := range batch {
+			b, err := json.Marshal(val)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal")
+			}
+			buf.Write(b)
+			buf.WriteByte('\n')
+		}
+		if err := c.query("INSERT INTO "+c.e.Database+"."+table+" FORMAT JSONEachRow", buf.Bytes()); err != nil {
+			return errors.Wrap(err, "failed to query")
+		}
+	}
+
+	return nil
+}
+
+func (c *clickhouse) query(query string, data []byte) error {
+	req := func() (*http.Request, error) {
+		r, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.e.Url, "/")+"/?query="+url.QueryEscape(query),
+			bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if c.e.User != "" {
+			r.SetBasicAuth(c.e.User, c.e.Pass)
+		}
+		return r, nil
+	}
+
+	if _, err := request(c.e.Retry, req); err != nil {
+		return errors.Wrap(err, "failed to request")
+	}
+
+	return nil
+}
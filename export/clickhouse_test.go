@@ -0,0 +1,59 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestClickHouse(t *testing.T) {
+	var queries []string
+	var rows int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		buf, _ := ioutil.ReadAll(r.Body)
+		rows += strings.Count(string(buf), "\n")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := &clickhouse{
+		e: config.Export{
+			Batch:    1,
+			Create:   true,
+			Database: "lintflow",
+			Name:     exportClickHouse,
+			Url:      ts.URL,
+		},
+	}
+
+	err := c.Run(runs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, len(queries))
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS lintflow", queries[0])
+	assert.Equal(t, "INSERT INTO lintflow.findings FORMAT JSONEachRow", queries[5])
+	assert.Equal(t, 3, rows)
+
+	err = c.Run(runs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 9, len(queries))
+}
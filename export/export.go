@@ -0,0 +1,247 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	exportBigQuery   = "bigquery"
+	exportClickHouse = "clickhouse"
+)
+
+const (
+	tableFindings = "findings"
+	tableRuns     = "runs"
+)
+
+const (
+	batchSize = 500
+	queueWait = 10 * time.Second
+	timeout   = 30 * time.Second
+)
+
+var (
+	backoff = time.Second
+)
+
+type Export interface {
+	Flush() error
+	Run([]proto.Run) error
+}
+
+type Config struct {
+	Exports []config.Export
+}
+
+// sink inserts runs into warehouse.
+type sink interface {
+	Run([]proto.Run) error
+}
+
+type export struct {
+	cfg   *Config
+	hdls  []sink
+	mutex sync.Mutex
+	queue []proto.Run
+	timer *time.Timer
+}
+
+func New(cfg *Config) Export {
+	var hdls []sink
+
+	for index := range cfg.Exports {
+		switch cfg.Exports[index].Name {
+		case exportBigQuery:
+			hdls = append(hdls, &bigquery{e: cfg.Exports[index]})
+		case exportClickHouse:
+			hdls = append(hdls, &clickhouse{e: cfg.Exports[index]})
+		}
+	}
+
+	return &export{
+		cfg:  cfg,
+		hdls: hdls,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Flush exports queued runs at once, e.g., before exit.
+func (e *export) Flush() error {
+	var err error
+
+	e.mutex.Lock()
+	runs := e.queue
+	e.queue = nil
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.mutex.Unlock()
+
+	if len(runs) == 0 {
+		return nil
+	}
+
+	for _, val := range e.hdls {
+		if e := val.Run(runs); e != nil {
+			err = errors.Wrap(e, "failed to export")
+		}
+	}
+
+	return err
+}
+
+// Run queues runs, which are exported in background once batch is full or queue waits long enough, lest slow
+// warehouses block runs.
+func (e *export) Run(runs []proto.Run) error {
+	if len(runs) == 0 || len(e.hdls) == 0 {
+		return nil
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.queue = append(e.queue, runs...)
+
+	if len(e.queue) >= batchSize {
+		go e.flush()
+	} else if e.timer == nil {
+		e.timer = time.AfterFunc(queueWait, e.flush)
+	}
+
+	return nil
+}
+
+func (e *export) flush() {
+	if err := e.Flush(); err != nil {
+		log.Println(err)
+	}
+}
+
+func rows(runs []proto.Run) (r, f []map[string]interface{}) {
+	for _, run := range runs {
+		r = append(r, map[string]interface{}{
+			"id":       run.ID,
+			"commit":   run.Commit,
+			"repo":     run.Repo,
+			"start":    run.Start.UTC().Unix(),
+			"duration": run.Duration.Milliseconds(),
+			"status":   run.Status,
+			"error":    run.Error,
+			"findings": len(run.Findings),
+		})
+		for index, val := range run.Findings {
+			f = append(f, map[string]interface{}{
+				"id":      run.ID + "-" + strconv.Itoa(index),
+				"run":     run.ID,
+				"file":    val.File,
+				"line":    val.Line,
+				"type":    val.Type,
+				"details": val.Details,
+			})
+		}
+	}
+
+	return r, f
+}
+
+func batches(data []map[string]interface{}, size int) [][]map[string]interface{} {
+	var buf [][]map[string]interface{}
+
+	if size <= 0 {
+		size = batchSize
+	}
+
+	for len(data) > size {
+		buf = append(buf, data[:size])
+		data = data[size:]
+	}
+
+	if len(data) != 0 {
+		buf = append(buf, data)
+	}
+
+	return buf
+}
+
+// request sends data with retry on transient failures, i.e. transport errors, 429 and 5xx.
+func request(retry int, req func() (*http.Request, error), accept ...int) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	wait := backoff
+
+	var err error
+
+	for i := 0; i <= retry; i++ {
+		if i != 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		r, e := req()
+		if e != nil {
+			return nil, errors.Wrap(e, "failed to request")
+		}
+
+		rsp, e := client.Do(r)
+		if e != nil {
+			err = errors.Wrap(e, "failed to do")
+			continue
+		}
+
+		buf, e := ioutil.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+
+		if e != nil {
+			err = errors.Wrap(e, "failed to read")
+			continue
+		}
+
+		if rsp.StatusCode == http.StatusOK || contains(accept, rsp.StatusCode) {
+			return buf, nil
+		}
+
+		err = errors.New("invalid status " + strconv.Itoa(rsp.StatusCode) + ": " + string(bytes.TrimSpace(buf)))
+
+		if rsp.StatusCode != http.StatusTooManyRequests && rsp.StatusCode < http.StatusInternalServerError {
+			break
+		}
+	}
+
+	return nil, err
+}
+
+func contains(data []int, val int) bool {
+	for _, item := range data {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+var (
+	runs = []proto.Run{
+		{
+			ID:       "foo",
+			Commit:   "8f71e42dbcd8c68d849e483c04670f58621aab9c",
+			Repo:     "repo",
+			Start:    time.Now(),
+			Duration: time.Second,
+			Status:   proto.StatusSuccess,
+			Findings: []proto.Format{
+				{File: "name", Line: 1, Type: proto.TypeError, Details: "text"},
+				{File: "name", Line: 2, Type: proto.TypeWarn, Details: "text"},
+			},
+		},
+	}
+)
+
+func TestExport(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Exports = []config.Export{{Name: "invalid"}}
+
+	e := New(cfg)
+	assert.Equal(t, 0, len(e.(*export).hdls))
+
+	err := e.Run(nil)
+	assert.Equal(t, nil, err)
+
+	err = e.Run(runs)
+	assert.Equal(t, nil, err)
+
+	err = e.Flush()
+	assert.Equal(t, nil, err)
+}
+
+type sinkTest struct {
+	runs []proto.Run
+}
+
+func (s *sinkTest) Run(runs []proto.Run) error {
+	s.runs = append(s.runs, runs...)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	s := &sinkTest{}
+	e := &export{cfg: DefaultConfig(), hdls: []sink{s}}
+
+	err := e.Run(runs)
+	assert.Equal(t, nil, err)
+	err = e.Run(runs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(s.runs))
+	assert.NotEqual(t, nil, e.timer)
+
+	err = e.Flush()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(s.runs))
+	assert.Equal(t, 0, len(e.queue))
+	assert.Equal(t, (*time.Timer)(nil), e.timer)
+}
+
+func TestRows(t *testing.T) {
+	r, f := rows(runs)
+	assert.Equal(t, 1, len(r))
+	assert.Equal(t, 2, len(f))
+	assert.Equal(t, "foo", f[1]["run"])
+	assert.Equal(t, "foo-1", f[1]["id"])
+}
+
+func TestBatches(t *testing.T) {
+	data := make([]map[string]interface{}, 5)
+
+	assert.Equal(t, 3, len(batches(data, 2)))
+	assert.Equal(t, 1, len(batches(data, 0)))
+	assert.Equal(t, 0, len(batches(nil, 2)))
+}
+
+func TestRequest(t *testing.T) {
+	backoff = time.Millisecond
+
+	count := 0
+	status := http.StatusServiceUnavailable
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if status == http.StatusBadRequest {
+			w.WriteHeader(status)
+			return
+		}
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	req := func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, ts.URL, nil)
+	}
+
+	_, err := request(1, req)
+	assert.NotEqual(t, nil, err)
+
+	_, err = request(1, req)
+	assert.Equal(t, nil, err)
+
+	count = 0
+	status = http.StatusBadRequest
+
+	_, err = request(3, req)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,439 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/ignore"
+)
+
+// Env is the change which expression is evaluated on.
+type Env struct {
+	Author  string
+	Branch  string
+	Files   []string
+	Groups  map[string][]string
+	Lines   int
+	Project string
+	Size    string
+}
+
+type Expr interface {
+	Eval(*Env) (bool, error)
+}
+
+type expr struct {
+	root node
+}
+
+type node interface {
+	eval(*Env) (interface{}, error)
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+type call struct {
+	name string
+	arg  string
+}
+
+type ident struct {
+	name string
+}
+
+type literal struct {
+	val interface{}
+}
+
+type not struct {
+	node node
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// Parse parses expression, e.g. project == "foo" && branch =~ "release/*" && (files > 10 || changed("src/**"))
+func Parse(data string) (Expr, error) {
+	tokens, err := lex(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lex")
+	}
+
+	p := &parser{tokens: tokens}
+
+	n, err := p.or()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse")
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, errors.New("unexpected " + p.tokens[p.pos])
+	}
+
+	return &expr{root: n}, nil
+}
+
+func (e *expr) Eval(env *Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to eval")
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.New("invalid result")
+	}
+
+	return b, nil
+}
+
+func lex(data string) ([]string, error) {
+	var tokens []string
+
+	for i := 0; i < len(data); {
+		c := rune(data[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(data[i+1:], data[i])
+			if j < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, data[i:i+j+2])
+			i += j + 2
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_':
+			j := i
+			for j < len(data) && (unicode.IsLetter(rune(data[j])) || unicode.IsDigit(rune(data[j])) || data[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, data[i:j])
+			i = j
+		default:
+			op := ""
+			for _, val := range []string{"&&", "||", "==", "!=", "=~", "!~", ">=", "<=", ">", "<", "!", "(", ")"} {
+				if strings.HasPrefix(data[i:], val) {
+					op = val
+					break
+				}
+			}
+			if op == "" {
+				return nil, errors.New("invalid character " + string(c))
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+
+	return t
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peek() == "!" {
+		p.next()
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{node: n}, nil
+	}
+
+	return p.compare()
+}
+
+func (p *parser) compare() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op := p.peek(); op {
+	case "==", "!=", "=~", "!~", ">=", "<=", ">", "<":
+		p.next()
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return &binary{op: op, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+
+	switch {
+	case t == "":
+		return nil, errors.New("unexpected end")
+	case t == "(":
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing )")
+		}
+		return n, nil
+	case t[0] == '"' || t[0] == '\'':
+		return &literal{val: t[1 : len(t)-1]}, nil
+	case t == "true" || t == "false":
+		return &literal{val: t == "true"}, nil
+	case unicode.IsDigit(rune(t[0])):
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, errors.New("invalid number " + t)
+		}
+		return &literal{val: n}, nil
+	}
+
+	switch t {
+	case "author", "branch", "files", "lines", "project", "size":
+		return &ident{name: t}, nil
+	case "changed", "member":
+		if p.next() != "(" {
+			return nil, errors.New("missing ( of " + t)
+		}
+		arg := p.next()
+		if arg == "" || (arg[0] != '"' && arg[0] != '\'') {
+			return nil, errors.New("invalid argument of " + t)
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing ) of " + t)
+		}
+		return &call{name: t, arg: arg[1 : len(arg)-1]}, nil
+	}
+
+	return nil, errors.New("unexpected " + t)
+}
+
+func (b *binary) eval(env *Env) (interface{}, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.op == "&&" || b.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, errors.New("invalid operand of " + b.op)
+		}
+		if (b.op == "&&" && !l) || (b.op == "||" && l) {
+			return l, nil
+		}
+	}
+
+	right, err := b.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := left.(type) {
+	case bool:
+		r, ok := right.(bool)
+		if !ok {
+			return nil, errors.New("invalid operand of " + b.op)
+		}
+		switch b.op {
+		case "&&", "||":
+			return r, nil
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		}
+	case int:
+		r, ok := right.(int)
+		if !ok {
+			return nil, errors.New("invalid operand of " + b.op)
+		}
+		switch b.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, errors.New("invalid operand of " + b.op)
+		}
+		switch b.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		case "=~", "!~":
+			m, err := glob(r, l)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to match")
+			}
+			return m == (b.op == "=~"), nil
+		}
+	}
+
+	return nil, errors.New("invalid operator " + b.op)
+}
+
+func (c *call) eval(env *Env) (interface{}, error) {
+	switch c.name {
+	case "changed":
+		ig := ignore.New([]byte(c.arg))
+		for _, val := range env.Files {
+			if ig.Match(val) {
+				return true, nil
+			}
+		}
+	case "member":
+		for _, val := range env.Groups[c.arg] {
+			if val == env.Author {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (i *ident) eval(env *Env) (interface{}, error) {
+	switch i.name {
+	case "author":
+		return env.Author, nil
+	case "branch":
+		return env.Branch, nil
+	case "files":
+		return len(env.Files), nil
+	case "lines":
+		return env.Lines, nil
+	case "size":
+		return env.Size, nil
+	default:
+		return env.Project, nil
+	}
+}
+
+func (l *literal) eval(_ *Env) (interface{}, error) {
+	return l.val, nil
+}
+
+func (n *not) eval(env *Env) (interface{}, error) {
+	v, err := n.node.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return nil, errors.New("invalid operand of !")
+	}
+
+	return !b, nil
+}
+
+// glob matches name against pattern in syntax of path.Match per segment, in which "**" matches zero or more segments.
+func glob(pattern, name string) (bool, error) {
+	return segments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func segments(pattern, name []string) (bool, error) {
+	for len(pattern) != 0 {
+		if pattern[0] == "**" {
+			for index := 0; index <= len(name); index++ {
+				if ok, err := segments(pattern[1:], name[index:]); err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0, nil
+}
@@ -0,0 +1,90 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	env := &Env{
+		Author:  "alice@example.com",
+		Branch:  "release/1.0",
+		Files:   []string{"src/main.c", "doc/README.md"},
+		Groups:  map[string][]string{"core": {"alice@example.com"}},
+		Lines:   120,
+		Project: "foo",
+		Size:    "M",
+	}
+
+	helper := func(data string) bool {
+		e, err := Parse(data)
+		assert.Equal(t, nil, err)
+		b, err := e.Eval(env)
+		assert.Equal(t, nil, err)
+		return b
+	}
+
+	assert.Equal(t, true, helper(`project == "foo"`))
+	assert.Equal(t, false, helper(`project != 'foo'`))
+	assert.Equal(t, true, helper(`branch =~ "release/*"`))
+	assert.Equal(t, false, helper(`branch !~ "release/*"`))
+	assert.Equal(t, true, helper(`branch =~ "**/1.0"`))
+	assert.Equal(t, true, helper(`files >= 2 && files < 10`))
+	assert.Equal(t, true, helper(`changed("src/**/*.c")`))
+	assert.Equal(t, false, helper(`changed("*.java")`))
+	assert.Equal(t, true, helper(`member("core")`))
+	assert.Equal(t, true, helper(`size != "XL" && lines > 100`))
+	assert.Equal(t, false, helper(`member("ops")`))
+	assert.Equal(t, true, helper(`!(project == "bar") && (files > 10 || changed("doc/"))`))
+	assert.Equal(t, true, helper(`true || files == "x"`))
+}
+
+func TestGlob(t *testing.T) {
+	helper := func(pattern, name string) bool {
+		ok, err := glob(pattern, name)
+		assert.Equal(t, nil, err)
+		return ok
+	}
+
+	assert.Equal(t, true, helper("**/*.go", "a/b/c.go"))
+	assert.Equal(t, true, helper("**/*.go", "c.go"))
+	assert.Equal(t, true, helper("a/**/c.go", "a/c.go"))
+	assert.Equal(t, true, helper("a/**", "a/b/c.go"))
+	assert.Equal(t, false, helper("a/*", "a/b/c.go"))
+	assert.Equal(t, false, helper("**/*.go", "a/b/c.c"))
+
+	_, err := glob("[", "a")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParse(t *testing.T) {
+	for _, val := range []string{``, `project ==`, `project == "foo`, `(project == "foo"`, `unknown == 1`, `changed(src)`, `project $ 1`, `files files`} {
+		_, err := Parse(val)
+		assert.NotEqual(t, nil, err, val)
+	}
+
+	e, err := Parse(`files == "x"`)
+	assert.Equal(t, nil, err)
+
+	_, err = e.Eval(&Env{})
+	assert.NotEqual(t, nil, err)
+
+	e, err = Parse(`project`)
+	assert.Equal(t, nil, err)
+
+	_, err = e.Eval(&Env{})
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,159 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feedback
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/demote"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+const (
+	window = 7 * 24 * 60 * 60
+)
+
+type Feedback interface {
+	Poll() error
+}
+
+type Config struct {
+	Feedback config.Feedback
+	History  history.History
+	Review   review.Review
+}
+
+type feedback struct {
+	cfg    *Config
+	demote demote.Demote
+}
+
+func New(cfg *Config) Feedback {
+	return &feedback{
+		cfg:    cfg,
+		demote: demote.New(&demote.Config{Demote: cfg.Feedback.Demote}),
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Poll reads replies with keyword to comments on commits of recent runs with findings, and records new ones in history
+// with rules of findings replied to. Admins are notified of rules beyond ratio of false positives by new ones.
+func (f *feedback) Poll() error {
+	if f.cfg.History == nil || f.cfg.Review == nil {
+		return errors.New("invalid config")
+	}
+
+	runs, err := f.cfg.History.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list")
+	}
+
+	known, err := f.cfg.History.Feedback()
+	if err != nil {
+		return errors.Wrap(err, "failed to feedback")
+	}
+
+	seen := map[string]bool{}
+	for _, val := range known {
+		seen[val.ID] = true
+	}
+
+	w := f.cfg.Feedback.Window
+	if w <= 0 {
+		w = window
+	}
+
+	since := time.Now().Add(-time.Duration(w) * time.Second)
+
+	// Findings of the latest run of commit are replied to
+	latest := map[string]proto.Run{}
+
+	for _, run := range runs {
+		if run.Start.Before(since) || len(run.Findings) == 0 {
+			continue
+		}
+		if r, ok := latest[run.Commit]; !ok || run.Start.After(r.Start) {
+			latest[run.Commit] = run
+		}
+	}
+
+	var added []proto.Feedback
+
+	for commit, run := range latest {
+		buf, err := f.cfg.Review.Feedback(commit, f.cfg.Feedback.Keyword)
+		if err != nil {
+			log.Println(errors.Wrap(err, "failed to feedback on "+commit))
+			continue
+		}
+		for _, item := range buf {
+			if seen[item.ID] {
+				continue
+			}
+			item.Lint, item.Rule = resolve(run.Findings, item)
+			if err := f.cfg.History.PutFeedback(item); err != nil {
+				return errors.Wrap(err, "failed to put")
+			}
+			seen[item.ID] = true
+			added = append(added, item)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	before := map[string]bool{}
+
+	for _, val := range f.demote.Rules(runs, known) {
+		before[val.Rule] = true
+	}
+
+	var buf []demote.Entry
+
+	for _, val := range f.demote.Rules(runs, append(append([]proto.Feedback{}, known...), added...)) {
+		if !before[val.Rule] {
+			buf = append(buf, val)
+		}
+	}
+
+	if err := f.demote.Notify(buf); err != nil {
+		return errors.Wrap(err, "failed to notify")
+	}
+
+	return nil
+}
+
+// resolve finds finding which feedback is on, by file and line, and by details in comment replied to if known.
+func resolve(findings []proto.Format, item proto.Feedback) (lint, rule string) {
+	for _, val := range findings {
+		if val.File != item.File || val.Line != item.Line {
+			continue
+		}
+		if item.Comment != "" && !strings.Contains(item.Comment, val.Details) {
+			continue
+		}
+		return val.Lint, val.Rule
+	}
+
+	return "", ""
+}
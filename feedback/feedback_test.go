@@ -0,0 +1,124 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feedback
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+type historyTest struct {
+	feedback []proto.Feedback
+	runs     []proto.Run
+}
+
+func (h *historyTest) Feedback() ([]proto.Feedback, error) {
+	return h.feedback, nil
+}
+
+func (h *historyTest) List() ([]proto.Run, error) {
+	return h.runs, nil
+}
+
+func (h *historyTest) Put(run proto.Run) error {
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+func (h *historyTest) PutFeedback(feedback proto.Feedback) error {
+	h.feedback = append(h.feedback, feedback)
+	return nil
+}
+
+func TestPoll(t *testing.T) {
+	f := New(DefaultConfig())
+	assert.NotEqual(t, nil, f.Poll())
+
+	h := &historyTest{}
+	_ = h.Put(proto.Run{Commit: "a", Start: time.Now().Add(-time.Hour), Findings: []proto.Format{{File: "main.go", Line: 3}}})
+	_ = h.Put(proto.Run{Commit: "a", Start: time.Now(), Findings: []proto.Format{{File: "main.go", Line: 3, Lint: "fake",
+		Rule: "errcheck"}}})
+	_ = h.Put(proto.Run{Commit: "b", Start: time.Now().Add(-30 * 24 * time.Hour), Findings: []proto.Format{{File: "main.go",
+		Line: 3}}})
+	_ = h.Put(proto.Run{Commit: "c", Start: time.Now()})
+
+	cfg := DefaultConfig()
+	cfg.Feedback = config.Feedback{Keyword: "/lintflow false-positive"}
+	cfg.History = h
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	f = New(cfg)
+
+	assert.Equal(t, nil, f.Poll())
+	assert.Equal(t, 1, len(h.feedback))
+	assert.Equal(t, "a", h.feedback[0].Commit)
+	assert.Equal(t, "fake", h.feedback[0].Lint)
+	assert.Equal(t, "errcheck", h.feedback[0].Rule)
+
+	assert.Equal(t, nil, f.Poll())
+	assert.Equal(t, 1, len(h.feedback))
+}
+
+func TestPollDemote(t *testing.T) {
+	var body []byte
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	h := &historyTest{}
+	_ = h.Put(proto.Run{Commit: "a", Start: time.Now(), Findings: []proto.Format{{File: "main.go", Line: 3, Lint: "fake",
+		Rule: "errcheck"}}})
+
+	cfg := DefaultConfig()
+	cfg.Feedback = config.Feedback{Demote: config.Demote{Findings: 1, Notify: s.URL, Ratio: 0.5},
+		Keyword: "/lintflow false-positive"}
+	cfg.History = h
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	f := New(cfg)
+
+	assert.Equal(t, nil, f.Poll())
+	assert.Equal(t, true, strings.Contains(string(body), `"rule":"errcheck"`))
+
+	body = nil
+
+	assert.Equal(t, nil, f.Poll())
+	assert.Equal(t, 0, len(body))
+}
+
+func TestResolve(t *testing.T) {
+	findings := []proto.Format{{File: "main.go", Line: 3, Details: "unchecked error", Lint: "fake", Rule: "errcheck"},
+		{File: "main.go", Line: 3, Details: "unused variable", Lint: "fake", Rule: "unused"}}
+
+	lint, rule := resolve(findings, proto.Feedback{File: "main.go", Line: 3, Comment: "unused variable"})
+	assert.Equal(t, "fake", lint)
+	assert.Equal(t, "unused", rule)
+
+	_, rule = resolve(findings, proto.Feedback{File: "main.go", Line: 3})
+	assert.Equal(t, "errcheck", rule)
+
+	_, rule = resolve(findings, proto.Feedback{File: "main.go", Line: 3, Comment: "nit"})
+	assert.Equal(t, "", rule)
+}
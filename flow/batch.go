@@ -0,0 +1,169 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// ballot is vote on commit of change held in batch, which is superseded by vote on later revision of change. Run
+// of vote is escalated once vote is posted.
+type ballot struct {
+	change int
+	commit string
+	data   []proto.Format
+	mode   string
+	note   *proto.Note
+	run    *proto.Run
+}
+
+// batch holds votes of changes by author for window, and posts them at once to reduce notifications, e.g.,
+// in large refactors.
+type batch struct {
+	cfg    *config.Batch
+	mutex  sync.Mutex
+	post   func([]ballot)
+	timers map[string]*time.Timer
+	votes  map[string][]ballot
+}
+
+func newBatch(cfg *config.Batch, post func([]ballot)) *batch {
+	return &batch{
+		cfg:    cfg,
+		post:   post,
+		timers: map[string]*time.Timer{},
+		votes:  map[string][]ballot{},
+	}
+}
+
+// batched reports whether vote of run from source by author is held in batch. Runs of CLI and library are voted
+// at once, since they exit right after.
+func (b *batch) batched(source, author string) bool {
+	if b == nil || b.cfg.Window <= 0 || source == "" || source == SourceCli || author == "" {
+		return false
+	}
+
+	if len(b.cfg.Author) == 0 {
+		return true
+	}
+
+	for _, val := range b.cfg.Author {
+		if ok, err := path.Match(strings.ToLower(val), strings.ToLower(author)); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hold adds vote by author to batch, which replaces held vote on the same change, and starts window of author
+// if not yet.
+func (b *batch) hold(author string, vote *ballot) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	votes := b.votes[author]
+
+	for index := range votes {
+		if vote.change != 0 && votes[index].change == vote.change {
+			log.Printf("vote on %s superseded by %s", votes[index].commit, vote.commit)
+			votes = append(votes[:index], votes[index+1:]...)
+			break
+		}
+	}
+
+	b.votes[author] = append(votes, *vote)
+
+	if _, ok := b.timers[author]; !ok {
+		b.timers[author] = time.AfterFunc(time.Duration(b.cfg.Window)*time.Second, func() {
+			b.release(author)
+		})
+	}
+}
+
+// release posts votes held by author, in order of holding.
+func (b *batch) release(author string) {
+	b.mutex.Lock()
+	if t, ok := b.timers[author]; ok {
+		t.Stop()
+	}
+	votes := b.votes[author]
+	delete(b.votes, author)
+	delete(b.timers, author)
+	b.mutex.Unlock()
+
+	if len(votes) != 0 {
+		log.Printf("%d votes by %s posted in batch", len(votes), author)
+		b.post(votes)
+	}
+}
+
+// flush posts votes held by all authors before their windows end.
+func (b *batch) flush() {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	var authors []string
+	for key := range b.timers {
+		authors = append(authors, key)
+	}
+	b.mutex.Unlock()
+
+	for _, val := range authors {
+		b.release(val)
+	}
+}
+
+// votes posts votes held in batch, and escalates their runs. Votes on more than one change are posted without
+// notifications, and summed up in one digest on the last change instead, so that author is notified once. Failures
+// are logged only since runs are recorded already.
+func (f *flow) votes(votes []ballot) {
+	quiet := len(votes) > 1
+
+	var buf []string
+
+	for index := range votes {
+		v := &votes[index]
+		n := proto.Note{Quiet: quiet}
+		if v.note != nil {
+			n.Text = v.note.Text
+		}
+		if err := f.cfg.Review.Vote(v.commit, v.data, v.mode, &n); err != nil {
+			log.Println(err)
+			continue
+		}
+		buf = append(buf, fmt.Sprintf("- change %d: %d findings", v.change, len(v.data)))
+		if v.run != nil {
+			f.escalate(v.run)
+		}
+	}
+
+	if !quiet || len(buf) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("lintflow voted on %d changes:\n\n%s", len(buf), strings.Join(buf, "\n"))
+	if err := f.cfg.Review.Notify(votes[len(votes)-1].commit, msg); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+func TestBatched(t *testing.T) {
+	var b *batch
+
+	assert.Equal(t, false, b.batched(SourceApi, "dev@example.com"))
+
+	b = newBatch(&config.Batch{}, nil)
+	assert.Equal(t, false, b.batched(SourceApi, "dev@example.com"))
+
+	b = newBatch(&config.Batch{Window: 600}, nil)
+	assert.Equal(t, true, b.batched(SourceApi, "dev@example.com"))
+	assert.Equal(t, false, b.batched(SourceCli, "dev@example.com"))
+	assert.Equal(t, false, b.batched("", "dev@example.com"))
+	assert.Equal(t, false, b.batched(SourceApi, ""))
+
+	b = newBatch(&config.Batch{Author: []string{"*@example.com"}, Window: 600}, nil)
+	assert.Equal(t, true, b.batched(SourceCommand, "Dev@Example.com"))
+	assert.Equal(t, false, b.batched(SourceCommand, "dev@example.org"))
+}
+
+func TestBatch(t *testing.T) {
+	var posted []string
+
+	b := newBatch(&config.Batch{Window: 600}, func(votes []ballot) {
+		for _, val := range votes {
+			posted = append(posted, val.commit)
+		}
+	})
+
+	b.hold("dev", &ballot{change: 1, commit: "a", mode: proto.ModeFull})
+	b.hold("dev", &ballot{change: 2, commit: "b", mode: proto.ModeFull})
+	b.hold("dev", &ballot{change: 1, commit: "c", mode: proto.ModeFull})
+	b.hold("ops", &ballot{change: 3, commit: "d", mode: proto.ModeFull})
+	assert.Equal(t, 0, len(posted))
+	assert.Equal(t, 2, len(b.timers))
+
+	b.release("dev")
+	assert.Equal(t, []string{"b", "c"}, posted)
+	assert.Equal(t, 1, len(b.timers))
+
+	b.flush()
+	assert.Equal(t, []string{"b", "c", "d"}, posted)
+	assert.Equal(t, 0, len(b.timers))
+	assert.Equal(t, 0, len(b.votes))
+
+	f := flow{cfg: DefaultConfig()}
+	f.Flush()
+}
+
+type batchReview struct {
+	review.Review
+	notes    []string
+	notifies []string
+	quiet    []bool
+}
+
+func (b *batchReview) Notify(commit, message string) error {
+	b.notifies = append(b.notifies, commit+": "+message)
+	return nil
+}
+
+func (b *batchReview) Vote(_ string, _ []proto.Format, _ string, note *proto.Note) error {
+	b.notes, b.quiet = append(b.notes, note.Text), append(b.quiet, note.Quiet)
+	return nil
+}
+
+func TestVotes(t *testing.T) {
+	r := &batchReview{}
+
+	cfg := DefaultConfig()
+	cfg.Review = r
+
+	f := flow{cfg: cfg}
+
+	f.votes([]ballot{{change: 1, commit: "a", note: &proto.Note{Text: "Change size XS: 1 files, 1 lines"}}})
+	assert.Equal(t, []string{"Change size XS: 1 files, 1 lines"}, r.notes)
+	assert.Equal(t, []bool{false}, r.quiet)
+	assert.Equal(t, 0, len(r.notifies))
+
+	f.votes([]ballot{{change: 1, commit: "a"}, {change: 2, commit: "b", data: []proto.Format{{Type: proto.TypeError}},
+		run: &proto.Run{Change: 2, Commit: "b"}}})
+	assert.Equal(t, []bool{false, true, true}, r.quiet)
+	assert.Equal(t, []string{"b: lintflow voted on 2 changes:\n\n- change 1: 0 findings\n- change 2: 1 findings"}, r.notifies)
+}
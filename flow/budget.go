@@ -0,0 +1,73 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+var (
+	stages  = []string{proto.StageFetch, proto.StageLint, proto.StageVote}
+	weights = map[string]int{proto.StageFetch: 2, proto.StageLint: 7, proto.StageVote: 1}
+)
+
+// budget divides remaining time before deadline across stages by weights, so that no stage eats the whole run.
+type budget struct {
+	ctx      context.Context
+	deadline time.Time
+}
+
+func newBudget(ctx context.Context, start time.Time, deadline time.Duration) *budget {
+	if deadline <= 0 {
+		return &budget{ctx: ctx}
+	}
+
+	return &budget{ctx: ctx, deadline: start.Add(deadline)}
+}
+
+// next allots share of remaining time to stage by its weight against the stages left.
+func (b *budget) next(stage string) (context.Context, context.CancelFunc, error) {
+	if err := b.ctx.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "canceled before "+stage)
+	}
+
+	if b.deadline.IsZero() {
+		ctx, cancel := context.WithCancel(b.ctx)
+		return ctx, cancel, nil
+	}
+
+	remain := time.Until(b.deadline)
+	if remain <= 0 {
+		return nil, nil, errors.New("deadline exceeded before " + stage)
+	}
+
+	sum := 0
+
+	for i := len(stages) - 1; i >= 0; i-- {
+		sum += weights[stages[i]]
+		if stages[i] == stage {
+			break
+		}
+	}
+
+	share := remain * time.Duration(weights[stage]) / time.Duration(sum)
+
+	ctx, cancel := context.WithTimeout(b.ctx, share)
+
+	return ctx, cancel, nil
+}
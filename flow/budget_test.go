@@ -0,0 +1,66 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestBudget(t *testing.T) {
+	b := newBudget(context.Background(), time.Now(), 0)
+
+	ctx, cancel, err := b.next(proto.StageLint)
+	assert.Equal(t, nil, err)
+	_, ok := ctx.Deadline()
+	assert.Equal(t, false, ok)
+	cancel()
+
+	b = newBudget(context.Background(), time.Now(), 80*time.Second)
+
+	ctx, cancel, err = b.next(proto.StageLint)
+	assert.Equal(t, nil, err)
+	d, ok := ctx.Deadline()
+	assert.Equal(t, true, ok)
+	assert.InDelta(t, float64(70*time.Second), float64(time.Until(d)), float64(time.Second))
+	cancel()
+
+	ctx, cancel, err = b.next(proto.StageVote)
+	assert.Equal(t, nil, err)
+	d, _ = ctx.Deadline()
+	assert.InDelta(t, float64(80*time.Second), float64(time.Until(d)), float64(time.Second))
+	cancel()
+
+	_, cancel, err = b.next(proto.StageFetch)
+	assert.Equal(t, nil, err)
+	cancel()
+
+	b = newBudget(context.Background(), time.Now().Add(-time.Minute), time.Second)
+
+	_, _, err = b.next(proto.StageFetch)
+	assert.NotEqual(t, nil, err)
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b = newBudget(c, time.Now(), 0)
+
+	_, _, err = b.next(proto.StageVote)
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+}
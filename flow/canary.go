@@ -0,0 +1,99 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"log"
+	"time"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// canaries returns lints in canary, which are promoted once their confidence period in history elapses if set.
+func (f *flow) canaries(now time.Time) []string {
+	var ret []string
+	var runs []proto.Run
+	var loaded bool
+
+	for index := range f.cfg.Config.Spec.Lint {
+		v := &f.cfg.Config.Spec.Lint[index]
+		if !v.Canary.Enabled {
+			continue
+		}
+		if v.Canary.Period <= 0 || f.cfg.History == nil {
+			ret = append(ret, v.Name)
+			continue
+		}
+		if !loaded {
+			var err error
+			if runs, err = f.cfg.History.List(); err != nil {
+				log.Println(err)
+			}
+			loaded = true
+		}
+		if promoted(v.Name, &v.Canary, runs, now) {
+			log.Printf("lint %s promoted from canary", v.Name)
+			continue
+		}
+		ret = append(ret, v.Name)
+	}
+
+	return ret
+}
+
+// promoted reports whether confidence period of canary elapses since its first run in history. Findings of canaries
+// are never posted, so that no feedback on them is measured.
+func promoted(name string, cfg *config.Canary, runs []proto.Run, now time.Time) bool {
+	var start time.Time
+
+	for _, run := range runs {
+		if _, ok := run.Canary[name]; !ok {
+			continue
+		}
+		if start.IsZero() || run.Start.Before(start) {
+			start = run.Start
+		}
+	}
+
+	return !start.IsZero() && now.Sub(start) >= time.Duration(cfg.Period)*time.Second
+}
+
+// split moves findings of lints in canary out of data into map by lints, in which lints without findings are kept.
+func split(data []proto.Format, canaries []string) ([]proto.Format, map[string][]proto.Format) {
+	if len(canaries) == 0 {
+		return data, nil
+	}
+
+	canary := map[string][]proto.Format{}
+
+	for _, val := range canaries {
+		canary[val] = []proto.Format{}
+	}
+
+	var ret []proto.Format
+
+	for _, val := range data {
+		if _, ok := canary[val.Lint]; ok {
+			canary[val.Lint] = append(canary[val.Lint], val)
+			continue
+		}
+		ret = append(ret, val)
+	}
+
+	if ret == nil && data != nil {
+		ret = []proto.Format{}
+	}
+
+	return ret, canary
+}
@@ -0,0 +1,85 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestCanaries(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	h := history.New(&history.Config{Path: filepath.Join(d, "history.json")})
+	now := time.Now()
+
+	_ = h.Put(proto.Run{Commit: "a", Start: now.Add(-48 * time.Hour), Canary: map[string][]proto.Format{
+		"lintnew": {{File: "main.go", Line: 1, Lint: "lintnew"}, {File: "main.go", Line: 2, Lint: "lintnew"}},
+	}})
+	_ = h.Put(proto.Run{Commit: "b", Start: now.Add(-time.Hour), Canary: map[string][]proto.Format{
+		"lintnew":   {},
+		"lintfresh": {{File: "main.go", Line: 1, Lint: "lintfresh"}},
+	}})
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Lint = []config.Lint{
+		{Name: "lintcpp"},
+		{Name: "lintnew", Canary: config.Canary{Enabled: true, Period: 86400}},
+		{Name: "lintfresh", Canary: config.Canary{Enabled: true, Period: 86400}},
+		{Name: "lintlong", Canary: config.Canary{Enabled: true, Period: 86400}},
+		{Name: "lintmanual", Canary: config.Canary{Enabled: true}},
+	}
+	cfg.History = h
+
+	f := flow{cfg: cfg}
+
+	assert.Equal(t, []string{"lintfresh", "lintlong", "lintmanual"}, f.canaries(now))
+
+	cfg.Config.Spec.Lint[1].Canary.Period = 7 * 86400
+
+	assert.Equal(t, []string{"lintnew", "lintfresh", "lintlong", "lintmanual"}, f.canaries(now))
+
+	cfg.History = nil
+
+	assert.Equal(t, []string{"lintnew", "lintfresh", "lintlong", "lintmanual"}, f.canaries(now))
+}
+
+func TestSplit(t *testing.T) {
+	data := []proto.Format{{File: "main.go", Lint: "lintcpp"}, {File: "main.go", Lint: "lintnew"}}
+
+	buf, canary := split(data, nil)
+	assert.Equal(t, data, buf)
+	assert.Equal(t, 0, len(canary))
+
+	buf, canary = split(data, []string{"lintnew", "lintidle"})
+	assert.Equal(t, []proto.Format{{File: "main.go", Lint: "lintcpp"}}, buf)
+	assert.Equal(t, map[string][]proto.Format{"lintnew": {{File: "main.go", Lint: "lintnew"}}, "lintidle": {}}, canary)
+
+	buf, _ = split(data[1:], []string{"lintnew"})
+	assert.Equal(t, []proto.Format{}, buf)
+
+	buf, _ = split(nil, []string{"lintnew"})
+	assert.Equal(t, 0, len(buf))
+}
@@ -0,0 +1,185 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/docs"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	capsuleConfig = "config.yml"
+	capsuleFiles  = "files"
+	capsuleRun    = "capsule.json"
+	capsulePerm   = 0600
+)
+
+// Capsule keeps inputs of run, i.e., config, content of files, change and versions of lints, to reproduce it.
+type Capsule struct {
+	Change proto.Change  `json:"change"`
+	Config config.Config `json:"-"`
+	Path   string        `json:"-"`
+	Run    proto.Run     `json:"run"`
+}
+
+// LoadCapsule loads capsule of run in id under path.
+func LoadCapsule(path, id string) (*Capsule, error) {
+	dir := filepath.Join(path, id)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, capsuleRun))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	c := Capsule{Path: dir}
+
+	if err := json.Unmarshal(buf, &c); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	buf, err = ioutil.ReadFile(filepath.Join(dir, capsuleConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	if err := yaml.Unmarshal(buf, &c.Config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if hash(buf) != c.Run.Config {
+		return nil, errors.New("mismatched config")
+	}
+
+	return &c, nil
+}
+
+// Reproduce lints files in capsule with lints and policy in cfg, which are built from config of capsule, and returns
+// findings with tool versions of lints.
+func Reproduce(ctx context.Context, cfg *Config, c *Capsule) ([]proto.Format, map[string]string, error) {
+	dir := filepath.Join(c.Path, capsuleFiles)
+
+	var files []string
+
+	for key, val := range c.Run.Files {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(key)))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read")
+		}
+		if hash(buf) != val {
+			return nil, nil, errors.New("mismatched file " + key)
+		}
+		files = append(files, key)
+	}
+
+	cfg.Config = c.Config
+
+	f := &flow{cfg: cfg, docs: docs.New(&docs.Config{Docs: cfg.Config.Spec.Docs})}
+	env := f.env(&c.Change, c.Run.Repo, files)
+
+	buf, versions, err := cfg.Lint.Run(lint.WithChange(ctx, &c.Change), dir, c.Run.Repo, files, f.matcher(env))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to run")
+	}
+
+	if cfg.Policy != nil {
+		buf = cfg.Policy.Normalize(c.Run.Repo, buf)
+	}
+
+	return f.link(buf), versions, nil
+}
+
+// capsule records hashes of config and files in run, and saves them with content under path of capsules if enabled.
+func (f *flow) capsule(run *proto.Run, change *proto.Change, dir string, files []string) error {
+	path := f.cfg.Config.Spec.Capsule.Path
+	if path == "" {
+		return nil
+	}
+
+	cfg := reproducible(&f.cfg.Config)
+
+	b, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	base := filepath.Join(path, run.ID)
+
+	if err := storage.WriteFile(filepath.Join(base, capsuleConfig), b, capsulePerm); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	run.Config = hash(b)
+	run.Files = map[string]string{}
+
+	s := f.cfg.Storage
+	if s == nil {
+		s = storage.New(storage.DefaultConfig())
+	}
+
+	for _, val := range files {
+		buf, err := s.Read(filepath.Join(dir, filepath.FromSlash(val)))
+		if err != nil {
+			return errors.Wrap(err, "failed to read")
+		}
+		if err := storage.WriteFile(filepath.Join(base, capsuleFiles, filepath.FromSlash(val)), buf, capsulePerm); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+		run.Files[val] = hash(buf)
+	}
+
+	b, err = json.Marshal(&Capsule{Change: *change, Run: *run})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if err := storage.WriteFile(filepath.Join(base, capsuleRun), b, capsulePerm); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// reproducible returns subset of config used to reproduce runs, i.e., lints and their docs, groups, policy and size,
+// so that credentials of reviews, exports, storage and others are never saved.
+func reproducible(cfg *config.Config) config.Config {
+	return config.Config{
+		ApiVersion: cfg.ApiVersion,
+		Kind:       cfg.Kind,
+		MetaData:   cfg.MetaData,
+		Spec: config.Spec{
+			Docs:   cfg.Spec.Docs,
+			Group:  cfg.Spec.Group,
+			Lint:   cfg.Spec.Lint,
+			Policy: cfg.Spec.Policy,
+			Size:   cfg.Spec.Size,
+		},
+	}
+}
+
+func hash(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
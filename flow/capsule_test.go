@@ -0,0 +1,102 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+func TestCapsule(t *testing.T) {
+	d, err := ioutil.TempDir("", "capsule")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	lints := []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Capsule.Path = d
+	cfg.Config.Spec.Lint = lints
+	cfg.Config.Spec.Review = []config.Review{{Name: "fake", Pass: "pass"}}
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: cfg.Config.Spec.Review})
+
+	buf, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+
+	ids, _ := ioutil.ReadDir(d)
+	assert.Equal(t, 1, len(ids))
+
+	c, err := LoadCapsule(d, ids[0].Name())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(c.Config.Spec.Review))
+	assert.Equal(t, buf, c.Run.Findings)
+	assert.Equal(t, 3, len(c.Run.Files))
+
+	r := DefaultConfig()
+	r.Lint = lint.New(&lint.Config{Lints: c.Config.Spec.Lint})
+
+	ret, versions, err := Reproduce(context.Background(), r, c)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "Fake error by lintflow",
+		Lint: "fake"}}, ret)
+	assert.Equal(t, 1, len(versions))
+
+	_ = ioutil.WriteFile(filepath.Join(c.Path, capsuleFiles, "main.go.base64"), []byte(""), capsulePerm)
+
+	_, _, err = Reproduce(context.Background(), r, c)
+	assert.NotEqual(t, nil, err)
+
+	_, err = LoadCapsule(d, "invalid")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCapsuleSecrets(t *testing.T) {
+	d, err := ioutil.TempDir("", "capsule")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Capsule.Path = d
+	cfg.Config.Spec.Export = []config.Export{{Name: "clickhouse", Pass: "hidden-export-pass"},
+		{Name: "bigquery", Token: "hidden-export-token"}}
+	cfg.Config.Spec.Leader.Url = "redis://:hidden-leader-pass@127.0.0.1:6379"
+	cfg.Config.Spec.Lint = []config.Lint{{Name: "fake"}}
+	cfg.Config.Spec.Review = []config.Review{{Name: "gerrit", Pass: "hidden-review-pass"}}
+	cfg.Config.Spec.Workspace.Lfs.Pass = "hidden-lfs-pass"
+	cfg.Config.Spec.Workspace.Storage.Key = "hidden-storage-key"
+	cfg.Config.Spec.Workspace.Storage.Secret = "hidden-storage-secret"
+
+	f := &flow{cfg: cfg}
+
+	err = f.capsule(&proto.Run{ID: "1"}, &proto.Change{}, d, nil)
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(d, "1", capsuleConfig))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, strings.Contains(string(buf), "hidden"))
+	assert.Equal(t, true, strings.Contains(string(buf), "fake"))
+}
@@ -0,0 +1,52 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/craftslab/lintflow/language"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// coverage returns languages of changed files if none of them is matched by lints, e.g., language new to repo.
+func (f *flow) coverage(repo string, files []string) []string {
+	if len(f.cfg.Config.Spec.Lint) == 0 {
+		return nil
+	}
+
+	found := map[string]bool{}
+
+	for _, file := range files {
+		if file == proto.Base64Message {
+			continue
+		}
+		for index := range f.cfg.Config.Spec.Lint {
+			if f.match(&f.cfg.Config.Spec.Lint[index].Filter, repo, file) {
+				return nil
+			}
+		}
+		found[language.Detect(strings.TrimSuffix(file, proto.Base64Content))] = true
+	}
+
+	var ret []string
+
+	for key := range found {
+		ret = append(ret, key)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
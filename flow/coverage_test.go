@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+func TestCoverage(t *testing.T) {
+	cfg := DefaultConfig()
+
+	f := flow{cfg: cfg}
+	files := []string{"main.rs.base64", "doc/README.md.base64", proto.Base64Message}
+
+	assert.Equal(t, 0, len(f.coverage("", files)))
+
+	cfg.Config.Spec.Lint = []config.Lint{{Name: "lintgo", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+	assert.Equal(t, []string{"Markdown", "Rust"}, f.coverage("", files))
+
+	cfg.Config.Spec.Lint = append(cfg.Config.Spec.Lint,
+		config.Lint{Name: "lintmd", Filter: config.Filter{Include: config.Include{Extension: []string{".md"}}}})
+	assert.Equal(t, 0, len(f.coverage("", files)))
+}
+
+func TestCoverageFail(t *testing.T) {
+	lints := []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".java"}}}}}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Lint = lints
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	cfg.Policy = policy.New(&policy.Config{Policy: config.Policy{Coverage: policy.CoverageWarn}})
+
+	_, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+
+	cfg.Policy = policy.New(&policy.Config{Policy: config.Policy{Coverage: policy.CoverageFail}})
+
+	_, err = New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.NotEqual(t, nil, err)
+}
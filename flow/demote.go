@@ -0,0 +1,46 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"log"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+// demoted demotes or drops findings of rules beyond ratio of false positives in feedback of history, if enabled.
+func (f *flow) demoted(data []proto.Format) []proto.Format {
+	if f.cfg.Config.Spec.Feedback.Demote.Ratio <= 0 || f.cfg.History == nil || f.demote == nil {
+		return data
+	}
+
+	runs, err := f.cfg.History.List()
+	if err != nil {
+		log.Println(err)
+		return data
+	}
+
+	feedback, err := f.cfg.History.Feedback()
+	if err != nil {
+		log.Println(err)
+		return data
+	}
+
+	buf := f.demote.Rules(runs, feedback)
+
+	for _, val := range buf {
+		log.Printf("rule %s %sd by false positive ratio %.3f", val.Rule, val.Action, val.Ratio)
+	}
+
+	return f.demote.Apply(data, buf)
+}
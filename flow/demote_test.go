@@ -0,0 +1,51 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/demote"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestDemoted(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	h := history.New(&history.Config{Path: filepath.Join(d, "history.json")})
+	data := []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Rule: "errcheck"}}
+
+	_ = h.Put(proto.Run{Commit: "a", Findings: data})
+	_ = h.PutFeedback(proto.Feedback{File: "main.go", Line: 1, Rule: "errcheck"})
+
+	cfg := DefaultConfig()
+	cfg.History = h
+
+	f := flow{cfg: cfg, demote: demote.New(&demote.Config{})}
+	assert.Equal(t, data, f.demoted(data))
+
+	cfg.Config.Spec.Feedback.Demote = config.Demote{Findings: 1, Ratio: 0.5}
+	f.demote = demote.New(&demote.Config{Demote: cfg.Config.Spec.Feedback.Demote})
+
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeInfo, Rule: "errcheck"}}, f.demoted(data))
+}
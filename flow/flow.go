@@ -14,50 +14,131 @@ package flow
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
-	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/demote"
+	"github.com/craftslab/lintflow/docs"
+	"github.com/craftslab/lintflow/export"
+	"github.com/craftslab/lintflow/expr"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/language"
+	"github.com/craftslab/lintflow/lfs"
 	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/progress"
 	"github.com/craftslab/lintflow/proto"
 	"github.com/craftslab/lintflow/review"
 	"github.com/craftslab/lintflow/runtime"
+	"github.com/craftslab/lintflow/sla"
+	"github.com/craftslab/lintflow/storage"
+	"github.com/craftslab/lintflow/telemetry"
 )
 
 type Flow interface {
+	Flush()
 	Run(string) ([]proto.Format, error)
+	RunContext(context.Context, string) ([]proto.Format, error)
 }
 
+const (
+	idLength = 8
+)
+
 type Config struct {
-	Config config.Config
-	Lint   lint.Lint
-	Review review.Review
+	Config    config.Config
+	Deadline  time.Duration
+	Export    export.Export
+	History   history.History
+	Hooks     []Hook
+	Keep      bool
+	Lint      lint.Lint
+	Policy    policy.Policy
+	Review    review.Review
+	Root      string
+	Storage   storage.Storage
+	Telemetry telemetry.Telemetry
 }
 
 type flow struct {
-	cfg *Config
+	batch    *batch
+	cfg      *Config
+	deadline time.Duration
+	demote   demote.Demote
+	docs     docs.Docs
+	hooks    []Hook
+	lfs      lfs.Lfs
+	sla      sla.Sla
 }
 
 func New(_ context.Context, cfg *Config) Flow {
-	return &flow{
-		cfg: cfg,
+	hooks := append([]Hook{}, cfg.Hooks...)
+
+	for _, val := range cfg.Config.Spec.Hook {
+		hooks = append(hooks, &execHook{cfg: val})
+	}
+
+	deadline := cfg.Deadline
+	if deadline <= 0 {
+		deadline = time.Duration(cfg.Config.Spec.Deadline) * time.Second
+	}
+
+	f := &flow{
+		cfg:      cfg,
+		deadline: deadline,
+		demote:   demote.New(&demote.Config{Demote: cfg.Config.Spec.Feedback.Demote}),
+		docs:     docs.New(&docs.Config{Docs: cfg.Config.Spec.Docs}),
+		hooks:    hooks,
+		lfs:      lfs.New(&lfs.Config{Lfs: cfg.Config.Spec.Workspace.Lfs}),
+		sla:      sla.New(&sla.Config{Rules: cfg.Config.Spec.Sla.Rule}),
+	}
+
+	if cfg.Config.Spec.Batch.Window > 0 {
+		f.batch = newBatch(&cfg.Config.Spec.Batch, f.votes)
 	}
+
+	return f
 }
 
 func DefaultConfig() *Config {
 	return &Config{}
 }
 
+// Flush posts votes held in batch and exports queued runs at once, e.g., before exit.
+func (f *flow) Flush() {
+	f.batch.flush()
+
+	if f.cfg.Export != nil {
+		if err := f.cfg.Export.Flush(); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
 func (f *flow) Run(commit string) ([]proto.Format, error) {
+	return f.RunContext(context.Background(), commit)
+}
+
+// RunContext runs flow on commit, which is stopped before the next stage and lints are interrupted if ctx is canceled.
+func (f *flow) RunContext(ctx context.Context, commit string) ([]proto.Format, error) {
 	var err error
 	var ret []proto.Format
 
-	buf, err := runtime.Run(f.routine, []interface{}{commit})
+	routine := func(data interface{}) interface{} {
+		return f.routine(ctx, data)
+	}
+
+	buf, err := runtime.Run(routine, []interface{}{commit})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to run")
 	}
@@ -77,42 +158,403 @@ func (f *flow) Run(commit string) ([]proto.Format, error) {
 	return ret, err
 }
 
-func (f *flow) routine(data interface{}) interface{} {
-	d, _ := os.Getwd()
-	t := time.Now()
-	root := filepath.Join(d, "gerrit-"+t.Format("2006-01-02"))
-
+func (f *flow) routine(ctx context.Context, data interface{}) interface{} {
 	commit := data.(string)
 
-	dir, repo, files, err := f.cfg.Review.Fetch(root, commit)
-	defer func() { _ = f.cfg.Review.Clean(root) }()
-	if err != nil {
+	run := proto.Run{ID: f.id(), Commit: commit, Start: time.Now(), Status: proto.StatusFailed}
+	defer f.record(&run)
+
+	p := progress.Of(ctx)
+
+	fail := func(err error, stage string) interface{} {
 		log.Println(err)
+		p.Report(progress.Event{Stage: stage, State: progress.StateFailed})
+		run.Error, run.Stage = err.Error(), stage
+		if ctx.Err() != nil {
+			run.Status = proto.StatusCanceled
+		} else if stage != proto.StageVote && run.Mode != proto.ModeSilent {
+			f.notify(commit, stage, err)
+		}
 		return nil
 	}
 
-	buf, err := f.cfg.Lint.Run(dir, repo, files, f.match)
+	b := newBudget(ctx, run.Start, f.deadline)
+
+	root, err := f.workspace(run.ID)
+	if err != nil {
+		return fail(err, proto.StageFetch)
+	}
+
+	h := HookData{Commit: commit}
+
+	if err := f.hook(HookPreFetch, &h); err != nil {
+		return fail(err, proto.StageFetch)
+	}
+
+	c, cancel, err := b.next(proto.StageFetch)
+	if err != nil {
+		return fail(err, proto.StageFetch)
+	}
+
+	p.Report(progress.Event{Stage: proto.StageFetch, State: progress.StateRunning})
+
+	dir, repo, files, err := review.WithContext(c, f.cfg.Review).Fetch(root, commit)
+	cancel()
+	defer f.clean(root)
 	if err != nil {
+		return fail(err, proto.StageFetch)
+	}
+
+	files = f.pointers(ctx, dir, repo, files)
+
+	run.Repo = repo
+	run.Mode = f.mode(repo, sourceOf(ctx), "")
+
+	h.Dir, h.Repo, h.Files = dir, repo, files
+	if err := f.hook(HookPreLint, &h); err != nil {
+		return fail(err, proto.StageLint)
+	}
+
+	change, err := f.cfg.Review.Change(commit)
+	if err != nil {
+		return fail(err, proto.StageFetch)
+	}
+
+	p.Report(progress.Event{Stage: proto.StageFetch, State: progress.StateDone})
+
+	run.Change = change.Number
+	run.Mode = f.mode(repo, sourceOf(ctx), change.Author)
+	if run.Mode == proto.ModeSkip {
+		log.Printf("change %s by %s skipped", commit, change.Author)
+		run.Status = proto.StatusSuccess
+		return []proto.Format{}
+	}
+
+	if f.unchanged(change.Kind) {
+		log.Printf("change %s in %s skipped", commit, change.Kind)
+		if run.Mode != proto.ModeSilent {
+			if err := f.cfg.Review.Notify(commit, "lintflow found no code change, previous results apply"); err != nil {
+				log.Println(err)
+			}
+		}
+		run.Mode = proto.ModeSkip
+		run.Status = proto.StatusSuccess
+		return []proto.Format{}
+	}
+
+	if (run.Mode == proto.ModeFull || run.Mode == proto.ModeVote) && f.frozen(repo, time.Now()) {
+		log.Printf("change %s in freeze, disapproving votes withheld", commit)
+		run.Mode = frozenMode(run.Mode)
+	}
+
+	env := f.env(&change, repo, h.Files)
+	run.Size = env.Size
+	run.Languages = language.Stats(change.Lines)
+
+	log.Printf("change %s in size %s: %d files, %d lines, languages %v", commit, env.Size, len(env.Files), env.Lines,
+		run.Languages)
+
+	if gap := f.coverage(repo, h.Files); len(gap) != 0 && f.cfg.Policy != nil {
+		msg := "no lint for changed files in " + strings.Join(gap, ", ")
+		switch f.cfg.Policy.Coverage() {
+		case policy.CoverageFail:
+			return fail(errors.New(msg), proto.StageLint)
+		case policy.CoverageWarn:
+			log.Println(msg)
+			if run.Mode == proto.ModeSilent {
+				break
+			}
+			if err := f.cfg.Review.Notify(commit, "lintflow found "+msg); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	var buf []proto.Format
+
+	if prev, carried := f.carry(&change); prev != nil {
+		log.Printf("change %s in %s of %s, findings of run %s carried forward", commit, change.Kind, change.Previous,
+			prev.ID)
+		p.Report(progress.Event{Stage: proto.StageLint, State: progress.StateSkipped})
+		run.Carried, run.Versions = prev.ID, prev.Versions
+		h.Findings = carried
+	} else {
+		if f.cfg.Lint.Parent() {
+			if err := f.cfg.Review.Parent(dir, commit, h.Files); err != nil {
+				return fail(err, proto.StageFetch)
+			}
+		}
+
+		match := f.matcher(env)
+		canaries := f.canaries(time.Now())
+
+		c, cancel, err := b.next(proto.StageLint)
+		if err != nil {
+			return fail(err, proto.StageLint)
+		}
+
+		p.Report(progress.Event{Stage: proto.StageLint, State: progress.StateRunning})
+
+		logs := &lint.Logs{}
+
+		buf, run.Versions, err = f.cfg.Lint.Run(lint.WithCanary(lint.WithLogs(lint.WithChange(c, &change), logs), canaries), dir, repo,
+			h.Files, match)
+		cancel()
+		run.Logs = logs.Map()
+		if err != nil {
+			return fail(err, proto.StageLint)
+		}
+
+		p.Report(progress.Event{Stage: proto.StageLint, State: progress.StateDone})
+
+		log.Printf("change %s linted by versions %v", commit, run.Versions)
+
+		if f.cfg.Policy != nil {
+			buf = f.cfg.Policy.Normalize(repo, buf)
+		}
+
+		buf = f.demoted(buf)
+
+		buf = f.link(buf)
+
+		if buf, run.Canary = split(buf, canaries); len(run.Canary) != 0 {
+			log.Printf("change %s linted by canaries %v, findings recorded only", commit, canaries)
+		}
+
+		h.Findings = buf
+		if err := f.hook(HookPostLint, &h); err != nil {
+			return fail(err, proto.StageLint)
+		}
+	}
+
+	buf = h.Findings
+	run.Findings = buf
+
+	if err := f.capsule(&run, &change, dir, h.Files); err != nil {
 		log.Println(err)
-		return nil
 	}
 
 	if buf == nil {
+		run.Status = proto.StatusSuccess
 		return []proto.Format{}
 	}
 
-	if err := f.cfg.Review.Vote(commit, buf); err != nil {
-		log.Println(err)
-		return nil
+	if run.Mode == proto.ModeSilent {
+		run.Status = proto.StatusSuccess
+		return buf
 	}
 
+	h.Findings = buf
+	if err := f.hook(HookPreVote, &h); err != nil {
+		return fail(err, proto.StageVote)
+	}
+
+	c, cancel, err = b.next(proto.StageVote)
+	if err != nil {
+		return fail(err, proto.StageVote)
+	}
+
+	defer cancel()
+
+	if f.batch.batched(sourceOf(ctx), change.Author) {
+		log.Printf("change %s by %s held in batch", commit, change.Author)
+		run.Status = proto.StatusSuccess
+		held := run
+		f.batch.hold(change.Author, &ballot{change: change.Number, commit: commit, data: h.Findings, mode: run.Mode,
+			note: note(env, run.Languages), run: &held})
+		p.Report(progress.Event{Stage: proto.StageVote, State: progress.StateSkipped})
+		return buf
+	}
+
+	p.Report(progress.Event{Stage: proto.StageVote, State: progress.StateRunning})
+	if err := review.WithContext(c, f.cfg.Review).Vote(commit, h.Findings, run.Mode, note(env, run.Languages)); err != nil {
+		return fail(err, proto.StageVote)
+	}
+	p.Report(progress.Event{Stage: proto.StageVote, State: progress.StateDone})
+
+	run.Status = proto.StatusSuccess
+	f.escalate(&run)
+
 	return buf
 }
 
+// unchanged reports whether revision of kind is without code change to lint, and skipped in config.
+func (f *flow) unchanged(kind string) bool {
+	if kind != proto.KindMergeFirstParentUpdate && kind != proto.KindNoCodeChange {
+		return false
+	}
+
+	for _, val := range f.cfg.Config.Spec.Kind.Skip {
+		if val == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// carry maps findings of the latest successful run of former revision onto change to skip linting, if change is
+// trivial rebase or without code change. Findings of former run are normalized and hooked already.
+func (f *flow) carry(change *proto.Change) (*proto.Run, []proto.Format) {
+	if !f.cfg.Config.Spec.Carry.Enabled || f.cfg.History == nil || change.Previous == "" {
+		return nil, nil
+	}
+
+	if change.Kind != proto.KindNoCodeChange && change.Kind != proto.KindTrivialRebase {
+		return nil, nil
+	}
+
+	runs, err := f.cfg.History.List()
+	if err != nil {
+		log.Println(err)
+		return nil, nil
+	}
+
+	var prev *proto.Run
+
+	for index := range runs {
+		r := &runs[index]
+		if r.Commit == change.Previous && r.Status == proto.StatusSuccess && r.Mode != proto.ModeSkip {
+			prev = r
+		}
+	}
+
+	if prev == nil {
+		return nil, nil
+	}
+
+	buf, err := f.cfg.Review.Carry(change.Previous, change.Commit, prev.Findings)
+	if err != nil {
+		log.Println(err)
+		return nil, nil
+	}
+
+	return prev, buf
+}
+
+// notify posts neutral message of failure which prevents linting to review, if enabled in policy.
+func (f *flow) notify(commit, stage string, err error) {
+	if f.cfg.Policy == nil || !f.cfg.Policy.Notify() {
+		return
+	}
+
+	msg := "lintflow could not " + stage + ": " + errors.Cause(err).Error()
+
+	var e *lint.Error
+	if errors.As(err, &e) {
+		msg = "lintflow could not run " + e.Name + ": " + errors.Cause(e.Err).Error()
+	}
+
+	if err := f.cfg.Review.Notify(commit, msg); err != nil {
+		log.Println(err)
+	}
+}
+
+// clean removes workspace of job, or keeps it for inspection.
+func (f *flow) clean(root string) {
+	if f.cfg.Keep {
+		log.Println("workspace kept in " + root)
+		return
+	}
+
+	if err := f.cfg.Review.Clean(root); err != nil {
+		log.Println(err)
+	}
+}
+
+func (f *flow) record(run *proto.Run) {
+	run.Duration = time.Since(run.Start)
+
+	if f.cfg.History != nil {
+		if err := f.cfg.History.Put(*run); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if f.cfg.Export != nil {
+		if err := f.cfg.Export.Run([]proto.Run{*run}); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if f.cfg.Telemetry != nil {
+		if err := f.cfg.Telemetry.Report(*run); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (f *flow) id() string {
+	buf := make([]byte, idLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+// env describes change for expressions of filter.
+func (f *flow) env(change *proto.Change, repo string, files []string) *expr.Env {
+	env := &expr.Env{
+		Author:  change.Author,
+		Branch:  change.Branch,
+		Groups:  f.cfg.Config.Spec.Group,
+		Lines:   change.Insertions + change.Deletions,
+		Project: repo,
+	}
+
+	for _, val := range files {
+		if val != proto.Base64Message {
+			env.Files = append(env.Files, strings.TrimSuffix(val, proto.Base64Content))
+		}
+	}
+
+	env.Size = classify(len(env.Files), env.Lines, &f.cfg.Config.Spec.Size)
+
+	return env
+}
+
+// matcher matches files with filter, and activates lint on change only if expression in when is true.
+func (f *flow) matcher(env *expr.Env) func(*config.Filter, string, string) bool {
+	var mutex sync.Mutex
+	when := map[string]bool{}
+
+	eval := func(data string) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if b, ok := when[data]; ok {
+			return b
+		}
+		e, err := expr.Parse(data)
+		if err == nil {
+			when[data], err = e.Eval(env)
+		}
+		if err != nil {
+			log.Println(errors.Wrap(err, "failed to eval "+data))
+			when[data] = false
+		}
+		return when[data]
+	}
+
+	return func(filter *config.Filter, repo, file string) bool {
+		if filter == nil || filter.When == "" {
+			return f.match(filter, repo, file)
+		}
+		if !eval(filter.When) {
+			return false
+		}
+		if len(filter.Include.Extension) == 0 && len(filter.Include.File) == 0 {
+			return true
+		}
+		return f.match(filter, repo, file)
+	}
+}
+
 func (f *flow) match(filter *config.Filter, repo, file string) bool {
 	matchExtension := func(filter *config.Filter, data string) bool {
 		for _, val := range filter.Include.Extension {
-			if val == filepath.Ext(strings.TrimSuffix(data, proto.Base64Content)) {
+			if val == path.Ext(strings.TrimSuffix(filepath.ToSlash(data), proto.Base64Content)) {
 				return true
 			}
 		}
@@ -121,7 +563,7 @@ func (f *flow) match(filter *config.Filter, repo, file string) bool {
 
 	matchFile := func(filter *config.Filter, data string) bool {
 		for _, val := range filter.Include.File {
-			if val == filepath.Base(strings.TrimSuffix(data, proto.Base64Content)) {
+			if val == path.Base(strings.TrimSuffix(filepath.ToSlash(data), proto.Base64Content)) {
 				return true
 			}
 		}
@@ -154,3 +596,18 @@ func (f *flow) match(filter *config.Filter, repo, file string) bool {
 
 	return true
 }
+
+// link sets documentation URLs of rules in findings.
+func (f *flow) link(data []proto.Format) []proto.Format {
+	if f.docs == nil {
+		return data
+	}
+
+	for index := range data {
+		if data[index].Docs == "" {
+			data[index].Docs = f.docs.Link(data[index].Rule)
+		}
+	}
+
+	return data
+}
@@ -13,13 +13,131 @@
 package flow
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
 )
 
+func TestRun(t *testing.T) {
+	lints := []config.Lint{
+		{
+			Name: "fake",
+			Filter: config.Filter{
+				Include: config.Include{
+					Extension: []string{".go"},
+					File:      []string{"message"},
+				},
+			},
+		},
+	}
+
+	reviews := []config.Review{
+		{
+			Name: "fake",
+			Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"},
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: reviews})
+
+	f := New(context.Background(), cfg)
+
+	buf, err := f.Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "Fake error by lintflow", Lint: "fake"}}, buf)
+}
+
+func TestKeep(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	reviews := []config.Review{
+		{
+			Name: "fake",
+			Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"},
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.Keep = true
+	cfg.Lint = lint.New(&lint.Config{})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: reviews})
+	cfg.Root = d
+
+	f := New(context.Background(), cfg)
+
+	_, err = f.Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+
+	buf, err := Leftover(d, &config.Workspace{}, nil, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+}
+
+func TestDeadline(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Deadline = time.Nanosecond
+	cfg.Lint = lint.New(&lint.Config{})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	f := New(context.Background(), cfg)
+
+	_, err := f.Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.NotEqual(t, nil, err)
+
+	cfg.Deadline = 0
+	cfg.Config.Spec.Deadline = 60
+
+	assert.Equal(t, time.Minute, New(context.Background(), cfg).(*flow).deadline)
+}
+
+func TestDeadlineFetch(t *testing.T) {
+	var count int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig()
+	cfg.Deadline = time.Second
+	cfg.Lint = lint.New(&lint.Config{})
+	cfg.Review = review.New(&review.Config{Name: "gerrit", Reviews: []config.Review{{Name: "gerrit", Url: ts.URL}}})
+
+	start := time.Now()
+
+	_, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, time.Since(start) < 2*time.Second)
+}
+
 // nolint: funlen
 // nolint: goconst
 func TestFilter(t *testing.T) {
@@ -48,6 +166,12 @@ func TestFilter(t *testing.T) {
 	ret = f.match(&filter, "", "foo.java")
 	assert.Equal(t, true, ret)
 
+	ret = f.match(&filter, "", "src/foo.java.base64")
+	assert.Equal(t, true, ret)
+
+	ret = f.match(&filter, "", "src.java/foo.base64")
+	assert.Equal(t, false, ret)
+
 	ret = f.match(nil, "", "foo.java")
 	assert.Equal(t, false, ret)
 
@@ -74,3 +198,178 @@ func TestFilter(t *testing.T) {
 	ret = f.match(nil, "alpha", "message")
 	assert.Equal(t, false, ret)
 }
+
+func TestMatcher(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Group = map[string][]string{"core": {"fake@example.com"}}
+	cfg.Config.Spec.Lint = []config.Lint{{Name: "fake", Filter: config.Filter{When: `member("core")`}}}
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	f := flow{cfg: cfg}
+	files := []string{"main.go.base64", proto.Base64Message}
+
+	change, err := cfg.Review.Change("commit")
+	assert.Equal(t, nil, err)
+
+	env := f.env(&change, "fake", files)
+	assert.Equal(t, []string{"main.go"}, env.Files)
+	assert.Equal(t, sizeXS, env.Size)
+
+	match := f.matcher(env)
+
+	assert.Equal(t, true, match(&config.Filter{When: `member("core") && changed("*.go")`}, "fake", "main.go.base64"))
+	assert.Equal(t, false, match(&config.Filter{When: `branch == "release"`}, "fake", "main.go.base64"))
+	assert.Equal(t, false, match(&config.Filter{When: `project ==`}, "fake", "main.go.base64"))
+	assert.Equal(t, true, match(&config.Filter{Include: config.Include{Extension: []string{".go"}}, When: `files == 1`},
+		"fake", "main.go.base64"))
+	assert.Equal(t, false, match(&config.Filter{Include: config.Include{Extension: []string{".java"}}, When: `files == 1`},
+		"fake", "main.go.base64"))
+	assert.Equal(t, true, match(&config.Filter{Include: config.Include{Extension: []string{".go"}}}, "fake", "main.go.base64"))
+}
+
+type notifyReview struct {
+	review.Review
+	message string
+}
+
+func (n *notifyReview) Notify(_, message string) error {
+	n.message = message
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	r := &notifyReview{}
+
+	cfg := DefaultConfig()
+	cfg.Review = r
+
+	f := flow{cfg: cfg}
+
+	f.notify("commit", proto.StageLint, errors.New("timeout"))
+	assert.Equal(t, "", r.message)
+
+	cfg.Policy = policy.New(&policy.Config{Policy: config.Policy{Notify: true}})
+
+	f.notify("commit", proto.StageFetch, errors.Wrap(errors.New("timeout"), "failed to fetch"))
+	assert.Equal(t, "lintflow could not fetch: timeout", r.message)
+
+	f.notify("commit", proto.StageLint, errors.Wrap(&lint.Error{Name: "lintcpp", Err: errors.Wrap(errors.New("worker timeout"),
+		"failed to routine")}, "failed to run"))
+	assert.Equal(t, "lintflow could not run lintcpp: worker timeout", r.message)
+}
+
+func TestRunContext(t *testing.T) {
+	r := &notifyReview{}
+
+	cfg := DefaultConfig()
+	cfg.Policy = policy.New(&policy.Config{Policy: config.Policy{Notify: true}})
+	cfg.Review = r
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(context.Background(), cfg).RunContext(ctx, "8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "", r.message)
+}
+
+func TestLink(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Docs = map[string]string{"custom-*": "https://example.com/{rule}"}
+
+	f := New(context.Background(), cfg).(*flow)
+
+	buf := f.link([]proto.Format{{Rule: "SC2086"}, {Rule: "custom-rule"}, {Rule: "unknown"}, {Rule: "SC2046", Docs: "https://example.com"}})
+	assert.Equal(t, "https://www.shellcheck.net/wiki/SC2086", buf[0].Docs)
+	assert.Equal(t, "https://example.com/custom-rule", buf[1].Docs)
+	assert.Equal(t, "", buf[2].Docs)
+	assert.Equal(t, "https://example.com", buf[3].Docs)
+}
+
+type carryReview struct {
+	review.Review
+	from string
+}
+
+func (c *carryReview) Carry(from, _ string, data []proto.Format) ([]proto.Format, error) {
+	c.from = from
+	return data, nil
+}
+
+func TestCarry(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	h := history.New(&history.Config{Path: filepath.Join(d, "history.json")})
+	findings := []proto.Format{{File: "main.go", Line: 3, Details: "error"}}
+
+	_ = h.Put(proto.Run{Commit: "previous", ID: "failed", Status: proto.StatusFailed})
+	_ = h.Put(proto.Run{Commit: "previous", Findings: findings, ID: "linted", Status: proto.StatusSuccess})
+	_ = h.Put(proto.Run{Commit: "previous", ID: "skipped", Mode: proto.ModeSkip, Status: proto.StatusSuccess})
+
+	r := &carryReview{}
+
+	cfg := DefaultConfig()
+	cfg.History = h
+	cfg.Review = r
+
+	f := New(context.Background(), cfg).(*flow)
+	change := proto.Change{Commit: "commit", Kind: proto.KindTrivialRebase, Previous: "previous"}
+
+	prev, _ := f.carry(&change)
+	assert.Equal(t, (*proto.Run)(nil), prev)
+
+	cfg.Config.Spec.Carry.Enabled = true
+
+	prev, buf := f.carry(&change)
+	assert.Equal(t, "linted", prev.ID)
+	assert.Equal(t, findings, buf)
+	assert.Equal(t, "previous", r.from)
+
+	change.Kind = "REWORK"
+	prev, _ = f.carry(&change)
+	assert.Equal(t, (*proto.Run)(nil), prev)
+}
+
+type kindReview struct {
+	review.Review
+	kind    string
+	message string
+}
+
+func (k *kindReview) Change(commit string) (proto.Change, error) {
+	c, err := k.Review.Change(commit)
+	c.Kind = k.kind
+	return c, err
+}
+
+func (k *kindReview) Notify(_, message string) error {
+	k.message = message
+	return nil
+}
+
+func TestRunKind(t *testing.T) {
+	lints := []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+	reviews := []config.Review{{Name: "fake", Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}}}
+
+	r := &kindReview{Review: review.New(&review.Config{Name: "fake", Reviews: reviews}), kind: proto.KindNoCodeChange}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Kind.Skip = []string{proto.KindMergeFirstParentUpdate, proto.KindNoCodeChange, proto.KindTrivialRebase}
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = r
+
+	buf, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+	assert.Equal(t, "lintflow found no code change, previous results apply", r.message)
+
+	r.kind, r.message = proto.KindTrivialRebase, ""
+
+	buf, err = New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "", r.message)
+}
@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	clockLayout = "15:04"
+)
+
+// frozen reports whether project is in any freeze of calendar at now, which withholds disapproving votes.
+func (f *flow) frozen(project string, now time.Time) bool {
+	for _, val := range f.cfg.Config.Spec.Freeze {
+		if val.Project != "" {
+			if ok, err := path.Match(val.Project, project); err != nil || !ok {
+				continue
+			}
+		}
+		ok, err := freeze(&val, now)
+		if err != nil {
+			log.Println(errors.Wrap(err, "failed to freeze"))
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// frozenMode returns mode of run in freeze, which keeps vote only runs free of comments.
+func frozenMode(mode string) string {
+	if mode == proto.ModeVote {
+		return proto.ModeFreezeVote
+	}
+
+	return proto.ModeFreeze
+}
+
+// freeze reports whether now is in window of RFC 3339 start and end, or in daily quiet hours of start and end
+// in clock, e.g., 22:00 to 06:00, on days of week if any.
+func freeze(cfg *config.Freeze, now time.Time) (bool, error) {
+	loc := time.Local
+
+	if cfg.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to load location")
+		}
+		loc = l
+	}
+
+	now = now.In(loc)
+
+	if start, err := time.Parse(time.RFC3339, cfg.Start); err == nil {
+		end, err := time.Parse(time.RFC3339, cfg.End)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to parse end")
+		}
+		return !now.Before(start) && now.Before(end), nil
+	}
+
+	start, err := time.Parse(clockLayout, cfg.Start)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse start")
+	}
+
+	end, err := time.Parse(clockLayout, cfg.End)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse end")
+	}
+
+	clock := now.Hour()*60 + now.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+
+	day := now
+	in := clock >= from && clock < to
+
+	if from > to {
+		// Quiet hours past midnight belong to the day they start
+		in = clock >= from || clock < to
+		if clock < to {
+			day = now.AddDate(0, 0, -1)
+		}
+	}
+
+	if !in {
+		return false, nil
+	}
+
+	if len(cfg.Day) == 0 {
+		return true, nil
+	}
+
+	for _, val := range cfg.Day {
+		if strings.EqualFold(val, day.Weekday().String()[:3]) || strings.EqualFold(val, day.Weekday().String()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,73 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestFreeze(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC)
+
+	ok, err := freeze(&config.Freeze{Start: "2026-10-16T00:00:00Z", End: "2026-10-20T00:00:00Z"}, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	ok, err = freeze(&config.Freeze{Start: "2026-10-17T00:00:00Z", End: "2026-10-20T00:00:00Z"}, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	ok, err = freeze(&config.Freeze{Start: "22:00", End: "06:00", Timezone: "UTC"}, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	ok, err = freeze(&config.Freeze{Day: []string{"Fri"}, Start: "22:00", End: "06:00", Timezone: "UTC"}, now.Add(2*time.Hour))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	ok, err = freeze(&config.Freeze{Day: []string{"Saturday"}, Start: "22:00", End: "06:00", Timezone: "UTC"},
+		now.Add(2*time.Hour))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	ok, err = freeze(&config.Freeze{Start: "09:00", End: "17:00", Timezone: "UTC"}, now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	_, err = freeze(&config.Freeze{Start: "invalid"}, now)
+	assert.NotEqual(t, nil, err)
+
+	_, err = freeze(&config.Freeze{Start: "22:00", End: "06:00", Timezone: "invalid"}, now)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFrozen(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Freeze = []config.Freeze{{Project: "release/*", Start: "2026-10-16T00:00:00Z", End: "2026-10-20T00:00:00Z"}}
+
+	f := flow{cfg: cfg}
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, true, f.frozen("release/foo", now))
+	assert.Equal(t, false, f.frozen("foo", now))
+	assert.Equal(t, false, f.frozen("release/foo", now.AddDate(0, 0, 7)))
+
+	assert.Equal(t, proto.ModeFreeze, frozenMode(proto.ModeFull))
+	assert.Equal(t, proto.ModeFreezeVote, frozenMode(proto.ModeVote))
+}
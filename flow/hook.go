@@ -0,0 +1,105 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	HookPreFetch = "preFetch"
+	HookPreLint  = "preLint"
+	HookPostLint = "postLint"
+	HookPreVote  = "preVote"
+)
+
+const (
+	hookTimeout = 60
+)
+
+// Hook intercepts stage of flow, and could modify files, findings or payload of vote in data.
+type Hook interface {
+	Run(stage string, data *HookData) error
+}
+
+type HookData struct {
+	Commit   string         `json:"commit"`
+	Dir      string         `json:"dir"`
+	Files    []string       `json:"files"`
+	Findings []proto.Format `json:"findings"`
+	Repo     string         `json:"repo"`
+}
+
+// execHook runs command configured for stage, with data in JSON on stdin and modified data in JSON on stdout.
+type execHook struct {
+	cfg config.Hook
+}
+
+func (h *execHook) Run(stage string, data *HookData) error {
+	if h.cfg.Stage != stage || len(h.cfg.Command) == 0 {
+		return nil
+	}
+
+	timeout := h.cfg.Timeout
+	if timeout <= 0 {
+		timeout = hookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, h.cfg.Command[0], h.cfg.Command[1:]...) // nolint:gosec
+	cmd.Stdin = bytes.NewReader(buf)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to run "+stage+" hook: "+stderr.String())
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), data); err != nil {
+		return errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return nil
+}
+
+func (f *flow) hook(stage string, data *HookData) error {
+	for _, val := range f.hooks {
+		if err := val.Run(stage, data); err != nil {
+			return errors.Wrap(err, "failed to hook")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+type hookTest struct {
+	stages []string
+}
+
+func (h *hookTest) Run(stage string, data *HookData) error {
+	h.stages = append(h.stages, stage)
+
+	if stage == HookPostLint {
+		for index := range data.Findings {
+			data.Findings[index].Details = "[org] " + data.Findings[index].Details
+		}
+	}
+
+	return nil
+}
+
+func TestHook(t *testing.T) {
+	h := &hookTest{}
+
+	cfg := DefaultConfig()
+	cfg.Hooks = []Hook{h}
+	cfg.Lint = lint.New(&lint.Config{Lints: []config.Lint{
+		{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}},
+	}})
+	cfg.Review = review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})
+
+	buf, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "[org] Fake error by lintflow", Lint: "fake"}}, buf)
+	assert.Equal(t, []string{HookPreFetch, HookPreLint, HookPostLint, HookPreVote}, h.stages)
+}
+
+func TestExecHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on windows")
+	}
+
+	h := &execHook{cfg: config.Hook{Command: []string{"sh", "-c", `echo '{"files":["main.go.base64"]}'`}, Stage: HookPreLint}}
+
+	data := HookData{Commit: "commit", Files: []string{"main.go.base64", "doc/README.md.base64"}}
+
+	err := h.Run(HookPreFetch, &data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(data.Files))
+
+	err = h.Run(HookPreLint, &data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"main.go.base64"}, data.Files)
+
+	h = &execHook{cfg: config.Hook{Command: []string{"sh", "-c", "exit 1"}, Stage: HookPreLint}}
+
+	err = h.Run(HookPreLint, &data)
+	assert.NotEqual(t, nil, err)
+}
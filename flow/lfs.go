@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"path/filepath"
+
+	"github.com/craftslab/lintflow/lfs"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+// pointers replaces pointer files of Git LFS in workspace with content of their objects if resolved, and returns files
+// without pointers unresolved, which are skipped instead of linting text of pointers.
+func (f *flow) pointers(ctx context.Context, dir, repo string, files []string) []string {
+	s := f.cfg.Storage
+	if s == nil {
+		s = storage.New(storage.DefaultConfig())
+	}
+
+	found := map[string]lfs.Pointer{}
+
+	var buf []lfs.Pointer
+
+	for _, val := range files {
+		if val == proto.Base64Message {
+			continue
+		}
+		b, err := s.Read(filepath.Join(dir, filepath.FromSlash(val)))
+		if err != nil {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(string(b))
+		if err != nil {
+			continue
+		}
+		if p, ok := lfs.Parse(dec); ok {
+			found[val] = p
+			buf = append(buf, p)
+		}
+	}
+
+	if len(found) == 0 {
+		return files
+	}
+
+	objects := map[string][]byte{}
+
+	if f.cfg.Config.Spec.Workspace.Lfs.Url != "" {
+		var err error
+		if objects, err = f.lfs.Resolve(ctx, repo, buf); err != nil {
+			log.Printf("failed to resolve lfs objects of %s: %v", repo, err)
+		}
+	}
+
+	var ret []string
+
+	for _, val := range files {
+		p, ok := found[val]
+		if !ok {
+			ret = append(ret, val)
+			continue
+		}
+		if b, ok := objects[p.Oid]; ok {
+			if err := s.Write(filepath.Join(dir, filepath.FromSlash(val)), []byte(base64.StdEncoding.EncodeToString(b))); err == nil {
+				ret = append(ret, val)
+				continue
+			}
+		}
+		log.Printf("lfs pointer %s skipped", val)
+	}
+
+	return ret
+}
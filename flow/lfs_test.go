@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lfs"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestPointers(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	sum := sha256.Sum256([]byte("large"))
+	oid := hex.EncodeToString(sum[:])
+	text := "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 5\n"
+
+	write := func(name, data string) {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(d, name)), os.ModePerm)
+		_ = ioutil.WriteFile(filepath.Join(d, name), []byte(base64.StdEncoding.EncodeToString([]byte(data))), 0600)
+	}
+
+	files := []string{proto.Base64Message, "main.go" + proto.Base64Content, "data.bin" + proto.Base64Content}
+
+	write(files[0], "Add data\n")
+	write(files[1], "package main\n")
+	write(files[2], text)
+
+	cfg := DefaultConfig()
+	f := flow{cfg: cfg, lfs: lfs.New(lfs.DefaultConfig())}
+
+	assert.Equal(t, files[:2], f.pointers(context.Background(), d, "repo", files))
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/objects" {
+			_, _ = w.Write([]byte("large"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"objects": [{"oid": "` + oid + `", "actions": {"download": {"href": "http://` + r.Host +
+			`/objects"}}}]}`))
+	}))
+	defer s.Close()
+
+	cfg.Config.Spec.Workspace.Lfs = config.Lfs{Url: s.URL}
+	f.lfs = lfs.New(&lfs.Config{Lfs: cfg.Config.Spec.Workspace.Lfs})
+
+	assert.Equal(t, files, f.pointers(context.Background(), d, "repo", files))
+
+	buf, err := ioutil.ReadFile(filepath.Join(d, files[2]))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("large")), string(buf))
+}
@@ -0,0 +1,68 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	SourceApi     = "api"
+	SourceCli     = "cli"
+	SourceCommand = "command"
+)
+
+type sourceKey struct{}
+
+// WithSource returns ctx carrying source of trigger, e.g., api, cli or webhook, which selects mode of run.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+func sourceOf(ctx context.Context) string {
+	source, _ := ctx.Value(sourceKey{}).(string)
+	return source
+}
+
+// mode selects mode of run by the first matched rule of project (glob), source and author (glob), or default.
+// Rules of author never match before author is known.
+func (f *flow) mode(project, source, author string) string {
+	m := &f.cfg.Config.Spec.Mode
+
+	for _, val := range m.Rule {
+		if val.Author != "" {
+			if ok, err := path.Match(strings.ToLower(val.Author), strings.ToLower(author)); err != nil || !ok {
+				continue
+			}
+		}
+		if val.Project != "" {
+			if ok, err := path.Match(val.Project, project); err != nil || !ok {
+				continue
+			}
+		}
+		if val.Source != "" && val.Source != source {
+			continue
+		}
+		return val.Mode
+	}
+
+	if m.Default != "" {
+		return m.Default
+	}
+
+	return proto.ModeFull
+}
@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+)
+
+func TestMode(t *testing.T) {
+	cfg := DefaultConfig()
+	f := flow{cfg: cfg}
+
+	assert.Equal(t, proto.ModeFull, f.mode("foo", SourceApi, ""))
+
+	cfg.Config.Spec.Mode = config.Mode{
+		Default: proto.ModeComment,
+		Rule: []config.ModeRule{
+			{Author: "*-bot@example.com", Mode: proto.ModeSkip},
+			{Mode: proto.ModeSilent, Project: "foo/*", Source: "webhook"},
+			{Mode: proto.ModeVote, Project: "foo/*"},
+		},
+	}
+
+	assert.Equal(t, proto.ModeSilent, f.mode("foo/bar", "webhook", ""))
+	assert.Equal(t, proto.ModeVote, f.mode("foo/bar", SourceCli, "dev@example.com"))
+	assert.Equal(t, proto.ModeComment, f.mode("bar", "webhook", "dev@example.com"))
+	assert.Equal(t, proto.ModeSkip, f.mode("foo/bar", "webhook", "Merge-Bot@example.com"))
+}
+
+func TestSource(t *testing.T) {
+	assert.Equal(t, "", sourceOf(context.Background()))
+	assert.Equal(t, SourceCli, sourceOf(WithSource(context.Background(), SourceCli)))
+}
+
+type silentReview struct {
+	review.Review
+}
+
+func (s *silentReview) Vote(_ string, _ []proto.Format, _ string, _ *proto.Note) error {
+	return errors.New("invalid vote")
+}
+
+func TestRunSilent(t *testing.T) {
+	lints := []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Mode = config.Mode{Rule: []config.ModeRule{{Mode: proto.ModeSilent, Source: SourceCli}}}
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = &silentReview{Review: review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})}
+
+	buf, err := New(context.Background(), cfg).RunContext(WithSource(context.Background(), SourceCli),
+		"8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+}
+
+func TestRunSkip(t *testing.T) {
+	lints := []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Mode = config.Mode{Rule: []config.ModeRule{{Author: "fake@*", Mode: proto.ModeSkip}}}
+	cfg.Lint = lint.New(&lint.Config{Lints: lints})
+	cfg.Review = &silentReview{Review: review.New(&review.Config{Name: "fake", Reviews: []config.Review{{Name: "fake"}}})}
+
+	buf, err := New(context.Background(), cfg).Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
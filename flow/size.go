@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/expr"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	sizeXS = "XS"
+	sizeS  = "S"
+	sizeM  = "M"
+	sizeL  = "L"
+	sizeXL = "XL"
+)
+
+var (
+	sizes = []string{sizeXS, sizeS, sizeM, sizeL}
+
+	// Upper bounds of XS, S, M and L
+	sizeFiles = []int{1, 5, 20, 50}
+	sizeLines = []int{10, 100, 500, 1000}
+)
+
+// classify returns the larger size of change in files and lines.
+func classify(files, lines int, cfg *config.Size) string {
+	helper := func(n int, bounds []int) int {
+		for index, val := range bounds {
+			if index < len(sizes) && n <= val {
+				return index
+			}
+		}
+		return len(sizes)
+	}
+
+	f, l := cfg.Files, cfg.Lines
+	if len(f) == 0 {
+		f = sizeFiles
+	}
+
+	if len(l) == 0 {
+		l = sizeLines
+	}
+
+	index := helper(files, f)
+	if i := helper(lines, l); i > index {
+		index = i
+	}
+
+	if index >= len(sizes) {
+		return sizeXL
+	}
+
+	return sizes[index]
+}
+
+// note reports size of change, and changed lines per language in descending order, in vote.
+func note(env *expr.Env, languages map[string]int) *proto.Note {
+	text := fmt.Sprintf("Change size %s: %d files, %d lines", env.Size, len(env.Files), env.Lines)
+
+	var keys []string
+
+	for key, val := range languages {
+		if val > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if languages[keys[i]] != languages[keys[j]] {
+			return languages[keys[i]] > languages[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var buf []string
+
+	for _, val := range keys {
+		buf = append(buf, fmt.Sprintf("%s %d", val, languages[val]))
+	}
+
+	if len(buf) != 0 {
+		text += "\nLanguages: " + strings.Join(buf, ", ")
+	}
+
+	return &proto.Note{Text: text}
+}
@@ -0,0 +1,45 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/expr"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestClassify(t *testing.T) {
+	cfg := &config.Size{}
+
+	assert.Equal(t, sizeXS, classify(1, 10, cfg))
+	assert.Equal(t, sizeS, classify(1, 11, cfg))
+	assert.Equal(t, sizeM, classify(6, 11, cfg))
+	assert.Equal(t, sizeL, classify(1, 1000, cfg))
+	assert.Equal(t, sizeXL, classify(51, 0, cfg))
+
+	cfg = &config.Size{Files: []int{2}, Lines: []int{100}}
+
+	assert.Equal(t, sizeXS, classify(2, 100, cfg))
+	assert.Equal(t, sizeXL, classify(3, 0, cfg))
+}
+
+func TestNote(t *testing.T) {
+	env := &expr.Env{Files: []string{"main.go", "README.md"}, Lines: 12, Size: sizeS}
+	assert.Equal(t, &proto.Note{Text: "Change size S: 2 files, 12 lines"}, note(env, nil))
+	assert.Equal(t, &proto.Note{Text: "Change size S: 2 files, 12 lines\nLanguages: Go 8, Markdown 4"},
+		note(env, map[string]int{"Markdown": 4, "Go": 8, "Text": 0}))
+}
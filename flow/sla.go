@@ -0,0 +1,55 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/sla"
+)
+
+// escalate posts findings of change open beyond SLA in history with run, if escalation is enabled.
+func (f *flow) escalate(run *proto.Run) {
+	if !f.cfg.Config.Spec.Sla.Escalate || f.cfg.History == nil || run.Change == 0 {
+		return
+	}
+
+	runs, err := f.cfg.History.List()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	var buf []string
+
+	for _, val := range sla.Breaches(f.sla.Report(append(runs, *run), time.Now())) {
+		if val.Change != run.Change {
+			continue
+		}
+		buf = append(buf, fmt.Sprintf("- %s:%d: %s (%d patchsets, %s)", val.Finding.File, val.Finding.Line,
+			val.Finding.Details, val.Patchsets, val.Age.Round(time.Minute)))
+	}
+
+	if len(buf) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("lintflow found %d findings open beyond SLA:\n\n%s", len(buf), strings.Join(buf, "\n"))
+	if err := f.cfg.Review.Notify(run.Commit, msg); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/sla"
+)
+
+func TestEscalate(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	h := history.New(&history.Config{Path: filepath.Join(d, "history.json")})
+	findings := []proto.Format{{File: "main.go", Line: 3, Type: "Error", Details: "error"}}
+
+	_ = h.Put(proto.Run{Change: 1, Commit: "a", Findings: findings, Start: time.Now().Add(-time.Hour),
+		Status: proto.StatusSuccess})
+
+	r := &notifyReview{}
+
+	cfg := DefaultConfig()
+	cfg.Config.Spec.Sla = config.Sla{Rule: []config.SlaRule{{Patchsets: 1}}}
+	cfg.History = h
+	cfg.Review = r
+
+	f := flow{cfg: cfg, sla: sla.New(&sla.Config{Rules: cfg.Config.Spec.Sla.Rule})}
+	run := proto.Run{Change: 1, Commit: "b", Findings: findings, Start: time.Now(), Status: proto.StatusSuccess}
+
+	f.escalate(&run)
+	assert.Equal(t, "", r.message)
+
+	cfg.Config.Spec.Sla.Escalate = true
+
+	f.escalate(&run)
+	assert.Equal(t, "lintflow found 1 findings open beyond SLA:\n\n- main.go:3: error (2 patchsets, 1h0m0s)", r.message)
+
+	r.message = ""
+	run.Findings = nil
+
+	f.escalate(&run)
+	assert.Equal(t, "", r.message)
+}
@@ -0,0 +1,157 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	workspacePrefix = "lintflow-"
+)
+
+// Root returns root of workspaces in order of root, config and working directory, or tmpfs if stored on.
+func Root(root string, cfg *config.Workspace) string {
+	if root != "" {
+		return root
+	}
+
+	if cfg.Root != "" || storage.Remote(&cfg.Storage) {
+		return cfg.Root
+	}
+
+	if cfg.Storage.Kind == storage.KindTmpfs {
+		return storage.TmpfsRoot
+	}
+
+	d, _ := os.Getwd()
+
+	return d
+}
+
+// Check rejects root of workspaces on disk which is not writable, while remote storage is checked on use.
+func Check(root string, cfg *config.Workspace) error {
+	if storage.Remote(&cfg.Storage) {
+		return nil
+	}
+
+	return CheckRoot(Root(root, cfg))
+}
+
+// CheckRoot rejects root of workspaces which is not writable, e.g., on read-only filesystem.
+func CheckRoot(root string) error {
+	if root == "" {
+		return errors.New("invalid workspace root")
+	}
+
+	if err := os.MkdirAll(root, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create workspace root "+root)
+	}
+
+	f, err := ioutil.TempFile(root, "."+workspacePrefix+"check")
+	if err != nil {
+		return errors.Wrap(err, "workspace root "+root+" is not writable")
+	}
+
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	return nil
+}
+
+// Leftover lists workspaces under root which are older than age, e.g., left by killed or kept jobs,
+// in which workspaces in remote storage are listed by objects in them, and disk of node is never touched.
+func Leftover(root string, cfg *config.Workspace, s storage.Storage, age time.Duration) ([]string, error) {
+	if storage.Remote(&cfg.Storage) {
+		return leftoverRemote(root, s, age)
+	}
+
+	buf, err := filepath.Glob(filepath.Join(root, workspacePrefix+"*"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to glob")
+	}
+
+	var ret []string
+
+	for _, val := range buf {
+		info, err := os.Stat(val)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if time.Since(info.ModTime()) >= age {
+			ret = append(ret, val)
+		}
+	}
+
+	return ret, nil
+}
+
+// leftoverRemote lists workspaces whose objects are all older than age, by last modification of objects under root.
+func leftoverRemote(root string, s storage.Storage, age time.Duration) ([]string, error) {
+	prefix := filepath.ToSlash(filepath.Join(root, workspacePrefix))
+
+	buf, err := s.List(prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list")
+	}
+
+	base := strings.TrimSuffix(prefix, workspacePrefix)
+	modified := map[string]time.Time{}
+
+	var names []string
+
+	for _, val := range buf {
+		rel := strings.TrimPrefix(strings.TrimPrefix(val.Name, "/"), strings.TrimPrefix(base, "/"))
+		index := strings.Index(rel, "/")
+		if !strings.HasPrefix(rel, workspacePrefix) || index < 0 {
+			continue
+		}
+		name := filepath.Join(root, rel[:index])
+		if t, ok := modified[name]; !ok || val.Modified.After(t) {
+			if !ok {
+				names = append(names, name)
+			}
+			modified[name] = val.Modified
+		}
+	}
+
+	var ret []string
+
+	for _, val := range names {
+		if time.Since(modified[val]) >= age {
+			ret = append(ret, val)
+		}
+	}
+
+	return ret, nil
+}
+
+// workspace returns unique directory of job under root, in which change and revision are fetched.
+func (f *flow) workspace(id string) (string, error) {
+	root := Root(f.cfg.Root, &f.cfg.Config.Spec.Workspace)
+
+	if err := Check(root, &f.cfg.Config.Spec.Workspace); err != nil {
+		return "", errors.Wrap(err, "failed to check root")
+	}
+
+	return filepath.Join(root, workspacePrefix+id), nil
+}
@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/storage"
+)
+
+func TestRoot(t *testing.T) {
+	d, _ := os.Getwd()
+
+	assert.Equal(t, d, Root("", &config.Workspace{}))
+	assert.Equal(t, "/config", Root("", &config.Workspace{Root: "/config"}))
+	assert.Equal(t, "/root", Root("/root", &config.Workspace{Root: "/config"}))
+}
+
+func TestCheckRoot(t *testing.T) {
+	d, err := ioutil.TempDir("", "workspace")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = CheckRoot("")
+	assert.NotEqual(t, nil, err)
+
+	err = CheckRoot(filepath.Join(d, "root"))
+	assert.Equal(t, nil, err)
+
+	buf, _ := ioutil.ReadDir(filepath.Join(d, "root"))
+	assert.Equal(t, 0, len(buf))
+
+	if os.Geteuid() == 0 {
+		return
+	}
+
+	err = os.Chmod(d, 0500)
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.Chmod(d, 0700) }()
+
+	err = CheckRoot(d)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, strings.Contains(err.Error(), "not writable"))
+}
+
+func TestWorkspace(t *testing.T) {
+	d, err := ioutil.TempDir("", "workspace")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	f := flow{cfg: &Config{Config: config.Config{Spec: config.Spec{Workspace: config.Workspace{Root: d}}}}}
+
+	a, err := f.workspace(f.id())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, d, filepath.Dir(a))
+
+	b, err := f.workspace(f.id())
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, a, b)
+}
+
+func TestLeftover(t *testing.T) {
+	d, err := ioutil.TempDir("", "workspace")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	for _, val := range []string{workspacePrefix + "old", workspacePrefix + "new", "other"} {
+		err = os.MkdirAll(filepath.Join(d, val), os.ModePerm)
+		assert.Equal(t, nil, err)
+	}
+
+	ti := time.Now().Add(-48 * time.Hour)
+	_ = os.Chtimes(filepath.Join(d, workspacePrefix+"old"), ti, ti)
+	_ = os.Chtimes(filepath.Join(d, "other"), ti, ti)
+
+	buf, err := Leftover(d, &config.Workspace{}, nil, 24*time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{filepath.Join(d, workspacePrefix+"old")}, buf)
+
+	buf, err = Leftover(d, &config.Workspace{}, nil, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+}
+
+func TestLeftoverRemote(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<ListBucketResult>" +
+			"<Contents><Key>root/lintflow-old/main.go.base64</Key><LastModified>2020-01-01T00:00:00Z</LastModified></Contents>" +
+			"<Contents><Key>root/lintflow-old/src/main.go.base64</Key><LastModified>2020-01-01T00:00:00Z</LastModified></Contents>" +
+			"<Contents><Key>root/lintflow-new/main.go.base64</Key><LastModified>" + time.Now().UTC().Format(time.RFC3339) +
+			"</LastModified></Contents>" +
+			"<Contents><Key>root/lintflow-file</Key><LastModified>2020-01-01T00:00:00Z</LastModified></Contents>" +
+			"<IsTruncated>false</IsTruncated></ListBucketResult>"))
+	}))
+	defer ts.Close()
+
+	d, err := ioutil.TempDir("", "workspace")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = os.MkdirAll(filepath.Join(d, workspacePrefix+"local"), os.ModePerm)
+	assert.Equal(t, nil, err)
+
+	cfg := config.Workspace{Root: "root", Storage: config.Storage{Bucket: "lintflow", Endpoint: ts.URL, Kind: storage.KindS3}}
+	s := storage.New(&storage.Config{Storage: cfg.Storage})
+
+	buf, err := Leftover("root", &cfg, s, 24*time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{filepath.Join("root", workspacePrefix+"old")}, buf)
+
+	buf, err = Leftover(d, &cfg, s, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
@@ -8,7 +8,6 @@ require (
 	github.com/alecthomas/units v0.0.0-20210208195552-ff826a37aa15 // indirect
 	github.com/golang/protobuf v1.5.2
 	github.com/pkg/errors v0.9.1
-	github.com/reviewdog/reviewdog v0.11.0
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/goleak v1.1.10
 	google.golang.org/grpc v1.36.0
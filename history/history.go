@@ -0,0 +1,163 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	bufSize     = 64 * 1024 * 1024
+	feedbackExt = ".feedback"
+	perm        = 0600
+)
+
+type History interface {
+	Feedback() ([]proto.Feedback, error)
+	List() ([]proto.Run, error)
+	Put(proto.Run) error
+	PutFeedback(proto.Feedback) error
+}
+
+type Config struct {
+	Path string
+}
+
+// history stores runs in file of JSON lines, one run per line, and feedback on findings in file next to it.
+type history struct {
+	cfg   *Config
+	mutex sync.Mutex
+}
+
+func New(cfg *Config) History {
+	return &history{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+func (h *history) Feedback() ([]proto.Feedback, error) {
+	var buf []proto.Feedback
+
+	err := h.read(h.cfg.Path+feedbackExt, func(data []byte) {
+		var f proto.Feedback
+		if err := json.Unmarshal(data, &f); err == nil {
+			buf = append(buf, f)
+		}
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	return buf, nil
+}
+
+func (h *history) List() ([]proto.Run, error) {
+	var buf []proto.Run
+
+	err := h.read(h.cfg.Path, func(data []byte) {
+		var r proto.Run
+		// Skip partial line written by interrupted run
+		if err := json.Unmarshal(data, &r); err == nil {
+			buf = append(buf, r)
+		}
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	return buf, nil
+}
+
+func (h *history) Put(run proto.Run) error {
+	if err := h.write(h.cfg.Path, run); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+func (h *history) PutFeedback(feedback proto.Feedback) error {
+	if err := h.write(h.cfg.Path+feedbackExt, feedback); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+func (h *history) read(name string, routine func([]byte)) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to open")
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), bufSize)
+
+	for scanner.Scan() {
+		routine(scanner.Bytes())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to scan")
+	}
+
+	return nil
+}
+
+func (h *history) write(name string, data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return errors.Wrap(err, "failed to open")
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
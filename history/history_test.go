@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestHistory(t *testing.T) {
+	d, err := ioutil.TempDir("", "history")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	cfg := DefaultConfig()
+	cfg.Path = filepath.Join(d, "history.jsonl")
+
+	h := New(cfg)
+
+	buf, err := h.List()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	run := proto.Run{
+		ID:     "foo",
+		Commit: "8f71e42dbcd8c68d849e483c04670f58621aab9c",
+		Start:  time.Now(),
+		Status: proto.StatusSuccess,
+		Findings: []proto.Format{
+			{File: "name", Line: 1, Type: proto.TypeError, Details: "text", Rule: "rule"},
+		},
+	}
+
+	err = h.Put(run)
+	assert.Equal(t, nil, err)
+
+	run.ID = "bar"
+
+	err = h.Put(run)
+	assert.Equal(t, nil, err)
+
+	buf, err = h.List()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, "bar", buf[1].ID)
+	assert.Equal(t, "rule", buf[1].Findings[0].Rule)
+}
+
+func TestFeedback(t *testing.T) {
+	d, err := ioutil.TempDir("", "history")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	cfg := DefaultConfig()
+	cfg.Path = filepath.Join(d, "history.jsonl")
+
+	h := New(cfg)
+
+	buf, err := h.Feedback()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	err = h.PutFeedback(proto.Feedback{ID: "foo", Commit: "8f71e42dbcd8c68d849e483c04670f58621aab9c", File: "name", Line: 1,
+		Rule: "rule"})
+	assert.Equal(t, nil, err)
+
+	buf, err = h.Feedback()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "rule", buf[0].Rule)
+
+	runs, err := h.List()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(runs))
+}
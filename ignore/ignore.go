@@ -0,0 +1,166 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+const (
+	Name = ".lintflowignore"
+)
+
+type Ignore interface {
+	Match(string) bool
+}
+
+type rule struct {
+	dir    bool
+	negate bool
+	regex  *regexp.Regexp
+}
+
+type ignore struct {
+	rules []rule
+}
+
+// New parses data in gitignore syntax, invalid patterns are skipped.
+func New(data []byte) Ignore {
+	var rules []rule
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		if r, ok := parse(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+
+	return &ignore{
+		rules: rules,
+	}
+}
+
+// Match reports whether the slash-separated path or any of its parent directories is ignored.
+func (i *ignore) Match(name string) bool {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || len(i.rules) == 0 {
+		return false
+	}
+
+	items := strings.Split(name, "/")
+
+	for index := 1; index < len(items); index++ {
+		if i.match(strings.Join(items[:index], "/"), true) {
+			return true
+		}
+	}
+
+	return i.match(name, false)
+}
+
+func (i *ignore) match(name string, dir bool) bool {
+	ret := false
+
+	for _, val := range i.rules {
+		if val.dir && !dir {
+			continue
+		}
+		if val.regex.MatchString(name) {
+			ret = !val.negate
+		}
+	}
+
+	return ret
+}
+
+// nolint:gocyclo
+func parse(line string) (rule, bool) {
+	var r rule
+
+	line = strings.TrimRight(strings.TrimSuffix(line, "\r"), " ")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return r, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dir = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	if line == "" {
+		return r, false
+	}
+
+	var buf strings.Builder
+
+	for index := 0; index < len(line); index++ {
+		c := line[index]
+		switch {
+		case strings.HasPrefix(line[index:], "**/"):
+			buf.WriteString("(.*/)?")
+			index += 2
+		case strings.HasPrefix(line[index:], "**"):
+			buf.WriteString(".*")
+			index++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[index:], ']')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := line[index+1 : index+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + class + "]")
+			index += end
+		case c == '\\' && index+1 < len(line):
+			index++
+			buf.WriteString(regexp.QuoteMeta(string(line[index])))
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	prefix := "^(.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+
+	regex, err := regexp.Compile(prefix + buf.String() + "$")
+	if err != nil {
+		return r, false
+	}
+
+	r.regex = regex
+
+	return r, true
+}
@@ -0,0 +1,73 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	data = `# comment
+
+*.log
+!keep.log
+/build
+vendor/
+docs/**/*.md
+src/*.gen.go
+test?.txt
+\#hash
+`
+)
+
+func TestMatch(t *testing.T) {
+	i := New([]byte(data))
+
+	assert.Equal(t, false, i.Match(""))
+	assert.Equal(t, false, i.Match("main.go"))
+
+	assert.Equal(t, true, i.Match("foo.log"))
+	assert.Equal(t, true, i.Match("a/b/foo.log"))
+	assert.Equal(t, false, i.Match("keep.log"))
+	assert.Equal(t, false, i.Match("a/keep.log"))
+
+	assert.Equal(t, true, i.Match("build"))
+	assert.Equal(t, true, i.Match("build/main.go"))
+	assert.Equal(t, false, i.Match("src/build/main.go"))
+
+	assert.Equal(t, true, i.Match("vendor/foo/bar.go"))
+	assert.Equal(t, true, i.Match("a/vendor/bar.go"))
+	assert.Equal(t, false, i.Match("vendor"))
+
+	assert.Equal(t, true, i.Match("docs/README.md"))
+	assert.Equal(t, true, i.Match("docs/a/b/README.md"))
+	assert.Equal(t, false, i.Match("README.md"))
+
+	assert.Equal(t, true, i.Match("src/foo.gen.go"))
+	assert.Equal(t, false, i.Match("src/a/foo.gen.go"))
+
+	assert.Equal(t, true, i.Match("test1.txt"))
+	assert.Equal(t, false, i.Match("test10.txt"))
+
+	assert.Equal(t, true, i.Match("#hash"))
+}
+
+func TestEmpty(t *testing.T) {
+	i := New(nil)
+	assert.Equal(t, false, i.Match("foo.log"))
+
+	i = New([]byte("\n# only comments\n\n"))
+	assert.Equal(t, false, i.Match("foo.log"))
+}
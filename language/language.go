@@ -0,0 +1,90 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package language
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+	Other = "Other"
+)
+
+var (
+	extensions = map[string]string{
+		".bash":  "Shell",
+		".c":     "C",
+		".cc":    "C++",
+		".cpp":   "C++",
+		".cs":    "C#",
+		".css":   "CSS",
+		".cxx":   "C++",
+		".go":    "Go",
+		".h":     "C",
+		".hpp":   "C++",
+		".html":  "HTML",
+		".java":  "Java",
+		".js":    "JavaScript",
+		".json":  "JSON",
+		".kt":    "Kotlin",
+		".md":    "Markdown",
+		".php":   "PHP",
+		".proto": "Protocol Buffers",
+		".py":    "Python",
+		".rb":    "Ruby",
+		".rs":    "Rust",
+		".scala": "Scala",
+		".sh":    "Shell",
+		".sql":   "SQL",
+		".swift": "Swift",
+		".ts":    "TypeScript",
+		".tsx":   "TypeScript",
+		".xml":   "XML",
+		".yaml":  "YAML",
+		".yml":   "YAML",
+	}
+
+	files = map[string]string{
+		"CMakeLists.txt": "CMake",
+		"Dockerfile":     "Dockerfile",
+		"Makefile":       "Makefile",
+		"go.mod":         "Go Module",
+	}
+)
+
+// Detect detects language of file in slash-separated name by its file name or extension.
+func Detect(name string) string {
+	base := path.Base(name)
+
+	if val, ok := files[base]; ok {
+		return val
+	}
+
+	if val, ok := extensions[strings.ToLower(path.Ext(base))]; ok {
+		return val
+	}
+
+	return Other
+}
+
+// Stats sums lines of files per language.
+func Stats(lines map[string]int) map[string]int {
+	ret := map[string]int{}
+
+	for key, val := range lines {
+		ret[Detect(key)] += val
+	}
+
+	return ret
+}
@@ -0,0 +1,31 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, "Go", Detect("cmd/cmd.go"))
+	assert.Equal(t, "C++", Detect("src/main.CPP"))
+	assert.Equal(t, "Dockerfile", Detect("build/Dockerfile"))
+	assert.Equal(t, Other, Detect("LICENSE"))
+}
+
+func TestStats(t *testing.T) {
+	buf := Stats(map[string]int{"main.go": 10, "flow/flow.go": 5, "README.md": 2, "LICENSE": 1})
+	assert.Equal(t, map[string]int{"Go": 15, "Markdown": 2, Other: 1}, buf)
+}
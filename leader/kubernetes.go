@@ -0,0 +1,243 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	kubernetesUrl = "https://kubernetes.default.svc"
+	leaseApi      = "/apis/coordination.k8s.io/v1/namespaces/"
+	microTime     = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+var (
+	// serviceAccount is mounted in pods, which keeps token, CA and namespace of pod.
+	serviceAccount = "/var/run/secrets/kubernetes.io/serviceaccount/"
+)
+
+// kubernetes holds Lease of coordination.k8s.io, which is updated in optimistic concurrency by resourceVersion.
+type kubernetes struct {
+	c         *http.Client
+	name      string
+	namespace string
+	url       string
+}
+
+type lease struct {
+	ApiVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	HolderIdentity       string `json:"holderIdentity"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds"`
+	LeaseTransitions     int    `json:"leaseTransitions"`
+	RenewTime            string `json:"renewTime,omitempty"`
+}
+
+func newKubernetes(client *http.Client, cfg *config.Leader, name string) lock {
+	k := &kubernetes{
+		c:         client,
+		name:      name,
+		namespace: cfg.Namespace,
+		url:       strings.TrimSuffix(cfg.Url, "/"),
+	}
+
+	if k.url == "" {
+		k.url = kubernetesUrl
+	}
+
+	if k.namespace == "" {
+		if buf, err := ioutil.ReadFile(serviceAccount + "namespace"); err == nil {
+			k.namespace = strings.TrimSpace(string(buf))
+		} else {
+			k.namespace = "default"
+		}
+	}
+
+	// CA of cluster is trusted if client is not customized
+	if client == http.DefaultClient {
+		if buf, err := ioutil.ReadFile(serviceAccount + "ca.crt"); err == nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(buf)
+			k.c = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}} // nolint:gosec
+		}
+	}
+
+	return k
+}
+
+func (k *kubernetes) acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	l, err := k.get(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get")
+	}
+
+	now := time.Now()
+
+	if l == nil {
+		l = &lease{
+			ApiVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: k.name, Namespace: k.namespace},
+		}
+	} else if l.Spec.HolderIdentity != id && l.Spec.HolderIdentity != "" && !expired(l, now) {
+		return false, nil
+	}
+
+	if l.Spec.HolderIdentity != id {
+		l.Spec.AcquireTime = now.UTC().Format(microTime)
+		l.Spec.LeaseTransitions++
+	}
+
+	l.Spec.HolderIdentity = id
+	l.Spec.LeaseDurationSeconds = int(ttl / time.Second)
+	l.Spec.RenewTime = now.UTC().Format(microTime)
+
+	return k.put(ctx, l)
+}
+
+func (k *kubernetes) release(ctx context.Context, id string) error {
+	l, err := k.get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get")
+	}
+
+	if l == nil || l.Spec.HolderIdentity != id {
+		return nil
+	}
+
+	l.Spec.HolderIdentity = ""
+
+	if _, err := k.put(ctx, l); err != nil {
+		return errors.Wrap(err, "failed to put")
+	}
+
+	return nil
+}
+
+func expired(l *lease, now time.Time) bool {
+	t, err := time.Parse(microTime, l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+
+	return now.After(t.Add(time.Duration(l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// get returns lease, or nil if not found.
+func (k *kubernetes) get(ctx context.Context) (*lease, error) {
+	rsp, err := k.do(ctx, http.MethodGet, k.leases()+"/"+k.name, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		return nil, nil
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		return nil, errors.New("invalid status " + rsp.Status)
+	}
+
+	var l lease
+
+	if err := json.NewDecoder(rsp.Body).Decode(&l); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	return &l, nil
+}
+
+// put creates lease if without resourceVersion, or replaces it otherwise, which is false on conflicts with others.
+func (k *kubernetes) put(ctx context.Context, l *lease) (bool, error) {
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to marshal")
+	}
+
+	method, url := http.MethodPut, k.leases()+"/"+k.name
+	if l.Metadata.ResourceVersion == "" {
+		method, url = http.MethodPost, k.leases()
+	}
+
+	rsp, err := k.do(ctx, method, url, buf)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	_, _ = io.Copy(ioutil.Discard, rsp.Body)
+
+	switch rsp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, errors.New("invalid status " + rsp.Status)
+	}
+}
+
+func (k *kubernetes) leases() string {
+	return k.url + leaseApi + k.namespace + "/leases"
+}
+
+func (k *kubernetes) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Token is read on each request since it is rotated by kubelet
+	if buf, err := ioutil.ReadFile(serviceAccount + "token"); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(buf)))
+	}
+
+	return k.c.Do(req)
+}
@@ -0,0 +1,125 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+// leaseServer serves lease of Kubernetes API, in which resourceVersion is bumped on each write.
+type leaseServer struct {
+	lease   *lease
+	mutex   sync.Mutex
+	version int
+}
+
+func (s *leaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	const path = "/apis/coordination.k8s.io/v1/namespaces/ci/leases"
+
+	if r.Method == http.MethodGet && r.URL.Path == path+"/lintflow" {
+		if s.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.lease)
+		return
+	}
+
+	var l lease
+
+	buf, _ := ioutil.ReadAll(r.Body)
+	_ = json.Unmarshal(buf, &l)
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == path:
+		if s.lease != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	case r.Method == http.MethodPut && r.URL.Path == path+"/lintflow":
+		if s.lease == nil || s.lease.Metadata.ResourceVersion != l.Metadata.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.version++
+	l.Metadata.ResourceVersion = strconv.Itoa(s.version)
+	s.lease = &l
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestKubernetes(t *testing.T) {
+	s := &leaseServer{}
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	k := newKubernetes(srv.Client(), &config.Leader{Namespace: "ci", Url: srv.URL}, "lintflow")
+	ctx := context.Background()
+
+	ok, err := k.acquire(ctx, "a", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "a", s.lease.Spec.HolderIdentity)
+	assert.Equal(t, 60, s.lease.Spec.LeaseDurationSeconds)
+	assert.Equal(t, 1, s.lease.Spec.LeaseTransitions)
+
+	ok, err = k.acquire(ctx, "a", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "2", s.lease.Metadata.ResourceVersion)
+	assert.Equal(t, 1, s.lease.Spec.LeaseTransitions)
+
+	ok, err = k.acquire(ctx, "b", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	s.lease.Spec.RenewTime = time.Now().Add(-2 * time.Minute).UTC().Format(microTime)
+
+	ok, err = k.acquire(ctx, "b", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 2, s.lease.Spec.LeaseTransitions)
+
+	err = k.release(ctx, "a")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "b", s.lease.Spec.HolderIdentity)
+
+	err = k.release(ctx, "b")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", s.lease.Spec.HolderIdentity)
+
+	ok, err = k.acquire(ctx, "a", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+}
@@ -0,0 +1,142 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	KindKubernetes = "kubernetes"
+	KindRedis      = "redis"
+)
+
+const (
+	leaderName = "lintflow"
+	leaderTtl  = 15
+)
+
+// Leader elects one of instances sharing config to consume events, e.g., of Gerrit, while all instances run jobs.
+type Leader interface {
+	Leading() bool
+	Run(context.Context) error
+}
+
+type Config struct {
+	Client   *http.Client
+	Identity string
+	Leader   config.Leader
+}
+
+// lock is held by identity till ttl expires, in which acquire renews lock already held.
+type lock interface {
+	acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	release(ctx context.Context, id string) error
+}
+
+type leader struct {
+	cfg     *Config
+	leading int32
+	lock    lock
+	ttl     time.Duration
+}
+
+// New returns leader of kind in config, or the one always leading if kind is empty, i.e., of single instance.
+func New(cfg *Config) Leader {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	name := cfg.Leader.Name
+	if name == "" {
+		name = leaderName
+	}
+
+	ttl := cfg.Leader.Ttl
+	if ttl <= 0 {
+		ttl = leaderTtl
+	}
+
+	l := &leader{cfg: cfg, ttl: time.Duration(ttl) * time.Second}
+
+	switch cfg.Leader.Kind {
+	case KindKubernetes:
+		l.lock = newKubernetes(client, &cfg.Leader, name)
+	case KindRedis:
+		l.lock = newRedis(&cfg.Leader, name)
+	default:
+		l.leading = 1
+	}
+
+	return l
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+func (l *leader) Leading() bool {
+	return atomic.LoadInt32(&l.leading) == 1
+}
+
+// Run campaigns for lock and renews it every third of ttl till ctx is done, and releases it then.
+func (l *leader) Run(ctx context.Context) error {
+	if l.lock == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		ok, err := l.lock.acquire(ctx, l.cfg.Identity, l.ttl)
+		if err != nil {
+			log.Println(err)
+		}
+		l.update(ok && err == nil)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if l.Leading() {
+				l.update(false)
+				c, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+				defer cancel()
+				return l.lock.release(c, l.cfg.Identity)
+			}
+			return nil
+		}
+	}
+}
+
+func (l *leader) update(leading bool) {
+	var v int32
+	if leading {
+		v = 1
+	}
+
+	if atomic.SwapInt32(&l.leading, v) != v {
+		if leading {
+			log.Printf("%s leading", l.cfg.Identity)
+		} else {
+			log.Printf("%s following", l.cfg.Identity)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+type lockTest struct {
+	holder string
+	mutex  sync.Mutex
+}
+
+func (l *lockTest) acquire(_ context.Context, id string, _ time.Duration) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.holder == "" {
+		l.holder = id
+	}
+
+	return l.holder == id, nil
+}
+
+func (l *lockTest) release(_ context.Context, id string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.holder == id {
+		l.holder = ""
+	}
+
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	l := New(DefaultConfig())
+	assert.Equal(t, true, l.Leading())
+
+	l = New(&Config{Leader: config.Leader{Kind: KindRedis}})
+	assert.Equal(t, false, l.Leading())
+}
+
+func TestRun(t *testing.T) {
+	lock := &lockTest{}
+
+	a := &leader{cfg: &Config{Identity: "a"}, lock: lock, ttl: 30 * time.Millisecond}
+	b := &leader{cfg: &Config{Identity: "b"}, lock: lock, ttl: 30 * time.Millisecond}
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+
+	go func() {
+		doneA <- a.Run(ctxA)
+	}()
+
+	assert.Eventually(t, a.Leading, time.Second, 10*time.Millisecond)
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+
+	go func() {
+		_ = b.Run(ctxB)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, false, b.Leading())
+
+	cancelA()
+	assert.Equal(t, nil, <-doneA)
+	assert.Equal(t, false, a.Leading())
+
+	assert.Eventually(t, b.Leading, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,155 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	redisAddr    = "127.0.0.1:6379"
+	redisTimeout = 5 * time.Second
+)
+
+const (
+	// acquireScript renews lock held by id, or sets it if free
+	acquireScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) end
+if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return 1 end
+return 0`
+
+	// releaseScript deletes lock only if held by id
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) end
+return 0`
+)
+
+// redis holds key in Redis, which expires in ttl and is renewed by its holder, in RESP without client library.
+type redis struct {
+	addr string
+	db   string
+	key  string
+	pass string
+}
+
+// newRedis parses url in the form of redis://[:pass@]host:port[/db].
+func newRedis(cfg *config.Leader, name string) lock {
+	r := &redis{addr: redisAddr, key: name}
+
+	u, err := url.Parse(cfg.Url)
+	if err != nil || cfg.Url == "" {
+		return r
+	}
+
+	if u.Host != "" {
+		r.addr = u.Host
+	}
+
+	if p, ok := u.User.Password(); ok {
+		r.pass = p
+	}
+
+	r.db = strings.Trim(u.Path, "/")
+
+	return r
+}
+
+func (r *redis) acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	ret, err := r.eval(ctx, acquireScript, id, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to eval")
+	}
+
+	return ret == 1, nil
+}
+
+func (r *redis) release(ctx context.Context, id string) error {
+	if _, err := r.eval(ctx, releaseScript, id); err != nil {
+		return errors.Wrap(err, "failed to eval")
+	}
+
+	return nil
+}
+
+func (r *redis) eval(ctx context.Context, script string, args ...string) (int64, error) {
+	d := net.Dialer{Timeout: redisTimeout}
+
+	conn, err := d.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to dial")
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_ = conn.SetDeadline(time.Now().Add(redisTimeout))
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if r.pass != "" {
+		if _, err := command(rw, "AUTH", r.pass); err != nil {
+			return 0, errors.Wrap(err, "failed to auth")
+		}
+	}
+
+	if r.db != "" {
+		if _, err := command(rw, "SELECT", r.db); err != nil {
+			return 0, errors.Wrap(err, "failed to select")
+		}
+	}
+
+	return command(rw, append([]string{"EVAL", script, "1", r.key}, args...)...)
+}
+
+// command writes command in array of bulk strings, and reads reply of status, error or integer.
+func command(rw *bufio.ReadWriter, args ...string) (int64, error) {
+	_, _ = fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, val := range args {
+		_, _ = fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(val), val)
+	}
+
+	if err := rw.Flush(); err != nil {
+		return 0, errors.Wrap(err, "failed to write")
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read")
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return 0, errors.New("invalid reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return 0, nil
+	case '-':
+		return 0, errors.New(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	default:
+		return 0, errors.New("invalid reply " + line)
+	}
+}
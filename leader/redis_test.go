@@ -0,0 +1,114 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+// serveRedis serves commands on listener, in which replies are by name of command, and records commands.
+func serveRedis(t *testing.T, l net.Listener, replies map[string]string, commands chan<- []string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer func() {
+				_ = conn.Close()
+			}()
+			r := bufio.NewReader(conn)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+				var args []string
+				for i := 0; i < n; i++ {
+					size, _ := r.ReadString('\n')
+					m, _ := strconv.Atoi(strings.TrimSpace(size[1:]))
+					arg := make([]byte, m+2)
+					_, _ = io.ReadFull(r, arg)
+					args = append(args, string(arg[:m]))
+				}
+				commands <- args
+				_, err = conn.Write([]byte(replies[args[0]] + "\r\n"))
+				assert.Equal(t, nil, err)
+			}
+		}(conn)
+	}
+}
+
+func TestRedis(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	defer func() {
+		_ = l.Close()
+	}()
+
+	replies := map[string]string{"AUTH": "+OK", "SELECT": "+OK", "EVAL": ":1"}
+	commands := make(chan []string, 10)
+
+	go serveRedis(t, l, replies, commands)
+
+	r := newRedis(&config.Leader{Url: "redis://:pass@" + l.Addr().String() + "/2"}, "lintflow")
+
+	ok, err := r.acquire(context.Background(), "a", 15*time.Second)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	assert.Equal(t, []string{"AUTH", "pass"}, <-commands)
+	assert.Equal(t, []string{"SELECT", "2"}, <-commands)
+	assert.Equal(t, []string{"EVAL", "1", "lintflow", "a", "15000"}, drop(<-commands, 1))
+}
+
+func TestCommand(t *testing.T) {
+	for reply, val := range map[string]int64{":0": 0, ":1": 1, "+OK": 0} {
+		a, b := net.Pipe()
+		go func(reply string) {
+			_, _ = bufio.NewReader(b).ReadString('\n')
+			_, _ = b.Write([]byte(reply + "\r\n"))
+		}(reply)
+		ret, err := command(bufio.NewReadWriter(bufio.NewReader(a), bufio.NewWriter(a)), "PING")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, val, ret)
+		_ = a.Close()
+		_ = b.Close()
+	}
+
+	a, b := net.Pipe()
+	go func() {
+		_, _ = bufio.NewReader(b).ReadString('\n')
+		_, _ = b.Write([]byte("-NOAUTH Authentication required\r\n"))
+	}()
+	_, err := command(bufio.NewReadWriter(bufio.NewReader(a), bufio.NewWriter(a)), "PING")
+	assert.Equal(t, "NOAUTH Authentication required", err.Error())
+}
+
+// drop drops argument at index, e.g., script of EVAL.
+func drop(args []string, index int) []string {
+	return append(append([]string{}, args[:index]...), args[index+1:]...)
+}
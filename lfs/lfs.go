@@ -0,0 +1,257 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	mediaType = "application/vnd.git-lfs+json"
+	repoKey   = "{repo}"
+)
+
+const (
+	// Size is default limit of size of objects resolved, which are skipped if larger.
+	Size = 10 * 1024 * 1024
+)
+
+const (
+	pointerLimit   = 1024
+	pointerVersion = "version https://git-lfs.github.com/spec/v1\n"
+)
+
+var (
+	pointerOid  = regexp.MustCompile(`(?m)^oid sha256:([0-9a-f]{64})$`)
+	pointerSize = regexp.MustCompile(`(?m)^size ([0-9]+)$`)
+)
+
+type Lfs interface {
+	Resolve(context.Context, string, []Pointer) (map[string][]byte, error)
+}
+
+type Config struct {
+	Client *http.Client
+	Lfs    config.Lfs
+}
+
+// Pointer is pointer file of Git LFS, which is committed in place of content of large object.
+type Pointer struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfs struct {
+	cfg *Config
+}
+
+type batchRequest struct {
+	Objects   []Pointer `json:"objects"`
+	Operation string    `json:"operation"`
+	Transfers []string  `json:"transfers"`
+}
+
+type batchResponse struct {
+	Objects []struct {
+		Actions struct {
+			Download *struct {
+				Header map[string]string `json:"header"`
+				Href   string            `json:"href"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Oid string `json:"oid"`
+	} `json:"objects"`
+}
+
+func New(cfg *Config) Lfs {
+	return &lfs{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Parse reports whether data is pointer file of Git LFS in format of spec v1, and returns the pointer if so.
+func Parse(data []byte) (Pointer, bool) {
+	if len(data) > pointerLimit || !bytes.HasPrefix(data, []byte(pointerVersion)) {
+		return Pointer{}, false
+	}
+
+	oid := pointerOid.FindSubmatch(data)
+	size := pointerSize.FindSubmatch(data)
+
+	if oid == nil || size == nil {
+		return Pointer{}, false
+	}
+
+	n, err := strconv.ParseInt(string(size[1]), 10, 64)
+	if err != nil {
+		return Pointer{}, false
+	}
+
+	return Pointer{Oid: string(oid[1]), Size: n}, true
+}
+
+// Resolve downloads objects of pointers in repo by batch API of Git LFS at URL with {repo}, and returns content of
+// objects by oid. Objects larger than limit, unavailable or corrupted are absent.
+func (l *lfs) Resolve(ctx context.Context, repo string, pointers []Pointer) (map[string][]byte, error) {
+	if l.cfg.Lfs.Url == "" {
+		return nil, errors.New("invalid url")
+	}
+
+	limit := l.cfg.Lfs.Size
+	if limit <= 0 {
+		limit = Size
+	}
+
+	var objects []Pointer
+
+	for _, val := range pointers {
+		if val.Size <= limit {
+			objects = append(objects, val)
+		}
+	}
+
+	ret := map[string][]byte{}
+
+	if len(objects) == 0 {
+		return ret, nil
+	}
+
+	buf, err := l.batch(ctx, repo, objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to batch")
+	}
+
+	for _, item := range buf.Objects {
+		if item.Error != nil || item.Actions.Download == nil {
+			continue
+		}
+		b, err := l.download(ctx, item.Actions.Download.Href, item.Actions.Download.Header, limit)
+		if err != nil {
+			continue
+		}
+		if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != item.Oid {
+			continue
+		}
+		ret[item.Oid] = b
+	}
+
+	return ret, nil
+}
+
+func (l *lfs) batch(ctx context.Context, repo string, objects []Pointer) (*batchResponse, error) {
+	b, err := json.Marshal(&batchRequest{Objects: objects, Operation: "download", Transfers: []string{"basic"}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	_url := strings.TrimSuffix(strings.ReplaceAll(l.cfg.Lfs.Url, repoKey, repo), "/") + "/objects/batch"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, _url, bytes.NewBuffer(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Content-Type", mediaType)
+
+	if l.cfg.Lfs.User != "" || l.cfg.Lfs.Pass != "" {
+		req.SetBasicAuth(l.cfg.Lfs.User, l.cfg.Lfs.Pass)
+	}
+
+	rsp, err := l.client().Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("invalid status " + strconv.Itoa(rsp.StatusCode))
+	}
+
+	ret := &batchResponse{}
+
+	if err := json.NewDecoder(rsp.Body).Decode(ret); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	return ret, nil
+}
+
+// download gets content of object by action of batch API, whose headers carry authentication if required.
+func (l *lfs) download(ctx context.Context, href string, header map[string]string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	for key, val := range header {
+		req.Header.Set(key, val)
+	}
+
+	rsp, err := l.client().Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("invalid status " + strconv.Itoa(rsp.StatusCode))
+	}
+
+	ret, err := ioutil.ReadAll(io.LimitReader(rsp.Body, limit+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	if int64(len(ret)) > limit {
+		return nil, errors.New("invalid size")
+	}
+
+	return ret, nil
+}
+
+func (l *lfs) client() *http.Client {
+	if l.cfg.Client != nil {
+		return l.cfg.Client
+	}
+
+	return http.DefaultClient
+}
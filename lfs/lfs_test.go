@@ -0,0 +1,101 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func pointer(data string) (Pointer, string) {
+	sum := sha256.Sum256([]byte(data))
+	oid := hex.EncodeToString(sum[:])
+
+	return Pointer{Oid: oid, Size: int64(len(data))}, "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid +
+		"\nsize " + strconv.Itoa(len(data)) + "\n"
+}
+
+func TestParse(t *testing.T) {
+	p, text := pointer("hello")
+
+	buf, ok := Parse([]byte(text))
+	assert.Equal(t, true, ok)
+	assert.Equal(t, p, buf)
+
+	_, ok = Parse([]byte("package main\n"))
+	assert.Equal(t, false, ok)
+
+	_, ok = Parse([]byte("version https://git-lfs.github.com/spec/v1\nsize 5\n"))
+	assert.Equal(t, false, ok)
+}
+
+func TestResolve(t *testing.T) {
+	hello, _ := pointer("hello")
+	large, _ := pointer("too large")
+	corrupt, _ := pointer("corrupt")
+
+	var s *httptest.Server
+
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo.git/info/lfs/objects/batch":
+			if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req batchRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, []Pointer{hello, corrupt}, req.Objects)
+			_, _ = w.Write([]byte(`{"objects": [` +
+				`{"oid": "` + hello.Oid + `", "actions": {"download": {"href": "` + s.URL + `/hello", "header": {"X-Token": "t"}}}},` +
+				`{"oid": "` + corrupt.Oid + `", "actions": {"download": {"href": "` + s.URL + `/corrupt"}}}]}`))
+		case "/hello":
+			if r.Header.Get("X-Token") != "t" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte("hello"))
+		case "/corrupt":
+			_, _ = w.Write([]byte("corrupted"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	l := New(&Config{Lfs: config.Lfs{Pass: "pass", Size: 8, Url: s.URL + "/{repo}.git/info/lfs", User: "user"}})
+
+	buf, err := l.Resolve(context.Background(), "repo", []Pointer{hello, large, corrupt})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string][]byte{hello.Oid: []byte("hello")}, buf)
+
+	l = New(&Config{Lfs: config.Lfs{Url: s.URL + "/{repo}.git/info/lfs"}})
+
+	_, err = l.Resolve(context.Background(), "repo", []Pointer{hello})
+	assert.NotEqual(t, nil, err)
+
+	l = New(DefaultConfig())
+
+	_, err = l.Resolve(context.Background(), "repo", []Pointer{hello})
+	assert.NotEqual(t, nil, err)
+}
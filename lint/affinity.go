@@ -0,0 +1,40 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"path"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+// affine pins lint to worker of the first affinity rule whose patterns match repo, e.g. pool of workers with warm cache
+// of the repo, and keeps the default worker of lint otherwise. Host and port of lint are kept if unset in rule.
+func affine(v config.Lint, repo string) config.Lint {
+	for _, item := range v.Affinity {
+		for _, val := range item.Repo {
+			if ok, _ := path.Match(val, repo); !ok {
+				continue
+			}
+			if item.Host != "" {
+				v.Host = item.Host
+			}
+			if item.Port != 0 {
+				v.Port = item.Port
+			}
+			return v
+		}
+	}
+
+	return v
+}
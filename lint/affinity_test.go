@@ -0,0 +1,52 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestAffine(t *testing.T) {
+	v := config.Lint{
+		Affinity: []config.Affinity{
+			{Host: "monorepo", Port: 9091, Repo: []string{"platform/monorepo"}},
+			{Host: "tools", Repo: []string{"tools/*"}},
+			{Port: 9092, Repo: []string{"docs/*"}},
+		},
+		Host: "default",
+		Port: 9090,
+	}
+
+	buf := affine(v, "platform/monorepo")
+	assert.Equal(t, "monorepo", buf.Host)
+	assert.Equal(t, 9091, buf.Port)
+
+	buf = affine(v, "tools/lint")
+	assert.Equal(t, "tools", buf.Host)
+	assert.Equal(t, 9090, buf.Port)
+
+	buf = affine(v, "docs/site")
+	assert.Equal(t, "default", buf.Host)
+	assert.Equal(t, 9092, buf.Port)
+
+	buf = affine(v, "other")
+	assert.Equal(t, "default", buf.Host)
+	assert.Equal(t, 9090, buf.Port)
+
+	buf = affine(v, "")
+	assert.Equal(t, "default", buf.Host)
+}
@@ -0,0 +1,291 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+// symbol is exported API of package in file, whose signature is of types only, regardless of names of parameters.
+// Methods of interfaces are owned by their interfaces.
+type symbol struct {
+	file      string
+	owner     string
+	line      int
+	signature string
+}
+
+// apidiff compares exported API of Go packages in changed files with parent revision, instead of calling worker, and
+// reports removed or changed API and methods added to interfaces as breaking changes. API moved between files in the
+// same package is not reported if both files are changed.
+func (l *lint) apidiff(data []byte) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	parents := map[string]string{}
+
+	if val, ok := buf[proto.Base64Parent]; ok {
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		if err := json.Unmarshal(dec, &parents); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+	}
+
+	before, after := map[string]map[string]symbol{}, map[string]map[string]symbol{}
+
+	for key, val := range buf {
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if !exported(file) {
+			continue
+		}
+		dir := path.Dir(file)
+		if after[dir] == nil {
+			before[dir], after[dir] = map[string]symbol{}, map[string]symbol{}
+		}
+		if err := api(file, val, after[dir]); err != nil {
+			return nil, errors.Wrap(err, "failed to api")
+		}
+		if p, ok := parents[key]; ok {
+			if err := api(file, p, before[dir]); err != nil {
+				return nil, errors.Wrap(err, "failed to api")
+			}
+		}
+	}
+
+	ret := []proto.Format{}
+
+	for dir, val := range before {
+		ret = append(ret, compare(val, after[dir])...)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].File != ret[j].File {
+			return ret[i].File < ret[j].File
+		}
+		if ret[i].Line != ret[j].Line {
+			return ret[i].Line < ret[j].Line
+		}
+		return ret[i].Details < ret[j].Details
+	})
+
+	return ret, nil
+}
+
+// exported reports whether file is Go source of package importable by others.
+func exported(file string) bool {
+	if path.Ext(file) != ".go" || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+
+	for _, val := range strings.Split(path.Dir(file), "/") {
+		if val == "internal" || val == "testdata" || val == "vendor" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compare(before, after map[string]symbol) []proto.Format {
+	var ret []proto.Format
+
+	for name, b := range before {
+		a, ok := after[name]
+		if !ok {
+			ret = append(ret, proto.Format{File: b.file, Type: proto.TypeError, Details: "Exported " + name + " is removed",
+				Rule: "apidiff-removed"})
+			continue
+		}
+		if a.signature != b.signature {
+			ret = append(ret, proto.Format{File: a.file, Line: a.line, Type: proto.TypeError, Details: "Exported " + name +
+				" is changed from " + b.signature + " to " + a.signature, Rule: "apidiff-changed"})
+		}
+	}
+
+	for name, a := range after {
+		if _, ok := before[name]; ok || a.owner == "" {
+			continue
+		}
+		if b, ok := before["type "+a.owner]; ok && b.signature == "interface" {
+			ret = append(ret, proto.Format{File: a.file, Line: a.line, Type: proto.TypeError, Details: "Exported " + name +
+				" is added to interface " + a.owner, Rule: "apidiff-interface"})
+		}
+	}
+
+	return ret
+}
+
+// api parses exported API of file in base64 into symbols, skipping package main.
+func api(file, data string, symbols map[string]symbol) error {
+	dec, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode")
+	}
+
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, file, dec, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
+	}
+
+	if f.Name.Name == "main" {
+		return nil
+	}
+
+	add := func(name string, pos token.Pos, signature, iface string) {
+		symbols[name] = symbol{file: file, owner: iface, line: fset.Position(pos).Line, signature: signature}
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			name := "func " + d.Name.Name
+			if d.Recv != nil {
+				recv := receiver(d.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				name = "method " + recv + "." + d.Name.Name
+			}
+			add(name, d.Pos(), signature(fset, d.Type), "")
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						declare(fset, s, add)
+					}
+				case *ast.ValueSpec:
+					sig := d.Tok.String()
+					if s.Type != nil {
+						sig += " " + expr(fset, s.Type)
+					}
+					for _, val := range s.Names {
+						if val.IsExported() {
+							add(d.Tok.String()+" "+val.Name, val.Pos(), sig, "")
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// declare adds type, with exported fields of struct and methods of interface as symbols of their own.
+func declare(fset *token.FileSet, s *ast.TypeSpec, add func(string, token.Pos, string, string)) {
+	name := s.Name.Name
+
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		add("type "+name, s.Pos(), "struct", "")
+		for _, field := range t.Fields.List {
+			for _, val := range field.Names {
+				if val.IsExported() {
+					add("field "+name+"."+val.Name, val.Pos(), expr(fset, field.Type), "")
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		add("type "+name, s.Pos(), "interface", "")
+		for _, method := range t.Methods.List {
+			if len(method.Names) == 0 {
+				add("embedded "+name+"."+expr(fset, method.Type), method.Pos(), "embedded", name)
+				continue
+			}
+			if f, ok := method.Type.(*ast.FuncType); ok {
+				add("method "+name+"."+method.Names[0].Name, method.Pos(), signature(fset, f), name)
+			}
+		}
+	default:
+		add("type "+name, s.Pos(), expr(fset, s.Type), "")
+	}
+}
+
+func receiver(data ast.Expr) string {
+	if star, ok := data.(*ast.StarExpr); ok {
+		data = star.X
+	}
+
+	if ident, ok := data.(*ast.Ident); ok {
+		return ident.Name
+	}
+
+	return ""
+}
+
+// signature prints function type with types of parameters and results only.
+func signature(fset *token.FileSet, f *ast.FuncType) string {
+	fields := func(list *ast.FieldList) []string {
+		var buf []string
+		if list == nil {
+			return buf
+		}
+		for _, val := range list.List {
+			n := len(val.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				buf = append(buf, expr(fset, val.Type))
+			}
+		}
+		return buf
+	}
+
+	ret := "func(" + strings.Join(fields(f.Params), ", ") + ")"
+
+	if results := fields(f.Results); len(results) == 1 {
+		ret += " " + results[0]
+	} else if len(results) > 1 {
+		ret += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	return ret
+}
+
+func expr(fset *token.FileSet, data ast.Expr) string {
+	if data == nil {
+		return ""
+	}
+
+	var b bytes.Buffer
+
+	_ = printer.Fprint(&b, fset, data)
+
+	return b.String()
+}
@@ -0,0 +1,99 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestApidiff(t *testing.T) {
+	encode := func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	before := `package api
+
+const Version = "1.0"
+
+type Client struct {
+	Addr    string
+	Timeout int
+}
+
+type Lint interface {
+	Run(name string) error
+}
+
+func New(addr string) *Client { return nil }
+
+func Old() {}
+
+func (c *Client) Get(key string) (string, error) { return "", nil }
+`
+
+	after := `package api
+
+const Version = "1.1"
+
+type Client struct {
+	Addr    string
+	Retry   int
+	Timeout string
+}
+
+type Lint interface {
+	Run(file string) error
+	Versions() map[string]string
+}
+
+func New(host string) *Client { return nil }
+
+func (c *Client) Get(key string, keep bool) (string, error) { return "", nil }
+
+func helper() {}
+`
+
+	parents, _ := json.Marshal(map[string]string{"api/api.go.base64": encode(before), "main.go.base64": encode("package main\n")})
+	m, _ := json.Marshal(map[string]string{
+		"api/api.go.base64":      encode(after),
+		"api/api_test.go.base64": encode("invalid"),
+		"main.go.base64":         encode("package main\n\nfunc Main() {}\n"),
+		"added.go.base64":        encode("package added\n\nfunc Added() {}\n"),
+		proto.Base64Parent:       base64.StdEncoding.EncodeToString(parents),
+	})
+
+	var l lint
+
+	buf, err := l.apidiff(m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "api/api.go", Type: proto.TypeError, Details: "Exported func Old is removed", Rule: "apidiff-removed"},
+		{File: "api/api.go", Line: 8, Type: proto.TypeError, Details: "Exported field Client.Timeout is changed from int to string",
+			Rule: "apidiff-changed"},
+		{File: "api/api.go", Line: 13, Type: proto.TypeError, Details: "Exported method Lint.Versions is added to interface Lint",
+			Rule: "apidiff-interface"},
+		{File: "api/api.go", Line: 18, Type: proto.TypeError, Details: "Exported method Client.Get is changed from " +
+			"func(string) (string, error) to func(string, bool) (string, error)", Rule: "apidiff-changed"},
+	}, buf)
+
+	m, _ = json.Marshal(map[string]string{"api/api.go.base64": encode("invalid")})
+
+	_, err = l.apidiff(m)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	binaryMaxSize = 1 << 20
+	sniffLen      = 512
+)
+
+var (
+	exifMarker = []byte("Exif\x00\x00")
+	textTypes  = []string{"application/json", "application/xml", "application/javascript"}
+)
+
+// sniff detects binary files by MIME type of content.
+func (l *lint) sniff(root string, files []string) map[string]bool {
+	ret := map[string]bool{}
+
+	for _, val := range files {
+		if val == proto.Base64Message {
+			continue
+		}
+		buf, err := l.storage.Read(filepath.Join(root, filepath.FromSlash(val)))
+		if err != nil {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(string(buf))
+		if err != nil {
+			continue
+		}
+		ret[val] = isBinary(dec)
+	}
+
+	return ret
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	t := http.DetectContentType(data)
+	if strings.HasPrefix(t, "text/") {
+		return false
+	}
+
+	for _, val := range textTypes {
+		if strings.HasPrefix(t, val) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// content keeps text files only for text lints, or binary files only for binary lints.
+func content(files []string, binary map[string]bool, accept, only bool) []string {
+	var buf []string
+
+	for _, val := range files {
+		if only && !binary[val] {
+			continue
+		}
+		if !accept && binary[val] {
+			continue
+		}
+		buf = append(buf, val)
+	}
+
+	return buf
+}
+
+// binary checks size of binary files and EXIF metadata of images, instead of calling worker.
+func (l *lint) binary(data []byte) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	var keys []string
+
+	for key := range buf {
+		if key != proto.Base64Findings {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	ret := []proto.Format{}
+
+	for _, key := range keys {
+		dec, err := base64.StdEncoding.DecodeString(buf[key])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if len(dec) > binaryMaxSize {
+			ret = append(ret, proto.Format{File: file, Line: 1, Type: proto.TypeWarn,
+				Details: "Binary file is larger than " + strconv.Itoa(binaryMaxSize) + " bytes", Rule: "binary-size"})
+		}
+		if http.DetectContentType(dec) == "image/jpeg" && bytes.Contains(dec, exifMarker) {
+			ret = append(ret, proto.Format{File: file, Line: 1, Type: proto.TypeWarn,
+				Details: "Image contains EXIF metadata, which should be stripped", Rule: "binary-exif"})
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,71 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+var (
+	jpeg = append([]byte("\xff\xd8\xff\xe1\x00\x10Exif\x00\x00"), bytes.Repeat([]byte{0}, 16)...)
+)
+
+func TestIsBinary(t *testing.T) {
+	assert.Equal(t, false, isBinary([]byte("package main\n")))
+	assert.Equal(t, false, isBinary([]byte(`{"foo": 1}`)))
+	assert.Equal(t, true, isBinary(jpeg))
+	assert.Equal(t, true, isBinary([]byte("text\x00text")))
+}
+
+func TestBinary(t *testing.T) {
+	var l lint
+
+	m, _ := json.Marshal(map[string]string{"logo.jpg.base64": base64.StdEncoding.EncodeToString(jpeg)})
+
+	buf, err := l.binary(m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "binary-exif", buf[0].Rule)
+}
+
+func TestRunBinary(t *testing.T) {
+	d, err := ioutil.TempDir("", "binary")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(base64.StdEncoding.EncodeToString([]byte("// lintflow:Error text\n"))), 0600)
+	_ = ioutil.WriteFile(filepath.Join(d, "logo.jpg.base64"), []byte(base64.StdEncoding.EncodeToString(jpeg)), 0600)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{{Name: lintFake}, {Name: lintBinary}}})
+
+	buf, _, err := l.Run(context.Background(), d, "", []string{"logo.jpg.base64", "main.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, proto.Format{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Lint: lintFake}, buf[0])
+	assert.Equal(t, "logo.jpg", buf[1].File)
+}
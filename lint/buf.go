@@ -0,0 +1,144 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	bufAnnotations = 100
+	bufBase        = "base"
+	bufCommand     = "buf"
+	bufHead        = "head"
+)
+
+// annotation is finding of buf in JSON error format.
+type annotation struct {
+	Message   string `json:"message"`
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	Type      string `json:"type"`
+}
+
+// buf runs buf breaking on .proto files against parent revision, and buf lint on them if enabled, instead of calling
+// worker. Files of lint, e.g., buf.yaml, are placed in both revisions, and command is the path of buf if set.
+func (l *lint) buf(ctx context.Context, v *config.Lint, data []byte) (findings []proto.Format, logs string, emsg error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, "", errors.Wrap(err, "failed to unmarshal")
+	}
+
+	parents := map[string]string{}
+
+	if val, ok := buf[proto.Base64Parent]; ok {
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to decode")
+		}
+		if err := json.Unmarshal(dec, &parents); err != nil {
+			return nil, "", errors.Wrap(err, "failed to unmarshal")
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "lintflow-buf")
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to mkdir")
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	count := map[string]int{}
+
+	for name, files := range map[string]map[string]string{bufBase: parents, bufHead: buf} {
+		if count[name], err = unpack(filepath.Join(dir, name), files, ".proto"); err != nil {
+			return nil, "", errors.Wrap(err, "failed to write")
+		}
+		if err := place(v, filepath.Join(dir, name)); err != nil {
+			return nil, "", errors.Wrap(err, "failed to place")
+		}
+	}
+
+	ctx, cancel := deadline(ctx, v)
+	defer cancel()
+
+	ret := []proto.Format{}
+
+	// Files added only are not breaking
+	if count[bufBase] != 0 {
+		r, stderr, err := l.bufRun(ctx, v, dir, "breaking", bufHead, "--against", bufBase, "--error-format", "json")
+		if err != nil {
+			return nil, stderr, errors.Wrap(err, "failed to breaking")
+		}
+		ret = append(ret, annotate(r, proto.TypeError)...)
+		logs = stderr
+	}
+
+	if v.Buf.Lint && count[bufHead] != 0 {
+		r, stderr, err := l.bufRun(ctx, v, dir, "lint", bufHead, "--error-format", "json")
+		if err != nil {
+			return nil, stderr, errors.Wrap(err, "failed to lint")
+		}
+		ret = append(ret, annotate(r, proto.TypeWarn)...)
+		logs = strings.TrimSpace(logs + "\n" + stderr)
+	}
+
+	return ret, tail(logs, logsSize), nil
+}
+
+// bufRun runs buf with args in dir, in which exit code of annotations found is not failure.
+func (l *lint) bufRun(ctx context.Context, v *config.Lint, dir string, args ...string) ([]annotation, string, error) {
+	stdout, stderr, err := tool(ctx, v, bufCommand, dir, []int{bufAnnotations}, args...)
+	if err != nil {
+		return nil, stderr, err
+	}
+
+	var ret []annotation
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+
+	for scanner.Scan() {
+		var a annotation
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			continue
+		}
+		ret = append(ret, a)
+	}
+
+	return ret, stderr, nil
+}
+
+func annotate(data []annotation, kind string) []proto.Format {
+	var ret []proto.Format
+
+	for _, val := range data {
+		ret = append(ret, proto.Format{File: val.Path, Line: val.StartLine, Type: kind, Details: val.Message,
+			Rule: val.Type})
+	}
+
+	return ret
+}
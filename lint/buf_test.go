@@ -0,0 +1,88 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestBuf(t *testing.T) {
+	d, err := ioutil.TempDir("", "buf")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "buf.yaml"), []byte("version: v1\n"), 0600)
+
+	encode := func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	parents, _ := json.Marshal(map[string]string{"foo/v1/foo.proto.base64": encode("syntax = \"proto3\";\n")})
+	m, _ := json.Marshal(map[string]string{
+		"foo/v1/foo.proto.base64": encode("syntax = \"proto3\";\n"),
+		proto.Base64Parent:        base64.StdEncoding.EncodeToString(parents),
+	})
+
+	// Fake buf checks layout of revisions, and reports finding of breaking or lint
+	script := `test -f base/foo/v1/foo.proto -a -f head/foo/v1/foo.proto -a -f head/buf.yaml || exit 1
+case "$0" in
+breaking) echo '{"path":"foo/v1/foo.proto","start_line":3,"type":"FIELD_NO_DELETE","message":"Previously present field \"1\" was deleted."}' ;;
+lint) echo '{"path":"foo/v1/foo.proto","start_line":1,"type":"PACKAGE_DEFINED","message":"Files must have a package defined."}' ;;
+esac
+echo done >&2
+exit 100`
+
+	v := config.Lint{
+		Buf:     config.Buf{Lint: true},
+		Command: []string{"sh", "-c", script},
+		Files:   map[string]string{"buf.yaml": filepath.Join(d, "buf.yaml")},
+		Name:    lintBuf,
+	}
+
+	var l lint
+
+	buf, logs, err := l.buf(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "foo/v1/foo.proto", Line: 3, Type: proto.TypeError, Details: "Previously present field \"1\" was deleted.",
+			Rule: "FIELD_NO_DELETE"},
+		{File: "foo/v1/foo.proto", Line: 1, Type: proto.TypeWarn, Details: "Files must have a package defined.",
+			Rule: "PACKAGE_DEFINED"},
+	}, buf)
+	assert.Equal(t, "done\ndone", logs)
+
+	v.Command = []string{"sh", "-c", "echo failure >&2; exit 1"}
+
+	_, logs, err = l.buf(context.Background(), &v, m)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "failure", logs)
+
+	m, _ = json.Marshal(map[string]string{"foo/v1/foo.proto.base64": encode("syntax = \"proto3\";\n")})
+	v.Buf.Lint = false
+
+	buf, _, err = l.buf(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
@@ -0,0 +1,75 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// bundle feeds centrally managed config files in directory of bundle to lints in reserved key of request, apart from
+// files of change to lint, which lints layer over workspace with config files of repository kept unless overridden.
+func (l *lint) bundle(data []byte, b *config.Bundle) ([]byte, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	bundle := proto.Bundle{Files: map[string]string{}, Override: b.Override}
+
+	err := filepath.Walk(b.Path, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.Path, name)
+		if err != nil {
+			return err
+		}
+		c, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		bundle.Files[filepath.ToSlash(rel)] = base64.StdEncoding.EncodeToString(c)
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to walk")
+	}
+
+	c, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	buf[proto.Base64Bundle] = base64.StdEncoding.EncodeToString(c)
+
+	ret, err := json.Marshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	return ret, nil
+}
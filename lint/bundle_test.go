@@ -0,0 +1,70 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestBundle(t *testing.T) {
+	d, err := ioutil.TempDir("", "bundle")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = os.MkdirAll(filepath.Join(d, "web"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(d, ".golangci.yml"), []byte("org"), filePerm)
+	_ = ioutil.WriteFile(filepath.Join(d, "web", ".eslintrc"), []byte("org"), filePerm)
+
+	var l lint
+
+	repo := base64.StdEncoding.EncodeToString([]byte("repo"))
+	org := base64.StdEncoding.EncodeToString([]byte("org"))
+
+	decode := func(data []byte) (map[string]string, proto.Bundle) {
+		var buf map[string]string
+		var b proto.Bundle
+		_ = json.Unmarshal(data, &buf)
+		c, _ := base64.StdEncoding.DecodeString(buf[proto.Base64Bundle])
+		_ = json.Unmarshal(c, &b)
+		delete(buf, proto.Base64Bundle)
+		return buf, b
+	}
+
+	ret, err := l.bundle([]byte(`{"main.go.base64":"","web/.eslintrc.base64":"`+repo+`"}`), &config.Bundle{Path: d})
+	assert.Equal(t, nil, err)
+
+	buf, b := decode(ret)
+	assert.Equal(t, map[string]string{"main.go.base64": "", "web/.eslintrc.base64": repo}, buf)
+	assert.Equal(t, proto.Bundle{Files: map[string]string{".golangci.yml": org, "web/.eslintrc": org}}, b)
+
+	ret, err = l.bundle([]byte(`{"web/.eslintrc.base64":"`+repo+`"}`), &config.Bundle{Override: true, Path: d})
+	assert.Equal(t, nil, err)
+
+	buf, b = decode(ret)
+	assert.Equal(t, repo, buf["web/.eslintrc.base64"])
+	assert.Equal(t, true, b.Override)
+
+	_, err = l.bundle([]byte(`{}`), &config.Bundle{Path: filepath.Join(d, "missing")})
+	assert.NotEqual(t, nil, err)
+}
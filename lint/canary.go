@@ -0,0 +1,36 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+)
+
+type canaryKey struct{}
+
+// WithCanary returns ctx running lints in names as canaries, whose failures are continued without failing runs.
+func WithCanary(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, canaryKey{}, names)
+}
+
+func canaryOf(ctx context.Context) map[string]bool {
+	names, _ := ctx.Value(canaryKey{}).([]string)
+
+	ret := map[string]bool{}
+
+	for _, val := range names {
+		ret[val] = true
+	}
+
+	return ret
+}
@@ -0,0 +1,44 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestCanary(t *testing.T) {
+	d, err := ioutil.TempDir("", "canary")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "main.go"), []byte("package main\n"), 0600)
+
+	l := New(&Config{Lints: []config.Lint{{Name: "lintsh", Command: []string{"sh", "-c", "exit 1"}}}})
+	match := func(*config.Filter, string, string) bool { return true }
+
+	_, _, err = l.Run(context.Background(), d, "repo", []string{"main.go"}, match)
+	assert.NotEqual(t, nil, err)
+
+	buf, _, err := l.Run(WithCanary(context.Background(), []string{"lintsh"}), d, "repo", []string{"main.go"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
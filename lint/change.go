@@ -0,0 +1,59 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+type changeKey struct{}
+
+// WithChange returns ctx carrying metadata of change, which is passed to workers for context-aware lints,
+// e.g., rules by branch or allowlist of authors.
+func WithChange(ctx context.Context, change *proto.Change) context.Context {
+	return context.WithValue(ctx, changeKey{}, change)
+}
+
+func changeOf(ctx context.Context) *proto.Change {
+	change, _ := ctx.Value(changeKey{}).(*proto.Change)
+	return change
+}
+
+// meta adds metadata of change to request in key change.base64, as base64 encoded JSON.
+func (l *lint) meta(data []byte, change *proto.Change) ([]byte, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	b, err := json.Marshal(change)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	buf[proto.Base64Change] = base64.StdEncoding.EncodeToString(b)
+
+	ret, err := json.Marshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,55 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestWithChange(t *testing.T) {
+	assert.Equal(t, (*proto.Change)(nil), changeOf(context.Background()))
+
+	c := &proto.Change{Branch: "master", Number: 1, Project: "fake"}
+	assert.Equal(t, c, changeOf(WithChange(context.Background(), c)))
+}
+
+func TestMeta(t *testing.T) {
+	var l lint
+
+	c := &proto.Change{Author: "fake@example.com", Branch: "master", Commit: "abc", Message: "text", Number: 1, Project: "fake"}
+
+	ret, err := l.meta([]byte(`{"main.go.base64":""}`), c)
+	assert.Equal(t, nil, err)
+
+	var buf map[string]string
+
+	_ = json.Unmarshal(ret, &buf)
+	assert.Equal(t, "", buf["main.go.base64"])
+
+	dec, _ := base64.StdEncoding.DecodeString(buf[proto.Base64Change])
+
+	var change proto.Change
+
+	_ = json.Unmarshal(dec, &change)
+	assert.Equal(t, *c, change)
+
+	_, err = l.meta([]byte("invalid"), c)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,156 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	conformLarge = 8 << 20
+)
+
+const (
+	CheckAuth    = "auth"
+	CheckCommand = "command"
+	CheckConnect = "connect"
+	CheckEmpty   = "empty"
+	CheckLarge   = "large"
+	CheckSchema  = "schema"
+	CheckTls     = "tls"
+	CheckVersion = "version"
+)
+
+// Check is result of one case in conformance of worker.
+type Check struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// conformFiles are canned inputs in languages commonly linted.
+var conformFiles = map[string]string{
+	"main.c":      "#include <stdio.h>\n\nint main(void)\n{\n    printf(\"lintflow\\n\");\n    return 0;\n}\n",
+	"main.go":     "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"lintflow\")\n}\n",
+	"main.py":     "import os\n\n\ndef main():\n    print(os.getcwd())\n",
+	"main.sh":     "#!/bin/bash\n\necho $1\n",
+	"Main.java":   "public class Main {\n    public static void main(String[] args) {\n        System.out.println(\"lintflow\");\n    }\n}\n",
+	"src/util.js": "var a = 1\nconsole.log(a)\n",
+}
+
+// Conform exercises worker at endpoint with canned inputs, and checks replies against the protocol,
+// where each case is to be replied within timeout.
+func Conform(ctx context.Context, endpoint string, timeout time.Duration) []Check {
+	start := time.Now()
+
+	c, cancel := context.WithTimeout(ctx, timeout)
+	conn, err := dial(c, endpoint)
+	cancel()
+
+	if err != nil {
+		return []Check{{Name: CheckConnect, Duration: time.Since(start), Err: errors.Wrap(err, "failed to dial")}}
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	ret := []Check{{Name: CheckConnect, Duration: time.Since(start)}}
+
+	run := func(name string, data []byte, check func([]proto.Format, string) error) {
+		c, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		start := time.Now()
+		buf, _, version, _, err := sendLint(c, conn, data)
+		if err == nil {
+			err = check(buf, version)
+		}
+		ret = append(ret, Check{Name: name, Duration: time.Since(start), Err: err})
+	}
+
+	files := conformInput(conformFiles)
+
+	run(CheckEmpty, []byte("{}"), func(buf []proto.Format, _ string) error {
+		if len(buf) != 0 {
+			return errors.New("invalid findings: reported on empty input")
+		}
+		return nil
+	})
+
+	run(CheckSchema, files, func(buf []proto.Format, _ string) error {
+		return conformFindings(files, buf)
+	})
+
+	run(CheckVersion, files, func(_ []proto.Format, version string) error {
+		if version == "" {
+			return errors.New("missing " + versionKey + " in header")
+		}
+		return nil
+	})
+
+	large := conformInput(map[string]string{"large.c": strings.Repeat("int a;\n", conformLarge/len("int a;\n"))})
+
+	run(CheckLarge, large, func(buf []proto.Format, _ string) error {
+		return conformFindings(large, buf)
+	})
+
+	return ret
+}
+
+func conformInput(files map[string]string) []byte {
+	buf := map[string]string{
+		proto.Base64Message: base64.StdEncoding.EncodeToString([]byte("Add lintflow conformance\n")),
+	}
+
+	for key, val := range files {
+		buf[key+proto.Base64Content] = base64.StdEncoding.EncodeToString([]byte(val))
+	}
+
+	ret, _ := json.Marshal(buf)
+
+	return ret
+}
+
+// conformFindings checks findings are reported on files in input and within their lines.
+func conformFindings(data []byte, findings []proto.Format) error {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return errors.Wrap(err, "failed to unmarshal")
+	}
+
+	for _, val := range findings {
+		if val.Scope == proto.ScopeChange {
+			continue
+		}
+		content, ok := buf[val.File+proto.Base64Content]
+		if !ok {
+			return errors.New("invalid file: " + val.File)
+		}
+		if val.Scope == proto.ScopeFile {
+			continue
+		}
+		dec, _ := base64.StdEncoding.DecodeString(content)
+		if val.Line < 0 || val.Line > lines(dec) {
+			return errors.Errorf("invalid line: %s:%d", val.File, val.Line)
+		}
+	}
+
+	return nil
+}
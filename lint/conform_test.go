@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+type conformServer struct {
+	UnimplementedLintProtoServer
+}
+
+func (c *conformServer) SendLint(ctx context.Context, req *LintRequest) (*LintReply, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal([]byte(req.GetMessage()), &buf); err != nil {
+		return nil, err
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(versionKey, "1.2.3"))
+
+	if _, ok := buf["main.go.base64"]; !ok {
+		return &LintReply{Message: "{}"}, nil
+	}
+
+	return &LintReply{Message: `{"lint":[{"file":"main.go","line":6,"type":"Error","details":"text"}]}`}, nil
+}
+
+func TestConform(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(math.MaxInt32))
+	RegisterLintProtoServer(s, &conformServer{})
+
+	go func() { _ = s.Serve(ln) }()
+	defer s.Stop()
+
+	buf := Conform(context.Background(), ln.Addr().String(), 3*time.Second)
+	assert.Equal(t, 5, len(buf))
+
+	for _, val := range buf {
+		assert.Equal(t, nil, val.Err, val.Name)
+	}
+
+	s = grpc.NewServer()
+	RegisterLintProtoServer(s, &lintServer{})
+
+	ln, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	go func() { _ = s.Serve(ln) }()
+	defer s.Stop()
+
+	buf = Conform(context.Background(), ln.Addr().String(), 3*time.Second)
+	assert.Equal(t, CheckEmpty, buf[1].Name)
+	assert.NotEqual(t, nil, buf[1].Err)
+	assert.Equal(t, nil, buf[2].Err)
+	assert.Equal(t, CheckLarge, buf[4].Name)
+	assert.NotEqual(t, nil, buf[4].Err)
+
+	buf = Conform(context.Background(), "127.0.0.1:1", 100*time.Millisecond)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, CheckConnect, buf[0].Name)
+	assert.NotEqual(t, nil, buf[0].Err)
+}
+
+func TestConformFindings(t *testing.T) {
+	data := conformInput(map[string]string{"main.go": "a\nb\n"})
+
+	assert.Equal(t, nil, conformFindings(data, nil))
+	assert.Equal(t, nil, conformFindings(data, []proto.Format{{File: "main.go", Line: 2}, {Scope: proto.ScopeChange}}))
+	assert.Equal(t, nil, conformFindings(data, []proto.Format{{File: "main.go", Line: 9, Scope: proto.ScopeFile}}))
+	assert.NotEqual(t, nil, conformFindings(data, []proto.Format{{File: "main.go", Line: 3}}))
+	assert.NotEqual(t, nil, conformFindings(data, []proto.Format{{File: "other.go", Line: 1}}))
+}
@@ -0,0 +1,95 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	claEmail = "{email}"
+)
+
+// dco checks Developer Certificate of Origin by Signed-off-by of author in commit message, and CLA of author by
+// service if any, instead of calling worker. Labels could be voted by findings of the lint.
+func (l *lint) dco(ctx context.Context, change *proto.Change, cfg *config.Lint) ([]proto.Format, error) {
+	if change == nil {
+		return nil, errors.New("invalid change")
+	}
+
+	ret := []proto.Format{}
+	author := committer(change)
+
+	if !signoff(change.Message, author) {
+		ret = append(ret, proto.Format{Type: proto.TypeError, Details: "Commit message lacks Signed-off-by of author " +
+			author + " to certify Developer Certificate of Origin", Rule: "dco-signoff", Scope: proto.ScopeChange})
+	}
+
+	if cfg.Dco.Cla == "" {
+		return ret, nil
+	}
+
+	signed, err := cla(ctx, cfg.Dco.Cla, author, cfg.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to cla")
+	}
+
+	if !signed {
+		ret = append(ret, proto.Format{Type: proto.TypeError, Details: "Author " + author +
+			" has not signed Contributor License Agreement", Rule: "dco-cla", Scope: proto.ScopeChange})
+	}
+
+	return ret, nil
+}
+
+// cla looks up author in CLA service by URL with {email} of author, in which author has signed if found, and not if
+// not found.
+func cla(ctx context.Context, addr, author string, timeout int) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(addr, claEmail,
+		url.QueryEscape(author)), nil)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to request")
+	}
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() { _ = rsp.Body.Close() }()
+
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errors.New("invalid status " + strconv.Itoa(rsp.StatusCode))
+	}
+}
@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestDco(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("email") {
+		case "dev@example.com":
+			w.WriteHeader(http.StatusOK)
+		case "other@example.com":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	var l lint
+
+	ctx := context.Background()
+	cfg := config.Lint{Dco: config.Dco{Cla: ts.URL + "/signed?email={email}"}, Name: lintDco}
+
+	_, err := l.dco(ctx, nil, &cfg)
+	assert.NotEqual(t, nil, err)
+
+	change := proto.Change{Author: "dev@example.com", Message: "Add main\n\nSigned-off-by: Dev <dev@example.com>\n"}
+
+	buf, err := l.dco(ctx, &change, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	change = proto.Change{Author: "other@example.com", Message: "Add main\n"}
+
+	buf, err = l.dco(ctx, &change, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, "dco-signoff", buf[0].Rule)
+	assert.Equal(t, "dco-cla", buf[1].Rule)
+
+	change = proto.Change{Author: "other@example.com", CommitAuthor: "dev@example.com",
+		Message: "Add main\n\nSigned-off-by: Dev <dev@example.com>\n"}
+
+	buf, err = l.dco(ctx, &change, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	change = proto.Change{Author: "unknown@example.com", Message: "Add main\n"}
+
+	_, err = l.dco(ctx, &change, &cfg)
+	assert.NotEqual(t, nil, err)
+
+	buf, err = l.dco(ctx, &change, &config.Lint{Name: lintDco})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+}
@@ -0,0 +1,177 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	descriptionFile = "/COMMIT_MSG"
+)
+
+var (
+	footerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: `)
+	headerPattern = regexp.MustCompile(`^(Parent|Merge Of|Author|AuthorDate|Commit|CommitDate): `)
+	linkPattern   = regexp.MustCompile(`https?://[^\s<>()"']+`)
+)
+
+// description checks commit message for length of description without footers, required sections, forbidden words
+// and validity of links, instead of calling worker. Findings are on lines of commit message.
+func (l *lint) description(ctx context.Context, data []byte, cfg *config.Lint) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	dec, err := base64.StdEncoding.DecodeString(buf[proto.Base64Message])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	lines := strings.Split(strings.TrimRight(string(dec), "\n"), "\n")
+	start := header(lines)
+	d := &cfg.Description
+
+	ret := []proto.Format{}
+
+	finding := func(line int, kind, rule, details string) {
+		ret = append(ret, proto.Format{File: descriptionFile, Line: line, Type: kind, Details: details,
+			Rule: "description-" + rule})
+	}
+
+	if n := length(lines[start:]); d.Length > 0 && n < d.Length {
+		finding(start+1, proto.TypeError, "length", "Description is "+strconv.Itoa(n)+" characters, shorter than "+
+			strconv.Itoa(d.Length))
+	}
+
+	for _, val := range d.Sections {
+		if !section(lines[start:], val) {
+			finding(start+1, proto.TypeError, "section", "Description lacks section "+val)
+		}
+	}
+
+	for index := start; index < len(lines); index++ {
+		for _, val := range d.Forbidden {
+			if forbidden(lines[index], val) {
+				finding(index+1, proto.TypeError, "forbidden", "Description contains forbidden word "+val)
+			}
+		}
+		if !d.Links {
+			continue
+		}
+		for _, val := range linkPattern.FindAllString(lines[index], -1) {
+			link := strings.TrimRight(val, ".,;:")
+			if err := probe(ctx, link, cfg.Timeout); err != nil {
+				finding(index+1, proto.TypeWarn, "link", "Link "+link+" is not valid: "+err.Error())
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// header returns index of the first line of message after header of commit, which Gerrit prepends to /COMMIT_MSG.
+func header(lines []string) int {
+	index := 0
+
+	for index < len(lines) && headerPattern.MatchString(lines[index]) {
+		index++
+	}
+
+	if index != 0 && index < len(lines) && strings.TrimSpace(lines[index]) == "" {
+		index++
+	}
+
+	return index
+}
+
+// length counts characters of message without footers in the last paragraph and blank lines.
+func length(lines []string) int {
+	end := len(lines)
+
+	for end > 0 && footerPattern.MatchString(lines[end-1]) {
+		end--
+	}
+
+	if end == 0 || strings.TrimSpace(lines[end-1]) != "" {
+		end = len(lines)
+	}
+
+	n := 0
+
+	for _, val := range lines[:end] {
+		n += len([]rune(strings.TrimSpace(val)))
+	}
+
+	return n
+}
+
+func section(lines []string, name string) bool {
+	for _, val := range lines {
+		if strings.HasPrefix(strings.TrimSpace(val), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func forbidden(line, word string) bool {
+	return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`).MatchString(line)
+}
+
+// probe requests link by HEAD, or GET if HEAD is not allowed, in which link is valid if replied without error.
+func probe(ctx context.Context, link string, timeout int) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	status := 0
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, link, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to request")
+		}
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to do")
+		}
+		_ = rsp.Body.Close()
+		if status = rsp.StatusCode; status != http.StatusMethodNotAllowed {
+			break
+		}
+	}
+
+	if status >= http.StatusBadRequest {
+		return errors.New("status " + strconv.Itoa(status))
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestDescription(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/head" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/head" || r.URL.Path == "/ok":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	body := "See " + ts.URL + "/ok, " + ts.URL + "/head and " + ts.URL + "/missing."
+	message := "Parent:     8f71e42d\nAuthor:     dev <dev@example.com>\n\nAdd main WIP\n\n" + body +
+		"\n\nBug: 1\nChange-Id: I8f71e42d\n"
+	m, _ := json.Marshal(map[string]string{proto.Base64Message: base64.StdEncoding.EncodeToString([]byte(message))})
+
+	var l lint
+
+	cfg := config.Lint{
+		Description: config.Description{Forbidden: []string{"wip"}, Length: 200, Links: true, Sections: []string{"Bug:", "Test:"}},
+		Name:        lintDescription,
+	}
+
+	buf, err := l.description(context.Background(), m, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "/COMMIT_MSG", Line: 4, Type: proto.TypeError, Details: "Description is " +
+			strconv.Itoa(len("Add main WIP")+len(body)) + " characters, shorter than 200", Rule: "description-length"},
+		{File: "/COMMIT_MSG", Line: 4, Type: proto.TypeError, Details: "Description lacks section Test:",
+			Rule: "description-section"},
+		{File: "/COMMIT_MSG", Line: 4, Type: proto.TypeError, Details: "Description contains forbidden word wip",
+			Rule: "description-forbidden"},
+		{File: "/COMMIT_MSG", Line: 6, Type: proto.TypeWarn, Details: "Link " + ts.URL + "/missing is not valid: status 404",
+			Rule: "description-link"},
+	}, buf)
+
+	buf, err = l.description(context.Background(), m, &config.Lint{Name: lintDescription})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	_, err = l.description(context.Background(), []byte("invalid"), &cfg)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDescriptionLength(t *testing.T) {
+	assert.Equal(t, 9, length([]string{"fix: typo"}))
+	assert.Equal(t, 8, length([]string{"Add main", "", "Change-Id: I8f71e42d"}))
+	assert.Equal(t, 21, length([]string{"Add main", "Bug: 1", "Test: 1"}))
+	assert.Equal(t, 0, header([]string{"Add main", "", "Body"}))
+	assert.Equal(t, 2, header([]string{"Parent: 1", "", "Add main"}))
+}
@@ -0,0 +1,226 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+// Readiness is result of checks on lint in config, in which built-in lints are ready without checks.
+type Readiness struct {
+	Checks   []Check
+	Endpoint string
+	Lint     string
+	Version  string
+}
+
+// Ready reports whether all checks pass.
+func (r *Readiness) Ready() bool {
+	for _, val := range r.Checks {
+		if val.Err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Doctor checks readiness of lints in config concurrently, i.e., commands found for local lints, and workers
+// reachable in plaintext, accepting env and files of lints, and replying in the protocol, where each check is to be
+// done within timeout.
+func Doctor(ctx context.Context, lints []config.Lint, timeout time.Duration) ([]Readiness, error) {
+	l := &lint{cfg: &Config{Lints: lints}}
+
+	if err := l.validate(); err != nil {
+		return nil, errors.Wrap(err, "failed to validate")
+	}
+
+	ret := make([]Readiness, len(lints))
+
+	var wg sync.WaitGroup
+
+	for i := range lints {
+		wg.Add(1)
+		go func(v *config.Lint, r *Readiness) {
+			defer wg.Done()
+			r.Lint = v.Name
+			if kind(v) != "" {
+				return
+			}
+			if len(v.Command) != 0 {
+				r.Checks = doctorCommand(v)
+				return
+			}
+			r.Endpoint = v.Host + ":" + strconv.Itoa(v.Port)
+			r.Checks, r.Version = doctorWorker(ctx, v, r.Endpoint, timeout)
+		}(&lints[i], &ret[i])
+	}
+
+	wg.Wait()
+
+	return ret, nil
+}
+
+func builtin(name string) bool {
+	switch name {
+	case lintApidiff, lintBinary, lintBuf, lintCheckov, lintDco, lintDescription, lintFake, lintMarkdown, lintMigration,
+		lintSignature, lintSubmodule, lintTflint, lintUi:
+		return true
+	default:
+		return false
+	}
+}
+
+// kind returns built-in lint to run, which is set by builtin explicitly, or by name of lint without worker host so
+// that workers named after built-in lints are not replaced.
+func kind(v *config.Lint) string {
+	if v.Builtin != "" {
+		return v.Builtin
+	}
+
+	if v.Host == "" && builtin(v.Name) {
+		return v.Name
+	}
+
+	return ""
+}
+
+func doctorCommand(v *config.Lint) []Check {
+	start := time.Now()
+
+	command := []string{v.Command[0]}
+	if v.Sandbox.Kind != "" {
+		command = append(command, v.Sandbox.Kind)
+	}
+
+	for _, val := range command {
+		if _, err := exec.LookPath(val); err != nil {
+			return []Check{{Name: CheckCommand, Duration: time.Since(start), Err: errors.Wrap(err, "failed to look")}}
+		}
+	}
+
+	return []Check{{Name: CheckCommand, Duration: time.Since(start)}}
+}
+
+func doctorWorker(ctx context.Context, v *config.Lint, endpoint string, timeout time.Duration) ([]Check, string) {
+	var ret []Check
+
+	check := func(name string, helper func() error) bool {
+		start := time.Now()
+		err := helper()
+		ret = append(ret, Check{Name: name, Duration: time.Since(start), Err: err})
+		return err == nil
+	}
+
+	connect := func() error {
+		if v.Host == "" || v.Port <= 0 {
+			return errors.New("invalid endpoint " + endpoint)
+		}
+		conn, err := net.DialTimeout("tcp", endpoint, timeout)
+		if err != nil {
+			return errors.Wrap(err, "failed to dial")
+		}
+		return conn.Close()
+	}
+
+	// Worker serving TLS never completes handshake of gRPC dialed in plaintext, and times out runs instead
+	plaintext := func() error {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", endpoint,
+			&tls.Config{InsecureSkipVerify: true}) // nolint:gosec
+		if err != nil {
+			return nil
+		}
+		_ = conn.Close()
+		return errors.New("worker serves TLS, but workers are dialed in plaintext")
+	}
+
+	if !check(CheckConnect, connect) || !check(CheckTls, plaintext) {
+		return ret, ""
+	}
+
+	c, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var conn *grpc.ClientConn
+	var header metadata.MD
+	var reply *LintReply
+	var sent error
+	var version string
+
+	auth := func() error {
+		var err error
+		if conn, err = dial(c, endpoint); err != nil {
+			return errors.Wrap(err, "failed to dial")
+		}
+		if c, err = outgoing(c, v); err != nil {
+			return errors.Wrap(err, "failed to forward")
+		}
+		reply, sent = NewLintProtoClient(conn).SendLint(c, &LintRequest{Message: "{}"}, grpc.Header(&header))
+		if code := status.Code(sent); code == codes.Unauthenticated || code == codes.PermissionDenied {
+			return errors.Wrap(sent, "failed to authenticate")
+		}
+		return nil
+	}
+
+	ok := check(CheckAuth, auth)
+
+	if conn != nil {
+		defer func() { _ = conn.Close() }()
+	}
+
+	if !ok {
+		return ret, ""
+	}
+
+	empty := func() error {
+		if sent != nil {
+			return errors.Wrap(sent, "failed to send")
+		}
+		buf, err := parse([]byte(reply.GetMessage()))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse")
+		}
+		if len(buf) != 0 {
+			return errors.New("invalid findings: reported on empty input")
+		}
+		return nil
+	}
+
+	if !check(CheckEmpty, empty) {
+		return ret, ""
+	}
+
+	check(CheckVersion, func() error {
+		if val := header.Get(versionKey); len(val) != 0 {
+			version = val[0]
+			return nil
+		}
+		return errors.New("missing " + versionKey + " in header")
+	})
+
+	return ret, version
+}
@@ -0,0 +1,116 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+type authServer struct {
+	UnimplementedLintProtoServer
+}
+
+func (a *authServer) SendLint(_ context.Context, _ *LintRequest) (*LintReply, error) {
+	return nil, status.Error(codes.Unauthenticated, "invalid token")
+}
+
+func TestDoctor(t *testing.T) {
+	serve := func(server LintProtoServer) (int, func()) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Equal(t, nil, err)
+		s := grpc.NewServer()
+		RegisterLintProtoServer(s, server)
+		go func() { _ = s.Serve(ln) }()
+		return ln.Addr().(*net.TCPAddr).Port, s.Stop
+	}
+
+	ready, stop := serve(&conformServer{})
+	defer stop()
+
+	auth, stop := serve(&authServer{})
+	defer stop()
+
+	s := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer s.Close()
+
+	secure, _ := strconv.Atoi(s.URL[len("https://127.0.0.1:"):])
+
+	lints := []config.Lint{
+		{Name: "lintready", Host: "127.0.0.1", Port: ready},
+		{Name: "lintauth", Host: "127.0.0.1", Port: auth},
+		{Name: "linttls", Host: "127.0.0.1", Port: secure},
+		{Name: "lintdown", Host: "127.0.0.1", Port: 1},
+		{Name: "lintsh", Command: []string{"sh", "-c", "true"}},
+		{Name: "lintmissing", Command: []string{"lintflow-missing"}},
+		{Name: lintMarkdown},
+	}
+
+	buf, err := Doctor(context.Background(), lints, 3*time.Second)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(lints), len(buf))
+
+	names := func(r Readiness) []string {
+		var ret []string
+		for _, val := range r.Checks {
+			ret = append(ret, val.Name)
+		}
+		return ret
+	}
+
+	assert.Equal(t, true, buf[0].Ready())
+	assert.Equal(t, []string{CheckConnect, CheckTls, CheckAuth, CheckEmpty, CheckVersion}, names(buf[0]))
+	assert.Equal(t, "1.2.3", buf[0].Version)
+
+	assert.Equal(t, false, buf[1].Ready())
+	assert.Equal(t, []string{CheckConnect, CheckTls, CheckAuth}, names(buf[1]))
+
+	assert.Equal(t, false, buf[2].Ready())
+	assert.Equal(t, []string{CheckConnect, CheckTls}, names(buf[2]))
+
+	assert.Equal(t, false, buf[3].Ready())
+	assert.Equal(t, []string{CheckConnect}, names(buf[3]))
+
+	assert.Equal(t, true, buf[4].Ready())
+	assert.Equal(t, false, buf[5].Ready())
+	assert.Equal(t, []string{CheckCommand}, names(buf[5]))
+
+	assert.Equal(t, true, buf[6].Ready())
+	assert.Equal(t, 0, len(buf[6].Checks))
+
+	_, err = Doctor(context.Background(), []config.Lint{{Name: "lintsh", Depends: []string{"lintinvalid"}}}, time.Second)
+	assert.NotEqual(t, nil, err)
+
+	_, err = Doctor(context.Background(), []config.Lint{{Name: "lintsh", Builtin: "invalid"}}, time.Second)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestKind(t *testing.T) {
+	assert.Equal(t, lintDco, kind(&config.Lint{Name: lintDco}))
+	assert.Equal(t, lintDco, kind(&config.Lint{Name: "lintcla", Builtin: lintDco}))
+	assert.Equal(t, lintBuf, kind(&config.Lint{Name: lintBuf, Command: []string{"/opt/buf"}}))
+	assert.Equal(t, "", kind(&config.Lint{Name: lintDco, Host: "127.0.0.1", Port: 9090}))
+	assert.Equal(t, "", kind(&config.Lint{Name: "lintsh", Command: []string{"sh"}}))
+}
@@ -0,0 +1,132 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	envKey     = "lint-env"
+	fileKey    = "lint-file"
+	filePerm   = 0600
+	workdirKey = "lint-workdir"
+)
+
+// environ returns environment of command with env of lint, in which environment of lintflow is dropped if sandboxed.
+func environ(v *config.Lint) []string {
+	var ret []string
+
+	if v.Sandbox.Kind != "" {
+		// Keep credentials in environment of lintflow away from tools
+		ret = []string{"PATH=" + os.Getenv("PATH")}
+	} else {
+		ret = os.Environ()
+	}
+
+	for _, key := range sorted(v.Env) {
+		ret = append(ret, key+"="+v.Env[key])
+	}
+
+	return ret
+}
+
+// workdir returns working directory of command, which is relative to workspace unless absolute.
+func workdir(v *config.Lint, root string) string {
+	if filepath.IsAbs(v.Workdir) {
+		return v.Workdir
+	}
+
+	if root == "" {
+		return ""
+	}
+
+	return filepath.Join(root, filepath.FromSlash(v.Workdir))
+}
+
+// place copies files of lint from their sources to targets relative to working directory, e.g., .eslintrc override.
+func place(v *config.Lint, dir string) error {
+	if len(v.Files) == 0 {
+		return nil
+	}
+
+	if dir == "" {
+		return errors.New("invalid working directory for files")
+	}
+
+	for _, key := range sorted(v.Files) {
+		buf, err := ioutil.ReadFile(v.Files[key])
+		if err != nil {
+			return errors.Wrap(err, "failed to read")
+		}
+		name := filepath.Join(dir, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			return errors.Wrap(err, "failed to mkdir")
+		}
+		if err := ioutil.WriteFile(name, buf, filePerm); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+// outgoing forwards env, working directory and files of lint to worker in metadata,
+// as KEY=VALUE in lint-env, path in lint-workdir and name=base64 of content in lint-file.
+func outgoing(ctx context.Context, v *config.Lint) (context.Context, error) {
+	var kv []string
+
+	for _, key := range sorted(v.Env) {
+		kv = append(kv, envKey, key+"="+v.Env[key])
+	}
+
+	if v.Workdir != "" {
+		kv = append(kv, workdirKey, v.Workdir)
+	}
+
+	for _, key := range sorted(v.Files) {
+		buf, err := ioutil.ReadFile(v.Files[key])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+		kv = append(kv, fileKey, key+"="+base64.StdEncoding.EncodeToString(buf))
+	}
+
+	if len(kv) == 0 {
+		return ctx, nil
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...), nil
+}
+
+func sorted(data map[string]string) []string {
+	var ret []string
+
+	for key := range data {
+		ret = append(ret, key)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
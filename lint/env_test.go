@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestEnviron(t *testing.T) {
+	v := config.Lint{Env: map[string]string{"GOFLAGS": "-mod=mod", "ESLINT_USE_FLAT_CONFIG": "false"}}
+
+	buf := environ(&v)
+	assert.Equal(t, len(os.Environ())+2, len(buf))
+	assert.Equal(t, []string{"ESLINT_USE_FLAT_CONFIG=false", "GOFLAGS=-mod=mod"}, buf[len(buf)-2:])
+
+	v.Sandbox.Kind = sandboxNsjail
+
+	buf = environ(&v)
+	assert.Equal(t, []string{"PATH=" + os.Getenv("PATH"), "ESLINT_USE_FLAT_CONFIG=false", "GOFLAGS=-mod=mod"}, buf)
+}
+
+func TestWorkdir(t *testing.T) {
+	assert.Equal(t, "/work", workdir(&config.Lint{}, "/work"))
+	assert.Equal(t, filepath.Join("/work", "web"), workdir(&config.Lint{Workdir: "web"}, "/work"))
+	assert.Equal(t, "/opt/lint", workdir(&config.Lint{Workdir: "/opt/lint"}, "/work"))
+	assert.Equal(t, "", workdir(&config.Lint{Workdir: "web"}, ""))
+}
+
+func TestPlace(t *testing.T) {
+	d, err := ioutil.TempDir("", "env")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	src := filepath.Join(d, "eslintrc")
+	_ = ioutil.WriteFile(src, []byte("{}"), filePerm)
+
+	v := config.Lint{Files: map[string]string{"web/.eslintrc": src}}
+
+	err = place(&v, filepath.Join(d, "work"))
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(d, "work", "web", ".eslintrc"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "{}", string(buf))
+
+	err = place(&v, "")
+	assert.NotEqual(t, nil, err)
+
+	err = place(&config.Lint{Files: map[string]string{".eslintrc": filepath.Join(d, "missing")}}, d)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestOutgoing(t *testing.T) {
+	d, err := ioutil.TempDir("", "env")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	src := filepath.Join(d, "eslintrc")
+	_ = ioutil.WriteFile(src, []byte("{}"), filePerm)
+
+	ctx, err := outgoing(context.Background(), &config.Lint{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, context.Background(), ctx)
+
+	ctx, err = outgoing(context.Background(), &config.Lint{Env: map[string]string{"GOFLAGS": "-mod=mod"},
+		Files: map[string]string{".eslintrc": src}, Workdir: "web"})
+	assert.Equal(t, nil, err)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"GOFLAGS=-mod=mod"}, md.Get(envKey))
+	assert.Equal(t, []string{".eslintrc=e30="}, md.Get(fileKey))
+	assert.Equal(t, []string{"web"}, md.Get(workdirKey))
+}
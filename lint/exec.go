@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	execTimeout = 300
+	stderrSize  = 4096
+)
+
+// exec runs command of lint locally with request on stdin and reply in Errorformat on stdout,
+// in which working directory is relative to workspace if on disk, and stderr is kept as execution logs.
+func (l *lint) exec(ctx context.Context, root string, v *config.Lint, data []byte) (findings []proto.Format,
+	files map[string]string, logs string, emsg error) {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = execTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	base := ""
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		base = root
+	}
+
+	dir := workdir(v, base)
+
+	if err := place(v, dir); err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to place")
+	}
+
+	args, err := sandbox(&v.Sandbox, dir, v.Command)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to sandbox")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) // nolint:gosec
+	cmd.Dir = dir
+	cmd.Env = environ(v)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, nil, tail(stderr.String(), logsSize), errors.Wrap(err, "failed to run: "+tail(stderr.String(), stderrSize))
+	}
+
+	logs = tail(strings.TrimSpace(strings.TrimSpace(stderr.String())+"\n"+parseLogs(stdout.Bytes())), logsSize)
+
+	buf, err := parse(stdout.Bytes())
+	if err != nil {
+		return nil, nil, logs, errors.Wrap(err, "failed to parse")
+	}
+
+	return buf, parseFiles(stdout.Bytes()), logs, nil
+}
@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestExec(t *testing.T) {
+	d, err := ioutil.TempDir("", "exec")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	var l lint
+
+	v := config.Lint{Command: []string{"sh", "-c", `cat >/dev/null; test -f .lintrc || exit 1; echo started >&2; ` +
+		`echo '{"lint":[{"file":"main.go","line":1,"type":"Error","details":"'$LINT_DETAILS'"}],"logs":"1 file"}'`},
+		Env: map[string]string{"LINT_DETAILS": "text"}, Files: map[string]string{".lintrc": "exec_test.go"},
+		Workdir: "web"}
+
+	buf, _, logs, err := l.exec(context.Background(), d, &v, []byte(`{"main.go.base64":""}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text"}}, buf)
+	assert.Equal(t, "started\n1 file", logs)
+
+	v.Env, v.Files, v.Workdir = nil, nil, ""
+
+	v.Command = []string{"sh", "-c", "echo failure >&2; exit 1"}
+
+	_, _, logs, err = l.exec(context.Background(), d, &v, nil)
+	assert.Equal(t, true, err != nil && err.Error() == "failed to run: failure\n: exit status 1")
+	assert.Equal(t, "failure\n", logs)
+
+	v.Command = []string{"sh", "-c", "echo invalid"}
+
+	_, _, _, err = l.exec(context.Background(), d, &v, nil)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	fakeMarker  = "lintflow:"
+	fakeMessage = "/COMMIT_MSG"
+)
+
+// fake reports findings marked in content with "lintflow:<type> <details>", instead of calling worker.
+func (l *lint) fake(data []byte) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	var keys []string
+
+	for key := range buf {
+		if key != proto.Base64Findings {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	ret := []proto.Format{}
+
+	for _, key := range keys {
+		dec, err := base64.StdEncoding.DecodeString(buf[key])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if key == proto.Base64Message {
+			file = fakeMessage
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(dec)))
+		for line := 1; scanner.Scan(); line++ {
+			index := strings.Index(scanner.Text(), fakeMarker)
+			if index < 0 {
+				continue
+			}
+			item := strings.SplitN(strings.TrimSpace(scanner.Text()[index+len(fakeMarker):]), " ", 2)
+			if len(item) != 2 {
+				continue
+			}
+			ret = append(ret, proto.Format{File: file, Line: line, Type: item[0], Details: item[1]})
+		}
+	}
+
+	return ret, nil
+}
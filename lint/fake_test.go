@@ -0,0 +1,44 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestFake(t *testing.T) {
+	var l lint
+
+	_, err := l.fake([]byte("invalid"))
+	assert.NotEqual(t, nil, err)
+
+	data := map[string]string{
+		"main.go.base64":    base64.StdEncoding.EncodeToString([]byte("package main\n\n// lintflow:Error Fake error\n")),
+		proto.Base64Message: base64.StdEncoding.EncodeToString([]byte("Fake\n\nlintflow:Warn Fake warning\nlintflow:\n")),
+	}
+
+	buf, _ := json.Marshal(data)
+
+	ret, err := l.fake(buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "main.go", Line: 3, Type: proto.TypeError, Details: "Fake error"},
+		{File: fakeMessage, Line: 3, Type: proto.TypeWarn, Details: "Fake warning"},
+	}, ret)
+}
@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+// correct converts lines of findings out of range of files in request to file-level on line 0,
+// and logs the lint reporting them. Findings on file or change are cleared of line, and file for change.
+func correct(name string, data []byte, findings []proto.Format) []proto.Format {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return findings
+	}
+
+	count := map[string]int{}
+
+	for key, val := range buf {
+		if key == proto.Base64Bundle || key == proto.Base64Change || key == proto.Base64Findings ||
+			key == proto.Base64Message || key == proto.Base64Parent {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			continue
+		}
+		count[strings.TrimSuffix(key, proto.Base64Content)] = lines(dec)
+	}
+
+	for i := range findings {
+		switch findings[i].Scope {
+		case proto.ScopeChange:
+			findings[i].File, findings[i].Line = "", 0
+			continue
+		case proto.ScopeFile:
+			findings[i].Line = 0
+			continue
+		}
+		n, ok := count[findings[i].File]
+		if findings[i].Line < 0 || (ok && findings[i].Line > n) {
+			log.Printf("lint %s reported line %d out of range of %s, corrected to file-level",
+				name, findings[i].Line, findings[i].File)
+			findings[i].Line = 0
+		}
+	}
+
+	return findings
+}
+
+func lines(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+
+	n := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestCorrect(t *testing.T) {
+	m, _ := json.Marshal(map[string]string{
+		"main.go.base64":    base64.StdEncoding.EncodeToString([]byte("package main\n\nfunc main() {}\n")),
+		proto.Base64Message: base64.StdEncoding.EncodeToString([]byte("message\n")),
+	})
+
+	buf := correct(lintFake, m, []proto.Format{
+		{File: "main.go", Line: 3},
+		{File: "main.go", Line: 4},
+		{File: "main.go", Line: -1},
+		{File: "main.go", Line: 0},
+		{File: "/COMMIT_MSG", Line: 9},
+		{File: "main.go", Line: 2, Scope: proto.ScopeFile},
+		{File: "main.go", Line: 2, Scope: proto.ScopeChange},
+	})
+
+	assert.Equal(t, []proto.Format{
+		{File: "main.go", Line: 3},
+		{File: "main.go", Line: 0},
+		{File: "main.go", Line: 0},
+		{File: "main.go", Line: 0},
+		{File: "/COMMIT_MSG", Line: 9},
+		{File: "main.go", Line: 0, Scope: proto.ScopeFile},
+		{File: "", Line: 0, Scope: proto.ScopeChange},
+	}, buf)
+}
+
+func TestLines(t *testing.T) {
+	assert.Equal(t, 0, lines(nil))
+	assert.Equal(t, 1, lines([]byte("text")))
+	assert.Equal(t, 1, lines([]byte("text\n")))
+	assert.Equal(t, 2, lines([]byte("text\n\n")))
+}
@@ -14,36 +14,79 @@ package lint
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
-	"io/ioutil"
+	"log"
 	"math"
-	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/progress"
 	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	lintApidiff     = "apidiff"
+	lintBinary      = "binary"
+	lintBuf         = "buf"
+	lintCheckov     = "checkov"
+	lintDco         = "dco"
+	lintDescription = "description"
+	lintFake        = "fake"
+	lintMarkdown    = "markdown"
+	lintMigration   = "migration"
+	lintSignature   = "signature"
+	lintSubmodule   = "submodule"
+	lintTflint      = "tflint"
+	lintUi          = "ui"
+)
+
+const (
+	failureContinue = "continue"
+	failureSkip     = "skip"
+)
+
+const (
+	versionKey = "lint-version"
 )
 
 type Lint interface {
-	Run(string, string, []string, func(*config.Filter, string, string) bool) ([]proto.Format, error)
+	Parent() bool
+	Run(context.Context, string, string, []string, func(*config.Filter, string, string) bool) ([]proto.Format,
+		map[string]string, error)
 }
 
 type Config struct {
-	Lints []config.Lint
+	Lints    []config.Lint
+	Snapshot config.Snapshot
+	Storage  storage.Storage
 }
 
 type lint struct {
-	cfg *Config
+	cfg     *Config
+	links   map[string]probed
+	mutex   sync.Mutex
+	storage storage.Storage
 }
 
 func New(cfg *Config) Lint {
+	s := cfg.Storage
+	if s == nil {
+		s = storage.New(storage.DefaultConfig())
+	}
+
 	return &lint{
-		cfg: cfg,
+		cfg:     cfg,
+		storage: s,
 	}
 }
 
@@ -51,7 +94,20 @@ func DefaultConfig() *Config {
 	return &Config{}
 }
 
-func (l *lint) Run(root, repo string, files []string, match func(*config.Filter, string, string) bool) ([]proto.Format, error) {
+// Error is failure of running lint, e.g., worker timeout.
+type Error struct {
+	Name string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return "failed to run " + e.Name + ": " + e.Err.Error()
+}
+
+// Run lints files, and returns findings with tool versions of lints reported in this run. Timeout of each lint is
+// bounded by deadline of ctx.
+func (l *lint) Run(ctx context.Context, root, repo string, files []string,
+	match func(*config.Filter, string, string) bool) ([]proto.Format, map[string]string, error) {
 	helper := func(filter *config.Filter, files []string) []string {
 		var buf []string
 		for _, item := range files {
@@ -62,65 +118,330 @@ func (l *lint) Run(root, repo string, files []string, match func(*config.Filter,
 		return buf
 	}
 
-	type result struct {
-		data []proto.Format
-		err  error
+	type node struct {
+		data  []proto.Format
+		done  chan struct{}
+		err   error
+		files map[string]string
+		skip  bool
+	}
+
+	if err := l.validate(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to validate")
 	}
 
 	bypass := true
-	ch := make(chan result, len(l.cfg.Lints))
+	nodes := map[string]*node{}
 
 	for _, val := range l.cfg.Lints {
-		buf := helper(&val.Filter, files)
-		if len(buf) != 0 {
+		nodes[val.Name] = &node{done: make(chan struct{})}
+	}
+
+	binary := l.sniff(root, files)
+	canary := canaryOf(ctx)
+	only := l.selected(lintsOf(ctx))
+	buf := map[string][]string{}
+
+	for _, val := range l.cfg.Lints {
+		k := kind(&val)
+		buf[val.Name] = content(helper(&val.Filter, files), binary, val.Binary || k == lintBinary, k == lintBinary)
+		if k == lintDco || k == lintDescription || k == lintSignature {
+			buf[val.Name] = message(buf[val.Name])
+		}
+		if k == lintSubmodule && len(message(buf[val.Name])) == 0 {
+			buf[val.Name] = append(buf[val.Name], message(files)...)
+		}
+		if only != nil && !only[val.Name] {
+			buf[val.Name] = nil
+		}
+		if len(buf[val.Name]) != 0 {
 			bypass = false
 		}
-		go func(f []string, v config.Lint) {
-			if len(f) != 0 {
-				m, e := l.marshal(root, f)
-				if e != nil {
-					ch <- result{nil, errors.Wrap(e, "failed to marshal")}
+	}
+
+	var mutex sync.Mutex
+	p, done, total := progress.Of(ctx), 0, 0
+	versions := map[string]string{}
+
+	for _, val := range buf {
+		if len(val) != 0 {
+			total++
+		}
+	}
+
+	report := func(name, state string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if state != progress.StateRunning {
+			done++
+		}
+		p.Report(progress.Event{Done: done, Lint: name, Stage: proto.StageLint, State: state, Total: total})
+	}
+
+	for _, val := range l.cfg.Lints {
+		go func(f []string, v config.Lint, n *node) {
+			defer close(n.done)
+			var feed []proto.Format
+			n.files = map[string]string{}
+			for _, d := range v.Depends {
+				<-nodes[d].done
+				if nodes[d].skip {
+					n.skip = true
 				}
-				r, e := l.routine(v.Host, v.Port, v.Timeout, m)
-				if e != nil {
-					ch <- result{nil, errors.Wrap(e, "failed to routine")}
+				feed = append(feed, nodes[d].data...)
+				for key, val := range nodes[d].files {
+					n.files[key] = val
+				}
+			}
+			if n.skip {
+				if len(f) != 0 {
+					report(v.Name, progress.StateSkipped)
 				}
-				ch <- result{r, nil}
-			} else {
-				ch <- result{[]proto.Format{}, nil}
+				return
 			}
-		}(buf, val)
+			if len(f) == 0 {
+				n.data = []proto.Format{}
+				return
+			}
+			report(v.Name, progress.StateRunning)
+			r, rewritten, version, e := l.send(ctx, root, f, v, feed, n.files)
+			if e == nil {
+				mutex.Lock()
+				versions[v.Name] = version
+				mutex.Unlock()
+				n.data = r
+				for key, val := range rewritten {
+					n.files[key] = val
+				}
+				report(v.Name, progress.StateDone)
+				return
+			}
+			report(v.Name, progress.StateFailed)
+			failure := v.Failure
+			if canary[v.Name] {
+				failure = failureContinue
+			}
+			switch failure {
+			case failureContinue:
+				log.Printf("lint %s failed and continued: %v", v.Name, e)
+				n.data = []proto.Format{}
+			case failureSkip:
+				log.Printf("lint %s failed and skipped dependents: %v", v.Name, e)
+				n.skip = true
+			default:
+				n.err, n.skip = &Error{Name: v.Name, Err: e}, true
+			}
+		}(buf[val.Name], affine(val, repo), nodes[val.Name])
+	}
+
+	for _, val := range l.cfg.Lints {
+		<-nodes[val.Name].done
 	}
 
 	if bypass {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	ret := []proto.Format{}
 
-	for range l.cfg.Lints {
-		r := <-ch
-		if r.err != nil {
-			return nil, r.err
+	for _, val := range l.cfg.Lints {
+		n := nodes[val.Name]
+		if n.err != nil {
+			return nil, nil, n.err
+		}
+		if len(n.data) != 0 {
+			ret = append(ret, n.data...)
+		}
+	}
+
+	return ret, versions, nil
+}
+
+// validate checks dependencies of lints are known and acyclic.
+func (l *lint) validate() error {
+	depends := map[string][]string{}
+
+	for _, val := range l.cfg.Lints {
+		if _, ok := depends[val.Name]; ok {
+			return errors.New("duplicate lint " + val.Name)
+		}
+		if val.Builtin != "" && !builtin(val.Builtin) {
+			return errors.New("invalid builtin " + val.Builtin)
+		}
+		depends[val.Name] = val.Depends
+	}
+
+	state := map[string]int{}
+
+	var visit func(string) error
+
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return errors.New("cyclic depends on " + name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, val := range depends[name] {
+			if _, ok := depends[val]; !ok {
+				return errors.New("invalid depends " + val)
+			}
+			if err := visit(val); err != nil {
+				return err
+			}
 		}
-		if len(r.data) != 0 {
-			ret = append(ret, r.data...)
+		state[name] = 2
+		return nil
+	}
+
+	for _, val := range l.cfg.Lints {
+		if err := visit(val.Name); err != nil {
+			return err
 		}
 	}
 
+	return nil
+}
+
+// send lints files, with findings of dependencies fed in key of findings and files rewritten by them in place of
+// content, and returns files rewritten by lint and version of tool reporting them.
+func (l *lint) send(ctx context.Context, root string, files []string, v config.Lint, feed []proto.Format,
+	rewritten map[string]string) ([]proto.Format, map[string]string, string, error) {
+	m, err := l.marshal(root, files)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to marshal")
+	}
+
+	if len(v.Depends) != 0 {
+		if m, err = l.feed(m, feed, rewritten); err != nil {
+			return nil, nil, "", errors.Wrap(err, "failed to feed")
+		}
+	}
+
+	if fed(&v) {
+		if m, err = l.parent(m, root, files); err != nil {
+			return nil, nil, "", errors.Wrap(err, "failed to parent")
+		}
+	}
+
+	var r []proto.Format
+	var logs string
+	var output map[string]string
+	version := config.Version
+
+	switch kind(&v) {
+	case lintFake:
+		r, err = l.fake(m)
+	case lintApidiff:
+		r, err = l.apidiff(m)
+	case lintBinary:
+		r, err = l.binary(m)
+	case lintBuf:
+		r, logs, err = l.buf(ctx, &v, m)
+	case lintCheckov:
+		r, logs, err = l.checkov(ctx, &v, m)
+	case lintDco:
+		r, err = l.dco(ctx, changeOf(ctx), &v)
+	case lintDescription:
+		r, err = l.description(ctx, m, &v)
+	case lintMarkdown:
+		r, err = l.markdown(ctx, root, m, &v)
+	case lintMigration:
+		r, err = l.migration(m, &v.Migration)
+	case lintSignature:
+		r, err = l.signature(changeOf(ctx), &v.Signature)
+	case lintSubmodule:
+		r, err = l.submodule(m, changeOf(ctx), v.Submodules)
+	case lintTflint:
+		r, logs, err = l.tflint(ctx, &v, m)
+	case lintUi:
+		r, err = l.ui(m, &v.Ui)
+	default:
+		if c := changeOf(ctx); c != nil {
+			if m, err = l.meta(m, c); err != nil {
+				return nil, nil, "", errors.Wrap(err, "failed to meta")
+			}
+		}
+		if v.Bundle.Path != "" {
+			if m, err = l.bundle(m, &v.Bundle); err != nil {
+				return nil, nil, "", errors.Wrap(err, "failed to bundle")
+			}
+		}
+		if len(v.Command) != 0 {
+			r, output, logs, err = l.exec(ctx, root, &v, m)
+		} else if ctx, err = outgoing(ctx, &v); err == nil {
+			compare := l.shadow(ctx, &v, m)
+			r, output, version, logs, err = l.routine(ctx, v.Host, v.Port, v.Timeout, m)
+			if compare != nil && err == nil {
+				logs = strings.TrimSpace(logs + "\n" + compare(r))
+			}
+		}
+	}
+
+	if err != nil {
+		if name, e := l.snapshot(ctx, &v, m, err); e != nil {
+			log.Printf("lint %s failed to snapshot: %v", v.Name, e)
+		} else if name != "" {
+			log.Printf("lint %s failed and snapshot in %s", v.Name, name)
+			logs = strings.TrimSpace(strings.TrimSpace(logs) + "\nsnapshot: " + name)
+		}
+	}
+
+	logsOf(ctx).put(v.Name, logs)
+
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "failed to routine")
+	}
+
+	r = correct(v.Name, m, r)
+
+	for i := range r {
+		r[i].Lint, r[i].Version = v.Name, version
+	}
+
+	return r, output, version, nil
+}
+
+// feed sets findings of dependencies in key of findings, and content of files rewritten by them, e.g., by formatters,
+// in keys of files which are requested.
+func (l *lint) feed(data []byte, findings []proto.Format, files map[string]string) ([]byte, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if findings == nil {
+		findings = []proto.Format{}
+	}
+
+	b, err := json.Marshal(findings)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	buf[proto.Base64Findings] = base64.StdEncoding.EncodeToString(b)
+
+	for key, val := range files {
+		if _, ok := buf[key+proto.Base64Content]; ok {
+			buf[key+proto.Base64Content] = val
+		}
+	}
+
+	ret, err := json.Marshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
 	return ret, nil
 }
 
 func (l *lint) marshal(root string, data []string) ([]byte, error) {
 	helper := func(name string) (string, error) {
-		fi, err := os.Open(name)
+		buf, err := l.storage.Read(name)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to open")
-		}
-		defer func() { _ = fi.Close() }()
-		buf, err := ioutil.ReadAll(fi)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to readall")
+			return "", errors.Wrap(err, "failed to read")
 		}
 		return string(buf), nil
 	}
@@ -133,7 +454,7 @@ func (l *lint) marshal(root string, data []string) ([]byte, error) {
 			err = errors.New("invalid data")
 			break
 		}
-		buf[val], err = helper(filepath.Join(root, val))
+		buf[val], err = helper(filepath.Join(root, filepath.FromSlash(val)))
 		if err != nil {
 			break
 		}
@@ -155,40 +476,48 @@ func (l *lint) marshal(root string, data []string) ([]byte, error) {
 	return ret, nil
 }
 
-func (l *lint) routine(host string, port, timeout int, data []byte) ([]proto.Format, error) {
-	helper := func(data string) ([]proto.Format, error) {
-		var buf map[string][]proto.Format
-		if err := json.Unmarshal([]byte(data), &buf); err != nil {
-			return nil, errors.Wrap(err, "failed to unmarshal")
-		}
-		var ret []proto.Format
-		for _, val := range buf {
-			ret = append(ret, val...)
-		}
-		return ret, nil
-	}
+// routine sends lint to worker, which reports version of its tool in header metadata, and rewritten files and
+// execution logs in reply.
+func (l *lint) routine(ctx context.Context, host string, port, timeout int, data []byte) (findings []proto.Format,
+	files map[string]string, version, logs string, emsg error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
 
-	conn, err := grpc.Dial(host+":"+strconv.Itoa(port), grpc.WithInsecure(), grpc.WithBlock(),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32), grpc.MaxCallSendMsgSize(math.MaxInt32)))
+	conn, err := dial(ctx, host+":"+strconv.Itoa(port))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to dial")
+		return nil, nil, "", "", errors.Wrap(err, "failed to dial")
 	}
 	defer func() { _ = conn.Close() }()
 
+	return sendLint(ctx, conn, data)
+}
+
+func dial(ctx context.Context, endpoint string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, endpoint, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32), grpc.MaxCallSendMsgSize(math.MaxInt32)))
+}
+
+func sendLint(ctx context.Context, conn *grpc.ClientConn, data []byte) (findings []proto.Format, files map[string]string,
+	version, logs string, emsg error) {
 	client := NewLintProtoClient(conn)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
-	defer cancel()
+	var header metadata.MD
 
-	reply, err := client.SendLint(ctx, &LintRequest{Message: string(data)})
+	reply, err := client.SendLint(ctx, &LintRequest{Message: string(data)}, grpc.Header(&header))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to send")
+		return nil, nil, "", "", errors.Wrap(err, "failed to send")
 	}
 
-	buf, err := helper(reply.GetMessage())
+	logs = parseLogs([]byte(reply.GetMessage()))
+
+	buf, err := parse([]byte(reply.GetMessage()))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get")
+		return nil, nil, "", logs, errors.Wrap(err, "failed to parse")
+	}
+
+	if val := header.Get(versionKey); len(val) != 0 {
+		version = val[0]
 	}
 
-	return buf, nil
+	return buf, parseFiles([]byte(reply.GetMessage())), version, logs, nil
 }
@@ -13,9 +13,23 @@
 package lint
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/progress"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
 )
 
 const (
@@ -24,7 +38,7 @@ const (
 
 func TestMarshal(t *testing.T) {
 	var buf []string
-	var l lint
+	l := lint{storage: storage.New(storage.DefaultConfig())}
 
 	_, err := l.marshal(root, buf)
 	assert.NotEqual(t, nil, err)
@@ -32,4 +46,126 @@ func TestMarshal(t *testing.T) {
 	buf = []string{"foo.base64"}
 	_, err = l.marshal(root, buf)
 	assert.NotEqual(t, nil, err)
+
+	buf = []string{"src/com/android/settings/ActivityPicker.java.base64"}
+	ret, err := l.marshal(root, buf)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, string(ret), `"src/com/android/settings/ActivityPicker.java.base64"`)
+}
+
+func TestRunDepends(t *testing.T) {
+	d, err := ioutil.TempDir("", "lint")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	content := base64.StdEncoding.EncodeToString([]byte("// lintflow:Error text\n"))
+	err = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(content), 0600)
+	assert.Equal(t, nil, err)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+	files := []string{"main.go.base64"}
+
+	helper := func(failure string, depends ...string) Lint {
+		return New(&Config{Lints: []config.Lint{
+			{Name: "lintinvalid", Host: "127.0.0.1", Port: 1, Timeout: 1, Failure: failure},
+			{Name: lintFake, Depends: depends},
+		}})
+	}
+
+	buf, _, err := helper(failureContinue, "lintinvalid").Run(context.Background(), d, "", files, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Lint: lintFake}}, buf)
+
+	var b bytes.Buffer
+
+	p := progress.New(&progress.Config{Mode: progress.ModeText, Writer: &b})
+
+	buf, _, err = helper(failureSkip, "lintinvalid").Run(progress.With(context.Background(), p), d, "", files, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{}, buf)
+	assert.Equal(t, "lint lintinvalid running (0/2 0%)\nlint lintinvalid failed (1/2 50%)\nlint fake skipped (2/2 100%)\n",
+		b.String())
+
+	_, _, err = helper("").Run(context.Background(), d, "", files, match)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "lintinvalid", err.(*Error).Name)
+
+	_, _, err = helper("", "invalid").Run(context.Background(), d, "", files, match)
+	assert.NotEqual(t, nil, err)
+
+	_, _, err = helper("", lintFake).Run(context.Background(), d, "", files, match)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRunRewrite(t *testing.T) {
+	d, err := ioutil.TempDir("", "lint")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(base64.StdEncoding.EncodeToString([]byte("\n"))), 0600)
+	assert.Equal(t, nil, err)
+
+	content := base64.StdEncoding.EncodeToString([]byte("// lintflow:Error formatted\n"))
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{
+		{Name: "lintformat", Command: []string{"sh", "-c", `cat >/dev/null; echo '{"files":{"main.go":"` + content + `"}}'`}},
+		{Name: lintFake, Depends: []string{"lintformat"}},
+	}})
+
+	buf, _, err := l.Run(context.Background(), d, "", []string{"main.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "formatted", Lint: lintFake}}, buf)
+}
+
+func TestFeed(t *testing.T) {
+	var l lint
+
+	buf, err := l.feed([]byte(`{"main.go.base64":""}`), nil, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"findings.base64":"W10=","main.go.base64":""}`, string(buf))
+
+	buf, err = l.feed([]byte(`{"main.go.base64":""}`), nil, map[string]string{"main.go": "Cg==", "util.go": "Cg=="})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"findings.base64":"W10=","main.go.base64":"Cg=="}`, string(buf))
+}
+
+type lintServer struct {
+	UnimplementedLintProtoServer
+}
+
+func (l *lintServer) SendLint(ctx context.Context, _ *LintRequest) (*LintReply, error) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs(versionKey, "1.2.3"))
+	return &LintReply{Message: `{"lint":[{"file":"main.go","line":1,"type":"Error","details":"text"}]}`}, nil
+}
+
+func TestVersions(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	s := grpc.NewServer()
+	RegisterLintProtoServer(s, &lintServer{})
+
+	go func() { _ = s.Serve(ln) }()
+	defer s.Stop()
+
+	d, err := ioutil.TempDir("", "lint")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(base64.StdEncoding.EncodeToString([]byte("text"))), 0600)
+	assert.Equal(t, nil, err)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{{Name: "lintgo", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, Timeout: 3}}})
+
+	buf, versions, err := l.Run(context.Background(), d, "", []string{"main.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Lint: "lintgo",
+		Version: "1.2.3"}}, buf)
+	assert.Equal(t, map[string]string{"lintgo": "1.2.3"}, versions)
 }
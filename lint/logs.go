@@ -0,0 +1,69 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"sync"
+)
+
+// Logs collects execution logs of lints in run, e.g. stderr of tools, for debugging odd findings.
+type Logs struct {
+	data  map[string]string
+	mutex sync.Mutex
+}
+
+type logsKey struct{}
+
+// WithLogs returns ctx collecting execution logs of lints into logs.
+func WithLogs(ctx context.Context, logs *Logs) context.Context {
+	return context.WithValue(ctx, logsKey{}, logs)
+}
+
+func logsOf(ctx context.Context) *Logs {
+	logs, _ := ctx.Value(logsKey{}).(*Logs)
+	return logs
+}
+
+// Map returns execution logs by names of lints, or nil if none.
+func (l *Logs) Map() map[string]string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.data) == 0 {
+		return nil
+	}
+
+	ret := map[string]string{}
+
+	for key, val := range l.data {
+		ret[key] = val
+	}
+
+	return ret
+}
+
+func (l *Logs) put(name, data string) {
+	if l == nil || data == "" {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.data == nil {
+		l.data = map[string]string{}
+	}
+
+	l.data[name] = data
+}
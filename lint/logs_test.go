@@ -0,0 +1,36 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogs(t *testing.T) {
+	var l *Logs
+
+	assert.Equal(t, l, logsOf(context.Background()))
+	l.put("lintcpp", "text")
+
+	l = &Logs{}
+	assert.Equal(t, map[string]string(nil), l.Map())
+
+	ctx := WithLogs(context.Background(), l)
+	logsOf(ctx).put("lintcpp", "text")
+	logsOf(ctx).put("lintjava", "")
+
+	assert.Equal(t, map[string]string{"lintcpp": "text"}, l.Map())
+}
@@ -0,0 +1,281 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	markdownCache = 3600
+)
+
+var (
+	markdownCode      = regexp.MustCompile("`+[^`]*`+")
+	markdownDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	markdownFence     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	markdownHeading   = regexp.MustCompile(`^\s{0,3}(#{1,6})(\s+(.*?))?\s*#*\s*$`)
+	markdownLink      = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(\s+"[^"]*")?\s*\)`)
+	markdownReference = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*(<[^>]*>|\S+)`)
+	markdownScheme    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+	markdownSlug      = regexp.MustCompile(`[^\p{L}\p{N}_ -]`)
+)
+
+// probed is result of probing external link, cached until expired.
+type probed struct {
+	at  time.Time
+	err error
+}
+
+// markdown checks changed Markdown files for broken relative links, malformed tables, heading hierarchy and,
+// if enabled, liveness of external links, instead of calling worker. Relative links are resolved in workspace.
+func (l *lint) markdown(ctx context.Context, root string, data []byte, cfg *config.Lint) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	files := map[string]string{}
+
+	for key, val := range buf {
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if key == file || (path.Ext(file) != ".md" && path.Ext(file) != ".markdown") {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		files[file] = string(dec)
+	}
+
+	var names []string
+
+	for key := range files {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	ret := []proto.Format{}
+
+	for _, name := range names {
+		finding := func(line int, kind, rule, details string) {
+			ret = append(ret, proto.Format{File: name, Line: line, Type: kind, Details: details,
+				Rule: "markdown-" + rule})
+		}
+		lines := prose(strings.Split(strings.ReplaceAll(files[name], "\r\n", "\n"), "\n"))
+		anchors := headings(lines, func(line, level, prev int) {
+			finding(line, proto.TypeInfo, "heading", "Heading level "+strconv.Itoa(level)+" follows level "+
+				strconv.Itoa(prev)+", skipping levels")
+		})
+		tables(lines, func(line int, details string) {
+			finding(line, proto.TypeWarn, "table", details)
+		})
+		for index, val := range lines {
+			for _, link := range links(val) {
+				if markdownScheme.MatchString(link) {
+					if !cfg.Markdown.Links || !(strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")) {
+						continue
+					}
+					if err := l.probed(ctx, link, cfg.Markdown.Cache, cfg.Timeout); err != nil {
+						finding(index+1, proto.TypeInfo, "external", "Link "+link+" is unreachable: "+err.Error())
+					}
+					continue
+				}
+				if details := resolve(root, name, link, anchors); details != "" {
+					finding(index+1, proto.TypeWarn, "link", details)
+				}
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// probed probes external link, and caches result for cache seconds, or markdownCache if not set.
+func (l *lint) probed(ctx context.Context, link string, cache, timeout int) error {
+	if cache <= 0 {
+		cache = markdownCache
+	}
+
+	l.mutex.Lock()
+	p, ok := l.links[link]
+	l.mutex.Unlock()
+
+	if ok && time.Since(p.at) < time.Duration(cache)*time.Second {
+		return p.err
+	}
+
+	err := probe(ctx, link, timeout)
+
+	l.mutex.Lock()
+	if l.links == nil {
+		l.links = map[string]probed{}
+	}
+	l.links[link] = probed{at: time.Now(), err: err}
+	l.mutex.Unlock()
+
+	return err
+}
+
+// prose blanks lines in fenced code blocks and inline code spans, keeping line numbers.
+func prose(lines []string) []string {
+	ret := make([]string, len(lines))
+	fence := ""
+
+	for index, val := range lines {
+		if m := markdownFence.FindStringSubmatch(val); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if fence == m[1] {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		ret[index] = markdownCode.ReplaceAllStringFunc(val, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+	}
+
+	return ret
+}
+
+// headings reports headings skipping levels to skip, and returns anchors of headings in GitHub style.
+func headings(lines []string, skip func(line, level, prev int)) map[string]bool {
+	ret := map[string]bool{}
+	count := map[string]int{}
+	prev := 0
+
+	for index, val := range lines {
+		m := markdownHeading.FindStringSubmatch(val)
+		if m == nil {
+			continue
+		}
+		level := len(m[1])
+		if prev != 0 && level > prev+1 {
+			skip(index+1, level, prev)
+		}
+		prev = level
+		slug := strings.ReplaceAll(markdownSlug.ReplaceAllString(strings.ToLower(strings.TrimSpace(m[3])), ""), " ", "-")
+		if n := count[slug]; n != 0 {
+			ret[slug+"-"+strconv.Itoa(n)] = true
+		} else {
+			ret[slug] = true
+		}
+		count[slug]++
+	}
+
+	return ret
+}
+
+// tables reports tables whose delimiter or body rows differ from header row in count of cells.
+func tables(lines []string, malformed func(line int, details string)) {
+	for index := 1; index < len(lines); index++ {
+		if !markdownDelimiter.MatchString(lines[index]) || !strings.Contains(lines[index], "|") ||
+			!strings.Contains(lines[index-1], "|") {
+			continue
+		}
+		header := cells(lines[index-1])
+		if n := cells(lines[index]); n != header {
+			malformed(index+1, "Table delimiter row has "+strconv.Itoa(n)+" cells, header row has "+
+				strconv.Itoa(header))
+		}
+		for index++; index < len(lines) && strings.Contains(lines[index], "|"); index++ {
+			if n := cells(lines[index]); n != header {
+				malformed(index+1, "Table row has "+strconv.Itoa(n)+" cells, header row has "+strconv.Itoa(header))
+			}
+		}
+	}
+}
+
+// cells counts cells of table row split by unescaped pipes.
+func cells(row string) int {
+	row = strings.TrimSpace(strings.ReplaceAll(row, `\|`, ""))
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+
+	return strings.Count(row, "|") + 1
+}
+
+// links returns targets of inline links, images and reference definitions in line.
+func links(line string) []string {
+	var ret []string
+
+	for _, m := range markdownLink.FindAllStringSubmatch(line, -1) {
+		ret = append(ret, strings.Trim(m[1], "<>"))
+	}
+
+	if m := markdownReference.FindStringSubmatch(line); m != nil {
+		ret = append(ret, strings.Trim(m[1], "<>"))
+	}
+
+	return ret
+}
+
+// resolve checks relative link in file against workspace in root and anchors of file, and returns details if broken.
+func resolve(root, file, link string, anchors map[string]bool) string {
+	target, anchor := link, ""
+
+	if i := strings.Index(link, "#"); i >= 0 {
+		target, anchor = link[:i], link[i+1:]
+	}
+
+	if i := strings.Index(target, "?"); i >= 0 {
+		target = target[:i]
+	}
+
+	if target == "" {
+		if anchor != "" && !anchors[strings.ToLower(anchor)] {
+			return "Anchor #" + anchor + " is not found in headings"
+		}
+		return ""
+	}
+
+	name := path.Join(path.Dir(file), target)
+	if strings.HasPrefix(target, "/") {
+		name = path.Clean(target[1:])
+	}
+
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "Link " + link + " is outside of repository"
+	}
+
+	if root == "" {
+		return ""
+	}
+
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name))); err != nil {
+		return "Link " + link + " is not found"
+	}
+
+	return ""
+}
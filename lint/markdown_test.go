@@ -0,0 +1,112 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestMarkdown(t *testing.T) {
+	count := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	d, err := ioutil.TempDir("", "markdown")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = os.MkdirAll(filepath.Join(d, "docs"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(d, "LICENSE"), []byte("foo\n"), 0600)
+
+	doc := "# Title\n" +
+		"\n" +
+		"### Skipped\n" +
+		"\n" +
+		"[License](../LICENSE) [Missing](missing.md) [Top](#title) [Bad](#nothing) [Up](../../foo)\n" +
+		"\n" +
+		"```\n" +
+		"[Code](missing.md)\n" +
+		"# Not heading\n" +
+		"```\n" +
+		"\n" +
+		"`[Span](missing.md)` [Live](" + s.URL + "/ok) [Dead](" + s.URL + "/gone) [Mail](mailto:foo@example.com)\n" +
+		"\n" +
+		"| Foo | Bar |\n" +
+		"| --- | --- | --- |\n" +
+		"| 1 | 2 |\n" +
+		"| 1 |\n" +
+		"\n" +
+		"[ref]: " + s.URL + "/gone\n"
+
+	m, _ := json.Marshal(map[string]string{
+		"docs/foo.md.base64": base64.StdEncoding.EncodeToString([]byte(doc)),
+		"main.go.base64":     base64.StdEncoding.EncodeToString([]byte("package main\n")),
+	})
+
+	v := config.Lint{
+		Markdown: config.Markdown{Links: true},
+		Name:     lintMarkdown,
+	}
+
+	var l lint
+
+	r, err := l.markdown(context.Background(), d, m, &v)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "docs/foo.md", Line: 3, Type: proto.TypeInfo, Details: "Heading level 3 follows level 1, skipping levels",
+			Rule: "markdown-heading"},
+		{File: "docs/foo.md", Line: 15, Type: proto.TypeWarn, Details: "Table delimiter row has 3 cells, header row has 2",
+			Rule: "markdown-table"},
+		{File: "docs/foo.md", Line: 17, Type: proto.TypeWarn, Details: "Table row has 1 cells, header row has 2",
+			Rule: "markdown-table"},
+		{File: "docs/foo.md", Line: 5, Type: proto.TypeWarn, Details: "Link missing.md is not found",
+			Rule: "markdown-link"},
+		{File: "docs/foo.md", Line: 5, Type: proto.TypeWarn, Details: "Anchor #nothing is not found in headings",
+			Rule: "markdown-link"},
+		{File: "docs/foo.md", Line: 5, Type: proto.TypeWarn, Details: "Link ../../foo is outside of repository",
+			Rule: "markdown-link"},
+		{File: "docs/foo.md", Line: 12, Type: proto.TypeInfo, Details: "Link " + s.URL + "/gone is unreachable: status 404",
+			Rule: "markdown-external"},
+		{File: "docs/foo.md", Line: 19, Type: proto.TypeInfo, Details: "Link " + s.URL + "/gone is unreachable: status 404",
+			Rule: "markdown-external"},
+	}, r)
+
+	// Results of external links are cached
+	assert.Equal(t, 2, count)
+
+	v.Markdown.Links = false
+
+	r, err = l.markdown(context.Background(), d, m, &v)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, len(r))
+}
@@ -0,0 +1,226 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// statement is SQL statement without comments, in which spaces are collapsed, at line of its start.
+type statement struct {
+	line int
+	text string
+}
+
+// hazard is pattern of statement, which is unless pattern of exception matches.
+type hazard struct {
+	details string
+	pattern *regexp.Regexp
+	unless  *regexp.Regexp
+}
+
+var (
+	irreversible = []hazard{
+		{details: "drops data irreversibly", pattern: regexp.MustCompile(`(?i)^DROP (TABLE|SCHEMA|DATABASE)\b`)},
+		{details: "drops column irreversibly", pattern: regexp.MustCompile(`(?i)^ALTER TABLE .* DROP (COLUMN )?\w`),
+			unless: regexp.MustCompile(`(?i) DROP (CONSTRAINT|INDEX|DEFAULT|NOT NULL|PRIMARY|FOREIGN|KEY|CHECK)\b`)},
+		{details: "deletes data irreversibly", pattern: regexp.MustCompile(`(?i)^TRUNCATE\b`)},
+		{details: "deletes all rows irreversibly", pattern: regexp.MustCompile(`(?i)^DELETE FROM `),
+			unless: regexp.MustCompile(`(?i) WHERE `)},
+	}
+
+	locking = []hazard{
+		{details: "locks writes of table while building index, which should be CONCURRENTLY",
+			pattern: regexp.MustCompile(`(?i)^CREATE (UNIQUE )?INDEX `), unless: regexp.MustCompile(`(?i) CONCURRENTLY `)},
+		{details: "rewrites table under exclusive lock by changing type of column",
+			pattern: regexp.MustCompile(`(?i)^ALTER TABLE .* ALTER (COLUMN )?\S+ (SET DATA )?TYPE `)},
+		{details: "scans table under exclusive lock by setting column not null",
+			pattern: regexp.MustCompile(`(?i)^ALTER TABLE .* SET NOT NULL`)},
+		{details: "scans table under exclusive lock by validating constraint, which should be NOT VALID",
+			pattern: regexp.MustCompile(`(?i)^ALTER TABLE .* ADD (CONSTRAINT \S+ )?(FOREIGN KEY|CHECK)\b`),
+			unless:  regexp.MustCompile(`(?i) NOT VALID`)},
+		{details: "locks table explicitly", pattern: regexp.MustCompile(`(?i)^LOCK (TABLE )?\w`)},
+	}
+
+	downPattern = regexp.MustCompile(`^(.*)\.down\.sql$`)
+	upPattern   = regexp.MustCompile(`^(.*)\.up\.sql$`)
+	undoPattern = regexp.MustCompile(`^U([^_]+__.*\.sql)$`)
+	versPattern = regexp.MustCompile(`^V([^_]+__.*\.sql)$`)
+)
+
+// migration checks SQL migrations in files matching paths (globs), or all .sql files if without paths, instead of calling
+// worker. Up migrations are reported for irreversible operations, DDL locking tables for long, and missing down
+// migrations of golang-migrate (.up.sql and .down.sql) or Flyway (V and U prefixes) in change.
+func (l *lint) migration(data []byte, cfg *config.Migration) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	files := map[string]string{}
+
+	for key, val := range buf {
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if key == proto.Base64Change || key == proto.Base64Findings || key == proto.Base64Message ||
+			key == proto.Base64Parent || !migrated(file, cfg.Path) {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		files[file] = string(dec)
+	}
+
+	var names []string
+
+	for key := range files {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	ret := []proto.Format{}
+
+	for _, name := range names {
+		dir, base := path.Split(name)
+		if downPattern.MatchString(base) || undoPattern.MatchString(base) {
+			continue
+		}
+		if down := counterpart(base); down != "" {
+			if _, ok := files[dir+down]; !ok {
+				ret = append(ret, proto.Format{File: name, Type: proto.TypeError, Details: "Down migration " + down +
+					" is missing", Rule: "migration-down"})
+			}
+		}
+		for _, s := range statements(files[name]) {
+			for _, h := range irreversible {
+				if h.match(s.text) {
+					ret = append(ret, proto.Format{File: name, Line: s.line, Type: proto.TypeError, Details: "Migration " +
+						h.details, Rule: "migration-irreversible"})
+				}
+			}
+			for _, h := range locking {
+				if h.match(s.text) {
+					ret = append(ret, proto.Format{File: name, Line: s.line, Type: proto.TypeError, Details: "Migration " +
+						h.details, Rule: "migration-lock"})
+				}
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (h *hazard) match(text string) bool {
+	return h.pattern.MatchString(text) && (h.unless == nil || !h.unless.MatchString(text))
+}
+
+func migrated(file string, paths []string) bool {
+	if len(paths) == 0 {
+		return path.Ext(file) == ".sql"
+	}
+
+	for _, val := range paths {
+		if ok, err := path.Match(val, file); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// counterpart returns name of down migration of up migration, or empty if not versioned.
+func counterpart(name string) string {
+	if m := upPattern.FindStringSubmatch(name); m != nil {
+		return m[1] + ".down.sql"
+	}
+
+	if m := versPattern.FindStringSubmatch(name); m != nil {
+		return "U" + m[1]
+	}
+
+	return ""
+}
+
+// statements splits SQL into statements by semicolons out of quotes, and strips comments.
+func statements(data string) []statement {
+	var ret []statement
+
+	var b strings.Builder
+
+	line, start := 1, 0
+	quote := byte(0)
+
+	flush := func() {
+		if text := strings.Join(strings.Fields(b.String()), " "); text != "" {
+			ret = append(ret, statement{line: start, text: text})
+		}
+		b.Reset()
+		start = 0
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '-' && strings.HasPrefix(data[i:], "--"):
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				line++
+			}
+			b.WriteByte(' ')
+			continue
+		case c == '/' && strings.HasPrefix(data[i:], "/*"):
+			end := strings.Index(data[i+2:], "*/")
+			if end < 0 {
+				end = len(data) - i - 2
+			}
+			line += strings.Count(data[i:i+2+end], "\n")
+			i += end + 3
+			b.WriteByte(' ')
+			continue
+		case c == ';':
+			flush()
+			continue
+		}
+		if c == '\n' {
+			line++
+		} else if start == 0 && c != ' ' && c != '\t' && c != '\r' {
+			start = line
+		}
+		b.WriteByte(c)
+	}
+
+	flush()
+
+	return ret
+}
@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestMigration(t *testing.T) {
+	encode := func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	up := `-- Add users
+CREATE TABLE users (id int, name text);
+CREATE INDEX CONCURRENTLY users_name ON users (name);
+CREATE INDEX users_id ON users (id);
+/* Drop legacy;
+   table */
+DROP TABLE accounts;
+INSERT INTO users VALUES (1, 'a;
+b');
+ALTER TABLE users
+  ALTER COLUMN name TYPE varchar(64);
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users DROP COLUMN name;
+ALTER TABLE orders ADD CONSTRAINT orders_user FOREIGN KEY (user_id) REFERENCES users (id) NOT VALID;
+DELETE FROM sessions WHERE expired;
+DELETE FROM sessions
+`
+
+	m, _ := json.Marshal(map[string]string{
+		"db/1_users.up.sql.base64":   encode(up),
+		"db/1_users.down.sql.base64": encode("DROP TABLE users;\n"),
+		"db/2_orders.up.sql.base64":  encode("SELECT 1;\n"),
+		"db/V3__roles.sql.base64":    encode("TRUNCATE roles;\n"),
+		"db/U3__roles.sql.base64":    encode("DROP TABLE roles;\n"),
+		"docs/schema.sql.base64":     encode("DROP TABLE users;\n"),
+		proto.Base64Message:          encode("DROP TABLE users;\n"),
+	})
+
+	var l lint
+
+	buf, err := l.migration(m, &config.Migration{Path: []string{"db/*.sql"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "db/1_users.up.sql", Line: 4, Type: proto.TypeError, Details: "Migration locks writes of table while building " +
+			"index, which should be CONCURRENTLY", Rule: "migration-lock"},
+		{File: "db/1_users.up.sql", Line: 7, Type: proto.TypeError, Details: "Migration drops data irreversibly",
+			Rule: "migration-irreversible"},
+		{File: "db/1_users.up.sql", Line: 10, Type: proto.TypeError, Details: "Migration rewrites table under exclusive lock " +
+			"by changing type of column", Rule: "migration-lock"},
+		{File: "db/1_users.up.sql", Line: 13, Type: proto.TypeError, Details: "Migration drops column irreversibly",
+			Rule: "migration-irreversible"},
+		{File: "db/1_users.up.sql", Line: 16, Type: proto.TypeError, Details: "Migration deletes all rows irreversibly",
+			Rule: "migration-irreversible"},
+		{File: "db/2_orders.up.sql", Type: proto.TypeError, Details: "Down migration 2_orders.down.sql is missing",
+			Rule: "migration-down"},
+		{File: "db/V3__roles.sql", Line: 1, Type: proto.TypeError, Details: "Migration deletes data irreversibly",
+			Rule: "migration-irreversible"},
+	}, buf)
+
+	buf, err = l.migration(m, &config.Migration{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 8, len(buf))
+	assert.Equal(t, "docs/schema.sql", buf[7].File)
+
+	_, err = l.migration([]byte("invalid"), &config.Migration{})
+	assert.NotEqual(t, nil, err)
+}
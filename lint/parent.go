@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// Parent reports whether any lint needs files in parent revision, which are fetched into parent directory of workspace.
+func (l *lint) Parent() bool {
+	for index := range l.cfg.Lints {
+		if fed(&l.cfg.Lints[index]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fed reports whether lint is fed files in parent revision, which built-in lints comparing revisions are.
+func fed(v *config.Lint) bool {
+	return v.Parent || kind(v) == lintApidiff || kind(v) == lintBuf
+}
+
+// parent adds content of files in parent revision to request in key parent.base64, as base64 encoded JSON of files
+// and their content, in which files added are absent.
+func (l *lint) parent(data []byte, root string, files []string) ([]byte, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	parents := map[string]string{}
+
+	for _, val := range files {
+		if val == proto.Base64Message {
+			continue
+		}
+		b, err := l.storage.Read(filepath.Join(root, proto.ParentDir, filepath.FromSlash(val)))
+		if err != nil {
+			continue
+		}
+		parents[val] = string(b)
+	}
+
+	b, err := json.Marshal(parents)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	buf[proto.Base64Parent] = base64.StdEncoding.EncodeToString(b)
+
+	ret, err := json.Marshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	return ret, nil
+}
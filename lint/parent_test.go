@@ -0,0 +1,56 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestParent(t *testing.T) {
+	d, err := ioutil.TempDir("", "parent")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	encode := func(data string) []byte {
+		return []byte(base64.StdEncoding.EncodeToString([]byte(data)))
+	}
+
+	_ = os.MkdirAll(filepath.Join(d, proto.ParentDir), 0700)
+	_ = ioutil.WriteFile(filepath.Join(d, "api.go.base64"), encode("package api\n"), 0600)
+	_ = ioutil.WriteFile(filepath.Join(d, "new.go.base64"), encode("package api\n"), 0600)
+	_ = ioutil.WriteFile(filepath.Join(d, proto.ParentDir, "api.go.base64"), encode("package api\n\nfunc Old() {}\n"), 0600)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{{Name: lintFake}}})
+	assert.Equal(t, false, l.Parent())
+
+	l = New(&Config{Lints: []config.Lint{{Name: lintApidiff}}})
+	assert.Equal(t, true, l.Parent())
+
+	buf, _, err := l.Run(context.Background(), d, "", []string{"api.go.base64", "new.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "api.go", Type: proto.TypeError, Details: "Exported func Old is removed",
+		Rule: "apidiff-removed", Lint: lintApidiff}}, buf)
+}
@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+)
+
+type lintsKey struct{}
+
+// WithLints returns ctx selecting lints by names, e.g., of profile triggered by command, where lints they depend on
+// are selected as well.
+func WithLints(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, lintsKey{}, names)
+}
+
+func lintsOf(ctx context.Context) []string {
+	names, _ := ctx.Value(lintsKey{}).([]string)
+	return names
+}
+
+// selected returns names with dependencies, or nil for all lints.
+func (l *lint) selected(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+
+	depends := map[string][]string{}
+	for _, val := range l.cfg.Lints {
+		depends[val.Name] = val.Depends
+	}
+
+	ret := map[string]bool{}
+
+	var helper func(string)
+	helper = func(name string) {
+		if ret[name] {
+			return
+		}
+		ret[name] = true
+		for _, val := range depends[name] {
+			helper(val)
+		}
+	}
+
+	for _, val := range names {
+		helper(val)
+	}
+
+	return ret
+}
@@ -0,0 +1,63 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSelected(t *testing.T) {
+	l := &lint{cfg: &Config{Lints: []config.Lint{
+		{Name: "lintcpp"},
+		{Name: "lintsecurity", Depends: []string{"lintsecret"}},
+		{Name: "lintsecret"},
+	}}}
+
+	assert.Equal(t, map[string]bool(nil), l.selected(nil))
+	assert.Equal(t, map[string]bool{"lintsecret": true, "lintsecurity": true}, l.selected([]string{"lintsecurity"}))
+}
+
+func TestRunLints(t *testing.T) {
+	d, err := ioutil.TempDir("", "lint")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	content := base64.StdEncoding.EncodeToString([]byte("// lintflow:Error text\n"))
+	err = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(content), 0600)
+	assert.Equal(t, nil, err)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{
+		{Name: "lintinvalid", Host: "127.0.0.1", Port: 1, Timeout: 1},
+		{Name: lintFake},
+	}})
+
+	_, _, err = l.Run(context.Background(), d, "", []string{"main.go.base64"}, match)
+	assert.NotEqual(t, nil, err)
+
+	buf, _, err := l.Run(WithLints(context.Background(), []string{lintFake}), d, "", []string{"main.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Lint: lintFake}}, buf)
+}
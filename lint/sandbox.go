@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	sandboxFirejail = "firejail"
+	sandboxNsjail   = "nsjail"
+	seccompDefault  = "default"
+)
+
+var (
+	// Files in /etc kept in sandbox of firejail, which are needed to run tools
+	sandboxEtc = []string{"alternatives", "ca-certificates", "group", "ld.so.cache", "ld.so.conf", "ld.so.conf.d",
+		"localtime", "passwd", "ssl"}
+
+	// Paths of tools mounted read-only in sandbox of nsjail
+	sandboxPaths = []string{"/bin", "/etc/alternatives", "/etc/ld.so.cache", "/etc/ld.so.conf", "/etc/ld.so.conf.d",
+		"/lib", "/lib64", "/sbin", "/usr"}
+)
+
+// sandbox wraps command in sandbox of kind, which has no network and read-only workspace unless enabled,
+// and exposes only workspace and paths of tools, lest tools reach credentials of CI on host.
+func sandbox(cfg *config.Sandbox, dir string, command []string) ([]string, error) {
+	if len(command) == 0 {
+		return nil, errors.New("invalid command")
+	}
+
+	switch cfg.Kind {
+	case "":
+		return command, nil
+	case sandboxFirejail:
+		return firejail(cfg, dir, command), nil
+	case sandboxNsjail:
+		return nsjail(cfg, dir, command), nil
+	default:
+		return nil, errors.New("invalid sandbox " + cfg.Kind)
+	}
+}
+
+// firejail runs command in workspace as private home, with private /dev, /etc and /tmp, and mounts whitelisted.
+func firejail(cfg *config.Sandbox, dir string, command []string) []string {
+	args := []string{sandboxFirejail, "--quiet", "--noprofile", "--private-dev", "--private-tmp",
+		"--private-etc=" + strings.Join(sandboxEtc, ",")}
+
+	if dir != "" {
+		args = append(args, "--private="+dir)
+		if !cfg.Writable {
+			args = append(args, "--read-only=${HOME}")
+		}
+	} else {
+		args = append(args, "--private")
+	}
+
+	for _, val := range mounts(cfg.Mount) {
+		args = append(args, "--whitelist="+val, "--read-only="+val)
+	}
+
+	if !cfg.Network {
+		args = append(args, "--net=none")
+	}
+
+	switch cfg.Seccomp {
+	case "":
+	case seccompDefault:
+		args = append(args, "--seccomp")
+	default:
+		args = append(args, "--seccomp="+cfg.Seccomp)
+	}
+
+	return append(append(args, "--"), command...)
+}
+
+// nsjail runs command in empty root, where only workspace, paths of tools, and directory of command are mounted.
+func nsjail(cfg *config.Sandbox, dir string, command []string) []string {
+	args := []string{sandboxNsjail, "--mode", "o", "--quiet", "--keep_env", "--tmpfsmount", "/tmp", "--bindmount",
+		"/dev/null"}
+
+	paths := append(append([]string{}, sandboxPaths...), cfg.Mount...)
+	if p, err := exec.LookPath(command[0]); err == nil {
+		paths = append(paths, filepath.Dir(p))
+	}
+
+	for _, val := range mounts(paths) {
+		args = append(args, "--bindmount_ro", val)
+	}
+
+	if cfg.Network {
+		args = append(args, "--disable_clone_newnet")
+	}
+
+	if dir != "" {
+		if cfg.Writable {
+			args = append(args, "--bindmount", dir)
+		} else {
+			args = append(args, "--bindmount_ro", dir)
+		}
+		args = append(args, "--cwd", dir)
+	}
+
+	if cfg.Seccomp != "" && cfg.Seccomp != seccompDefault {
+		args = append(args, "--seccomp_policy", cfg.Seccomp)
+	}
+
+	return append(append(args, "--"), command...)
+}
+
+// mounts returns existing paths without duplicates, or paths under others.
+func mounts(paths []string) []string {
+	var ret []string
+
+	for _, val := range paths {
+		val = filepath.Clean(val)
+		if _, err := os.Stat(val); err != nil {
+			continue
+		}
+		dup := false
+		for _, item := range ret {
+			if val == item || strings.HasPrefix(val, item+string(filepath.Separator)) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			ret = append(ret, val)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestSandbox(t *testing.T) {
+	d, err := ioutil.TempDir("", "sandbox")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	usr, tool := filepath.Join(d, "usr"), filepath.Join(d, "opt")
+	_ = os.MkdirAll(filepath.Join(usr, "bin"), os.ModePerm)
+	_ = os.MkdirAll(tool, os.ModePerm)
+
+	paths, etc := sandboxPaths, sandboxEtc
+	sandboxPaths, sandboxEtc = []string{usr, filepath.Join(usr, "bin"), filepath.Join(d, "invalid")}, []string{"ssl"}
+
+	defer func() { sandboxPaths, sandboxEtc = paths, etc }()
+
+	command := []string{"golint-invalid", "-"}
+
+	buf, err := sandbox(&config.Sandbox{}, "/work", command)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, command, buf)
+
+	buf, err = sandbox(&config.Sandbox{Kind: sandboxFirejail, Mount: []string{tool}, Seccomp: seccompDefault}, "/work",
+		command)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"firejail", "--quiet", "--noprofile", "--private-dev", "--private-tmp", "--private-etc=ssl",
+		"--private=/work", "--read-only=${HOME}", "--whitelist=" + tool, "--read-only=" + tool, "--net=none", "--seccomp",
+		"--", "golint-invalid", "-"}, buf)
+
+	buf, err = sandbox(&config.Sandbox{Kind: sandboxFirejail, Network: true, Writable: true}, "", command)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"firejail", "--quiet", "--noprofile", "--private-dev", "--private-tmp", "--private-etc=ssl",
+		"--private", "--", "golint-invalid", "-"}, buf)
+
+	buf, err = sandbox(&config.Sandbox{Kind: sandboxNsjail, Mount: []string{tool}, Seccomp: "/etc/lintflow/policy.kafel"},
+		"/work", command)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"nsjail", "--mode", "o", "--quiet", "--keep_env", "--tmpfsmount", "/tmp", "--bindmount",
+		"/dev/null", "--bindmount_ro", usr, "--bindmount_ro", tool, "--bindmount_ro", "/work", "--cwd", "/work",
+		"--seccomp_policy", "/etc/lintflow/policy.kafel", "--", "golint-invalid", "-"}, buf)
+
+	buf, err = sandbox(&config.Sandbox{Kind: sandboxNsjail, Network: true, Writable: true}, "/work", command)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"nsjail", "--mode", "o", "--quiet", "--keep_env", "--tmpfsmount", "/tmp", "--bindmount",
+		"/dev/null", "--bindmount_ro", usr, "--disable_clone_newnet", "--bindmount", "/work", "--cwd", "/work", "--",
+		"golint-invalid", "-"}, buf)
+
+	_, err = sandbox(&config.Sandbox{Kind: "invalid"}, "", command)
+	assert.NotEqual(t, nil, err)
+
+	_, err = sandbox(&config.Sandbox{}, "", nil)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,240 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/codeclimate"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	kindInteger = "integer"
+	kindString  = "string"
+)
+
+const (
+	replyFiles = "files"
+	replyLogs  = "logs"
+	logsSize   = 64 * 1024
+)
+
+type field struct {
+	kind     string
+	nonempty bool
+	required bool
+	values   []string
+}
+
+// schema of finding in Errorformat, in which unknown fields are ignored for compatibility.
+var schema = map[string]field{
+	"category": {kind: kindString},
+	"details":  {kind: kindString, required: true},
+	"file":     {kind: kindString, nonempty: true, required: true},
+	"lint":     {kind: kindString},
+	"line":     {kind: kindInteger, required: true},
+	"rule":     {kind: kindString},
+	"scope":    {kind: kindString, values: []string{proto.ScopeChange, proto.ScopeFile, proto.ScopeLine}},
+	"type":     {kind: kindString, required: true, values: []string{proto.TypeError, proto.TypeInfo, proto.TypeWarn}},
+	"version":  {kind: kindString},
+}
+
+// scopes lists fields which are not required by scope of finding.
+var scopes = map[string][]string{
+	proto.ScopeChange: {"file", "line"},
+	proto.ScopeFile:   {"line"},
+}
+
+// parse validates reply of worker against schema and rejects it as whole if malformed,
+// instead of partially unmarshalling it into findings with zero values. Reply in Code Climate format is accepted as well.
+func parse(data []byte) ([]proto.Format, error) {
+	if codeclimate.Match(data) {
+		ret, err := codeclimate.Unmarshal(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid reply")
+		}
+		return ret, nil
+	}
+
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid reply")
+	}
+
+	buf := map[string][]map[string]json.RawMessage{}
+
+	for key, val := range raw {
+		if key == replyLogs && isString(val) {
+			continue
+		}
+		if key == replyFiles && isObject(val) {
+			continue
+		}
+		var item []map[string]json.RawMessage
+		d := json.NewDecoder(bytes.NewReader(val))
+		d.UseNumber()
+		if err := d.Decode(&item); err != nil {
+			return nil, errors.Wrap(err, "invalid reply")
+		}
+		buf[key] = item
+	}
+
+	var keys []string
+
+	for key := range buf {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var ret []proto.Format
+
+	for _, key := range keys {
+		for index, item := range buf[key] {
+			if err := check(item); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("invalid finding %d of %s", index, key))
+			}
+			var f proto.Format
+			b, _ := json.Marshal(item)
+			if err := json.Unmarshal(b, &f); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("invalid finding %d of %s", index, key))
+			}
+			ret = append(ret, f)
+		}
+	}
+
+	return ret, nil
+}
+
+// parseLogs returns execution logs of worker in reserved key of reply, which are kept in tail within logsSize.
+func parseLogs(data []byte) string {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ""
+	}
+
+	var ret string
+
+	if val, ok := raw[replyLogs]; ok && isString(val) {
+		_ = json.Unmarshal(val, &ret)
+	}
+
+	return tail(ret, logsSize)
+}
+
+// parseFiles returns files rewritten by worker in reserved key of reply, e.g., by formatters, as base64 encoded
+// content by path of file, or nil if malformed.
+func parseFiles(data []byte) map[string]string {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	val, ok := raw[replyFiles]
+	if !ok || !isObject(val) {
+		return nil
+	}
+
+	var ret map[string]string
+
+	if err := json.Unmarshal(val, &ret); err != nil {
+		return nil
+	}
+
+	return ret
+}
+
+func tail(data string, size int) string {
+	if len(data) > size {
+		return data[len(data)-size:]
+	}
+
+	return data
+}
+
+func isString(data json.RawMessage) bool {
+	return len(data) != 0 && data[0] == '"'
+}
+
+func isObject(data json.RawMessage) bool {
+	return len(data) != 0 && data[0] == '{'
+}
+
+func check(item map[string]json.RawMessage) error {
+	var names []string
+
+	for key := range schema {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	var scope string
+
+	if val, ok := item["scope"]; ok {
+		_ = json.Unmarshal(val, &scope)
+	}
+
+	for _, name := range names {
+		f := schema[name]
+		val, ok := item[name]
+		if !ok || string(val) == "null" {
+			if f.required && !contains(scopes[scope], name) {
+				return errors.New("missing " + name)
+			}
+			continue
+		}
+		switch f.kind {
+		case kindInteger:
+			var n json.Number
+			if val[0] == '"' || json.Unmarshal(val, &n) != nil {
+				return errors.New("invalid " + name + ": not integer")
+			}
+			if _, err := n.Int64(); err != nil {
+				return errors.New("invalid " + name + ": not integer")
+			}
+		case kindString:
+			var s string
+			if err := json.Unmarshal(val, &s); err != nil {
+				return errors.New("invalid " + name + ": not string")
+			}
+			if f.nonempty && s == "" {
+				return errors.New("invalid " + name + ": empty")
+			}
+			if len(f.values) != 0 && !contains(f.values, s) {
+				return errors.New("invalid " + name + ": " + s)
+			}
+		}
+	}
+
+	return nil
+}
+
+func contains(data []string, value string) bool {
+	for _, val := range data {
+		if val == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestParse(t *testing.T) {
+	buf, err := parse([]byte(`{"lint":[{"file":"main.go","line":1,"type":"Error","details":"text","rule":"id","extra":true}]}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Rule: "id"}}, buf)
+
+	buf, err = parse([]byte(`{"lint":[]}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	_, err = parse([]byte(`[{"file":"main.go"}]`))
+	assert.NotEqual(t, nil, err)
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","type":"Error","details":"text"}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: missing line", err.Error())
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","line":"1","type":"Error","details":"text"}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: invalid line: not integer", err.Error())
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","line":1.5,"type":"Error","details":"text"}]}`))
+	assert.NotEqual(t, nil, err)
+
+	_, err = parse([]byte(`{"lint":[{"file":"","line":1,"type":"Error","details":"text"}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: invalid file: empty", err.Error())
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","line":1,"type":"Fatal","details":"text"}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: invalid type: Fatal", err.Error())
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","line":1,"type":"Error","details":1}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: invalid details: not string", err.Error())
+}
+
+func TestParseCodeClimate(t *testing.T) {
+	buf, err := parse([]byte(`[{"check_name":"G101","description":"secret","location":{"path":"main.go","lines":{"begin":2}},` +
+		`"severity":"major"}]`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{{Details: "secret", File: "main.go", Line: 2, Rule: "G101", Type: proto.TypeError}}, buf)
+
+	_, err = parse([]byte(`[{"check_name":"G101","location":{"path":"main.go"}}]`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseLogs(t *testing.T) {
+	data := []byte(`{"lint":[{"file":"main.go","line":1,"type":"Error","details":"text"}],"logs":"eslint v8.0.0\n1 file"}`)
+
+	buf, err := parse(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "eslint v8.0.0\n1 file", parseLogs(data))
+
+	assert.Equal(t, "", parseLogs([]byte(`{"lint":[]}`)))
+	assert.Equal(t, "", parseLogs([]byte(`invalid`)))
+	assert.Equal(t, logsSize, len(parseLogs([]byte(`{"logs":"`+strings.Repeat("x", logsSize+1)+`"}`))))
+
+	_, err = parse([]byte(`{"logs":1}`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseFiles(t *testing.T) {
+	data := []byte(`{"lint":[{"file":"main.go","line":1,"type":"Error","details":"text"}],"files":{"main.go":"Cg=="}}`)
+
+	buf, err := parse(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, map[string]string{"main.go": "Cg=="}, parseFiles(data))
+
+	assert.Equal(t, map[string]string(nil), parseFiles([]byte(`{"lint":[]}`)))
+	assert.Equal(t, map[string]string(nil), parseFiles([]byte(`{"files":{"main.go":1}}`)))
+	assert.Equal(t, map[string]string(nil), parseFiles([]byte(`invalid`)))
+
+	_, err = parse([]byte(`{"files":1}`))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseScope(t *testing.T) {
+	buf, err := parse([]byte(`{"lint":[{"file":"main.go","type":"Error","details":"text","scope":"file"},` +
+		`{"type":"Error","details":"missing CODEOWNERS entry","scope":"change"}]}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "main.go", Type: proto.TypeError, Details: "text", Scope: proto.ScopeFile},
+		{Type: proto.TypeError, Details: "missing CODEOWNERS entry", Scope: proto.ScopeChange},
+	}, buf)
+
+	_, err = parse([]byte(`{"lint":[{"type":"Error","details":"text","scope":"file"}]}`))
+	assert.Equal(t, "invalid finding 0 of lint: missing file", err.Error())
+
+	_, err = parse([]byte(`{"lint":[{"file":"main.go","type":"Error","details":"text","scope":"project"}]}`))
+	assert.NotEqual(t, nil, err)
+}
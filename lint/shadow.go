@@ -0,0 +1,110 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	shadowDiffs = 10
+)
+
+type shadowReply struct {
+	err      error
+	findings []proto.Format
+	version  string
+}
+
+// shadow sends request to shadow worker of lint concurrently, and returns function to diff findings of primary with
+// the ones of shadow in logs, or nil if shadow is not set. Findings of shadow are never returned.
+func (l *lint) shadow(ctx context.Context, v *config.Lint, data []byte) func([]proto.Format) string {
+	if v.Shadow.Host == "" {
+		return nil
+	}
+
+	endpoint := v.Shadow.Host + ":" + strconv.Itoa(v.Shadow.Port)
+	ch := make(chan shadowReply, 1)
+
+	go func() {
+		r, _, version, _, err := l.routine(ctx, v.Shadow.Host, v.Shadow.Port, v.Timeout, data)
+		ch <- shadowReply{err: err, findings: r, version: version}
+	}()
+
+	return func(primary []proto.Format) string {
+		reply := <-ch
+		if reply.err != nil {
+			log.Printf("lint %s shadow %s failed: %v", v.Name, endpoint, reply.err)
+			return "shadow " + endpoint + " failed: " + reply.err.Error()
+		}
+		added, removed := diff(primary, reply.findings)
+		msg := fmt.Sprintf("shadow %s version %s: %d added, %d removed", endpoint, reply.version, len(added),
+			len(removed))
+		log.Printf("lint %s %s", v.Name, msg)
+		buf := []string{msg}
+		for _, val := range added {
+			buf = append(buf, "+ "+val)
+		}
+		for _, val := range removed {
+			buf = append(buf, "- "+val)
+		}
+		if len(buf) > shadowDiffs+1 {
+			buf = append(buf[:shadowDiffs+1], "...")
+		}
+		return strings.Join(buf, "\n")
+	}
+}
+
+// diff returns findings added in shadow and removed from primary in order, compared by file, line, type, rule and
+// details.
+func diff(primary, shadow []proto.Format) (added, removed []string) {
+	helper := func(data proto.Format) string {
+		return fmt.Sprintf("%s:%d: %s: %s (%s)", data.File, data.Line, data.Type, data.Details, data.Rule)
+	}
+
+	count := map[string]int{}
+
+	for _, val := range primary {
+		count[helper(val)]++
+	}
+
+	for _, val := range shadow {
+		k := helper(val)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		added = append(added, k)
+	}
+
+	for _, val := range primary {
+		k := helper(val)
+		if count[k] > 0 {
+			count[k]--
+			removed = append(removed, k)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestShadow(t *testing.T) {
+	serve := func(server LintProtoServer) int {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Equal(t, nil, err)
+		s := grpc.NewServer()
+		RegisterLintProtoServer(s, server)
+		go func() { _ = s.Serve(ln) }()
+		t.Cleanup(s.Stop)
+		return ln.Addr().(*net.TCPAddr).Port
+	}
+
+	d, err := ioutil.TempDir("", "shadow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "main.go"), []byte("package main\n"), 0600)
+
+	primary, shadow := serve(&lintServer{}), serve(&conformServer{})
+	match := func(*config.Filter, string, string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{{Name: "lintgo", Host: "127.0.0.1", Port: primary, Timeout: 3,
+		Shadow: config.Shadow{Host: "127.0.0.1", Port: shadow}}}})
+	logs := &Logs{}
+
+	buf, _, err := l.Run(WithLogs(context.Background(), logs), d, "repo", []string{"main.go"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, 1, buf[0].Line)
+	assert.Equal(t, "shadow 127.0.0.1:"+strconv.Itoa(shadow)+" version 1.2.3: 0 added, 1 removed\n"+
+		"- main.go:1: Error: text ()", logs.Map()["lintgo"])
+
+	l = New(&Config{Lints: []config.Lint{{Name: "lintgo", Host: "127.0.0.1", Port: primary, Timeout: 1,
+		Shadow: config.Shadow{Host: "127.0.0.1", Port: 1}}}})
+
+	buf, _, err = l.Run(context.Background(), d, "repo", []string{"main.go"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+}
+
+func TestDiff(t *testing.T) {
+	a := []proto.Format{{File: "a.go", Line: 1}, {File: "a.go", Line: 1}, {File: "b.go", Line: 2}}
+	b := []proto.Format{{File: "a.go", Line: 1}, {File: "c.go", Line: 3}}
+
+	added, removed := diff(a, b)
+	assert.Equal(t, []string{"c.go:3: :  ()"}, added)
+	assert.Equal(t, []string{"a.go:1: :  ()", "b.go:2: :  ()"}, removed)
+
+	added, removed = diff(a, a)
+	assert.Equal(t, 0, len(added)+len(removed))
+}
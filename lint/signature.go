@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	signoffFooter = "Signed-off-by:"
+)
+
+// message keeps commit message only of files, for lints of change instead of content.
+func message(files []string) []string {
+	for _, val := range files {
+		if val == proto.Base64Message {
+			return []string{val}
+		}
+	}
+
+	return nil
+}
+
+// signature verifies push certificate of revision and Signed-off-by of author in commit message, instead of calling
+// worker, and reports violations on change.
+func (l *lint) signature(change *proto.Change, cfg *config.Signature) ([]proto.Format, error) {
+	if change == nil {
+		return nil, errors.New("invalid change")
+	}
+
+	ret := []proto.Format{}
+
+	if cfg.Certificate {
+		if f := certificate(change.Signature); f != nil {
+			ret = append(ret, *f)
+		}
+	}
+
+	if author := committer(change); cfg.Signoff && !signoff(change.Message, author) {
+		ret = append(ret, proto.Format{Type: proto.TypeError, Details: "Commit message lacks Signed-off-by of author " +
+			author, Rule: "signature-signoff", Scope: proto.ScopeChange})
+	}
+
+	return ret, nil
+}
+
+func certificate(data *proto.Signature) *proto.Format {
+	f := proto.Format{Type: proto.TypeError, Rule: "signature-certificate", Scope: proto.ScopeChange}
+
+	switch {
+	case data == nil:
+		f.Details = "Revision is not pushed with signed push certificate"
+	case data.Status == proto.SignatureTrusted:
+		return nil
+	case data.Status == proto.SignatureOk:
+		f.Details, f.Type = "Key of push certificate is valid but not trusted", proto.TypeWarn
+	default:
+		f.Details = "Signature of push certificate is not valid"
+		if len(data.Problems) != 0 {
+			f.Details += ": " + strings.Join(data.Problems, "; ")
+		}
+	}
+
+	return &f
+}
+
+// committer returns author of commit in change, and falls back to author of change if not reported by review.
+func committer(change *proto.Change) string {
+	if change.CommitAuthor != "" {
+		return change.CommitAuthor
+	}
+
+	return change.Author
+}
+
+// signoff reports whether footers of message have Signed-off-by of author by email.
+func signoff(message, author string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(message))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, signoffFooter) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(line), "<"+strings.ToLower(author)+">") {
+			return true
+		}
+	}
+
+	return false
+}
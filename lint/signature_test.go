@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSignature(t *testing.T) {
+	var l lint
+
+	cfg := config.Signature{Certificate: true, Signoff: true}
+
+	_, err := l.signature(nil, &cfg)
+	assert.NotEqual(t, nil, err)
+
+	change := proto.Change{
+		Author:    "dev@example.com",
+		Message:   "Add main\n\nSigned-off-by: Dev <Dev@example.com>\n",
+		Signature: &proto.Signature{Status: proto.SignatureTrusted},
+	}
+
+	buf, err := l.signature(&change, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	change.Message = "Add main\n\nSigned-off-by: Other <other@example.com>\n"
+	change.Signature = &proto.Signature{Problems: []string{"Key is expired"}, Status: proto.SignatureBad}
+
+	buf, err = l.signature(&change, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{Type: proto.TypeError, Details: "Signature of push certificate is not valid: Key is expired",
+			Rule: "signature-certificate", Scope: proto.ScopeChange},
+		{Type: proto.TypeError, Details: "Commit message lacks Signed-off-by of author dev@example.com",
+			Rule: "signature-signoff", Scope: proto.ScopeChange},
+	}, buf)
+
+	change.Signature = &proto.Signature{Status: proto.SignatureOk}
+
+	buf, _ = l.signature(&change, &config.Signature{Certificate: true})
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, proto.TypeWarn, buf[0].Type)
+
+	change.Signature = nil
+
+	buf, _ = l.signature(&change, &config.Signature{Certificate: true})
+	assert.Equal(t, "Revision is not pushed with signed push certificate", buf[0].Details)
+}
+
+func TestRunSignature(t *testing.T) {
+	d, err := ioutil.TempDir("", "signature")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "main.go.base64"), []byte(base64.StdEncoding.EncodeToString([]byte("package main\n"))), 0600)
+	_ = ioutil.WriteFile(filepath.Join(d, proto.Base64Message), []byte(base64.StdEncoding.EncodeToString([]byte("Add main\n"))), 0600)
+
+	match := func(_ *config.Filter, _, _ string) bool { return true }
+
+	l := New(&Config{Lints: []config.Lint{{Name: lintSignature, Signature: config.Signature{Signoff: true}}}})
+	ctx := WithChange(context.Background(), &proto.Change{Author: "dev@example.com", Message: "Add main\n"})
+
+	buf, _, err := l.Run(ctx, d, "", []string{"main.go.base64", proto.Base64Message}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "signature-signoff", buf[0].Rule)
+	assert.Equal(t, lintSignature, buf[0].Lint)
+
+	buf, _, err = l.Run(ctx, d, "", []string{"main.go.base64"}, match)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
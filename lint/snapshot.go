@@ -0,0 +1,158 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	snapshotFiles    = "files"
+	snapshotMetadata = "metadata.json"
+	snapshotRequest  = "request.json"
+	snapshotTime     = "20060102T150405.000000000Z"
+)
+
+// manifest is request metadata of lint in snapshot, in which only keys of env are kept against leaking secrets.
+type manifest struct {
+	Change  *proto.Change `json:"change,omitempty"`
+	Command []string      `json:"command,omitempty"`
+	Env     []string      `json:"env,omitempty"`
+	Error   string        `json:"error"`
+	Host    string        `json:"host,omitempty"`
+	Lint    string        `json:"lint"`
+	Port    int           `json:"port,omitempty"`
+	Time    string        `json:"time"`
+	Timeout int           `json:"timeout,omitempty"`
+}
+
+// snapshot archives request sent to failing lint in tar.gz to storage under path of snapshot, and returns its name,
+// or empty if disabled. Files are kept decoded in files, so that owners of workers could reproduce failure offline.
+func (l *lint) snapshot(ctx context.Context, v *config.Lint, data []byte, emsg error) (string, error) {
+	if l.cfg == nil || l.cfg.Snapshot.Path == "" {
+		return "", nil
+	}
+
+	now := time.Now().UTC()
+	change := changeOf(ctx)
+
+	meta := manifest{
+		Change:  change,
+		Command: v.Command,
+		Env:     sorted(v.Env),
+		Error:   emsg.Error(),
+		Host:    v.Host,
+		Lint:    v.Name,
+		Port:    v.Port,
+		Time:    now.Format(time.RFC3339),
+		Timeout: v.Timeout,
+	}
+
+	m, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal")
+	}
+
+	var files map[string]string
+
+	if err := json.Unmarshal(data, &files); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal")
+	}
+
+	entries := map[string][]byte{snapshotMetadata: m, snapshotRequest: data}
+
+	for key, val := range files {
+		if key == proto.Base64Bundle || key == proto.Base64Change || key == proto.Base64Findings ||
+			key == proto.Base64Message || key == proto.Base64Parent {
+			continue
+		}
+		name := strings.TrimSuffix(key, proto.Base64Content)
+		if name == key {
+			entries[path.Join(snapshotFiles, name)] = []byte(val)
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to decode")
+		}
+		entries[path.Join(snapshotFiles, name)] = dec
+	}
+
+	buf, err := archive(entries, now)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to archive")
+	}
+
+	name := v.Name
+	if change != nil {
+		name += "-" + strconv.Itoa(change.Number)
+	}
+
+	name = path.Join(l.cfg.Snapshot.Path, name+"-"+now.Format(snapshotTime)+".tar.gz")
+
+	if err := l.storage.Write(name, buf); err != nil {
+		return "", errors.Wrap(err, "failed to write")
+	}
+
+	return name, nil
+}
+
+func archive(entries map[string][]byte, now time.Time) ([]byte, error) {
+	var names []string
+
+	for key := range entries {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: filePerm, ModTime: now, Size: int64(len(entries[name]))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, errors.Wrap(err, "failed to write header")
+		}
+		if _, err := tw.Write(entries[name]); err != nil {
+			return nil, errors.Wrap(err, "failed to write")
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close")
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close")
+	}
+
+	return buf.Bytes(), nil
+}
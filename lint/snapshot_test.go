@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestSnapshot(t *testing.T) {
+	d, err := ioutil.TempDir("", "snapshot")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, "main.go"), []byte("package main\n"), 0600)
+
+	l := New(&Config{
+		Lints: []config.Lint{{Name: "lintsh", Command: []string{"sh", "-c", "echo failure >&2; exit 1"},
+			Env: map[string]string{"TOKEN": "secret"}}},
+		Snapshot: config.Snapshot{Path: filepath.Join(d, "snapshots")},
+	})
+
+	logs := &Logs{}
+	match := func(*config.Filter, string, string) bool { return true }
+
+	_, _, err = l.Run(WithLogs(context.Background(), logs), d, "repo", []string{"main.go"}, match)
+	assert.NotEqual(t, nil, err)
+
+	names, _ := filepath.Glob(filepath.Join(d, "snapshots", "lintsh-*.tar.gz"))
+	assert.Equal(t, 1, len(names))
+	assert.Equal(t, "failure\nsnapshot: "+names[0], logs.Map()["lintsh"])
+
+	buf, _ := ioutil.ReadFile(names[0])
+	gr, err := gzip.NewReader(bytes.NewReader(buf))
+	assert.Equal(t, nil, err)
+
+	entries := map[string]string{}
+	tr := tar.NewReader(gr)
+
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(tr)
+		entries[hdr.Name] = string(b)
+	}
+
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "package main\n", entries["files/main.go"])
+	assert.Equal(t, true, strings.Contains(entries[snapshotRequest], "main.go"))
+
+	var m manifest
+
+	assert.Equal(t, nil, json.Unmarshal([]byte(entries[snapshotMetadata]), &m))
+	assert.Equal(t, "lintsh", m.Lint)
+	assert.Equal(t, []string{"TOKEN"}, m.Env)
+	assert.Equal(t, false, strings.Contains(entries[snapshotMetadata], "secret"))
+
+	l = New(&Config{Lints: []config.Lint{{Name: "lintsh", Command: []string{"sh", "-c", "exit 1"}}}})
+
+	_, _, err = l.Run(context.Background(), d, "repo", []string{"main.go"}, match)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,108 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// submodule checks updates of submodules in change are consistent with lockfiles in change, and with changes of
+// the same topic in repositories of submodules, instead of calling worker. Lockfiles are matched by filter of lint.
+func (l *lint) submodule(data []byte, change *proto.Change, cfg []config.Submodule) ([]proto.Format, error) {
+	if change == nil {
+		return nil, errors.New("invalid change")
+	}
+
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	modules := map[string]proto.Submodule{}
+
+	for _, val := range change.Submodules {
+		modules[val.Path] = val
+	}
+
+	related := map[string]proto.Related{}
+
+	for _, val := range change.Related {
+		related[val.Project] = val
+	}
+
+	ret := []proto.Format{}
+
+	for _, item := range cfg {
+		s, ok := modules[item.Path]
+		r, topic := related[item.Project]
+		if !ok || s.New == "" {
+			if topic && item.Project != "" {
+				ret = append(ret, proto.Format{Type: proto.TypeWarn, Details: "Change " + strconv.Itoa(r.Number) + " of " +
+					r.Project + " in topic " + change.Topic + " is not referenced by submodule " + item.Path,
+					Rule: "submodule-topic", Scope: proto.ScopeChange})
+			}
+			continue
+		}
+		if item.Lockfile != "" {
+			if f := lockfile(buf, item.Lockfile, s); f != nil {
+				ret = append(ret, *f)
+			}
+		}
+		if topic && item.Project != "" && r.Commit != s.New {
+			ret = append(ret, proto.Format{Type: proto.TypeError, Details: "Submodule " + s.Path + " is updated to " +
+				s.New + ", but change " + strconv.Itoa(r.Number) + " of " + r.Project + " in topic " + change.Topic + " is at " +
+				r.Commit, Rule: "submodule-topic", Scope: proto.ScopeChange})
+		}
+	}
+
+	return ret, nil
+}
+
+// lockfile checks lockfile in change pins new commit of submodule, and reports the line pinning its old commit if any.
+func lockfile(data map[string]string, name string, s proto.Submodule) *proto.Format {
+	val, ok := data[name+proto.Base64Content]
+	if !ok {
+		return &proto.Format{Type: proto.TypeError, Details: "Submodule " + s.Path + " is updated to " + s.New +
+			", but lockfile " + name + " is not updated", Rule: "submodule-lockfile", Scope: proto.ScopeChange}
+	}
+
+	dec, err := base64.StdEncoding.DecodeString(val)
+	if err != nil || strings.Contains(string(dec), s.New) {
+		return nil
+	}
+
+	ret := &proto.Format{File: name, Type: proto.TypeError, Details: "Lockfile " + name +
+		" does not match commit " + s.New + " of submodule " + s.Path, Rule: "submodule-lockfile"}
+
+	if s.Old == "" {
+		return ret
+	}
+
+	for index, line := range strings.Split(string(dec), "\n") {
+		if strings.Contains(line, s.Old) {
+			ret.Line = index + 1
+			break
+		}
+	}
+
+	return ret
+}
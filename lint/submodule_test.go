@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSubmodule(t *testing.T) {
+	l := lint{}
+
+	cfg := []config.Submodule{
+		{Lockfile: "deps.lock", Path: "lib", Project: "platform/lib"},
+		{Lockfile: "ext.lock", Path: "ext"},
+		{Path: "tools", Project: "platform/tools"},
+	}
+
+	change := &proto.Change{
+		Related: []proto.Related{
+			{Commit: "3333333", Number: 101, Project: "platform/lib"},
+			{Commit: "4444444", Number: 102, Project: "platform/tools"},
+		},
+		Submodules: []proto.Submodule{
+			{New: "2222222", Old: "1111111", Path: "lib"},
+			{New: "5555555", Path: "ext"},
+		},
+		Topic: "bump",
+	}
+
+	data, _ := json.Marshal(map[string]string{
+		proto.Base64Message:               base64.StdEncoding.EncodeToString([]byte("Bump lib\n")),
+		"deps.lock" + proto.Base64Content: base64.StdEncoding.EncodeToString([]byte("foo 0000000\nlib 1111111\n")),
+	})
+
+	_, err := l.submodule(data, nil, cfg)
+	assert.NotEqual(t, nil, err)
+
+	buf, err := l.submodule(data, change, cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, len(buf))
+
+	assert.Equal(t, "deps.lock", buf[0].File)
+	assert.Equal(t, 2, buf[0].Line)
+	assert.Equal(t, "submodule-lockfile", buf[0].Rule)
+
+	assert.Equal(t, "Submodule lib is updated to 2222222, but change 101 of platform/lib in topic bump is at 3333333",
+		buf[1].Details)
+	assert.Equal(t, proto.ScopeChange, buf[1].Scope)
+
+	assert.Equal(t, "Submodule ext is updated to 5555555, but lockfile ext.lock is not updated", buf[2].Details)
+
+	assert.Equal(t, proto.TypeWarn, buf[3].Type)
+	assert.Equal(t, "submodule-topic", buf[3].Rule)
+
+	change.Related[0].Commit = "2222222"
+	data, _ = json.Marshal(map[string]string{
+		"deps.lock" + proto.Base64Content: base64.StdEncoding.EncodeToString([]byte("lib 2222222\n")),
+		"ext.lock" + proto.Base64Content:  base64.StdEncoding.EncodeToString([]byte("ext 5555555\n")),
+	})
+
+	buf, err = l.submodule(data, change, cfg[:2])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
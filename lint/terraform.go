@@ -0,0 +1,214 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	checkovCommand = "checkov"
+	checkovFailed  = 1
+	tflintCommand  = "tflint"
+	tflintConfig   = ".tflint.hcl"
+	tflintFailure  = 3
+	tflintIssues   = 2
+)
+
+var (
+	checkovSeverity = map[string]string{
+		"CRITICAL": proto.TypeError,
+		"HIGH":     proto.TypeError,
+		"LOW":      proto.TypeWarn,
+		"MEDIUM":   proto.TypeWarn,
+	}
+
+	tflintSeverity = map[string]string{
+		"error":   proto.TypeError,
+		"info":    proto.TypeInfo,
+		"notice":  proto.TypeInfo,
+		"warning": proto.TypeWarn,
+	}
+
+	terraformExtensions = []string{".tf", ".tfvars"}
+)
+
+// issues is report of tflint in JSON format.
+type issues struct {
+	Issues []struct {
+		Message string `json:"message"`
+		Range   struct {
+			Filename string `json:"filename"`
+			Start    struct {
+				Line int `json:"line"`
+			} `json:"start"`
+		} `json:"range"`
+		Rule struct {
+			Link     string `json:"link"`
+			Name     string `json:"name"`
+			Severity string `json:"severity"`
+		} `json:"rule"`
+	} `json:"issues"`
+}
+
+// checks is report of checkov in JSON format per framework.
+type checks struct {
+	Results struct {
+		FailedChecks []struct {
+			CheckID       string `json:"check_id"`
+			CheckName     string `json:"check_name"`
+			FilePath      string `json:"file_path"`
+			FileLineRange []int  `json:"file_line_range"`
+			Guideline     string `json:"guideline"`
+			Severity      string `json:"severity"`
+		} `json:"failed_checks"`
+	} `json:"results"`
+}
+
+// tflint runs tflint on modules of Terraform files instead of calling worker. Files of lint, e.g., .tflint.hcl,
+// are placed in root of modules, and command is the path of tflint if set.
+func (l *lint) tflint(ctx context.Context, v *config.Lint, data []byte) ([]proto.Format, string, error) {
+	dir, count, err := terraform(v, data)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to terraform")
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if count == 0 {
+		return []proto.Format{}, "", nil
+	}
+
+	ctx, cancel := deadline(ctx, v)
+	defer cancel()
+
+	args := []string{"--recursive", "--format", "json"}
+	if _, err := os.Stat(filepath.Join(dir, tflintConfig)); err == nil {
+		args = append(args, "--config", filepath.Join(dir, tflintConfig))
+	}
+
+	stdout, stderr, err := tool(ctx, v, tflintCommand, dir, []int{tflintIssues, tflintFailure}, args...)
+	if err != nil {
+		return nil, stderr, errors.Wrap(err, "failed to run")
+	}
+
+	var r issues
+
+	if err := json.Unmarshal(stdout, &r); err != nil {
+		return nil, stderr, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	ret := []proto.Format{}
+
+	for _, val := range r.Issues {
+		kind, ok := tflintSeverity[strings.ToLower(val.Rule.Severity)]
+		if !ok {
+			kind = proto.TypeWarn
+		}
+		ret = append(ret, proto.Format{File: filepath.ToSlash(filepath.Clean(val.Range.Filename)),
+			Line: val.Range.Start.Line, Type: kind, Details: val.Message, Rule: val.Rule.Name, Docs: val.Rule.Link})
+	}
+
+	return ret, tail(stderr, logsSize), nil
+}
+
+// checkov runs checkov policies on Terraform files instead of calling worker. Files of lint, e.g., .checkov.yaml,
+// are placed in root of modules, and command is the path of checkov if set.
+func (l *lint) checkov(ctx context.Context, v *config.Lint, data []byte) ([]proto.Format, string, error) {
+	dir, count, err := terraform(v, data)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to terraform")
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if count == 0 {
+		return []proto.Format{}, "", nil
+	}
+
+	ctx, cancel := deadline(ctx, v)
+	defer cancel()
+
+	stdout, stderr, err := tool(ctx, v, checkovCommand, dir, []int{checkovFailed}, "--directory", ".",
+		"--framework", "terraform", "--output", "json", "--quiet", "--compact")
+	if err != nil {
+		return nil, stderr, errors.Wrap(err, "failed to run")
+	}
+
+	// Report is list of frameworks if more than one is checked
+	var r []checks
+
+	if err := json.Unmarshal(stdout, &r); err != nil {
+		var c checks
+		if err := json.Unmarshal(stdout, &c); err != nil {
+			return nil, stderr, errors.Wrap(err, "failed to unmarshal")
+		}
+		r = []checks{c}
+	}
+
+	ret := []proto.Format{}
+
+	for _, c := range r {
+		for _, val := range c.Results.FailedChecks {
+			kind, ok := checkovSeverity[strings.ToUpper(val.Severity)]
+			if !ok {
+				kind = proto.TypeError
+			}
+			line := 0
+			if len(val.FileLineRange) != 0 {
+				line = val.FileLineRange[0]
+			}
+			ret = append(ret, proto.Format{File: strings.TrimPrefix(filepath.ToSlash(val.FilePath), "/"), Line: line,
+				Type: kind, Details: val.CheckName, Rule: val.CheckID, Docs: val.Guideline})
+		}
+	}
+
+	return ret, tail(stderr, logsSize), nil
+}
+
+// terraform writes Terraform files and files of lint into temporary dir, and returns count of Terraform files.
+func terraform(v *config.Lint, data []byte) (string, int, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return "", 0, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	dir, err := ioutil.TempDir("", "lintflow-terraform")
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to mkdir")
+	}
+
+	count, err := unpack(dir, buf, terraformExtensions...)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", 0, errors.Wrap(err, "failed to write")
+	}
+
+	if err := place(v, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", 0, errors.Wrap(err, "failed to place")
+	}
+
+	return dir, count, nil
+}
@@ -0,0 +1,120 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestTflint(t *testing.T) {
+	d, err := ioutil.TempDir("", "tflint")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	_ = ioutil.WriteFile(filepath.Join(d, tflintConfig), []byte("config {}\n"), 0600)
+
+	m, _ := json.Marshal(map[string]string{
+		"infra/main.tf.base64": base64.StdEncoding.EncodeToString([]byte("variable \"foo\" {}\n")),
+		"README.md.base64":     base64.StdEncoding.EncodeToString([]byte("foo\n")),
+	})
+
+	// Fake tflint checks layout of modules, and reports issue
+	script := `test -f infra/main.tf -a ! -f README.md -a "$2" = json -a -f "$4" || exit 1
+echo '{"issues":[{"rule":{"name":"terraform_unused_declarations","severity":"warning","link":"https://example.com"},` +
+		`"message":"variable \"foo\" is declared but not used","range":{"filename":"infra/main.tf","start":{"line":1}}}],` +
+		`"errors":[]}'
+echo done >&2
+exit 2`
+
+	v := config.Lint{
+		Command: []string{"sh", "-c", script},
+		Files:   map[string]string{tflintConfig: filepath.Join(d, tflintConfig)},
+		Name:    lintTflint,
+	}
+
+	var l lint
+
+	r, logs, err := l.tflint(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "infra/main.tf", Line: 1, Type: proto.TypeWarn, Details: "variable \"foo\" is declared but not used",
+			Rule: "terraform_unused_declarations", Docs: "https://example.com"},
+	}, r)
+	assert.Equal(t, "done", logs)
+
+	v.Command = []string{"sh", "-c", "echo failure >&2; exit 1"}
+
+	_, logs, err = l.tflint(context.Background(), &v, m)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "failure", logs)
+
+	m, _ = json.Marshal(map[string]string{"README.md.base64": base64.StdEncoding.EncodeToString([]byte("foo\n"))})
+
+	r, _, err = l.tflint(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(r))
+}
+
+func TestCheckov(t *testing.T) {
+	m, _ := json.Marshal(map[string]string{
+		"main.tf.base64": base64.StdEncoding.EncodeToString([]byte("resource \"aws_s3_bucket\" \"foo\" {}\n")),
+	})
+
+	// Fake checkov reports failed checks of frameworks
+	script := `test -f main.tf || exit 1
+echo '[{"check_type":"terraform","results":{"failed_checks":[{"check_id":"CKV_AWS_18",` +
+		`"check_name":"Ensure the S3 bucket has access logging enabled","file_path":"/main.tf","file_line_range":[1,1],` +
+		`"severity":null,"guideline":"https://example.com"},{"check_id":"CKV_AWS_21",` +
+		`"check_name":"Ensure all data stored in the S3 bucket have versioning enabled","file_path":"/main.tf",` +
+		`"file_line_range":[1,1],"severity":"LOW","guideline":""}]}}]'
+exit 1`
+
+	v := config.Lint{
+		Command: []string{"sh", "-c", script},
+		Name:    lintCheckov,
+	}
+
+	var l lint
+
+	r, _, err := l.checkov(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "main.tf", Line: 1, Type: proto.TypeError, Details: "Ensure the S3 bucket has access logging enabled",
+			Rule: "CKV_AWS_18", Docs: "https://example.com"},
+		{File: "main.tf", Line: 1, Type: proto.TypeWarn,
+			Details: "Ensure all data stored in the S3 bucket have versioning enabled", Rule: "CKV_AWS_21"},
+	}, r)
+
+	v.Command = []string{"sh", "-c", `echo '{"passed":0,"failed":0}'`}
+
+	r, _, err = l.checkov(context.Background(), &v, m)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(r))
+
+	v.Command = []string{"sh", "-c", "exit 2"}
+
+	_, _, err = l.checkov(context.Background(), &v, m)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,122 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// deadline limits context of built-in lint driving tools to timeout of lint.
+func deadline(ctx context.Context, v *config.Lint) (context.Context, context.CancelFunc) {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = execTimeout
+	}
+
+	return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+}
+
+// tool runs name, or command of lint if set, with args in dir, in which exit codes of findings found are not failures.
+func tool(ctx context.Context, v *config.Lint, name, dir string, codes []int, args ...string) ([]byte, string, error) {
+	command := append([]string{name}, args...)
+	if len(v.Command) != 0 {
+		command = append(append([]string{}, v.Command...), args...)
+	}
+
+	command, err := sandbox(&v.Sandbox, dir, command)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to sandbox")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...) // nolint:gosec
+	cmd.Dir = dir
+	cmd.Env = environ(v)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var e *exec.ExitError
+		if !errors.As(err, &e) || !found(e.ExitCode(), codes) {
+			return nil, strings.TrimSpace(stderr.String()), errors.Wrap(err, "failed to run: "+tail(stderr.String(),
+				stderrSize))
+		}
+	}
+
+	return stdout.Bytes(), strings.TrimSpace(stderr.String()), nil
+}
+
+func found(code int, codes []int) bool {
+	for _, val := range codes {
+		if val == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unpack writes files in base64 with extensions into dir, and returns count of them.
+func unpack(dir string, files map[string]string, exts ...string) (int, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return 0, errors.Wrap(err, "failed to mkdir")
+	}
+
+	count := 0
+
+	for key, val := range files {
+		name := strings.TrimSuffix(key, proto.Base64Content)
+		if key == name || !extended(name, exts) {
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to decode")
+		}
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			return 0, errors.Wrap(err, "failed to mkdir")
+		}
+		if err := ioutil.WriteFile(name, dec, filePerm); err != nil {
+			return 0, errors.Wrap(err, "failed to write")
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func extended(name string, exts []string) bool {
+	for _, val := range exts {
+		if path.Ext(name) == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+var (
+	uiAttributes = []string{"alt", "aria-label", "label", "placeholder", "title"}
+	uiExtensions = []string{".htm", ".html", ".jsx", ".svelte", ".tsx", ".vue"}
+	uiImages     = []string{"img"}
+	uiSkip       = []string{"code", "kbd", "pre", "script", "style"}
+	uiWrappers   = []string{`^<FormattedMessage\b`, `^<Trans\b`, `\si18n\b`, `\sv-t\b`}
+)
+
+var (
+	uiAlt     = regexp.MustCompile(`\balt\s*=|\{\s*\.\.\.`)
+	uiComment = regexp.MustCompile(`<!--.*?-->`)
+	uiEntity  = regexp.MustCompile(`&[#\w]+;`)
+	uiLetters = regexp.MustCompile(`\p{L}{2,}`)
+	uiTag     = `<(%s)\b((?:[^<>"']|"[^"]*"|'[^']*')*)>`
+	uiText    = regexp.MustCompile(`<([A-Za-z][\w.:-]*)(?:[^<>"'{}]|"[^"]*"|'[^']*')*>([^<>{}]*)<`)
+)
+
+// ui checks changed frontend files for user-facing strings hardcoded without i18n wrappers, and images without
+// alt text, instead of calling worker. Strings are text of elements and values of attributes on a line.
+func (l *lint) ui(data []byte, cfg *config.Ui) ([]proto.Format, error) {
+	var buf map[string]string
+
+	if err := json.Unmarshal(data, &buf); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	wrappers, err := compile(cfg.Wrappers, uiWrappers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile")
+	}
+
+	ignore, err := compile(cfg.Ignore, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile")
+	}
+
+	attributes := cfg.Attributes
+	if len(attributes) == 0 {
+		attributes = uiAttributes
+	}
+
+	images := cfg.Images
+	if len(images) == 0 {
+		images = uiImages
+	}
+
+	attribute := regexp.MustCompile(`(?:^|\s)(` + alternate(attributes) + `)=("([^"]*)"|'([^']*)')`)
+	image := regexp.MustCompile(strings.Replace(uiTag, "%s", alternate(images), 1))
+
+	var names []string
+
+	for key := range buf {
+		file := strings.TrimSuffix(key, proto.Base64Content)
+		if key != file && extended(file, uiExtensions) {
+			names = append(names, file)
+		}
+	}
+
+	sort.Strings(names)
+
+	ret := []proto.Format{}
+
+	for _, name := range names {
+		dec, err := base64.StdEncoding.DecodeString(buf[name+proto.Base64Content])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		hardcoded := func(line int, details, text string) {
+			text = strings.TrimSpace(uiEntity.ReplaceAllString(text, " "))
+			if !uiLetters.MatchString(text) || matched(text, ignore) {
+				return
+			}
+			ret = append(ret, proto.Format{File: name, Line: line, Type: proto.TypeWarn,
+				Details: details + " \"" + text + "\" is hardcoded without i18n wrapper", Rule: "ui-i18n"})
+		}
+		content := string(dec)
+		for index, val := range strings.Split(content, "\n") {
+			if trimmed := strings.TrimSpace(val); strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "/*") ||
+				strings.HasPrefix(trimmed, "*") {
+				continue
+			}
+			val = uiComment.ReplaceAllString(val, "")
+			for _, m := range uiText.FindAllStringSubmatch(val, -1) {
+				tag := strings.TrimSuffix(m[0], m[2]+"<")
+				if contains(uiSkip, strings.ToLower(m[1])) || matched(tag, wrappers) {
+					continue
+				}
+				hardcoded(index+1, "Text", m[2])
+			}
+			for _, m := range attribute.FindAllStringSubmatch(val, -1) {
+				hardcoded(index+1, "Attribute "+m[1], m[3]+m[4])
+			}
+		}
+		for _, m := range image.FindAllStringSubmatchIndex(content, -1) {
+			attrs := content[m[4]:m[5]]
+			if uiAlt.MatchString(attrs) {
+				continue
+			}
+			ret = append(ret, proto.Format{File: name, Line: strings.Count(content[:m[0]], "\n") + 1,
+				Type: proto.TypeError, Details: "Image " + content[m[2]:m[3]] + " is missing alt text", Rule: "ui-alt"})
+		}
+	}
+
+	return ret, nil
+}
+
+func compile(patterns, defaults []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		patterns = defaults
+	}
+
+	var ret []*regexp.Regexp
+
+	for _, val := range patterns {
+		r, err := regexp.Compile(val)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compile "+val)
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nil
+}
+
+func matched(data string, patterns []*regexp.Regexp) bool {
+	for _, val := range patterns {
+		if val.MatchString(data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func alternate(data []string) string {
+	var ret []string
+
+	for _, val := range data {
+		ret = append(ret, regexp.QuoteMeta(val))
+	}
+
+	return strings.Join(ret, "|")
+}
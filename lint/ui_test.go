@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestUi(t *testing.T) {
+	page := `export const Page = () => (
+  <div title="Greeting" className="page">
+    <h1>Welcome home</h1>
+    <p>{t("welcome")}</p>
+    <Trans>Hello world</Trans>
+    <code>npm install</code>
+    <span>&copy; 42</span>
+    <input placeholder={t("name")} />
+    <!-- <p>Commented out</p> -->
+    <b>TODO</b>
+    <img src="logo.png" alt="" />
+    <img
+      src="banner.png"
+    />
+    <img {...props} />
+  </div>
+)
+`
+
+	m, _ := json.Marshal(map[string]string{
+		"src/page.tsx.base64": base64.StdEncoding.EncodeToString([]byte(page)),
+		"src/page.go.base64":  base64.StdEncoding.EncodeToString([]byte("<p>Hello</p>\n")),
+	})
+
+	cfg := config.Ui{
+		Ignore: []string{"^[A-Z]+$"},
+	}
+
+	var l lint
+
+	r, err := l.ui(m, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{
+		{File: "src/page.tsx", Line: 2, Type: proto.TypeWarn,
+			Details: "Attribute title \"Greeting\" is hardcoded without i18n wrapper", Rule: "ui-i18n"},
+		{File: "src/page.tsx", Line: 3, Type: proto.TypeWarn,
+			Details: "Text \"Welcome home\" is hardcoded without i18n wrapper", Rule: "ui-i18n"},
+		{File: "src/page.tsx", Line: 12, Type: proto.TypeError, Details: "Image img is missing alt text",
+			Rule: "ui-alt"},
+	}, r)
+
+	cfg.Wrappers = []string{`^<h1\b`}
+
+	r, err = l.ui(m, &cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(r))
+	assert.Equal(t, "Text \"Hello world\" is hardcoded without i18n wrapper", r[1].Details)
+
+	cfg.Ignore = []string{"("}
+
+	_, err = l.ui(m, &cfg)
+	assert.NotEqual(t, nil, err)
+}
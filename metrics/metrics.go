@@ -0,0 +1,244 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/proto"
+)
+
+type Metrics interface {
+	Refresh() error
+	Write(io.Writer) error
+}
+
+type Config struct {
+	History history.History
+}
+
+type label struct {
+	project string
+	rule    string
+}
+
+// snapshot holds gauges precomputed from history, so that scrapes are cheap.
+type snapshot struct {
+	distinct       map[string]int
+	falsePositives map[string]int
+	findings       map[string]int
+	languages      map[string]int
+	oldest         map[label]time.Time
+	open           map[label]int
+	refreshed      time.Time
+	runs           map[string]int
+}
+
+type metrics struct {
+	cfg   *Config
+	data  snapshot
+	mutex sync.RWMutex
+}
+
+func New(cfg *Config) Metrics {
+	return &metrics{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+func (m *metrics) Refresh() error {
+	if m.cfg.History == nil {
+		return errors.New("invalid history")
+	}
+
+	runs, err := m.cfg.History.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list")
+	}
+
+	feedback, err := m.cfg.History.Feedback()
+	if err != nil {
+		return errors.Wrap(err, "failed to feedback")
+	}
+
+	data := snapshot{
+		distinct:       map[string]int{},
+		falsePositives: map[string]int{},
+		findings:       map[string]int{},
+		languages:      map[string]int{},
+		oldest:         map[label]time.Time{},
+		open:           map[label]int{},
+		refreshed:      time.Now(),
+		runs:           map[string]int{},
+	}
+
+	first := map[string]time.Time{}
+	latest := map[string]proto.Run{}
+
+	for _, run := range runs {
+		data.runs[run.Status]++
+		for key, val := range run.Languages {
+			data.languages[key] += val
+		}
+		for _, val := range run.Findings {
+			data.findings[val.Type]++
+			k := key(run.Repo, val)
+			t, ok := first[k]
+			if !ok {
+				data.distinct[val.Rule]++
+			}
+			if !ok || run.Start.Before(t) {
+				first[k] = run.Start
+			}
+		}
+		if r, ok := latest[run.Commit]; !ok || run.Start.After(r.Start) {
+			latest[run.Commit] = run
+		}
+	}
+
+	// Findings in the latest run of commit are open
+	for _, run := range latest {
+		for _, val := range run.Findings {
+			l := label{project: run.Repo, rule: val.Rule}
+			data.open[l]++
+			if t, ok := data.oldest[l]; !ok || first[key(run.Repo, val)].Before(t) {
+				data.oldest[l] = first[key(run.Repo, val)]
+			}
+		}
+	}
+
+	for _, val := range feedback {
+		data.falsePositives[val.Rule]++
+	}
+
+	m.mutex.Lock()
+	m.data = data
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// Write writes metrics in Prometheus text exposition format.
+func (m *metrics) Write(w io.Writer) error {
+	m.mutex.RLock()
+	data := m.data
+	m.mutex.RUnlock()
+
+	var buf []string
+
+	buf = append(buf, "# HELP lintflow_runs_total Runs recorded in history.", "# TYPE lintflow_runs_total counter")
+	for _, k := range sortKeys(data.runs) {
+		buf = append(buf, fmt.Sprintf(`lintflow_runs_total{status="%s"} %d`, escape(k), data.runs[k]))
+	}
+
+	buf = append(buf, "# HELP lintflow_findings_total Findings recorded in history.", "# TYPE lintflow_findings_total counter")
+	for _, k := range sortKeys(data.findings) {
+		buf = append(buf, fmt.Sprintf(`lintflow_findings_total{type="%s"} %d`, escape(k), data.findings[k]))
+	}
+
+	buf = append(buf, "# HELP lintflow_changed_lines_total Changed lines per language recorded in history.",
+		"# TYPE lintflow_changed_lines_total counter")
+	for _, k := range sortKeys(data.languages) {
+		buf = append(buf, fmt.Sprintf(`lintflow_changed_lines_total{language="%s"} %d`, escape(k), data.languages[k]))
+	}
+
+	labels := sortLabels(data.open)
+
+	buf = append(buf, "# HELP lintflow_findings_open Open findings per project and rule.", "# TYPE lintflow_findings_open gauge")
+	for _, l := range labels {
+		buf = append(buf, fmt.Sprintf(`lintflow_findings_open{project="%s",rule="%s"} %d`, escape(l.project), escape(l.rule), data.open[l]))
+	}
+
+	buf = append(buf, "# HELP lintflow_findings_oldest_age_seconds Age of oldest open finding per project and rule.",
+		"# TYPE lintflow_findings_oldest_age_seconds gauge")
+	for _, l := range labels {
+		buf = append(buf, fmt.Sprintf(`lintflow_findings_oldest_age_seconds{project="%s",rule="%s"} %.0f`,
+			escape(l.project), escape(l.rule), data.refreshed.Sub(data.oldest[l]).Seconds()))
+	}
+
+	buf = append(buf, "# HELP lintflow_false_positives_total Findings replied to as false positive per rule.",
+		"# TYPE lintflow_false_positives_total counter")
+	for _, k := range sortKeys(data.falsePositives) {
+		buf = append(buf, fmt.Sprintf(`lintflow_false_positives_total{rule="%s"} %d`, escape(k), data.falsePositives[k]))
+	}
+
+	buf = append(buf, "# HELP lintflow_false_positive_ratio Ratio of false positives to distinct findings per rule.",
+		"# TYPE lintflow_false_positive_ratio gauge")
+	for _, k := range sortKeys(data.falsePositives) {
+		if data.distinct[k] > 0 {
+			buf = append(buf, fmt.Sprintf(`lintflow_false_positive_ratio{rule="%s"} %.3f`, escape(k),
+				float64(data.falsePositives[k])/float64(data.distinct[k])))
+		}
+	}
+
+	if !data.refreshed.IsZero() {
+		buf = append(buf, "# HELP lintflow_metrics_refresh_timestamp_seconds Time of last refresh from history.",
+			"# TYPE lintflow_metrics_refresh_timestamp_seconds gauge",
+			fmt.Sprintf("lintflow_metrics_refresh_timestamp_seconds %d", data.refreshed.Unix()))
+	}
+
+	if _, err := io.WriteString(w, strings.Join(buf, "\n")+"\n"); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+func key(repo string, data proto.Format) string {
+	return strings.Join([]string{repo, data.File, data.Rule, data.Type, data.Details}, "\x00")
+}
+
+func escape(data string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(data)
+}
+
+func sortKeys(data map[string]int) []string {
+	var buf []string
+
+	for k := range data {
+		buf = append(buf, k)
+	}
+
+	sort.Strings(buf)
+
+	return buf
+}
+
+func sortLabels(data map[label]int) []label {
+	var buf []label
+
+	for k := range data {
+		buf = append(buf, k)
+	}
+
+	sort.Slice(buf, func(i, j int) bool {
+		if buf[i].project != buf[j].project {
+			return buf[i].project < buf[j].project
+		}
+		return buf[i].rule < buf[j].rule
+	})
+
+	return buf
+}
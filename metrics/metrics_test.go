@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+type historyTest struct {
+	feedback []proto.Feedback
+	runs     []proto.Run
+}
+
+func (h *historyTest) Feedback() ([]proto.Feedback, error) {
+	return h.feedback, nil
+}
+
+func (h *historyTest) List() ([]proto.Run, error) {
+	return h.runs, nil
+}
+
+func (h *historyTest) Put(run proto.Run) error {
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+func (h *historyTest) PutFeedback(feedback proto.Feedback) error {
+	h.feedback = append(h.feedback, feedback)
+	return nil
+}
+
+func TestMetrics(t *testing.T) {
+	now := time.Now()
+	finding := proto.Format{File: "name", Line: 1, Type: proto.TypeError, Details: "text", Rule: "errcheck"}
+
+	h := &historyTest{}
+	_ = h.Put(proto.Run{ID: "1", Commit: "a", Repo: "foo", Start: now.Add(-2 * time.Hour), Status: proto.StatusSuccess,
+		Findings: []proto.Format{finding}, Languages: map[string]int{"Go": 10}})
+	_ = h.Put(proto.Run{ID: "2", Commit: "b", Repo: "foo", Start: now.Add(-time.Hour), Status: proto.StatusSuccess,
+		Findings:  []proto.Format{finding, {File: "name", Line: 2, Type: proto.TypeWarn, Details: "text"}},
+		Languages: map[string]int{"Go": 5, "Markdown": 1}})
+	_ = h.Put(proto.Run{ID: "3", Commit: "a", Repo: "foo", Start: now, Status: proto.StatusFailed})
+	_ = h.PutFeedback(proto.Feedback{ID: "1", Commit: "b", File: "name", Line: 1, Rule: "errcheck"})
+
+	m := New(DefaultConfig())
+	assert.NotEqual(t, nil, m.Refresh())
+
+	cfg := DefaultConfig()
+	cfg.History = h
+
+	m = New(cfg)
+	assert.Equal(t, nil, m.Refresh())
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, m.Write(&buf))
+
+	ret := buf.String()
+	assert.Contains(t, ret, `lintflow_runs_total{status="failed"} 1`)
+	assert.Contains(t, ret, `lintflow_runs_total{status="success"} 2`)
+	assert.Contains(t, ret, `lintflow_findings_total{type="Error"} 2`)
+	assert.Contains(t, ret, `lintflow_changed_lines_total{language="Go"} 15`)
+	assert.Contains(t, ret, `lintflow_findings_open{project="foo",rule="errcheck"} 1`)
+	assert.Contains(t, ret, `lintflow_findings_open{project="foo",rule=""} 1`)
+	assert.Contains(t, ret, `lintflow_findings_oldest_age_seconds{project="foo",rule="errcheck"} 7200`)
+	assert.Contains(t, ret, `lintflow_false_positives_total{rule="errcheck"} 1`)
+	assert.Contains(t, ret, `lintflow_false_positive_ratio{rule="errcheck"} 1.000`)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\n`, escape("a\"b\\c\n"))
+}
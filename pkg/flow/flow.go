@@ -0,0 +1,164 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flow exposes the fetch, lint and vote pipeline of lintflow as a library.
+package flow
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	core "github.com/craftslab/lintflow/flow"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/review"
+	"github.com/craftslab/lintflow/storage"
+)
+
+type Finding = proto.Format
+
+type Hook = core.Hook
+
+type HookData = core.HookData
+
+type Lint = lint.Lint
+
+type Policy = policy.Policy
+
+type Review = review.Review
+
+type Flow interface {
+	Run(commit string) ([]Finding, error)
+	RunContext(ctx context.Context, commit string) ([]Finding, error)
+}
+
+type Option func(*options)
+
+type options struct {
+	client *http.Client
+	config *config.Config
+	hooks  []Hook
+	lint   Lint
+	name   string
+	policy Policy
+	review Review
+	root   string
+}
+
+// WithClient sets HTTP client of review, instead of the one built from config.
+func WithClient(c *http.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithCodeReview sets name of review in config, e.g. gerrit.
+func WithCodeReview(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithConfig sets config of reviews, lints and policy.
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithHook adds hook to intercept stages of flow.
+func WithHook(h Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, h)
+	}
+}
+
+// WithLint sets lint, instead of the one built from config.
+func WithLint(l Lint) Option {
+	return func(o *options) {
+		o.lint = l
+	}
+}
+
+// WithPolicy sets vote policy, instead of the one built from config.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithReview sets review, instead of the one built from config.
+func WithReview(r Review) Option {
+	return func(o *options) {
+		o.review = r
+	}
+}
+
+// WithWorkDir sets root of workspaces for fetched files, instead of the one in config.
+func WithWorkDir(dir string) Option {
+	return func(o *options) {
+		o.root = dir
+	}
+}
+
+func New(ctx context.Context, opts ...Option) (Flow, error) {
+	o := &options{config: config.New()}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.root == "" {
+		o.root = o.config.Spec.Workspace.Root
+	}
+
+	if o.root == "" {
+		return nil, errors.New("invalid work dir")
+	}
+
+	if err := core.Check(o.root, &o.config.Spec.Workspace); err != nil {
+		return nil, errors.Wrap(err, "failed to check work dir")
+	}
+
+	s := storage.New(&storage.Config{Storage: o.config.Spec.Workspace.Storage})
+
+	if o.policy == nil {
+		o.policy = policy.New(&policy.Config{Policy: o.config.Spec.Policy})
+	}
+
+	if o.review == nil {
+		if o.name == "" {
+			return nil, errors.New("invalid code review")
+		}
+		o.review = review.New(&review.Config{Client: o.client, Name: o.name, Policy: o.policy, Reviews: o.config.Spec.Review,
+			Storage: s})
+	}
+
+	if o.lint == nil {
+		o.lint = lint.New(&lint.Config{Lints: o.config.Spec.Lint, Storage: s})
+	}
+
+	c := core.DefaultConfig()
+	c.Config = *o.config
+	c.Hooks = o.hooks
+	c.Lint = o.lint
+	c.Policy = o.policy
+	c.Review = o.review
+	c.Root = o.root
+	c.Storage = s
+
+	return core.New(ctx, c), nil
+}
@@ -0,0 +1,51 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background())
+	assert.NotEqual(t, nil, err)
+
+	_, err = New(context.Background(), WithWorkDir(os.TempDir()))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRun(t *testing.T) {
+	d, err := ioutil.TempDir("", "flow")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	cfg := config.New()
+	cfg.Spec.Lint = []config.Lint{{Name: "fake", Filter: config.Filter{Include: config.Include{Extension: []string{".go"}}}}}
+	cfg.Spec.Review = []config.Review{{Name: "fake", Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}}}
+
+	f, err := New(context.Background(), WithConfig(cfg), WithCodeReview("fake"), WithClient(http.DefaultClient), WithWorkDir(d))
+	assert.Equal(t, nil, err)
+
+	buf, err := f.Run("8f71e42dbcd8c68d849e483c04670f58621aab9c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Finding{{File: "main.go", Line: 3, Type: "Error", Details: "Fake error by lintflow", Lint: "fake"}}, buf)
+}
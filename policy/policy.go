@@ -0,0 +1,196 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"path"
+	"strings"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/ignore"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	CoverageFail = "fail"
+	CoverageWarn = "warn"
+)
+
+type Policy interface {
+	Approve([]proto.Format) bool
+	Coverage() string
+	Filter([]proto.Format) []proto.Format
+	Normalize(string, []proto.Format) []proto.Format
+	Notify() bool
+}
+
+type Config struct {
+	Policy config.Policy
+}
+
+type policy struct {
+	cfg      *Config
+	file     ignore.Ignore
+	severity []ignore.Ignore
+}
+
+type Result struct {
+	Before        []proto.Format
+	After         []proto.Format
+	Added         []proto.Format
+	Removed       []proto.Format
+	ApproveBefore bool
+	ApproveAfter  bool
+}
+
+func New(cfg *Config) Policy {
+	var severity []ignore.Ignore
+
+	for _, val := range cfg.Policy.Severity {
+		severity = append(severity, ignore.New([]byte(strings.Join(val.File, "\n"))))
+	}
+
+	return &policy{
+		cfg:      cfg,
+		file:     ignore.New([]byte(strings.Join(cfg.Policy.Exclude.File, "\n"))),
+		severity: severity,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Approve reports whether findings pass thresholds. Without thresholds, any finding disapproves.
+func (p *policy) Approve(data []proto.Format) bool {
+	if len(p.cfg.Policy.Threshold) == 0 {
+		return len(data) == 0
+	}
+
+	count := map[string]int{}
+
+	for _, val := range data {
+		count[val.Type]++
+	}
+
+	for key, val := range p.cfg.Policy.Threshold {
+		if val > 0 && count[key] >= val {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Coverage returns action on changes matched by no lint, i.e. fail, warn, or none if empty.
+func (p *policy) Coverage() string {
+	return p.cfg.Policy.Coverage
+}
+
+func (p *policy) Filter(data []proto.Format) []proto.Format {
+	contains := func(data []string, val string) bool {
+		for _, item := range data {
+			if item == val {
+				return true
+			}
+		}
+		return false
+	}
+
+	ret := []proto.Format{}
+
+	for _, val := range data {
+		if contains(p.cfg.Policy.Exclude.Type, val.Type) || (val.Rule != "" && contains(p.cfg.Policy.Exclude.Rule, val.Rule)) {
+			continue
+		}
+		if p.file.Match(val.File) {
+			continue
+		}
+		ret = append(ret, val)
+	}
+
+	return ret
+}
+
+// Normalize overrides types of findings by the first matched severity of rule, project and file.
+func (p *policy) Normalize(project string, data []proto.Format) []proto.Format {
+	match := func(index int, item proto.Format) bool {
+		s := p.cfg.Policy.Severity[index]
+		if s.Rule != "" && s.Rule != item.Rule {
+			return false
+		}
+		if s.Project != "" {
+			if ok, err := path.Match(s.Project, project); err != nil || !ok {
+				return false
+			}
+		}
+		if len(s.File) != 0 && !p.severity[index].Match(item.File) {
+			return false
+		}
+		return true
+	}
+
+	if len(p.cfg.Policy.Severity) == 0 || data == nil {
+		return data
+	}
+
+	ret := make([]proto.Format, len(data))
+
+	for i, item := range data {
+		for j := range p.cfg.Policy.Severity {
+			if match(j, item) {
+				item.Type = p.cfg.Policy.Severity[j].Type
+				break
+			}
+		}
+		ret[i] = item
+	}
+
+	return ret
+}
+
+// Notify reports whether failures of infrastructure are posted to review as neutral messages.
+func (p *policy) Notify() bool {
+	return p.cfg.Policy.Notify
+}
+
+// Simulate evaluates findings against current and proposed policies.
+func Simulate(current, proposed Policy, data []proto.Format) *Result {
+	diff := func(a, b []proto.Format) []proto.Format {
+		count := map[proto.Format]int{}
+		for _, val := range b {
+			count[val]++
+		}
+		var ret []proto.Format
+		for _, val := range a {
+			if count[val] > 0 {
+				count[val]--
+				continue
+			}
+			ret = append(ret, val)
+		}
+		return ret
+	}
+
+	r := &Result{
+		Before: current.Filter(data),
+		After:  proposed.Filter(data),
+	}
+
+	r.ApproveBefore = current.Approve(r.Before)
+	r.ApproveAfter = proposed.Approve(r.After)
+	r.Added = diff(r.After, r.Before)
+	r.Removed = diff(r.Before, r.After)
+
+	return r
+}
@@ -0,0 +1,105 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+var (
+	findings = []proto.Format{
+		{File: "main.go", Line: 1, Type: proto.TypeError, Details: "error", Rule: "E001"},
+		{File: "main.go", Line: 2, Type: proto.TypeWarn, Details: "warn", Rule: "W001"},
+		{File: "vendor/lib.go", Line: 3, Type: proto.TypeError, Details: "error", Rule: "E002"},
+	}
+)
+
+func TestApprove(t *testing.T) {
+	p := New(DefaultConfig())
+	assert.Equal(t, true, p.Approve(nil))
+	assert.Equal(t, false, p.Approve(findings))
+
+	p = New(&Config{Policy: config.Policy{Threshold: map[string]int{proto.TypeError: 3}}})
+	assert.Equal(t, true, p.Approve(findings))
+
+	p = New(&Config{Policy: config.Policy{Threshold: map[string]int{proto.TypeError: 2}}})
+	assert.Equal(t, false, p.Approve(findings))
+}
+
+func TestNotify(t *testing.T) {
+	p := New(DefaultConfig())
+	assert.Equal(t, false, p.Notify())
+
+	p = New(&Config{Policy: config.Policy{Notify: true}})
+	assert.Equal(t, true, p.Notify())
+}
+
+func TestCoverage(t *testing.T) {
+	p := New(DefaultConfig())
+	assert.Equal(t, "", p.Coverage())
+
+	p = New(&Config{Policy: config.Policy{Coverage: CoverageWarn}})
+	assert.Equal(t, CoverageWarn, p.Coverage())
+}
+
+func TestFilter(t *testing.T) {
+	p := New(DefaultConfig())
+	assert.Equal(t, findings, p.Filter(findings))
+
+	p = New(&Config{Policy: config.Policy{Exclude: config.Exclude{File: []string{"vendor/"}}}})
+	assert.Equal(t, findings[:2], p.Filter(findings))
+
+	p = New(&Config{Policy: config.Policy{Exclude: config.Exclude{Rule: []string{"E001"}, Type: []string{proto.TypeWarn}}}})
+	assert.Equal(t, findings[2:], p.Filter(findings))
+}
+
+func TestSimulate(t *testing.T) {
+	current := New(DefaultConfig())
+	proposed := New(&Config{Policy: config.Policy{
+		Exclude:   config.Exclude{File: []string{"vendor/"}},
+		Threshold: map[string]int{proto.TypeError: 2},
+	}})
+
+	r := Simulate(current, proposed, findings)
+	assert.Equal(t, 3, len(r.Before))
+	assert.Equal(t, 2, len(r.After))
+	assert.Equal(t, false, r.ApproveBefore)
+	assert.Equal(t, true, r.ApproveAfter)
+	assert.Equal(t, 0, len(r.Added))
+	assert.Equal(t, findings[2:], r.Removed)
+}
+
+func TestNormalize(t *testing.T) {
+	p := New(DefaultConfig())
+	assert.Equal(t, findings, p.Normalize("foo", findings))
+
+	p = New(&Config{Policy: config.Policy{Severity: []config.Severity{
+		{File: []string{"vendor/"}, Rule: "E002", Type: proto.TypeInfo},
+		{Project: "foo*", Rule: "W001", Type: proto.TypeError},
+		{Rule: "W001", Type: proto.TypeInfo},
+	}}})
+
+	buf := p.Normalize("foobar", findings)
+	assert.Equal(t, proto.TypeError, buf[0].Type)
+	assert.Equal(t, proto.TypeError, buf[1].Type)
+	assert.Equal(t, proto.TypeInfo, buf[2].Type)
+	assert.Equal(t, proto.TypeWarn, findings[1].Type)
+
+	buf = p.Normalize("bar", findings)
+	assert.Equal(t, proto.TypeInfo, buf[1].Type)
+}
@@ -0,0 +1,251 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ModeJSON  = "json"
+	ModeQuiet = "quiet"
+	ModeText  = "text"
+	ModeTTY   = "tty"
+)
+
+const (
+	StateDone    = "done"
+	StateFailed  = "failed"
+	StateRunning = "running"
+	StateSkipped = "skipped"
+)
+
+const (
+	spinInterval = 100 * time.Millisecond
+)
+
+var (
+	quiet   = New(&Config{Mode: ModeQuiet, Writer: ioutil.Discard})
+	spinner = []string{"|", "/", "-", "\\"}
+)
+
+// Event is progress of stage of run, or of lint in stage of lint with count of lints done in total.
+type Event struct {
+	Done  int       `json:"done,omitempty"`
+	Lint  string    `json:"lint,omitempty"`
+	Stage string    `json:"stage"`
+	State string    `json:"state"`
+	Time  time.Time `json:"time"`
+	Total int       `json:"total,omitempty"`
+}
+
+// Progress reports events of run, and writes logs without breaking its output, e.g. the line of spinner.
+type Progress interface {
+	Report(Event)
+	Write([]byte) (int, error)
+	Close() error
+}
+
+type Config struct {
+	Mode   string
+	Writer io.Writer
+}
+
+type progress struct {
+	cfg   *Config
+	done  chan struct{}
+	event Event
+	lints map[string]string
+	mutex sync.Mutex
+	once  sync.Once
+	spin  int
+	wg    sync.WaitGroup
+}
+
+type progressKey struct{}
+
+func New(cfg *Config) Progress {
+	if cfg.Writer == nil {
+		cfg.Writer = os.Stderr
+	}
+
+	p := &progress{cfg: cfg, done: make(chan struct{}), lints: map[string]string{}}
+
+	if cfg.Mode == ModeTTY {
+		p.wg.Add(1)
+		go p.routine()
+	}
+
+	return p
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Mode returns mode of progress on file, which is tty for terminal, or text otherwise.
+func Mode(f *os.File) string {
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return ModeText
+	}
+
+	return ModeTTY
+}
+
+// With returns ctx carrying progress, which is reported by stages of run and lints.
+func With(ctx context.Context, p Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// Of returns progress in ctx, or the quiet one if absent.
+func Of(ctx context.Context) Progress {
+	if p, ok := ctx.Value(progressKey{}).(Progress); ok {
+		return p
+	}
+
+	return quiet
+}
+
+func (p *progress) Report(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if e.Lint != "" {
+		p.lints[e.Lint] = e.State
+	} else if e.Stage != p.event.Stage {
+		p.lints = map[string]string{}
+	}
+
+	p.event = e
+
+	switch p.cfg.Mode {
+	case ModeJSON:
+		buf, _ := json.Marshal(e)
+		_, _ = fmt.Fprintln(p.cfg.Writer, string(buf))
+	case ModeText:
+		_, _ = fmt.Fprintln(p.cfg.Writer, text(e))
+	case ModeTTY:
+		p.draw()
+	}
+}
+
+func (p *progress) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cfg.Mode != ModeTTY || p.event.Stage == "" {
+		return p.cfg.Writer.Write(data)
+	}
+
+	// Clear line of spinner before writing, and draw it again after
+	_, _ = fmt.Fprint(p.cfg.Writer, "\r\033[K")
+	n, err := p.cfg.Writer.Write(data)
+	p.draw()
+
+	return n, err
+}
+
+func (p *progress) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.cfg.Mode == ModeTTY && p.event.Stage != "" {
+			_, _ = fmt.Fprintln(p.cfg.Writer)
+		}
+	})
+
+	return nil
+}
+
+func (p *progress) routine() {
+	defer p.wg.Done()
+
+	t := time.NewTicker(spinInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			p.mutex.Lock()
+			p.spin++
+			p.draw()
+			p.mutex.Unlock()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// draw draws line of spinner with stage and states of lints, e.g. | lint 1/2 50% lintcpp:done lintjava:running
+func (p *progress) draw() {
+	if p.event.Stage == "" {
+		return
+	}
+
+	var names []string
+
+	for key := range p.lints {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	buf := []string{spinner[p.spin%len(spinner)], p.event.Stage}
+
+	if p.event.Total > 0 {
+		buf = append(buf, fmt.Sprintf("%d/%d %d%%", p.event.Done, p.event.Total, p.event.Done*100/p.event.Total))
+	}
+
+	for _, val := range names {
+		buf = append(buf, val+":"+p.lints[val])
+	}
+
+	if len(names) == 0 {
+		buf = append(buf, p.event.State)
+	}
+
+	_, _ = fmt.Fprint(p.cfg.Writer, "\r\033[K"+strings.Join(buf, " "))
+}
+
+// text formats event in line, e.g. lint lintcpp done (1/2 50%)
+func text(e Event) string {
+	buf := []string{e.Stage}
+
+	if e.Lint != "" {
+		buf = append(buf, e.Lint)
+	}
+
+	buf = append(buf, e.State)
+
+	if e.Total > 0 {
+		buf = append(buf, fmt.Sprintf("(%d/%d %d%%)", e.Done, e.Total, e.Done*100/e.Total))
+	}
+
+	return strings.Join(buf, " ")
+}
@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	var b bytes.Buffer
+
+	p := New(&Config{Mode: ModeJSON, Writer: &b})
+	p.Report(Event{Done: 1, Lint: "lintcpp", Stage: "lint", State: StateDone, Time: time.Unix(0, 0).UTC(), Total: 2})
+	_ = p.Close()
+
+	assert.Equal(t, `{"done":1,"lint":"lintcpp","stage":"lint","state":"done","time":"1970-01-01T00:00:00Z","total":2}`+"\n",
+		b.String())
+}
+
+func TestText(t *testing.T) {
+	var b bytes.Buffer
+
+	p := New(&Config{Mode: ModeText, Writer: &b})
+	p.Report(Event{Stage: "fetch", State: StateRunning})
+	p.Report(Event{Done: 1, Lint: "lintcpp", Stage: "lint", State: StateFailed, Total: 2})
+	_, _ = p.Write([]byte("log\n"))
+	_ = p.Close()
+
+	assert.Equal(t, "fetch running\nlint lintcpp failed (1/2 50%)\nlog\n", b.String())
+}
+
+func TestQuiet(t *testing.T) {
+	var b bytes.Buffer
+
+	p := New(&Config{Mode: ModeQuiet, Writer: &b})
+	p.Report(Event{Stage: "fetch", State: StateRunning})
+	_, _ = p.Write([]byte("log\n"))
+	_ = p.Close()
+
+	assert.Equal(t, "log\n", b.String())
+}
+
+func TestTTY(t *testing.T) {
+	var b bytes.Buffer
+
+	p := New(&Config{Mode: ModeTTY, Writer: &b})
+	_, _ = p.Write([]byte("log\n"))
+	p.Report(Event{Done: 0, Lint: "lintjava", Stage: "lint", State: StateRunning, Total: 2})
+	p.Report(Event{Done: 1, Lint: "lintcpp", Stage: "lint", State: StateDone, Total: 2})
+	_, _ = p.Write([]byte("log\n"))
+	_ = p.Close()
+	_ = p.Close()
+
+	buf := b.String()
+	assert.Equal(t, true, strings.HasPrefix(buf, "log\n"))
+	assert.Equal(t, true, strings.Contains(buf, "\r\033[K| lint 1/2 50% lintcpp:done lintjava:running"))
+	assert.Equal(t, true, strings.Contains(buf, "\r\033[Klog\n\r\033[K"))
+	assert.Equal(t, true, strings.HasSuffix(buf, "\n"))
+}
+
+func TestMode(t *testing.T) {
+	f, err := ioutil.TempFile("", "progress")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.Remove(f.Name()) }()
+	defer func() { _ = f.Close() }()
+
+	assert.Equal(t, ModeText, Mode(f))
+}
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, quiet, Of(context.Background()))
+
+	p := New(DefaultConfig())
+	assert.Equal(t, p, Of(With(context.Background(), p)))
+}
@@ -12,6 +12,10 @@
 
 package proto
 
+import (
+	"time"
+)
+
 // Prototype
 // {
 //   "lint": [
@@ -19,14 +23,37 @@ package proto
 //       "file": "name",
 //       "line": 1,
 //       "type": "Error",
-//       "details": "text"
+//       "details": "text",
+//       "rule": "id",
+//       "category": "security",
+//       "scope": "line"
 //     }
 //   ]
 // }
 
 const (
-	Base64Content = ".base64"
-	Base64Message = "message.base64"
+	Base64Bundle   = "bundle.base64"
+	Base64Change   = "change.base64"
+	Base64Content  = ".base64"
+	Base64Findings = "findings.base64"
+	Base64Message  = "message.base64"
+	Base64Parent   = "parent.base64"
+)
+
+// ParentDir is directory in workspace keeping files of parent revision, which are fed to lints in parent.base64 as
+// base64 encoded JSON of files and their content.
+const (
+	ParentDir = ".parent"
+)
+
+const (
+	CategorySecurity = "security"
+)
+
+const (
+	ScopeChange = "change"
+	ScopeFile   = "file"
+	ScopeLine   = "line"
 )
 
 const (
@@ -36,8 +63,139 @@ const (
 )
 
 type Format struct {
-	File    string `json:"file"`
-	Line    int    `json:"line"`
-	Type    string `json:"type"`
-	Details string `json:"details"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Type     string `json:"type"`
+	Details  string `json:"details"`
+	Rule     string `json:"rule,omitempty"`
+	Category string `json:"category,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	Lint     string `json:"lint,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Docs     string `json:"docs,omitempty"`
+}
+
+// Kinds of revisions in Gerrit, whose findings are carried forward from former revisions or skipped.
+const (
+	KindMergeFirstParentUpdate = "MERGE_FIRST_PARENT_UPDATE"
+	KindNoCodeChange           = "NO_CODE_CHANGE"
+	KindTrivialRebase          = "TRIVIAL_REBASE"
+)
+
+// Statuses of keys of signatures in Gerrit.
+const (
+	SignatureBad     = "BAD"
+	SignatureOk      = "OK"
+	SignatureTrusted = "TRUSTED"
+)
+
+const (
+	ModeComment    = "comment"
+	ModeFreeze     = "freeze"
+	ModeFreezeVote = "freeze-vote"
+	ModeFull       = "full"
+	ModeSilent     = "silent"
+	ModeSkip       = "skip"
+	ModeVote       = "vote"
+)
+
+const (
+	StageFetch = "fetch"
+	StageLint  = "lint"
+	StageVote  = "vote"
+)
+
+const (
+	StatusCanceled = "canceled"
+	StatusFailed   = "failed"
+	StatusSuccess  = "success"
+)
+
+type Change struct {
+	Author       string         `json:"author"`
+	Branch       string         `json:"branch"`
+	Commit       string         `json:"commit"`
+	CommitAuthor string         `json:"commitAuthor,omitempty"`
+	Deletions    int            `json:"deletions"`
+	Insertions   int            `json:"insertions"`
+	Kind         string         `json:"kind,omitempty"`
+	Lines        map[string]int `json:"lines"`
+	Message      string         `json:"message"`
+	Number       int            `json:"number"`
+	Previous     string         `json:"previous,omitempty"`
+	Project      string         `json:"project"`
+	Related      []Related      `json:"related,omitempty"`
+	Signature    *Signature     `json:"signature,omitempty"`
+	Submodules   []Submodule    `json:"submodules,omitempty"`
+	Topic        string         `json:"topic,omitempty"`
+}
+
+// Bundle is centrally managed config files of lint fed to lints in bundle.base64, which are layered over workspace
+// by lints, and kept off files of repository unless Override.
+type Bundle struct {
+	Files    map[string]string `json:"files"`
+	Override bool              `json:"override"`
+}
+
+// Related is change in other repository of the same topic, e.g., of multi-repo changes in Gerrit.
+type Related struct {
+	Commit  string `json:"commit"`
+	Number  int    `json:"number"`
+	Project string `json:"project"`
+}
+
+// Signature is status of key signing push certificate of revision, which is absent if pushed without signing.
+type Signature struct {
+	Problems []string `json:"problems,omitempty"`
+	Status   string   `json:"status"`
+}
+
+// Submodule is update of pointer of submodule in change, whose commits are empty if submodule is added or removed.
+type Submodule struct {
+	New  string `json:"new,omitempty"`
+	Old  string `json:"old,omitempty"`
+	Path string `json:"path"`
+}
+
+// Feedback is reply to comment of lintflow, in which comment is message of comment replied to, if known,
+// to tell findings on the same line apart.
+type Feedback struct {
+	ID      string    `json:"id"`
+	Author  string    `json:"author"`
+	Comment string    `json:"comment,omitempty"`
+	Commit  string    `json:"commit"`
+	File    string    `json:"file"`
+	Line    int       `json:"line"`
+	Lint    string    `json:"lint,omitempty"`
+	Rule    string    `json:"rule,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Note is appended to message of vote, e.g., size of change, and vote is posted without notifications if Quiet,
+// where review supports it.
+type Note struct {
+	Quiet bool   `json:"quiet,omitempty"`
+	Text  string `json:"text"`
+}
+
+type Run struct {
+	ID        string              `json:"id"`
+	Commit    string              `json:"commit"`
+	Change    int                 `json:"change,omitempty"`
+	Repo      string              `json:"repo"`
+	Start     time.Time           `json:"start"`
+	Duration  time.Duration       `json:"duration"`
+	Status    string              `json:"status"`
+	Stage     string              `json:"stage,omitempty"`
+	Size      string              `json:"size,omitempty"`
+	Languages map[string]int      `json:"languages,omitempty"`
+	Versions  map[string]string   `json:"versions,omitempty"`
+	Logs      map[string]string   `json:"logs,omitempty"`
+	Config    string              `json:"config,omitempty"`
+	Files     map[string]string   `json:"files,omitempty"`
+	Mode      string              `json:"mode,omitempty"`
+	Carried   string              `json:"carried,omitempty"`
+	Canary    map[string][]Format `json:"canary,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Findings  []Format            `json:"findings"`
 }
@@ -0,0 +1,258 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	colorBlue   = "\033[34m"
+	colorBold   = "\033[1m"
+	colorDim    = "\033[2m"
+	colorRed    = "\033[31m"
+	colorReset  = "\033[0m"
+	colorYellow = "\033[33m"
+)
+
+const (
+	changeFile  = "(change)"
+	snippetSize = 160
+)
+
+var (
+	types = []string{proto.TypeError, proto.TypeWarn, proto.TypeInfo}
+)
+
+// Snippets keeps content of lines with findings, by file and line.
+type Snippets map[string]map[int]string
+
+// Report prints findings for humans, grouped by file with snippets and a summary table.
+type Report interface {
+	Print([]proto.Format) error
+}
+
+type Config struct {
+	Color    bool
+	Snippets Snippets
+	Writer   io.Writer
+}
+
+type report struct {
+	cfg *Config
+}
+
+func New(cfg *Config) Report {
+	return &report{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Color:  Color(os.Stdout),
+		Writer: os.Stdout,
+	}
+}
+
+// Color reports whether output to f is colored, i.e., f is terminal and NO_COLOR is unset.
+func Color(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Capture reads lines with findings from files fetched into dir of s, in which unreadable files are skipped.
+func Capture(s storage.Storage, dir string, data []proto.Format) Snippets {
+	ret := Snippets{}
+	lines := map[string][]string{}
+
+	for _, val := range data {
+		if val.File == "" || val.Line <= 0 {
+			continue
+		}
+		buf, ok := lines[val.File]
+		if !ok {
+			buf = read(s, dir, val.File)
+			lines[val.File] = buf
+		}
+		if val.Line > len(buf) {
+			continue
+		}
+		if ret[val.File] == nil {
+			ret[val.File] = map[int]string{}
+		}
+		ret[val.File][val.Line] = trim(buf[val.Line-1])
+	}
+
+	return ret
+}
+
+func read(s storage.Storage, dir, file string) []string {
+	buf, err := s.Read(filepath.Join(dir, filepath.FromSlash(file)+proto.Base64Content))
+	if err != nil {
+		return nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(string(buf))
+	if err != nil {
+		return nil
+	}
+
+	var ret []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(b)+1)
+
+	for scanner.Scan() {
+		ret = append(ret, scanner.Text())
+	}
+
+	return ret
+}
+
+func trim(line string) string {
+	line = strings.TrimSpace(strings.ReplaceAll(line, "\t", "    "))
+	if r := []rune(line); len(r) > snippetSize {
+		line = string(r[:snippetSize]) + "..."
+	}
+
+	return line
+}
+
+func (r *report) Print(data []proto.Format) error {
+	files, group := r.group(data)
+
+	for _, file := range files {
+		if err := r.printFile(file, group[file]); err != nil {
+			return err
+		}
+	}
+
+	return r.printSummary(data, len(files))
+}
+
+func (r *report) group(data []proto.Format) ([]string, map[string][]proto.Format) {
+	var files []string
+	group := map[string][]proto.Format{}
+
+	for _, val := range data {
+		file := val.File
+		if file == "" {
+			file = changeFile
+		}
+		if _, ok := group[file]; !ok {
+			files = append(files, file)
+		}
+		group[file] = append(group[file], val)
+	}
+
+	sort.Strings(files)
+
+	for _, val := range files {
+		buf := group[val]
+		sort.SliceStable(buf, func(i, j int) bool {
+			return buf[i].Line < buf[j].Line
+		})
+	}
+
+	return files, group
+}
+
+func (r *report) printFile(file string, data []proto.Format) error {
+	w := r.cfg.Writer
+
+	if _, err := fmt.Fprintln(w, r.paint(colorBold, file)); err != nil {
+		return err
+	}
+
+	for _, val := range data {
+		line := ""
+		if val.Line > 0 {
+			line = strconv.Itoa(val.Line)
+		}
+		rule := val.Lint
+		if val.Rule != "" {
+			rule += "/" + val.Rule
+		}
+		_, _ = fmt.Fprintf(w, "  %5s  %s  %s  %s\n", line, r.paint(color(val.Type), fmt.Sprintf("%-5s", val.Type)),
+			val.Details, r.paint(colorDim, rule))
+		if snippet, ok := r.cfg.Snippets[val.File][val.Line]; ok {
+			_, _ = fmt.Fprintf(w, "  %5s  %s\n", "", r.paint(colorDim, "| "+snippet))
+		}
+	}
+
+	_, err := fmt.Fprintln(w)
+
+	return err
+}
+
+func (r *report) printSummary(data []proto.Format, files int) error {
+	count := map[string]int{}
+	for _, val := range data {
+		count[val.Type]++
+	}
+
+	w := r.cfg.Writer
+
+	_, _ = fmt.Fprintf(w, "%-7s  %5s\n", "TYPE", "COUNT")
+	for _, val := range types {
+		_, _ = fmt.Fprintf(w, "%s  %5d\n", r.paint(color(val), fmt.Sprintf("%-7s", val)), count[val])
+	}
+	_, _ = fmt.Fprintf(w, "%s  %5d\n", r.paint(colorBold, fmt.Sprintf("%-7s", "Total")), len(data))
+
+	_, err := fmt.Fprintf(w, "\n%d findings in %d files\n", len(data), files)
+
+	return err
+}
+
+// paint wraps s in color, which is padded by callers before for alignment.
+func (r *report) paint(c, s string) string {
+	if !r.cfg.Color || c == "" {
+		return s
+	}
+
+	return c + s + colorReset
+}
+
+func color(typ string) string {
+	switch typ {
+	case proto.TypeError:
+		return colorRed
+	case proto.TypeWarn:
+		return colorYellow
+	case proto.TypeInfo:
+		return colorBlue
+	default:
+		return ""
+	}
+}
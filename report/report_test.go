@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+func TestCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	assert.Equal(t, nil, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	content := base64.StdEncoding.EncodeToString([]byte("package main\n\n\tf.Close()\n"))
+	err = ioutil.WriteFile(filepath.Join(dir, "main.go"+proto.Base64Content), []byte(content), 0600)
+	assert.Equal(t, nil, err)
+
+	data := []proto.Format{
+		{File: "main.go", Line: 3},
+		{File: "main.go", Line: 9},
+		{File: "util.go", Line: 1},
+		{Details: "change"},
+	}
+
+	s := Capture(storage.New(storage.DefaultConfig()), dir, data)
+	assert.Equal(t, Snippets{"main.go": {3: "f.Close()"}}, s)
+}
+
+func TestPrint(t *testing.T) {
+	var b bytes.Buffer
+
+	data := []proto.Format{
+		{File: "main.go", Line: 7, Type: proto.TypeWarn, Details: "unused", Lint: "lintgo"},
+		{File: "main.go", Line: 3, Type: proto.TypeError, Details: "unchecked error", Lint: "lintgo", Rule: "errcheck"},
+		{Type: proto.TypeInfo, Details: "large change", Lint: "lintsize"},
+	}
+
+	r := New(&Config{Snippets: Snippets{"main.go": {3: "f.Close()"}}, Writer: &b})
+	err := r.Print(data)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, "(change)\n"+
+		"         Info   large change  lintsize\n"+
+		"\n"+
+		"main.go\n"+
+		"      3  Error  unchecked error  lintgo/errcheck\n"+
+		"         | f.Close()\n"+
+		"      7  Warn   unused  lintgo\n"+
+		"\n"+
+		"TYPE     COUNT\n"+
+		"Error        1\n"+
+		"Warn         1\n"+
+		"Info         1\n"+
+		"Total        3\n"+
+		"\n"+
+		"3 findings in 2 files\n", b.String())
+}
+
+func TestPrintColor(t *testing.T) {
+	var b bytes.Buffer
+
+	r := New(&Config{Color: true, Writer: &b})
+	err := r.Print([]proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "text", Lint: "lintgo"}})
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, true, strings.Contains(b.String(), colorBold+"main.go"+colorReset))
+	assert.Equal(t, true, strings.Contains(b.String(), colorRed+"Error"+colorReset))
+	assert.Equal(t, true, strings.Contains(b.String(), colorYellow+"Warn   "+colorReset+"      0"))
+}
+
+func TestColor(t *testing.T) {
+	f, err := ioutil.TempFile("", "report")
+	assert.Equal(t, nil, err)
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	assert.Equal(t, false, Color(f))
+}
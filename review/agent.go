@@ -0,0 +1,49 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	agentName = "lintflow"
+)
+
+// tag identifies requests to review by User-Agent, and W3C traceparent of new trace if enabled,
+// so that admins of review could attribute and rate-shape traffic.
+func tag(req *http.Request, r *config.Review) {
+	agent := r.UserAgent
+	if agent == "" {
+		agent = agentName + "/" + config.Version
+	}
+
+	req.Header.Set("User-Agent", agent)
+
+	if r.Trace {
+		req.Header.Set("traceparent", traceparent())
+	}
+}
+
+func traceparent() string {
+	trace, span := make([]byte, 16), make([]byte, 8)
+
+	_, _ = rand.Read(trace)
+	_, _ = rand.Read(span)
+
+	return "00-" + hex.EncodeToString(trace) + "-" + hex.EncodeToString(span) + "-01"
+}
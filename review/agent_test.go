@@ -0,0 +1,35 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestTag(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1", nil)
+
+	tag(req, &config.Review{})
+	assert.Equal(t, "lintflow/"+config.Version, req.Header.Get("User-Agent"))
+	assert.Equal(t, "", req.Header.Get("traceparent"))
+
+	tag(req, &config.Review{Trace: true, UserAgent: "lintflow-ci/1.0 (team@example.com)"})
+	assert.Equal(t, "lintflow-ci/1.0 (team@example.com)", req.Header.Get("User-Agent"))
+	assert.Regexp(t, regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`), req.Header.Get("traceparent"))
+}
@@ -0,0 +1,148 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"crypto/md5" // nolint:gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authDigest = "digest"
+)
+
+// do sends request with static headers and authentication of review, and answers digest challenge if required.
+func do(c *http.Client, req *http.Request, r *config.Review) (*http.Response, error) {
+	tag(req, r)
+
+	for key, val := range r.Header {
+		req.Header.Set(key, val)
+	}
+
+	if r.User == "" || r.Pass == "" {
+		return c.Do(req)
+	}
+
+	switch strings.ToLower(r.Auth) {
+	case authBearer:
+		req.Header.Set("Authorization", "Bearer "+r.Pass)
+	case authDigest:
+		return doDigest(c, req, r)
+	default:
+		req.SetBasicAuth(r.User, r.Pass)
+	}
+
+	return c.Do(req)
+}
+
+func doDigest(c *http.Client, req *http.Request, r *config.Review) (*http.Response, error) {
+	rsp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := rsp.Header.Get("WWW-Authenticate")
+	if rsp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(strings.ToLower(challenge), authDigest+" ") {
+		return rsp, nil
+	}
+
+	_ = rsp.Body.Close()
+
+	auth, err := digest(req.Method, req.URL.RequestURI(), r.User, r.Pass, challenge)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to digest")
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, errors.Wrap(err, "failed to get body")
+		}
+	}
+
+	retry.Header.Set("Authorization", auth)
+
+	return c.Do(retry)
+}
+
+// digest answers challenge of digest access authentication in RFC 7616.
+func digest(method, uri, user, pass, challenge string) (string, error) {
+	param := map[string]string{}
+
+	for _, val := range strings.Split(challenge[len(authDigest)+1:], ",") {
+		kv := strings.SplitN(strings.TrimSpace(val), "=", 2)
+		if len(kv) == 2 {
+			param[strings.ToLower(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+
+	if param["nonce"] == "" {
+		return "", errors.New("invalid nonce")
+	}
+
+	var h func() hash.Hash
+
+	switch strings.ToUpper(param["algorithm"]) {
+	case "", "MD5":
+		h = md5.New
+	case "SHA-256":
+		h = sha256.New
+	default:
+		return "", errors.New("invalid algorithm")
+	}
+
+	sum := func(data string) string {
+		d := h()
+		_, _ = d.Write([]byte(data))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	ha1 := sum(user + ":" + param["realm"] + ":" + pass)
+	ha2 := sum(method + ":" + uri)
+
+	buf := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, user, param["realm"], param["nonce"], uri)
+
+	if param["qop"] == "" {
+		buf += fmt.Sprintf(`, response="%s"`, sum(ha1+":"+param["nonce"]+":"+ha2))
+	} else {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", errors.Wrap(err, "failed to read")
+		}
+		cnonce, nc := hex.EncodeToString(b), "00000001"
+		buf += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, cnonce,
+			sum(ha1+":"+param["nonce"]+":"+nc+":"+cnonce+":auth:"+ha2))
+	}
+
+	if param["algorithm"] != "" {
+		buf += ", algorithm=" + param["algorithm"]
+	}
+
+	if param["opaque"] != "" {
+		buf += fmt.Sprintf(`, opaque="%s"`, param["opaque"])
+	}
+
+	return buf, nil
+}
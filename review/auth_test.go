@@ -0,0 +1,92 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"crypto/md5" // nolint:gosec
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestDo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization") + "|" + r.Header.Get("X-Proxy")))
+	}))
+	defer ts.Close()
+
+	helper := func(r *config.Review) string {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+		rsp, err := do(http.DefaultClient, req, r)
+		assert.Equal(t, nil, err)
+		buf, _ := ioutil.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+		return string(buf)
+	}
+
+	assert.Equal(t, "|proxy", helper(&config.Review{Header: map[string]string{"X-Proxy": "proxy"}}))
+	assert.Equal(t, "Basic dXNlcjpwYXNz|", helper(&config.Review{User: "user", Pass: "pass"}))
+	assert.Equal(t, "Bearer pass|", helper(&config.Review{Auth: authBearer, User: "user", Pass: "pass"}))
+}
+
+func TestDoDigest(t *testing.T) {
+	sum := func(data string) string {
+		h := md5.Sum([]byte(data)) // nolint:gosec
+		return hex.EncodeToString(h[:])
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="gerrit", nonce="abc", qop="auth", opaque="xyz"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		param := map[string]string{}
+		for _, val := range strings.Split(strings.TrimPrefix(auth, "Digest "), ", ") {
+			kv := strings.SplitN(val, "=", 2)
+			param[kv[0]] = strings.Trim(kv[1], "\"")
+		}
+		ha1, ha2 := sum("user:gerrit:pass"), sum(r.Method+":"+param["uri"])
+		if param["response"] != sum(ha1+":abc:"+param["nc"]+":"+param["cnonce"]+":auth:"+ha2) || param["opaque"] != "xyz" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		buf, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(buf)
+	}))
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/a/changes/1/revisions/1/review", bytes.NewBufferString("{}"))
+
+	rsp, err := do(http.DefaultClient, req, &config.Review{Auth: authDigest, User: "user", Pass: "pass"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	buf, _ := ioutil.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+	assert.Equal(t, "{}", string(buf))
+
+	_, err = digest(http.MethodGet, "/", "user", "pass", `Digest realm="gerrit"`)
+	assert.NotEqual(t, nil, err)
+
+	_, err = digest(http.MethodGet, "/", "user", "pass", `Digest realm="gerrit", nonce="abc", algorithm=SHA-512`)
+	assert.NotEqual(t, nil, err)
+}
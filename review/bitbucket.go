@@ -0,0 +1,701 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/ignore"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	bitbucketApproved  = "APPROVED"
+	bitbucketNeedsWork = "NEEDS_WORK"
+)
+
+const (
+	bitbucketAdd       = "ADD"
+	bitbucketCommented = "COMMENTED"
+	bitbucketDelete    = "DELETE"
+	bitbucketOpen      = "OPEN"
+	bitbucketSubmodule = "SUBMODULE"
+)
+
+const (
+	bitbucketCommentSize = 32768
+	bitbucketLimit       = 100
+	bitbucketPages       = 30
+)
+
+type bitbucket struct {
+	c *http.Client
+	p policy.Policy
+	r config.Review
+	s storage.Storage
+}
+
+type bitbucketUser struct {
+	EmailAddress string `json:"emailAddress"`
+	Name         string `json:"name"`
+}
+
+type bitbucketRef struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+type bitbucketPull struct {
+	Author struct {
+		User bitbucketUser `json:"user"`
+	} `json:"author"`
+	FromRef bitbucketRef `json:"fromRef"`
+	ID      int          `json:"id"`
+	State   string       `json:"state"`
+	ToRef   bitbucketRef `json:"toRef"`
+}
+
+type bitbucketPath struct {
+	ToString string `json:"toString"`
+}
+
+type bitbucketChange struct {
+	NodeType string         `json:"nodeType"`
+	Path     bitbucketPath  `json:"path"`
+	SrcPath  *bitbucketPath `json:"srcPath"`
+	Type     string         `json:"type"`
+}
+
+type bitbucketCommit struct {
+	Author  bitbucketUser `json:"author"`
+	Message string        `json:"message"`
+}
+
+type bitbucketAnchor struct {
+	FileType string `json:"fileType,omitempty"`
+	FromHash string `json:"fromHash,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	LineType string `json:"lineType,omitempty"`
+	Path     string `json:"path"`
+	SrcPath  string `json:"srcPath,omitempty"`
+	ToHash   string `json:"toHash,omitempty"`
+}
+
+type bitbucketComment struct {
+	Author      bitbucketUser      `json:"author"`
+	Comments    []bitbucketComment `json:"comments"`
+	ID          int64              `json:"id"`
+	Text        string             `json:"text"`
+	UpdatedDate int64              `json:"updatedDate"`
+}
+
+type bitbucketActivity struct {
+	Action        string            `json:"action"`
+	Comment       *bitbucketComment `json:"comment"`
+	CommentAnchor *bitbucketAnchor  `json:"commentAnchor"`
+}
+
+type bitbucketCommentInput struct {
+	Anchor *bitbucketAnchor `json:"anchor,omitempty"`
+	Text   string           `json:"text"`
+}
+
+type bitbucketParticipant struct {
+	Approved bool          `json:"approved,omitempty"`
+	Role     string        `json:"role,omitempty"`
+	Status   string        `json:"status,omitempty"`
+	User     bitbucketUser `json:"user"`
+}
+
+// bitbucketPage is page of paged APIs of Bitbucket, whose values are decoded by callers.
+type bitbucketPage struct {
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+	Values        json.RawMessage `json:"values"`
+}
+
+// Carry is unsupported, since Change reports no previous revision of pull request for flow to carry from.
+func (b *bitbucket) Carry(_, _ string, _ []proto.Format) ([]proto.Format, error) {
+	return nil, errors.New("unsupported carry")
+}
+
+func (b *bitbucket) Change(commit string) (proto.Change, error) {
+	p, err := b.pull(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to pull")
+	}
+
+	c, err := b.commit(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to commit")
+	}
+
+	diffs, err := b.diff(p.ID)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to diff")
+	}
+
+	ret := proto.Change{
+		Author:       c.Author.EmailAddress,
+		Branch:       p.ToRef.DisplayID,
+		Commit:       commit,
+		CommitAuthor: c.Author.EmailAddress,
+		Message:      c.Message,
+		Number:       p.ID,
+		Project:      b.r.Repo,
+	}
+
+	if ret.Author == "" {
+		ret.Author = p.Author.User.EmailAddress
+	}
+
+	ret.Lines, ret.Insertions, ret.Deletions = stats(diffs)
+	ret.Submodules = submodules(diffs)
+
+	return ret, nil
+}
+
+func (b *bitbucket) Clean(name string) error {
+	if err := b.s.Remove(name); err != nil {
+		return errors.Wrap(err, "failed to clean")
+	}
+
+	return nil
+}
+
+// Feedback gets replies to comments on files of pull request of commit, which contain keyword. Replies are on file
+// and line of comments replied to.
+func (b *bitbucket) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if keyword == "" {
+		return nil, nil
+	}
+
+	p, err := b.pull(commit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pull")
+	}
+
+	activities, err := b.activities(p.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to activities")
+	}
+
+	var ret []proto.Feedback
+
+	var walk func(anchor *bitbucketAnchor, comments []bitbucketComment)
+
+	walk = func(anchor *bitbucketAnchor, comments []bitbucketComment) {
+		for _, item := range comments {
+			if strings.Contains(item.Text, keyword) {
+				ret = append(ret, proto.Feedback{ID: strconv.FormatInt(item.ID, 10), Author: item.Author.Name,
+					Commit: commit, File: anchor.Path, Line: anchor.Line,
+					Time: time.Unix(0, item.UpdatedDate*int64(time.Millisecond)).UTC()})
+			}
+			walk(anchor, item.Comments)
+		}
+	}
+
+	for _, item := range activities {
+		if item.Action != bitbucketCommented || item.Comment == nil || item.CommentAnchor == nil {
+			continue
+		}
+		if item.CommentAnchor.ToHash != "" && item.CommentAnchor.ToHash != commit {
+			continue
+		}
+		walk(item.CommentAnchor, item.Comment.Comments)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+
+	return ret, nil
+}
+
+func (b *bitbucket) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
+	p, err := b.pull(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to pull")
+	}
+
+	c, err := b.commit(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to commit")
+	}
+
+	fs, err := b.changes(p.ID)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to changes")
+	}
+
+	base := filepath.Join(root, strconv.Itoa(p.ID), commit)
+
+	if err := b.write(base, proto.Base64Message, base64.StdEncoding.EncodeToString([]byte(c.Message))); err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to fetch")
+	}
+
+	files := []string{proto.Base64Message}
+
+	// Match ignore
+	ig := b.ignore(commit)
+
+	for _, item := range fs {
+		name := item.Path.ToString
+		if item.Type == bitbucketDelete || item.NodeType == bitbucketSubmodule || ig.Match(name) {
+			continue
+		}
+		buf, err := b.get(b.urlRaw(name, commit))
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to raw")
+		}
+		if err := b.write(base, b.file(name), base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to fetch")
+		}
+		files = append(files, b.file(name))
+	}
+
+	return base, b.r.Repo, files, nil
+}
+
+// Notify posts message to pull request of commit as general comment without status.
+func (b *bitbucket) Notify(commit, message string) error {
+	p, err := b.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	if err := b.post(b.endpoint(nil, "pull-requests", strconv.Itoa(p.ID), "comments"),
+		&bitbucketCommentInput{Text: message}); err != nil {
+		return errors.Wrap(err, "failed to comment")
+	}
+
+	return nil
+}
+
+// Parent writes content of files in target of pull request of commit into parent directory of dir, skipping files
+// added.
+func (b *bitbucket) Parent(dir, commit string, files []string) error {
+	p, err := b.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	fs, err := b.changes(p.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to changes")
+	}
+
+	info := map[string]bitbucketChange{}
+
+	for _, item := range fs {
+		info[item.Path.ToString] = item
+	}
+
+	for _, val := range files {
+		name := strings.TrimSuffix(val, proto.Base64Content)
+		item, ok := info[name]
+		if val == proto.Base64Message || !ok || item.Type == bitbucketAdd {
+			continue
+		}
+		if item.SrcPath != nil && item.SrcPath.ToString != "" {
+			name = item.SrcPath.ToString
+		}
+		buf, err := b.get(b.urlRaw(name, p.ToRef.LatestCommit))
+		if err != nil {
+			return errors.Wrap(err, "failed to raw")
+		}
+		if err := b.write(filepath.Join(dir, proto.ParentDir), val, base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+// Vote comments findings on lines added by pull request of commit, and sets status of the bot as reviewer to approved
+// or needs work by policy. Findings on commit message or change, and out of diff, are posted in one general comment.
+// nolint:gocyclo
+func (b *bitbucket) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	match := func(data proto.Format, diffs []*diff.File) *diff.File {
+		for _, d := range diffs {
+			if d.New == data.File && data.Line != 0 && d.Added(data.Line) {
+				return d
+			}
+		}
+		return nil
+	}
+
+	size := bitbucketCommentSize
+	if b.r.Vote.CommentSize > 0 && b.r.Vote.CommentSize < size {
+		size = b.r.Vote.CommentSize
+	}
+
+	p, err := b.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	diffs, err := b.diff(p.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to diff")
+	}
+
+	var remarks []remark
+
+	if b.r.Vote.Dedup.Enabled {
+		if remarks, err = b.remarks(p.ID, commit); err != nil {
+			log.Println(errors.Wrap(err, "failed to remarks"))
+		}
+	}
+
+	c := map[string][]commentInput{}
+	src := map[string]string{}
+
+	var f, change []proto.Format
+
+	for _, item := range data {
+		if item.Details == "" {
+			continue
+		}
+		f = append(f, item)
+		d := match(item, diffs)
+		if item.Scope == proto.ScopeChange || item.File == proto.Base64Message || d == nil {
+			change = append(change, item)
+			continue
+		}
+		if duplicate(&item, remarks, &b.r.Vote.Dedup) {
+			continue
+		}
+		c[item.File] = append(c[item.File], commentInput{Line: item.Line, Message: message(&item)})
+		if d.Old != d.New {
+			src[item.File] = d.Old
+		}
+	}
+
+	var comments []bitbucketCommentInput
+
+	for key, val := range c {
+		for _, item := range pack(val, &b.r.Vote) {
+			link := ""
+			if b.r.Vote.Link != "" {
+				link = detail(b.r.Vote.Link, commit, key, item.Line)
+			}
+			comments = append(comments, bitbucketCommentInput{Anchor: &bitbucketAnchor{FileType: "TO", Line: item.Line,
+				LineType: "ADDED", Path: key, SrcPath: src[key], ToHash: commit}, Text: truncate(item.Message, size, link)})
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].Anchor.Path != comments[j].Anchor.Path {
+			return comments[i].Anchor.Path < comments[j].Anchor.Path
+		}
+		return comments[i].Anchor.Line < comments[j].Anchor.Line
+	})
+
+	text := changeLevel(b.r.Vote.Message, change)
+	if mode == proto.ModeVote || mode == proto.ModeFreezeVote {
+		comments, text = nil, summary(b.r.Vote.Message, f)
+	}
+
+	text = annotate(text, note)
+
+	if text != "" {
+		comments = append(comments, bitbucketCommentInput{Text: truncate(text, size, "")})
+	}
+
+	for index := range comments {
+		if err := b.post(b.endpoint(nil, "pull-requests", strconv.Itoa(p.ID), "comments"), &comments[index]); err != nil {
+			return errors.Wrap(err, "failed to comment")
+		}
+	}
+
+	// Status is kept as is in comment mode, and is never set to needs work in freeze mode
+	block := disapproving(labels(f, &b.r.Vote, b.p), &b.r.Vote)
+	frozen := mode == proto.ModeFreeze || mode == proto.ModeFreezeVote
+	if mode != proto.ModeComment && !(frozen && block) {
+		status := bitbucketApproved
+		if block {
+			status = bitbucketNeedsWork
+		}
+		if err := b.put(b.endpoint(nil, "pull-requests", strconv.Itoa(p.ID), "participants", b.r.User),
+			&bitbucketParticipant{Approved: !block, Status: status, User: bitbucketUser{Name: b.r.User}}); err != nil {
+			return errors.Wrap(err, "failed to status")
+		}
+	}
+
+	if security(data, &b.r.Security) {
+		for _, val := range b.r.Security.Reviewers {
+			if err := b.post(b.endpoint(nil, "pull-requests", strconv.Itoa(p.ID), "participants"),
+				&bitbucketParticipant{Role: "REVIEWER", User: bitbucketUser{Name: val}}); err != nil {
+				return errors.Wrap(err, "failed to reviewer")
+			}
+		}
+		if b.r.Security.Hashtag != "" {
+			log.Println("security hashtag unavailable")
+		}
+	}
+
+	return nil
+}
+
+// pull finds the pull request of commit, preferring open ones whose latest commit is commit.
+func (b *bitbucket) pull(commit string) (*bitbucketPull, error) {
+	buf, err := b.get(b.endpoint(nil, "commits", commit, "pull-requests"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	var page bitbucketPage
+
+	if err := json.Unmarshal(buf, &page); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	var pulls []bitbucketPull
+
+	if err := json.Unmarshal(page.Values, &pulls); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if len(pulls) == 0 {
+		return nil, errors.New("invalid pull request")
+	}
+
+	for index := range pulls {
+		if pulls[index].FromRef.LatestCommit == commit && pulls[index].State == bitbucketOpen {
+			return &pulls[index], nil
+		}
+	}
+
+	return &pulls[0], nil
+}
+
+func (b *bitbucket) commit(commit string) (*bitbucketCommit, error) {
+	buf, err := b.get(b.endpoint(nil, "commits", commit))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	ret := &bitbucketCommit{}
+
+	if err := json.Unmarshal(buf, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return ret, nil
+}
+
+func (b *bitbucket) activities(id int) ([]bitbucketActivity, error) {
+	var ret []bitbucketActivity
+
+	if err := b.paged(b.endpoint(nil, "pull-requests", strconv.Itoa(id), "activities"), func(data []byte) error {
+		var buf []bitbucketActivity
+		if err := json.Unmarshal(data, &buf); err != nil {
+			return err
+		}
+		ret = append(ret, buf...)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to paged")
+	}
+
+	return ret, nil
+}
+
+// remarks gets comments and replies of reviewers on commit in pull request, on line of their root comments.
+func (b *bitbucket) remarks(id int, commit string) ([]remark, error) {
+	activities, err := b.activities(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to activities")
+	}
+
+	var ret []remark
+
+	var walk func(anchor *bitbucketAnchor, comments []bitbucketComment)
+
+	walk = func(anchor *bitbucketAnchor, comments []bitbucketComment) {
+		for _, item := range comments {
+			if human(item.Author.Name, b.r.User) {
+				ret = append(ret, remark{Author: item.Author.Name, File: anchor.Path, Line: anchor.Line, Message: item.Text})
+			}
+			walk(anchor, item.Comments)
+		}
+	}
+
+	for _, item := range activities {
+		if item.Action != bitbucketCommented || item.Comment == nil || item.CommentAnchor == nil {
+			continue
+		}
+		if item.CommentAnchor.ToHash != "" && item.CommentAnchor.ToHash != commit {
+			continue
+		}
+		walk(item.CommentAnchor, []bitbucketComment{*item.Comment})
+	}
+
+	return ret, nil
+}
+
+func (b *bitbucket) changes(id int) ([]bitbucketChange, error) {
+	var ret []bitbucketChange
+
+	if err := b.paged(b.endpoint(nil, "pull-requests", strconv.Itoa(id), "changes"), func(data []byte) error {
+		var buf []bitbucketChange
+		if err := json.Unmarshal(data, &buf); err != nil {
+			return err
+		}
+		ret = append(ret, buf...)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to paged")
+	}
+
+	return ret, nil
+}
+
+func (b *bitbucket) diff(id int) ([]*diff.File, error) {
+	buf, err := b.get(b.endpoint(nil, "pull-requests", strconv.Itoa(id)+".diff"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	diffs, err := diff.Parse(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse")
+	}
+
+	return diffs, nil
+}
+
+// paged gets pages of paged API from start till the last page, up to limit of pages.
+func (b *bitbucket) paged(_url string, handle func([]byte) error) error {
+	u, err := url.Parse(_url)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
+	}
+
+	start := 0
+
+	for page := 0; page < bitbucketPages; page++ {
+		u.RawQuery = url.Values{"limit": {strconv.Itoa(bitbucketLimit)}, "start": {strconv.Itoa(start)}}.Encode()
+		buf, err := b.get(u.String())
+		if err != nil {
+			return errors.Wrap(err, "failed to get")
+		}
+		var p bitbucketPage
+		if err := json.Unmarshal(buf, &p); err != nil {
+			return errors.Wrap(err, "failed to unmarshal")
+		}
+		if err := handle(p.Values); err != nil {
+			return errors.Wrap(err, "failed to handle")
+		}
+		if p.IsLastPage {
+			break
+		}
+		start = p.NextPageStart
+	}
+
+	return nil
+}
+
+func (b *bitbucket) ignore(commit string) ignore.Ignore {
+	buf, err := b.get(b.urlRaw(ignore.Name, commit))
+	if err != nil {
+		// Missing ignore file in commit
+		return ignore.New(nil)
+	}
+
+	return ignore.New(buf)
+}
+
+// file maps the slash-separated file name in Bitbucket to the slash-separated name in workspace
+func (b *bitbucket) file(name string) string {
+	return path.Join(path.Dir(name), path.Base(name)+proto.Base64Content)
+}
+
+func (b *bitbucket) write(root, file, data string) error {
+	if err := b.s.Write(filepath.Join(root, filepath.FromSlash(file)), []byte(data)); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// base returns URL of Bitbucket including optional path prefix, e.g. https://example.com/bitbucket
+func (b *bitbucket) base() string {
+	if b.r.Url != "" {
+		return strings.TrimSuffix(b.r.Url, "/")
+	}
+
+	return strings.TrimSuffix(b.r.Host, "/") + ":" + strconv.Itoa(b.r.Port)
+}
+
+// endpoint joins escaped path elements to API of repository in repo of {project}/{slug}, e.g.
+// /rest/api/1.0/projects/{project}/repos/{slug}/pull-requests
+func (b *bitbucket) endpoint(query url.Values, elem ...string) string {
+	u, err := url.Parse(b.base())
+	if err != nil {
+		return ""
+	}
+
+	project, slug := b.r.Repo, ""
+	if i := strings.Index(b.r.Repo, "/"); i >= 0 {
+		project, slug = b.r.Repo[:i], b.r.Repo[i+1:]
+	}
+
+	name := []string{strings.TrimSuffix(u.Path, "/"), "rest", "api", "1.0"}
+	raw := []string{strings.TrimSuffix(u.EscapedPath(), "/"), "rest", "api", "1.0"}
+
+	for _, val := range append([]string{"projects", project, "repos", slug}, elem...) {
+		name, raw = append(name, val), append(raw, url.PathEscape(val))
+	}
+
+	u.Path = strings.Join(name, "/")
+	u.RawPath = strings.Join(raw, "/")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (b *bitbucket) urlRaw(name, ref string) string {
+	return b.endpoint(url.Values{"at": {ref}}, append([]string{"raw"}, strings.Split(name, "/")...)...)
+}
+
+func (b *bitbucket) get(_url string) ([]byte, error) {
+	return request(b.c, &b.r, http.MethodGet, _url, "", nil)
+}
+
+func (b *bitbucket) post(_url string, data interface{}) error {
+	_, err := request(b.c, &b.r, http.MethodPost, _url, "", data)
+	return err
+}
+
+func (b *bitbucket) put(_url string, data interface{}) error {
+	_, err := request(b.c, &b.r, http.MethodPut, _url, "", data)
+	return err
+}
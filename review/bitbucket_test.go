@@ -0,0 +1,296 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	commitBitbucket = "e00cf62997a027bbf785614a93e2e55bb331d268"
+	repoBitbucket   = "PROJ/repo"
+)
+
+// fakeBitbucket implements the subset of Bitbucket REST API used by lintflow, and records what is posted.
+type fakeBitbucket struct {
+	*httptest.Server
+	mu           sync.Mutex
+	comments     []bitbucketCommentInput
+	participants []bitbucketParticipant
+	statuses     []bitbucketParticipant
+}
+
+func newFakeBitbucket() *fakeBitbucket {
+	s := &fakeBitbucket{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *fakeBitbucket) bitbucket(r config.Review) bitbucket {
+	r.Repo, r.Url, r.User = repoBitbucket, s.URL, "lintflow"
+
+	return bitbucket{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), r: r, s: storage.New(storage.DefaultConfig())}
+}
+
+// nolint:gocyclo
+func (s *fakeBitbucket) serve(w http.ResponseWriter, r *http.Request) {
+	prefix := "/rest/api/1.0/projects/PROJ/repos/repo/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	content := map[string]string{
+		commitBitbucket + "/main.go":    "package main\n\nfunc main() {}\n",
+		commitBitbucket + "/src/lib.go": "package src\n",
+		"base/old/lib.go":               "package old\n",
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p := strings.TrimPrefix(r.URL.Path, prefix); {
+	case p == "commits/"+commitBitbucket+"/pull-requests":
+		s.page(w, []bitbucketPull{{FromRef: bitbucketRef{LatestCommit: commitBitbucket}, ID: 3, State: bitbucketOpen,
+			ToRef: bitbucketRef{DisplayID: "main", LatestCommit: "base"}}}, true)
+	case p == "commits/"+commitBitbucket:
+		s.json(w, bitbucketCommit{Author: bitbucketUser{EmailAddress: "dev@example.com", Name: "dev"}, Message: "Add main\n"})
+	case p == "pull-requests/3.diff":
+		_, _ = w.Write([]byte("diff --git a/main.go b/main.go\nnew file mode 100644\n--- /dev/null\n+++ b/main.go\n" +
+			"@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n" +
+			"diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package gone\n" +
+			"diff --git a/old/lib.go b/src/lib.go\nrename from old/lib.go\nrename to src/lib.go\n--- a/old/lib.go\n" +
+			"+++ b/src/lib.go\n@@ -1 +1 @@\n-package old\n+package src\n"))
+	case p == "pull-requests/3/changes":
+		if r.URL.Query().Get("start") == "0" {
+			s.page(w, []bitbucketChange{
+				{NodeType: "FILE", Path: bitbucketPath{ToString: "main.go"}, Type: bitbucketAdd},
+				{NodeType: "FILE", Path: bitbucketPath{ToString: "gone.go"}, Type: bitbucketDelete},
+			}, false)
+			return
+		}
+		s.page(w, []bitbucketChange{
+			{NodeType: "FILE", Path: bitbucketPath{ToString: "src/lib.go"}, SrcPath: &bitbucketPath{ToString: "old/lib.go"},
+				Type: "MOVE"},
+			{NodeType: bitbucketSubmodule, Path: bitbucketPath{ToString: "ext"}, Type: "MODIFY"},
+		}, true)
+	case p == "pull-requests/3/activities":
+		s.page(w, []bitbucketActivity{
+			{Action: bitbucketCommented, Comment: &bitbucketComment{ID: 1, Text: "unused", Comments: []bitbucketComment{
+				{Author: bitbucketUser{Name: "dev"}, ID: 2, Text: "/lintflow false-positive", UpdatedDate: 1600000000000},
+			}}, CommentAnchor: &bitbucketAnchor{Line: 3, Path: "main.go", ToHash: commitBitbucket}},
+			{Action: "APPROVED"},
+		}, true)
+	case p == "pull-requests/3/comments":
+		var buf bitbucketCommentInput
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.comments = append(s.comments, buf)
+		w.WriteHeader(http.StatusCreated)
+	case p == "pull-requests/3/participants/lintflow" && r.Method == http.MethodPut:
+		var buf bitbucketParticipant
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.statuses = append(s.statuses, buf)
+	case p == "pull-requests/3/participants" && r.Method == http.MethodPost:
+		var buf bitbucketParticipant
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.participants = append(s.participants, buf)
+	case strings.HasPrefix(p, "raw/"):
+		if val, ok := content[r.URL.Query().Get("at")+"/"+strings.TrimPrefix(p, "raw/")]; ok {
+			_, _ = w.Write([]byte(val))
+			return
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeBitbucket) page(w http.ResponseWriter, data interface{}, last bool) {
+	buf, _ := json.Marshal(data)
+	s.json(w, bitbucketPage{IsLastPage: last, NextPageStart: 2, Values: buf})
+}
+
+func (s *fakeBitbucket) json(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// nolint: dogsled
+func TestBitbucketFetch(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "bitbucket-test-fetch")
+
+	_, _, _, err := h.Fetch(root, "invalid")
+	assert.NotEqual(t, nil, err)
+
+	dname, rname, files, err := h.Fetch(root, commitBitbucket)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join(root, "3", commitBitbucket), dname)
+	assert.Equal(t, repoBitbucket, rname)
+
+	sort.Strings(files)
+	assert.Equal(t, []string{"main.go" + proto.Base64Content, proto.Base64Message, "src/lib.go" + proto.Base64Content}, files)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dname, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package src\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestBitbucketChange(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{})
+
+	c, err := h.Change(commitBitbucket)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dev@example.com", c.Author)
+	assert.Equal(t, "main", c.Branch)
+	assert.Equal(t, 4, c.Insertions)
+	assert.Equal(t, 2, c.Deletions)
+	assert.Equal(t, "Add main\n", c.Message)
+	assert.Equal(t, 3, c.Number)
+	assert.Equal(t, repoBitbucket, c.Project)
+	assert.Equal(t, map[string]int{"gone.go": 1, "main.go": 3, "src/lib.go": 2}, c.Lines)
+}
+
+func TestBitbucketParent(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "bitbucket-test-parent")
+
+	err := h.Parent(root, commitBitbucket, []string{"main.go" + proto.Base64Content, proto.Base64Message,
+		"src/lib.go" + proto.Base64Content})
+	assert.Equal(t, nil, err)
+
+	_, err = os.Stat(filepath.Join(root, proto.ParentDir, "main.go"+proto.Base64Content))
+	assert.NotEqual(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(root, proto.ParentDir, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package old\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestBitbucketVote(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{Security: config.Security{Reviewers: []string{"security"}},
+		Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}})
+
+	err := h.Vote(commitBitbucket, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(s.statuses))
+	assert.Equal(t, bitbucketApproved, s.statuses[0].Status)
+	assert.Equal(t, "lintflow", s.statuses[0].User.Name)
+	assert.Equal(t, 0, len(s.comments))
+
+	data := []proto.Format{
+		{Details: "unused", File: "main.go", Line: 3, Type: proto.TypeError},
+		{Details: "changed", File: "src/lib.go", Line: 1, Type: proto.TypeWarn},
+		{Category: proto.CategorySecurity, Details: "too long", File: proto.Base64Message, Line: 1, Type: proto.TypeError},
+	}
+
+	err = h.Vote(commitBitbucket, data, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, bitbucketNeedsWork, s.statuses[1].Status)
+	assert.Equal(t, 3, len(s.comments))
+	assert.Equal(t, "main.go", s.comments[0].Anchor.Path)
+	assert.Equal(t, 3, s.comments[0].Anchor.Line)
+	assert.Equal(t, "old/lib.go", s.comments[1].Anchor.SrcPath)
+	assert.Equal(t, (*bitbucketAnchor)(nil), s.comments[2].Anchor)
+	assert.Equal(t, true, strings.Contains(s.comments[2].Text, "too long"))
+	assert.Equal(t, "security", s.participants[0].User.Name)
+	assert.Equal(t, "REVIEWER", s.participants[0].Role)
+
+	err = h.Vote(commitBitbucket, data, proto.ModeComment, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(s.statuses))
+
+	err = h.Vote(commitBitbucket, data, proto.ModeFreeze, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(s.statuses))
+
+	err = h.Vote(commitBitbucket, data, proto.ModeVote, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(s.statuses))
+	assert.Equal(t, true, strings.Contains(s.comments[len(s.comments)-1].Text, "lintflow found 3 findings"))
+}
+
+func TestBitbucketNotify(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{})
+
+	err := h.Notify(commitBitbucket, "hello")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []bitbucketCommentInput{{Text: "hello"}}, s.comments)
+}
+
+func TestBitbucketFeedback(t *testing.T) {
+	s := newFakeBitbucket()
+	defer s.Close()
+
+	h := s.bitbucket(config.Review{})
+
+	buf, err := h.Feedback(commitBitbucket, "/lintflow false-positive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "2", buf[0].ID)
+	assert.Equal(t, "dev", buf[0].Author)
+	assert.Equal(t, "main.go", buf[0].File)
+	assert.Equal(t, 3, buf[0].Line)
+	assert.Equal(t, int64(1600000000), buf[0].Time.Unix())
+}
+
+func TestBitbucketEndpoint(t *testing.T) {
+	h := bitbucket{r: config.Review{Repo: repoBitbucket, Url: "https://example.com/bitbucket/"}}
+	assert.Equal(t, "https://example.com/bitbucket/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/3.diff",
+		h.endpoint(nil, "pull-requests", "3.diff"))
+	assert.Equal(t, "https://example.com/bitbucket/rest/api/1.0/projects/PROJ/repos/repo/raw/src/a%20b.go?at=main",
+		h.urlRaw("src/a b.go", "main"))
+}
@@ -0,0 +1,69 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"context"
+	"net/http"
+)
+
+// bound sends requests within context, e.g., budget of stage in flow, which cancels them once it is done.
+type bound struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// WithContext returns review whose requests to server are bounded by ctx, or review itself if it sends none.
+func WithContext(ctx context.Context, r Review) Review {
+	client := func(c *http.Client) *http.Client {
+		ret := *c
+		ret.Transport = &bound{ctx: ctx, next: c.Transport}
+		return &ret
+	}
+
+	switch h := r.(type) {
+	case *review:
+		ret := *h
+		if h.hdl != nil {
+			ret.hdl = WithContext(ctx, h.hdl)
+		}
+		return &ret
+	case *bitbucket:
+		ret := *h
+		ret.c = client(h.c)
+		return &ret
+	case *gerrit:
+		ret := *h
+		ret.c = client(h.c)
+		return &ret
+	case *github:
+		ret := *h
+		ret.c = client(h.c)
+		return &ret
+	case *gitlab:
+		ret := *h
+		ret.c = client(h.c)
+		return &ret
+	}
+
+	return r
+}
+
+func (b *bound) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := b.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return next.RoundTrip(req.WithContext(b.ctx))
+}
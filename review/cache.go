@@ -0,0 +1,155 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"crypto/sha1" // nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	cacheExt = ".json"
+)
+
+type entry struct {
+	Etag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+// cache keeps file content of revisions, which are immutable so hits skip network unless revalidated by ETag.
+type cache struct {
+	path string
+}
+
+// Stale lists cache entries in path which are older than age.
+func Stale(path string, age time.Duration) ([]string, error) {
+	buf, err := filepath.Glob(filepath.Join(path, "*"+cacheExt))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to glob")
+	}
+
+	var ret []string
+
+	for _, val := range buf {
+		info, err := os.Stat(val)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if time.Since(info.ModTime()) >= age {
+			ret = append(ret, val)
+		}
+	}
+
+	return ret, nil
+}
+
+func (c *cache) name(key string) string {
+	h := sha1.Sum([]byte(key)) // nolint:gosec
+	return filepath.Join(c.path, hex.EncodeToString(h[:])+cacheExt)
+}
+
+func (c *cache) load(key string) (*entry, bool) {
+	buf, err := ioutil.ReadFile(c.name(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, false
+	}
+
+	return &e, true
+}
+
+func (c *cache) remove(key string) {
+	_ = os.Remove(c.name(key))
+}
+
+func (c *cache) save(key string, e *entry) error {
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if err := storage.WriteFile(c.name(key), buf, filePerm); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// content gets file content in key of change/revision/file through cache if enabled.
+func (g *gerrit) content(_url, key string) ([]byte, error) {
+	if g.r.Cache.Path == "" {
+		return g.getReplica(_url)
+	}
+
+	c := cache{path: g.r.Cache.Path}
+
+	e, ok := c.load(key)
+	if ok && !g.r.Cache.Revalidate {
+		return e.Body, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, _url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	if ok && e.Etag != "" {
+		req.Header.Set("If-None-Match", e.Etag)
+	}
+
+	rsp, err := g.fetch(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if ok && rsp.StatusCode == http.StatusNotModified {
+		return e.Body, nil
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		// Drain body to reuse connection
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		return nil, errors.New("invalid status " + rsp.Status)
+	}
+
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	if err := c.save(key, &entry{Etag: rsp.Header.Get("ETag"), Body: data}); err != nil {
+		return nil, errors.Wrap(err, "failed to save")
+	}
+
+	return data, nil
+}
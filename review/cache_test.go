@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestContent(t *testing.T) {
+	d, err := ioutil.TempDir("", "cache")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	count := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "\"v1\"" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "\"v1\"")
+		_, _ = w.Write([]byte("Y29udGVudA=="))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Cache: config.Cache{Path: d}}}
+
+	buf, err := g.content(ts.URL, "1/abc/main.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Y29udGVudA==", string(buf))
+	assert.Equal(t, 1, count)
+
+	buf, err = g.content(ts.URL, "1/abc/main.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Y29udGVudA==", string(buf))
+	assert.Equal(t, 1, count)
+
+	g.r.Cache.Revalidate = true
+
+	buf, err = g.content(ts.URL, "1/abc/main.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Y29udGVudA==", string(buf))
+	assert.Equal(t, 2, count)
+
+	g.r.Cache.Path = ""
+
+	_, err = g.content(ts.URL, "1/abc/main.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, count)
+
+	g.r.Cache.Path = d
+
+	_, err = g.content(ts.URL+"/missing", "1/abc/missing.go")
+	assert.Equal(t, "invalid status 404 Not Found", err.Error())
+}
+
+func TestStale(t *testing.T) {
+	d, err := ioutil.TempDir("", "cache")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	c := cache{path: d}
+
+	err = c.save("old", &entry{})
+	assert.Equal(t, nil, err)
+
+	err = c.save("new", &entry{})
+	assert.Equal(t, nil, err)
+
+	ti := time.Now().Add(-48 * time.Hour)
+	_ = os.Chtimes(c.name("old"), ti, ti)
+
+	buf, err := Stale(d, 24*time.Hour)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{c.name("old")}, buf)
+
+	buf, err = Stale(filepath.Join(d, "missing"), 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
@@ -0,0 +1,144 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	capabilityUnknown = "unknown"
+)
+
+var (
+	// Minimum version of Gerrit with attention set
+	attentionVersion = []int{3, 3}
+	// Minimum version of Gerrit with patchset-level comments
+	patchsetVersion = []int{3, 2}
+	// Minimum version of Gerrit with robot comments
+	robotVersion = []int{2, 14}
+)
+
+var (
+	capabilities = map[string]*capability{}
+	capabilityMu sync.Mutex
+)
+
+type capability struct {
+	AttentionSet     bool
+	PatchsetComments bool
+	Plugins          []string
+	RobotComments    bool
+	Version          string
+}
+
+// capability probes Gerrit once per server, and falls back to the conservative set on older or locked servers.
+func (g *gerrit) capability() *capability {
+	capabilityMu.Lock()
+	defer capabilityMu.Unlock()
+
+	key := g.base()
+
+	if c, ok := capabilities[key]; ok {
+		return c
+	}
+
+	c := &capability{Version: capabilityUnknown}
+
+	if v, err := g.version(); err == nil {
+		c.Version = v
+		c.AttentionSet = versionAtLeast(v, attentionVersion)
+		c.PatchsetComments = versionAtLeast(v, patchsetVersion)
+		c.RobotComments = versionAtLeast(v, robotVersion)
+	}
+
+	if p, err := g.plugins(); err == nil {
+		c.Plugins = p
+	}
+
+	plugins := capabilityUnknown
+	if c.Plugins != nil {
+		plugins = strings.Join(c.Plugins, ",")
+	}
+
+	log.Printf("gerrit capability on %s: version=%s plugins=%s robot_comments=%t patchset_comments=%t attention_set=%t",
+		key, c.Version, plugins, c.RobotComments, c.PatchsetComments, c.AttentionSet)
+
+	capabilities[key] = c
+
+	return c
+}
+
+func (g *gerrit) version() (string, error) {
+	buf, err := g.get(g.urlVersion())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get")
+	}
+
+	var v string
+
+	if err := decode(buf, &v); err != nil {
+		return "", errors.Wrap(err, "failed to decode")
+	}
+
+	return v, nil
+}
+
+// plugins lists plugins, which requires permission of viewing plugins on Gerrit.
+func (g *gerrit) plugins() ([]string, error) {
+	buf, err := g.get(g.urlPlugins())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	p := map[string]interface{}{}
+
+	if err := decode(buf, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	ret := []string{}
+
+	for key := range p {
+		ret = append(ret, key)
+	}
+
+	sort.Strings(ret)
+
+	return ret, nil
+}
+
+func versionAtLeast(version string, minimum []int) bool {
+	buf := strings.Split(strings.SplitN(version, "-", 2)[0], ".")
+
+	for index, val := range minimum {
+		if index >= len(buf) {
+			return false
+		}
+		n, err := strconv.Atoi(buf[index])
+		if err != nil {
+			return false
+		}
+		if n != val {
+			return n > val
+		}
+	}
+
+	return true
+}
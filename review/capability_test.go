@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func initCapability(t *testing.T, h http.HandlerFunc) (*gerrit, func()) {
+	ts := httptest.NewServer(h)
+
+	u, err := url.Parse(ts.URL)
+	assert.Equal(t, nil, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Equal(t, nil, err)
+
+	return &gerrit{c: http.DefaultClient, r: config.Review{Host: "http://" + u.Hostname(), Port: port}}, ts.Close
+}
+
+func TestCapability(t *testing.T) {
+	g, done := initCapability(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config/server/version":
+			_, _ = w.Write([]byte(")]}'\n\"3.4.1\""))
+		case "/plugins/":
+			_, _ = w.Write([]byte(")]}'\n{\"checks\":{},\"replication\":{}}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer done()
+
+	c := g.capability()
+	assert.Equal(t, "3.4.1", c.Version)
+	assert.Equal(t, []string{"checks", "replication"}, c.Plugins)
+	assert.Equal(t, true, c.AttentionSet)
+	assert.Equal(t, true, c.RobotComments)
+}
+
+func TestCapabilityUnknown(t *testing.T) {
+	g, done := initCapability(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer done()
+
+	c := g.capability()
+	assert.Equal(t, capabilityUnknown, c.Version)
+	assert.Equal(t, []string(nil), c.Plugins)
+	assert.Equal(t, false, c.AttentionSet)
+	assert.Equal(t, false, c.RobotComments)
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.Equal(t, true, versionAtLeast("2.14", robotVersion))
+	assert.Equal(t, true, versionAtLeast("2.16.27-RC1", robotVersion))
+	assert.Equal(t, true, versionAtLeast("3.0.0", robotVersion))
+	assert.Equal(t, false, versionAtLeast("2.13.9", robotVersion))
+	assert.Equal(t, false, versionAtLeast("2", robotVersion))
+	assert.Equal(t, false, versionAtLeast(capabilityUnknown, robotVersion))
+}
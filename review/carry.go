@@ -0,0 +1,54 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// carry maps finding on patch before onto patch after by offsets of hunks in the same order, which is false if file
+// differs in hunks or line is out of hunks.
+func carry(data proto.Format, before, after []*diff.File) (proto.Format, bool) {
+	if data.File == "" || data.Scope == proto.ScopeChange {
+		return data, true
+	}
+
+	b, a := find(before, data.File), find(after, data.File)
+	if b == nil || a == nil || len(b.Hunks) != len(a.Hunks) {
+		return data, false
+	}
+
+	if data.Line == 0 {
+		return data, true
+	}
+
+	for index, val := range b.Hunks {
+		if data.Line >= val.NewStart && data.Line < val.NewStart+val.NewLines {
+			data.Line += a.Hunks[index].NewStart - val.NewStart
+			return data, true
+		}
+	}
+
+	return data, false
+}
+
+func find(diffs []*diff.File, name string) *diff.File {
+	for _, val := range diffs {
+		if val.New == name {
+			return val
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestCarry(t *testing.T) {
+	before := []*diff.File{{New: "main.go", Hunks: []*diff.Hunk{{NewStart: 10, NewLines: 3}, {NewStart: 40, NewLines: 2}}}}
+	after := []*diff.File{{New: "main.go", Hunks: []*diff.Hunk{{NewStart: 15, NewLines: 3}, {NewStart: 42, NewLines: 2}}}}
+
+	buf, ok := carry(proto.Format{File: "main.go", Line: 11}, before, after)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 16, buf.Line)
+
+	buf, ok = carry(proto.Format{File: "main.go", Line: 41}, before, after)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 43, buf.Line)
+
+	_, ok = carry(proto.Format{File: "main.go", Line: 20}, before, after)
+	assert.Equal(t, false, ok)
+
+	buf, ok = carry(proto.Format{File: "main.go"}, before, after)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 0, buf.Line)
+
+	_, ok = carry(proto.Format{File: "util.go", Line: 1}, before, after)
+	assert.Equal(t, false, ok)
+
+	_, ok = carry(proto.Format{File: "main.go", Line: 11}, before, after[:0])
+	assert.Equal(t, false, ok)
+
+	_, ok = carry(proto.Format{File: "main.go", Line: 11}, before,
+		[]*diff.File{{New: "main.go", Hunks: []*diff.Hunk{{NewStart: 15, NewLines: 3}}}})
+	assert.Equal(t, false, ok)
+
+	buf, ok = carry(proto.Format{Details: "change"}, before, after)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "change", buf.Details)
+}
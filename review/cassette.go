@@ -0,0 +1,137 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"crypto/sha1" // nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	cassetteExt = ".json"
+)
+
+// episode is one recorded response, request headers are never recorded to keep credentials out of cassette.
+type episode struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cassette records responses from review server into path, or replays them from path without network.
+type cassette struct {
+	next   http.RoundTripper
+	path   string
+	replay bool
+}
+
+func (c *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		buf, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+		_ = req.Body.Close()
+		body = buf
+		req.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	}
+
+	name := filepath.Join(c.path, c.key(req.Method, req.URL.String(), body)+cassetteExt)
+
+	if c.replay {
+		return c.load(req, name)
+	}
+
+	rsp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	rsp.Body = ioutil.NopCloser(bytes.NewReader(buf))
+
+	if err := c.save(name, &episode{Method: req.Method, Url: req.URL.String(), Status: rsp.StatusCode,
+		Header: rsp.Header, Body: buf}); err != nil {
+		return nil, errors.Wrap(err, "failed to save")
+	}
+
+	return rsp, nil
+}
+
+func (c *cassette) key(method, _url string, body []byte) string {
+	h := sha1.New() // nolint:gosec
+	_, _ = h.Write([]byte(method + " " + _url + "\n"))
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *cassette) load(req *http.Request, name string) (*http.Response, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to replay "+req.Method+" "+req.URL.String())
+	}
+
+	var e episode
+
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *cassette) save(name string, data *episode) error {
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), dirPerm); err != nil {
+		return errors.Wrap(err, "failed to mkdir")
+	}
+
+	if err := ioutil.WriteFile(name, buf, filePerm); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassette(t *testing.T) {
+	d, err := ioutil.TempDir("", "cassette")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte(")]}'\n"), buf...))
+	}))
+
+	record := &http.Client{Transport: &cassette{next: http.DefaultTransport, path: d}}
+
+	rsp, err := record.Post(ts.URL+"/a/changes/1/revisions/1/review", "application/json", bytes.NewBufferString("{}"))
+	assert.Equal(t, nil, err)
+	buf, _ := ioutil.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+	assert.Equal(t, ")]}'\n{}", string(buf))
+
+	ts.Close()
+
+	replay := &http.Client{Transport: &cassette{path: d, replay: true}}
+
+	rsp, err = replay.Post(ts.URL+"/a/changes/1/revisions/1/review", "application/json", bytes.NewBufferString("{}"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	buf, _ = ioutil.ReadAll(rsp.Body)
+	_ = rsp.Body.Close()
+	assert.Equal(t, ")]}'\n{}", string(buf))
+
+	_, err = replay.Post(ts.URL+"/a/changes/1/revisions/1/review", "application/json", bytes.NewBufferString("{\"foo\":1}"))
+	assert.NotEqual(t, nil, err)
+}
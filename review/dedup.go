@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	dedupSimilarity = 0.5
+)
+
+// stopWords are common words of comments, which carry nothing of findings to compare.
+var stopWords = map[string]bool{
+	"and": true, "are": true, "but": true, "can": true, "could": true, "does": true, "for": true, "from": true,
+	"has": true, "have": true, "here": true, "how": true, "its": true, "not": true, "please": true, "should": true,
+	"that": true, "the": true, "there": true, "this": true, "was": true, "what": true, "when": true, "where": true,
+	"which": true, "who": true, "why": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// remark is comment on line of file left by reviewer, which is not the bot itself.
+type remark struct {
+	Author  string
+	File    string
+	Line    int
+	Message string
+}
+
+// duplicate reports whether finding has been raised by reviewer already, in remark within range lines of finding,
+// which mentions rule of finding or is similar to its details in words.
+func duplicate(data *proto.Format, remarks []remark, cfg *config.Dedup) bool {
+	if !cfg.Enabled || data.Line == 0 {
+		return false
+	}
+
+	threshold := cfg.Similarity
+	if threshold <= 0 {
+		threshold = dedupSimilarity
+	}
+
+	for _, item := range remarks {
+		if item.File != data.File || item.Line-data.Line > cfg.Range || data.Line-item.Line > cfg.Range {
+			continue
+		}
+		if data.Rule != "" && strings.Contains(strings.ToLower(item.Message), strings.ToLower(data.Rule)) {
+			return true
+		}
+		if similarity(item.Message, data.Details) >= threshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// similarity returns Jaccard index of words in a and b, i.e. shared words over all words of both, in which short
+// words and stop words are left out.
+func similarity(a, b string) float64 {
+	split := func(s string) map[string]bool {
+		ret := map[string]bool{}
+		for _, item := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(item) > 2 && !stopWords[item] {
+				ret[item] = true
+			}
+		}
+		return ret
+	}
+
+	x, y := split(a), split(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+
+	n := 0
+
+	for key := range x {
+		if y[key] {
+			n++
+		}
+	}
+
+	return float64(n) / float64(len(x)+len(y)-n)
+}
+
+// human reports whether author is not the bot, whose name is user of review.
+func human(author, user string) bool {
+	return author != "" && !strings.EqualFold(author, user)
+}
+
+// remarks gets comments of reviewers on commit in change.
+func (g *gerrit) remarks(change int, commit string) ([]remark, error) {
+	r, err := g.get(g.urlComments(change))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	comments := map[string][]commentInfo{}
+
+	if err := decode(r, &comments); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	var ret []remark
+
+	for key, val := range comments {
+		for _, item := range val {
+			if item.CommitID != "" && item.CommitID != commit {
+				continue
+			}
+			author := item.Author.Username
+			if author == "" {
+				author = item.Author.Email
+			}
+			if human(author, g.r.User) {
+				ret = append(ret, remark{Author: author, File: key, Line: item.Line, Message: item.Message})
+			}
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,85 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestDuplicate(t *testing.T) {
+	remarks := []remark{
+		{Author: "dev", File: "main.go", Line: 3, Message: "Please check the error returned by Close"},
+		{Author: "dev", File: "main.go", Line: 10, Message: "errcheck complains here"},
+	}
+
+	data := proto.Format{File: "main.go", Line: 3, Details: "error returned by Close is not checked"}
+
+	assert.Equal(t, false, duplicate(&data, remarks, &config.Dedup{}))
+	assert.Equal(t, true, duplicate(&data, remarks, &config.Dedup{Enabled: true}))
+
+	data.Line = 5
+	assert.Equal(t, false, duplicate(&data, remarks, &config.Dedup{Enabled: true}))
+	assert.Equal(t, true, duplicate(&data, remarks, &config.Dedup{Enabled: true, Range: 2}))
+
+	data = proto.Format{File: "main.go", Line: 10, Details: "unchecked result", Rule: "errcheck"}
+	assert.Equal(t, true, duplicate(&data, remarks, &config.Dedup{Enabled: true}))
+
+	data = proto.Format{File: "main.go", Line: 3, Details: "line is too long"}
+	assert.Equal(t, false, duplicate(&data, remarks, &config.Dedup{Enabled: true}))
+
+	data = proto.Format{File: "main.go", Details: "error returned by Close is not checked"}
+	assert.Equal(t, false, duplicate(&data, remarks, &config.Dedup{Enabled: true, Range: 10}))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, float64(0), similarity("", "text"))
+	assert.Equal(t, float64(0), similarity("Why this?", "Why is this variable unused"))
+	assert.Equal(t, float64(1), similarity("Unused variable", "variable is unused"))
+	assert.Equal(t, 0.25, similarity("unused variable", "variable foo is declared"))
+	assert.Equal(t, 0.5, similarity("variable unused", "Why is this variable of foo bar unused"))
+}
+
+func TestHuman(t *testing.T) {
+	assert.Equal(t, false, human("", "bot"))
+	assert.Equal(t, false, human("Bot", "bot"))
+	assert.Equal(t, true, human("dev", "bot"))
+}
+
+func TestRemarks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/changes/41/comments":
+			_, _ = w.Write([]byte(")]}'\n" + `{"main.go":[` +
+				`{"id":"a","author":{"username":"bot"},"commit_id":"` + commitGerrit + `","line":3,"message":"text"},` +
+				`{"id":"b","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","line":5,"message":"nit"},` +
+				`{"id":"c","author":{"username":"dev"},"commit_id":"other","line":3,"message":"old"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), r: config.Review{Url: ts.URL, User: "bot"}}
+
+	buf, err := g.remarks(41, commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []remark{{Author: "dev@example.com", File: "main.go", Line: 5, Message: "nit"}}, buf)
+}
@@ -0,0 +1,29 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestMessage(t *testing.T) {
+	item := proto.Format{Details: "Double quote to prevent globbing", Rule: "SC2086"}
+	assert.Equal(t, "Double quote to prevent globbing", message(&item))
+
+	item.Docs = "https://www.shellcheck.net/wiki/SC2086"
+	assert.Equal(t, "Double quote to prevent globbing\n\nDocs: https://www.shellcheck.net/wiki/SC2086", message(&item))
+}
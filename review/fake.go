@@ -0,0 +1,259 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	fakeAuthor = "fake@example.com"
+	fakeBranch = "master"
+	fakeRepo   = "fake"
+)
+
+var (
+	fakeChange = map[string]string{
+		proto.Base64Message:                   "Fake change for lintflow\n\nChange-Id: I0000000000000000000000000000000000000000\n",
+		"main.go" + proto.Base64Content:       "package main\n\n// lintflow:Error Fake error by lintflow\nfunc main() {}\n",
+		"doc/README.md" + proto.Base64Content: "# Fake\n\nlintflow:Warn Fake warning by lintflow\n",
+	}
+)
+
+// fake serves the canned change in path (or the built-in one) and logs votes instead of posting them,
+// which is used to test policies and vote logic without a live review server.
+type fake struct {
+	hashtags []string
+	p        policy.Policy
+	r        config.Review
+	s        storage.Storage
+	vote     *reviewInput
+}
+
+// Carry keeps findings as is, since files of the canned change are the same in all revisions.
+func (f *fake) Carry(_, _ string, data []proto.Format) ([]proto.Format, error) {
+	return data, nil
+}
+
+// Change reports all lines of files in change as inserted.
+func (f *fake) Change(commit string) (proto.Change, error) {
+	ret := proto.Change{Author: fakeAuthor, Branch: fakeBranch, Commit: commit,
+		CommitAuthor: fakeAuthor, Lines: map[string]int{}, Number: 1,
+		Project: fakeRepo}
+
+	change, err := f.change()
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to change")
+	}
+
+	for key, val := range change {
+		dec, err := base64.StdEncoding.DecodeString(string(val))
+		if err != nil {
+			return proto.Change{}, errors.Wrap(err, "failed to decode")
+		}
+		if key == proto.Base64Message {
+			ret.Message = string(dec)
+			continue
+		}
+		n := strings.Count(string(dec), "\n")
+		ret.Lines[strings.TrimSuffix(key, proto.Base64Content)] = n
+		ret.Insertions += n
+	}
+
+	return ret, nil
+}
+
+func (f *fake) Clean(name string) error {
+	if err := f.s.Remove(name); err != nil {
+		return errors.Wrap(err, "failed to clean")
+	}
+
+	return nil
+}
+
+func (f *fake) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
+	base := filepath.Join(root, commit)
+
+	change, err := f.change()
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to change")
+	}
+
+	var files []string
+
+	for key, val := range change {
+		if err := f.s.Write(filepath.Join(base, filepath.FromSlash(key)), val); err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to write")
+		}
+		files = append(files, key)
+	}
+
+	sort.Strings(files)
+
+	return base, fakeRepo, files, nil
+}
+
+// Parent writes files of the canned change in parent directory, which are the same in all revisions.
+func (f *fake) Parent(dir, _ string, files []string) error {
+	change, err := f.change()
+	if err != nil {
+		return errors.Wrap(err, "failed to change")
+	}
+
+	for _, val := range files {
+		if _, ok := change[val]; !ok || val == proto.Base64Message {
+			continue
+		}
+		if err := f.s.Write(filepath.Join(dir, proto.ParentDir, filepath.FromSlash(val)), change[val]); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+// Feedback replies with keyword to the fake error in main.go.
+func (f *fake) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if keyword == "" {
+		return nil, nil
+	}
+
+	return []proto.Feedback{{ID: "fake-" + commit, Author: fakeAuthor, Commit: commit, File: "main.go", Line: 3}}, nil
+}
+
+func (f *fake) Notify(commit, message string) error {
+	f.hashtags, f.vote = nil, &reviewInput{Message: message}
+
+	log.Printf("fake notify on %s: %s", commit, message)
+
+	return nil
+}
+
+func (f *fake) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	comments := map[string][]commentInput{}
+
+	var m []proto.Format
+
+	for _, item := range data {
+		if item.Details == "" {
+			continue
+		}
+		comments[item.File] = append(comments[item.File], commentInput{Line: item.Line, Message: message(&item)})
+		m = append(m, item)
+	}
+
+	for key, val := range comments {
+		buf := pack(val, &f.r.Vote)
+		for index := range buf {
+			link := ""
+			if f.r.Vote.Link != "" {
+				link = detail(f.r.Vote.Link, commit, key, buf[index].Line)
+			}
+			buf[index].Message = truncate(buf[index].Message, f.r.Vote.CommentSize, link)
+		}
+		comments[key] = buf
+	}
+
+	f.hashtags = nil
+	f.vote = &reviewInput{Comments: comments, Labels: labels(m, &f.r.Vote, f.p), Message: f.r.Vote.Message}
+
+	attention(f.vote, disapproving(f.vote.Labels, &f.r.Vote), fakeAuthor, &f.r.Vote)
+
+	switch mode {
+	case proto.ModeComment:
+		f.vote.Labels = nil
+	case proto.ModeFreeze:
+		f.vote.Labels = approving(f.vote.Labels, &f.r.Vote)
+	case proto.ModeFreezeVote:
+		f.vote.Labels = approving(f.vote.Labels, &f.r.Vote)
+		f.vote.Comments, f.vote.Message = nil, summary(f.r.Vote.Message, data)
+	case proto.ModeVote:
+		f.vote.Comments, f.vote.Message = nil, summary(f.r.Vote.Message, data)
+	}
+
+	f.vote.Message = annotate(f.vote.Message, note)
+
+	if note != nil && note.Quiet {
+		f.vote.Notify = notifyNone
+	}
+
+	if f.r.Vote.Draft {
+		f.vote.Drafts = draftsPublish
+	}
+
+	if security(data, &f.r.Security) {
+		f.vote.Reviewers = reviewers(&f.r.Security)
+		f.hashtags = []string{f.r.Security.Hashtag}
+	}
+
+	buf, err := json.Marshal(f.vote)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	log.Printf("fake vote on %s: %s", commit, string(buf))
+
+	return nil
+}
+
+// change returns files of change in workspace layout, i.e. base64 encoded with suffix.
+func (f *fake) change() (map[string][]byte, error) {
+	buf := map[string][]byte{}
+
+	if f.r.Path == "" {
+		for key, val := range fakeChange {
+			buf[key] = []byte(base64.StdEncoding.EncodeToString([]byte(val)))
+		}
+		return buf, nil
+	}
+
+	err := filepath.Walk(f.r.Path, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(f.r.Path, name)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		buf[filepath.ToSlash(rel)] = b
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to walk")
+	}
+
+	if len(buf) == 0 {
+		return nil, errors.New("invalid change")
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,199 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	fakePath = "../tests/gerrit-2021-03-06/21/c5d3440911e06ed4fc60252bd89e7756f9ae67ee"
+)
+
+func initFake() *fake {
+	return &fake{
+		p: policy.New(policy.DefaultConfig()),
+		r: config.Review{
+			Name: reviewFake,
+			Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review", Message: "Voting Code-Review by lintflow"},
+		},
+		s: storage.New(storage.DefaultConfig()),
+	}
+}
+
+func TestFakeFetch(t *testing.T) {
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "fake-test-fetch")
+
+	f := initFake()
+
+	dir, repo, files, err := f.Fetch(root, commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, fakeRepo, repo)
+	assert.Equal(t, []string{"doc/README.md.base64", "main.go.base64", proto.Base64Message}, files)
+
+	_, err = os.Stat(filepath.Join(dir, "doc", "README.md.base64"))
+	assert.Equal(t, nil, err)
+
+	f.r.Path = fakePath
+
+	_, _, files, err = f.Fetch(root, commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, files, "src/com/android/settings/ActivityPicker.java.base64")
+
+	f.r.Path = "invalid"
+
+	_, _, _, err = f.Fetch(root, commitGerrit)
+	assert.NotEqual(t, nil, err)
+
+	err = f.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestFakeVote(t *testing.T) {
+	f := initFake()
+
+	err := f.Vote(commitGerrit, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, f.vote.Labels)
+
+	err = f.Vote(commitGerrit, []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "text"}}, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "-1"}, f.vote.Labels)
+}
+
+func TestFakeNotify(t *testing.T) {
+	f := initFake()
+
+	err := f.Notify(commitGerrit, "lintflow could not run lintcpp: timeout")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &reviewInput{Message: "lintflow could not run lintcpp: timeout"}, f.vote)
+}
+
+func TestFakeVoteTruncate(t *testing.T) {
+	f := initFake()
+	f.r.Vote.CommentSize = 60
+	f.r.Vote.Link = "https://example.com/{commit}"
+
+	err := f.Vote("abc", []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: strings.Repeat("text ", 20)}},
+		proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+
+	buf := f.vote.Comments["main.go"][0].Message
+	assert.Equal(t, true, len(buf) <= 60)
+	assert.Equal(t, true, strings.HasSuffix(buf, "see https://example.com/abc)"))
+}
+
+func TestFakeVoteMode(t *testing.T) {
+	f := initFake()
+	data := []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "text"}}
+
+	err := f.Vote(commitGerrit, data, proto.ModeComment, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}(nil), f.vote.Labels)
+	assert.Equal(t, 1, len(f.vote.Comments))
+
+	err = f.Vote(commitGerrit, data, proto.ModeVote, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "-1"}, f.vote.Labels)
+	assert.Equal(t, map[string][]commentInput(nil), f.vote.Comments)
+	assert.Equal(t, "Voting Code-Review by lintflow\n\nlintflow found 1 findings: 1 Error", f.vote.Message)
+
+	err = f.Vote(commitGerrit, data, proto.ModeFreeze, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}(nil), f.vote.Labels)
+	assert.Equal(t, 1, len(f.vote.Comments))
+
+	err = f.Vote(commitGerrit, nil, proto.ModeFreeze, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, f.vote.Labels)
+
+	err = f.Vote(commitGerrit, data, proto.ModeFreezeVote, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}(nil), f.vote.Labels)
+	assert.Equal(t, map[string][]commentInput(nil), f.vote.Comments)
+	assert.Equal(t, "Voting Code-Review by lintflow\n\nlintflow found 1 findings: 1 Error", f.vote.Message)
+
+	err = f.Vote(commitGerrit, data, proto.ModeVote, &proto.Note{Text: "Change size XS: 1 files, 3 lines"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Voting Code-Review by lintflow\n\nlintflow found 1 findings: 1 Error\n\nChange size XS: 1 files, 3 lines",
+		f.vote.Message)
+	assert.Equal(t, "", f.vote.Notify)
+
+	err = f.Vote(commitGerrit, data, proto.ModeFull, &proto.Note{Quiet: true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, notifyNone, f.vote.Notify)
+}
+
+func TestFakeVotePolicy(t *testing.T) {
+	f := initFake()
+	f.p = policy.New(&policy.Config{Policy: config.Policy{Threshold: map[string]int{proto.TypeError: 2}}})
+
+	err := f.Vote(commitGerrit, []proto.Format{{File: "main.go", Line: 3, Type: proto.TypeError, Details: "text"}}, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, f.vote.Labels)
+}
+
+func TestFakeChange(t *testing.T) {
+	f := initFake()
+
+	c, err := f.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proto.Change{Author: fakeAuthor, Branch: fakeBranch, Commit: commitGerrit, CommitAuthor: fakeAuthor,
+		Insertions: 7, Lines: map[string]int{"doc/README.md": 3, "main.go": 4}, Message: "Fake change for lintflow\n\nChange-Id: " +
+			"I0000000000000000000000000000000000000000\n", Number: 1, Project: fakeRepo}, c)
+}
+
+func TestFakeParent(t *testing.T) {
+	f := initFake()
+
+	d, err := ioutil.TempDir("", "fake-test-parent")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = f.Parent(d, commitGerrit, []string{proto.Base64Message, "main.go" + proto.Base64Content, "invalid.go"})
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(d, proto.ParentDir, "main.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(fakeChange["main.go"+proto.Base64Content])), string(buf))
+
+	_, err = os.Stat(filepath.Join(d, proto.ParentDir, proto.Base64Message))
+	assert.Equal(t, true, os.IsNotExist(err))
+}
+
+func TestFakeFeedback(t *testing.T) {
+	f := initFake()
+
+	buf, err := f.Feedback(commitGerrit, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	buf, err = f.Feedback(commitGerrit, "/lintflow false-positive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Feedback{{ID: "fake-" + commitGerrit, Author: fakeAuthor, Commit: commitGerrit, File: "main.go",
+		Line: 3}}, buf)
+}
@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	// Timestamp of Gerrit in UTC, e.g. 2013-02-26 15:40:43.986000000
+	timeLayout = "2006-01-02 15:04:05.000000000"
+)
+
+// Feedback gets replies to comments of user of review on commit, which contain keyword, e.g. /lintflow false-positive.
+// Replies are on file and line of comments replied to, and carry messages of them, while replies in threads of
+// others are ignored.
+func (g *gerrit) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if keyword == "" {
+		return nil, nil
+	}
+
+	c, err := g.query("commit:" + commit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+
+	r, err := g.get(g.urlComments(c.Number))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	comments := map[string][]commentInfo{}
+
+	if err := decode(r, &comments); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	parents := map[string]commentInfo{}
+
+	for _, val := range comments {
+		for _, item := range val {
+			parents[item.ID] = item
+		}
+	}
+
+	var ret []proto.Feedback
+
+	for key, val := range comments {
+		for _, item := range val {
+			if item.InReplyTo == "" || !strings.Contains(item.Message, keyword) {
+				continue
+			}
+			if item.CommitID != "" && item.CommitID != commit {
+				continue
+			}
+			parent, ok := parents[item.InReplyTo]
+			if !ok {
+				continue
+			}
+			author := parent.Author.Username
+			if author == "" {
+				author = parent.Author.Email
+			}
+			if author == "" || human(author, g.r.User) {
+				continue
+			}
+			t, _ := time.Parse(timeLayout, item.Updated)
+			ret = append(ret, proto.Feedback{ID: item.ID, Author: item.Author.Email, Comment: parent.Message, Commit: commit,
+				File: key, Line: parent.Line, Time: t})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+
+	return ret, nil
+}
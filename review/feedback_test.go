@@ -0,0 +1,64 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestFeedback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/changes/":
+			_, _ = w.Write([]byte(")]}'\n" + `[{"_number":41}]`))
+		case "/changes/41/comments":
+			_, _ = w.Write([]byte(")]}'\n" + `{"main.go":[` +
+				`{"id":"a","author":{"email":"bot@example.com","username":"bot"},"commit_id":"` + commitGerrit +
+				`","line":3,"message":"text"},` +
+				`{"id":"e","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","line":3,"message":"nit"},` +
+				`{"id":"f","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","in_reply_to":"e","line":3,` +
+				`"message":"/lintflow false-positive"},` +
+				`{"id":"g","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","in_reply_to":"z","line":3,` +
+				`"message":"/lintflow false-positive"},` +
+				`{"id":"b","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","in_reply_to":"a","line":3,` +
+				`"message":"/lintflow false-positive","updated":"2013-02-26 15:40:43.986000000"},` +
+				`{"id":"c","author":{"email":"dev@example.com"},"commit_id":"other","in_reply_to":"a","line":3,` +
+				`"message":"/lintflow false-positive"},` +
+				`{"id":"d","author":{"email":"dev@example.com"},"commit_id":"` + commitGerrit + `","in_reply_to":"a","line":3,` +
+				`"message":"Done"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), r: config.Review{Url: ts.URL, User: "bot"}}
+
+	buf, err := g.Feedback(commitGerrit, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	buf, err = g.Feedback(commitGerrit, "/lintflow false-positive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Feedback{{ID: "b", Author: "dev@example.com", Comment: "text", Commit: commitGerrit, File: "main.go",
+		Line: 3, Time: time.Date(2013, 2, 26, 15, 40, 43, 986000000, time.UTC)}}, buf)
+}
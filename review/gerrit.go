@@ -13,41 +13,98 @@
 package review
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/reviewdog/reviewdog/diff"
 
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/ignore"
+	"github.com/craftslab/lintflow/policy"
 	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
 )
 
 const (
-	commitMsg = "/COMMIT_MSG"
+	commitMsg     = "/COMMIT_MSG"
+	draftsPublish = "PUBLISH"
+	// Default of change.commentSizeLimit, which is not exposed by REST API of Gerrit
+	gerritCommentSize = 16384
+	notifyNone        = "NONE"
+	patchsetLevel     = "/PATCHSET_LEVEL"
+	robotId           = "lintflow"
 )
 
 const (
-	diffBin    = "Binary files differ"
-	diffSep    = "diff --git"
-	pathPrefix = "b/"
+	diffSep = "diff --git"
 )
 
 type gerrit struct {
+	c *http.Client
+	h *replica
+	p policy.Policy
 	r config.Review
+	s storage.Storage
+}
+
+func (g *gerrit) Change(commit string) (proto.Change, error) {
+	c, err := g.query("commit:"+commit, "ALL_REVISIONS", "CURRENT_COMMIT", "CURRENT_FILES", "DETAILED_ACCOUNTS",
+		"PUSH_CERTIFICATES")
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to query")
+	}
+
+	ret := proto.Change{
+		Author:     c.Owner.Email,
+		Branch:     c.Branch,
+		Commit:     c.CurrentRevision,
+		Deletions:  c.Deletions,
+		Insertions: c.Insertions,
+		Number:     c.Number,
+		Project:    c.Project,
+	}
+
+	// Changed lines of files in current revision
+	if rev, err := c.current(); err == nil {
+		ret.Kind = rev.Kind
+		ret.CommitAuthor = rev.Commit.Author.Email
+		ret.Message = rev.Commit.Message
+		ret.Lines = map[string]int{}
+		for key, val := range rev.Files {
+			ret.Lines[key] = val.LinesInserted + val.LinesDeleted
+		}
+		ret.Previous, _ = c.revision(rev.Number - 1)
+		if p := rev.PushCertificate; p != nil {
+			ret.Signature = &proto.Signature{Problems: p.Key.Problems, Status: p.Key.Status}
+		}
+		if diffs, err := g.patch(c.Number, rev.Number); err == nil {
+			ret.Submodules = submodules(diffs)
+		}
+	}
+
+	if c.Topic != "" {
+		ret.Topic = c.Topic
+		// Related changes are best-effort, which should not fail lint of change itself
+		if ret.Related, err = g.related(c.Topic, c.Number); err != nil {
+			log.Println(errors.Wrap(err, "failed to related"))
+		}
+	}
+
+	return ret, nil
 }
 
 func (g *gerrit) Clean(name string) error {
-	if err := os.RemoveAll(name); err != nil {
+	if err := g.s.Remove(name); err != nil {
 		return errors.Wrap(err, "failed to clean")
 	}
 
@@ -56,65 +113,82 @@ func (g *gerrit) Clean(name string) error {
 
 // nolint:funlen,gocyclo
 func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
-	filterFiles := func(data map[string]interface{}) map[string]interface{} {
-		buf := make(map[string]interface{})
+	filterFiles := func(data map[string]fileInfo) map[string]fileInfo {
+		buf := make(map[string]fileInfo)
 		for key, val := range data {
-			if v, ok := val.(map[string]interface{})["status"]; ok {
-				if v.(string) == "D" || v.(string) == "R" {
-					continue
-				}
+			if val.Status == "D" || val.Status == "R" {
+				continue
 			}
 			buf[key] = val
 		}
 		return buf
 	}
 
+	// Probe capability
+	_ = g.capability()
+
 	// Query commit
-	r, err := g.get(g.urlQuery("commit:"+commit, []string{"CURRENT_REVISION"}, 0))
+	c, err := g.query("commit:"+commit, "CURRENT_REVISION")
 	if err != nil {
 		return "", "", nil, errors.Wrap(err, "failed to query")
 	}
 
-	queryRet, err := g.unmarshalList(r)
+	current, err := c.current()
 	if err != nil {
-		return "", "", nil, errors.Wrap(err, "failed to unmarshalList")
+		return "", "", nil, errors.Wrap(err, "failed to current")
 	}
 
-	revisions := queryRet["revisions"].(map[string]interface{})
-	current := revisions[queryRet["current_revision"].(string)].(map[string]interface{})
+	changeNum := c.Number
+	revisionNum := current.Number
 
-	changeNum := int(queryRet["_number"].(float64))
-	revisionNum := int(current["_number"].(float64))
-
-	path := filepath.Join(root, strconv.Itoa(changeNum), queryRet["current_revision"].(string))
+	base := filepath.Join(root, strconv.Itoa(changeNum), c.CurrentRevision)
 
 	// Get files
-	buf, err := g.get(g.urlFiles(changeNum, revisionNum))
+	buf, err := g.getReplica(g.urlFiles(changeNum, revisionNum))
 	if err != nil {
 		return "", "", nil, errors.Wrap(err, "failed to files")
 	}
 
-	fs, err := g.unmarshal(buf)
-	if err != nil {
-		return "", "", nil, errors.Wrap(err, "failed to unmarshal")
+	fs := map[string]fileInfo{}
+
+	if err := decode(buf, &fs); err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to decode")
+	}
+
+	// Derive files from patch if details of files are omitted
+	if _, ok := fs[commitMsg]; len(fs) == 0 || (ok && len(fs) == 1) {
+		diffs, err := g.patch(changeNum, revisionNum)
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to patch")
+		}
+		fs = g.patchFiles(diffs)
 	}
 
 	// Match files
 	fs = filterFiles(fs)
 
-	// Get content
+	// Match ignore
+	ig := g.ignore(c.Project, c.CurrentRevision)
+
 	for key := range fs {
-		buf, err = g.get(g.urlContent(changeNum, revisionNum, key))
-		if err != nil {
-			return "", "", nil, errors.Wrap(err, "failed to content")
+		if key != commitMsg && ig.Match(key) {
+			delete(fs, key)
 		}
+	}
 
-		file := filepath.Base(key) + proto.Base64Content
+	// Get content
+	for key, val := range fs {
+		info := &val
 		if key == commitMsg {
-			file = proto.Base64Message
+			info = nil
+		}
+		buf, err = g.verified(g.urlContent(changeNum, revisionNum, key), path.Join(strconv.Itoa(changeNum), c.CurrentRevision,
+			key), info)
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to content")
 		}
 
-		err = g.write(filepath.Join(path, filepath.Dir(key)), file, string(buf))
+		err = g.write(base, g.file(key), string(buf))
 		if err != nil {
 			return "", "", nil, errors.Wrap(err, "failed to fetch")
 		}
@@ -124,220 +198,486 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 	var files []string
 
 	for key := range fs {
-		if key == commitMsg {
-			files = append(files, proto.Base64Message)
-		} else {
-			files = append(files, filepath.Join(filepath.Dir(key), filepath.Base(key)+proto.Base64Content))
-		}
+		files = append(files, g.file(key))
 	}
 
-	return path, queryRet["project"].(string), files, nil
+	return base, c.Project, files, nil
 }
 
 // nolint:gocyclo
-func (g *gerrit) Vote(commit string, data []proto.Format) error {
-	match := func(data proto.Format, diffs []*diff.FileDiff) bool {
+func (g *gerrit) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	match := func(data proto.Format, diffs []*diff.File) bool {
 		for _, d := range diffs {
-			if strings.Replace(d.PathNew, pathPrefix, "", 1) != data.File {
+			if d.New != data.File {
 				continue
 			}
-			for _, h := range d.Hunks {
-				for _, l := range h.Lines {
-					if l.Type == diff.LineAdded && l.LnumNew == data.Line {
-						return true
-					}
-				}
+			if data.Line == 0 || d.Added(data.Line) {
+				return true
 			}
 		}
 		return false
 	}
 
-	build := func(data []proto.Format, diffs []*diff.FileDiff) (map[string]interface{}, map[string]interface{}, string) {
+	caps := g.capability()
+	if g.r.Vote.Robot && !caps.RobotComments {
+		log.Println("robot comments unavailable, falling back to comments")
+	}
+
+	size := gerritCommentSize
+	if g.r.Vote.CommentSize > 0 && g.r.Vote.CommentSize < size {
+		size = g.r.Vote.CommentSize
+	}
+
+	var remarks []remark
+
+	build := func(data []proto.Format, diffs []*diff.File) (map[string][]commentInput, map[string]interface{}, string) {
 		if len(data) == 0 {
-			return nil, map[string]interface{}{g.r.Vote.Label: g.r.Vote.Approval}, g.r.Vote.Message
+			return nil, labels(nil, &g.r.Vote, g.p), g.r.Vote.Message
 		}
-		c := map[string]interface{}{}
+		c := map[string][]commentInput{}
+		var m, change []proto.Format
 		for _, item := range data {
-			if item.Details == "" || (item.File != commitMsg && !match(item, diffs)) {
+			if item.Scope == proto.ScopeChange && item.Details != "" {
+				m = append(m, item)
+				if !caps.PatchsetComments || (g.r.Vote.Robot && caps.RobotComments) {
+					change = append(change, item)
+					continue
+				}
+				item.File = patchsetLevel
+			} else if item.Details == "" || (item.File != commitMsg && !match(item, diffs)) {
 				continue
-			}
-			b := map[string]interface{}{"line": item.Line, "message": item.Details}
-			if _, ok := c[item.File]; !ok {
-				c[item.File] = []map[string]interface{}{b}
 			} else {
-				c[item.File] = append(c[item.File].([]map[string]interface{}), b)
+				m = append(m, item)
+				if duplicate(&item, remarks, &g.r.Vote.Dedup) {
+					continue
+				}
+			}
+			b := commentInput{Line: item.Line, Message: message(&item)}
+			if g.r.Vote.Robot && caps.RobotComments {
+				b.RobotID, b.RobotRunID = robotId, commit
 			}
+			c[item.File] = append(c[item.File], b)
+		}
+		for key, val := range c {
+			buf := pack(val, &g.r.Vote)
+			for index := range buf {
+				link := ""
+				if g.r.Vote.Link != "" {
+					link = detail(g.r.Vote.Link, commit, key, buf[index].Line)
+				}
+				buf[index].Message = truncate(buf[index].Message, size, link)
+			}
+			c[key] = buf
+		}
+		if len(m) == 0 {
+			return nil, labels(nil, &g.r.Vote, g.p), g.r.Vote.Message
 		}
 		if len(c) == 0 {
-			return nil, map[string]interface{}{g.r.Vote.Label: g.r.Vote.Approval}, g.r.Vote.Message
-		} else {
-			return c, map[string]interface{}{g.r.Vote.Label: g.r.Vote.Disapproval}, g.r.Vote.Message
+			c = nil
 		}
+		return c, labels(m, &g.r.Vote, g.p), changeLevel(g.r.Vote.Message, change)
 	}
 
 	// Query commit
-	ret, err := g.get(g.urlQuery("commit:"+commit, []string{"CURRENT_REVISION"}, 0))
+	c, err := g.query("commit:"+commit, "CURRENT_REVISION")
 	if err != nil {
 		return errors.Wrap(err, "failed to query")
 	}
 
-	c, err := g.unmarshalList(ret)
+	current, err := c.current()
 	if err != nil {
-		return errors.Wrap(err, "failed to unmarshalList")
+		return errors.Wrap(err, "failed to current")
 	}
 
-	revisions := c["revisions"].(map[string]interface{})
-	current := revisions[c["current_revision"].(string)].(map[string]interface{})
-
 	// Get patch
-	ret, err = g.get(g.urlPatch(int(c["_number"].(float64)), int(current["_number"].(float64))))
+	diffs, err := g.patch(c.Number, current.Number)
 	if err != nil {
 		return errors.Wrap(err, "failed to patch")
 	}
 
-	// Parse diff
-	dec := make([]byte, base64.StdEncoding.DecodedLen(len(ret)))
-	if _, err = base64.StdEncoding.Decode(dec, ret); err != nil {
-		return errors.Wrap(err, "failed to decode")
+	// Get remarks
+	if g.r.Vote.Dedup.Enabled {
+		if remarks, err = g.remarks(c.Number, commit); err != nil {
+			log.Println(errors.Wrap(err, "failed to remarks"))
+		}
 	}
 
-	index := bytes.Index(dec, []byte(diffSep))
-	if index < 0 {
-		return errors.New("failed to index")
+	// Review commit
+	comments, labels, message := build(data, diffs)
+	block := disapproving(labels, &g.r.Vote)
+	switch mode {
+	case proto.ModeComment:
+		labels = nil
+	case proto.ModeFreeze:
+		labels = approving(labels, &g.r.Vote)
+	case proto.ModeFreezeVote:
+		labels = approving(labels, &g.r.Vote)
+		comments, message = nil, summary(message, data)
+	case proto.ModeVote:
+		comments, message = nil, summary(message, data)
+	}
+	message = annotate(message, note)
+	var drafts []string
+	buf := reviewInput{Comments: comments, Labels: labels, Message: message}
+	if g.r.Vote.Robot && caps.RobotComments {
+		buf = reviewInput{Labels: labels, Message: message, RobotComments: comments}
+	} else if g.r.Vote.Draft {
+		if drafts, err = g.drafts(c.Number, current.Number, comments); err != nil {
+			return errors.Wrap(err, "failed to drafts")
+		}
+		buf = reviewInput{Drafts: draftsPublish, Labels: labels, Message: message}
+	}
+	if note != nil && note.Quiet {
+		buf.Notify = notifyNone
+	}
+	if caps.AttentionSet {
+		attention(&buf, block, c.Owner.account(), &g.r.Vote)
+	} else if g.r.Vote.Attention {
+		log.Println("attention set unavailable")
+	}
+	sec := security(data, &g.r.Security)
+	if sec && len(g.r.Security.Reviewers) != 0 {
+		buf.Reviewers = reviewers(&g.r.Security)
+	}
+	if err := g.review(c.Number, current.Number, &buf); err != nil {
+		if e := g.discard(c.Number, current.Number, drafts); e != nil {
+			log.Println(e)
+		}
+		return errors.Wrap(err, "failed to review")
 	}
 
-	var b []byte
+	// Apply hashtag
+	if sec && g.r.Security.Hashtag != "" {
+		if err := g.post(g.urlHashtags(c.Number), &hashtagsInput{Add: []string{g.r.Security.Hashtag}}); err != nil {
+			return errors.Wrap(err, "failed to hashtag")
+		}
+	}
 
-	for _, item := range bytes.SplitAfter(dec[index:], []byte(diffSep)) {
-		if !bytes.Contains(item, []byte(diffBin)) {
-			b = bytes.Join([][]byte{b, item}, []byte(""))
+	return nil
+}
+
+// Carry maps findings on revision of commit from onto revision of commit to in the same change, whose patches are
+// identical except offsets of hunks, e.g., trivial rebase. Findings out of patch are dropped, and findings on commit
+// message are kept only if messages are identical.
+func (g *gerrit) Carry(from, to string, data []proto.Format) ([]proto.Format, error) {
+	c, err := g.query("commit:"+to, "ALL_COMMITS", "ALL_REVISIONS")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+
+	before, ok := c.Revisions[from]
+	if !ok {
+		return nil, errors.New("invalid revision " + from)
+	}
+
+	after, ok := c.Revisions[to]
+	if !ok {
+		return nil, errors.New("invalid revision " + to)
+	}
+
+	diffs := [2][]*diff.File{}
+
+	for index, val := range []int{before.Number, after.Number} {
+		if diffs[index], err = g.patch(c.Number, val); err != nil {
+			return nil, errors.Wrap(err, "failed to patch")
+		}
+	}
+
+	var ret []proto.Format
+
+	for _, item := range data {
+		if item.File == commitMsg {
+			if before.Commit.Message == after.Commit.Message {
+				ret = append(ret, item)
+			}
+			continue
+		}
+		if buf, ok := carry(item, diffs[0], diffs[1]); ok {
+			ret = append(ret, buf)
 		}
 	}
 
-	diffs, err := diff.ParseMultiFile(bytes.NewReader(b))
+	return ret, nil
+}
+
+// Parent writes content of files in parent revision of commit into parent directory of dir, skipping files added.
+func (g *gerrit) Parent(dir, commit string, files []string) error {
+	c, err := g.query("commit:"+commit, "CURRENT_REVISION")
 	if err != nil {
-		return errors.Wrap(err, "failed to parse")
+		return errors.Wrap(err, "failed to query")
 	}
 
-	// Review commit
-	comments, labels, message := build(data, diffs)
-	buf := map[string]interface{}{"comments": comments, "labels": labels, "message": message}
-	if err := g.post(g.urlReview(int(c["_number"].(float64)), int(current["_number"].(float64))), buf); err != nil {
-		return errors.Wrap(err, "failed to review")
+	current, err := c.current()
+	if err != nil {
+		return errors.Wrap(err, "failed to current")
+	}
+
+	buf, err := g.getReplica(g.urlFiles(c.Number, current.Number))
+	if err != nil {
+		return errors.Wrap(err, "failed to files")
+	}
+
+	fs := map[string]fileInfo{}
+
+	if err := decode(buf, &fs); err != nil {
+		return errors.Wrap(err, "failed to decode")
+	}
+
+	for _, val := range files {
+		name := strings.TrimSuffix(val, proto.Base64Content)
+		if info, ok := fs[name]; val == proto.Base64Message || !ok || info.Status == "A" {
+			continue
+		}
+		buf, err := g.getReplica(g.urlParent(c.Number, current.Number, name))
+		if err != nil {
+			return errors.Wrap(err, "failed to content")
+		}
+		if err := g.write(filepath.Join(dir, proto.ParentDir), val, string(buf)); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
 	}
 
 	return nil
 }
 
-func (g *gerrit) write(dir, file, data string) error {
-	_ = os.MkdirAll(dir, os.ModePerm)
+// Notify posts message to current revision of commit without voting.
+func (g *gerrit) Notify(commit, message string) error {
+	c, err := g.query("commit:"+commit, "CURRENT_REVISION")
+	if err != nil {
+		return errors.Wrap(err, "failed to query")
+	}
 
-	f, err := os.Create(filepath.Join(dir, file))
+	current, err := c.current()
 	if err != nil {
-		return errors.Wrap(err, "failed to create")
+		return errors.Wrap(err, "failed to current")
 	}
-	defer func() { _ = f.Close() }()
 
-	w := bufio.NewWriter(f)
-	if _, err := w.WriteString(data); err != nil {
-		return errors.Wrap(err, "failed to write")
+	if err := g.post(g.urlReview(c.Number, current.Number), &reviewInput{Message: message}); err != nil {
+		return errors.Wrap(err, "failed to review")
 	}
-	defer func() { _ = w.Flush() }()
 
 	return nil
 }
 
-func (g *gerrit) unmarshal(data []byte) (map[string]interface{}, error) {
-	buf := map[string]interface{}{}
+// drafts creates comments as drafts, which are published at once by review, and returns IDs of drafts to discard
+// them if review fails. Drafts left on revision by former runs which failed are discarded first, lest they are
+// published along.
+func (g *gerrit) drafts(change, revision int, comments map[string][]commentInput) ([]string, error) {
+	r, err := g.get(g.urlDrafts(change, revision))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	stale := map[string][]commentInfo{}
 
-	if err := json.Unmarshal(data[4:], &buf); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal")
+	if err := decode(r, &stale); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
 	}
 
-	return buf, nil
-}
+	var ids []string
 
-func (g *gerrit) unmarshalList(data []byte) (map[string]interface{}, error) {
-	var buf []map[string]interface{}
+	for _, val := range stale {
+		for _, item := range val {
+			ids = append(ids, item.ID)
+		}
+	}
 
-	if err := json.Unmarshal(data[4:], &buf); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal")
+	if err := g.discard(change, revision, ids); err != nil {
+		return nil, errors.Wrap(err, "failed to discard")
+	}
+
+	var ret []string
+
+	for key, val := range comments {
+		for _, item := range val {
+			item.Path = key
+			buf, err := g.send(http.MethodPut, g.urlDrafts(change, revision), &item)
+			var info commentInfo
+			if err == nil {
+				err = decode(buf, &info)
+			}
+			if err != nil {
+				if e := g.discard(change, revision, ret); e != nil {
+					log.Println(e)
+				}
+				return nil, errors.Wrap(err, "failed to put")
+			}
+			ret = append(ret, info.ID)
+		}
 	}
 
-	if len(buf) == 0 {
-		return nil, errors.New("failed to match")
+	return ret, nil
+}
+
+// discard deletes drafts of IDs on revision.
+func (g *gerrit) discard(change, revision int, ids []string) error {
+	for _, val := range ids {
+		if err := g.remove(g.urlDraft(change, revision, val)); err != nil {
+			return errors.Wrap(err, "failed to remove "+val)
+		}
 	}
 
-	return buf[0], nil
+	return nil
 }
 
-func (g *gerrit) urlContent(change, revision int, name string) string {
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + strconv.Itoa(change) +
-		"/revisions/" + strconv.Itoa(revision) + "/files/" + url.QueryEscape(name) + "/content"
+func (g *gerrit) patch(change, revision int) ([]*diff.File, error) {
+	ret, err := g.get(g.urlPatch(change, revision))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
 
-	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + strconv.Itoa(change) +
-			"/revisions/" + strconv.Itoa(revision) + "/files/" + url.QueryEscape(name) + "/content"
+	dec := make([]byte, base64.StdEncoding.DecodedLen(len(ret)))
+	if _, err = base64.StdEncoding.Decode(dec, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
 	}
 
-	return buf
+	if !bytes.Contains(dec, []byte(diffSep)) {
+		return nil, errors.New("failed to index")
+	}
+
+	diffs, err := diff.Parse(dec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse")
+	}
+
+	return diffs, nil
 }
 
-func (g *gerrit) urlDetail(change int) string {
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + strconv.Itoa(change) + "/detail"
+// patchFiles derives files in layout of files of revision from patch, excluding deleted files.
+func (g *gerrit) patchFiles(diffs []*diff.File) map[string]fileInfo {
+	buf := map[string]fileInfo{commitMsg: {}}
 
-	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + strconv.Itoa(change) + "/detail"
+	for _, d := range diffs {
+		if d.New == "" || d.Binary {
+			continue
+		}
+		buf[d.New] = fileInfo{}
 	}
 
 	return buf
 }
 
-func (g *gerrit) urlFiles(change, revision int) string {
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + strconv.Itoa(change) +
-		"/revisions/" + strconv.Itoa(revision) + "/files/"
+func (g *gerrit) ignore(project, commit string) ignore.Ignore {
+	buf, err := g.get(g.urlCommitContent(project, commit, ignore.Name))
+	if err != nil {
+		// Missing ignore file in revision
+		return ignore.New(nil)
+	}
 
-	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + strconv.Itoa(change) +
-			"/revisions/" + strconv.Itoa(revision) + "/files/"
+	dec, err := base64.StdEncoding.DecodeString(string(buf))
+	if err != nil {
+		return ignore.New(nil)
 	}
 
-	return buf
+	return ignore.New(dec)
 }
 
-func (g *gerrit) urlPatch(change, revision int) string {
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + strconv.Itoa(change) +
-		"/revisions/" + strconv.Itoa(revision) + "/patch"
-
-	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + strconv.Itoa(change) +
-			"/revisions/" + strconv.Itoa(revision) + "/patch"
+// file maps the slash-separated file key in Gerrit to the slash-separated name in workspace
+func (g *gerrit) file(key string) string {
+	if key == commitMsg {
+		return proto.Base64Message
 	}
 
-	return buf
+	return path.Join(path.Dir(key), path.Base(key)+proto.Base64Content)
 }
 
-func (g *gerrit) urlQuery(search string, option []string, start int) string {
-	query := "?q=" + search + "&o=" + strings.Join(option, "&o=") + "&n=" + strconv.Itoa(start)
+func (g *gerrit) write(root, file, data string) error {
+	if err := g.s.Write(filepath.Join(root, filepath.FromSlash(file)), []byte(data)); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
 
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + query
-	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + query
+	return nil
+}
+
+// base returns URL of Gerrit including optional path prefix, e.g. https://example.com/gerrit
+func (g *gerrit) base() string {
+	if g.r.Url != "" {
+		return strings.TrimSuffix(g.r.Url, "/")
 	}
 
-	return buf
+	return strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port)
 }
 
-func (g *gerrit) urlReview(change, revision int) string {
-	buf := strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/changes/" + strconv.Itoa(change) +
-		"/revisions/" + strconv.Itoa(revision) + "/review"
+// endpoint joins escaped path elements to base, with prefix of authentication if required
+func (g *gerrit) endpoint(query url.Values, elem ...string) string {
+	u, err := url.Parse(g.base())
+	if err != nil {
+		return ""
+	}
+
+	name := []string{strings.TrimSuffix(u.Path, "/")}
+	raw := []string{strings.TrimSuffix(u.EscapedPath(), "/")}
 
 	if g.r.User != "" && g.r.Pass != "" {
-		buf = strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port) + "/a/changes/" + strconv.Itoa(change) +
-			"/revisions/" + strconv.Itoa(revision) + "/review"
+		name, raw = append(name, "a"), append(raw, "a")
 	}
 
-	return buf
+	for _, val := range elem {
+		name, raw = append(name, val), append(raw, url.PathEscape(val))
+	}
+
+	u.Path = strings.Join(name, "/")
+	u.RawPath = strings.Join(raw, "/")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (g *gerrit) urlCommitContent(project, commit, name string) string {
+	return g.endpoint(nil, "projects", project, "commits", commit, "files", name, "content")
+}
+
+func (g *gerrit) urlComments(change int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "comments")
+}
+
+func (g *gerrit) urlContent(change, revision int, name string) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "files", name, "content")
+}
+
+func (g *gerrit) urlParent(change, revision int, name string) string {
+	return g.endpoint(url.Values{"parent": {"1"}}, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision),
+		"files", name, "content")
+}
+
+func (g *gerrit) urlDetail(change int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "detail")
+}
+
+func (g *gerrit) urlDraft(change, revision int, id string) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "drafts", id)
+}
+
+func (g *gerrit) urlDrafts(change, revision int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "drafts")
+}
+
+func (g *gerrit) urlFiles(change, revision int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "files", "")
+}
+
+func (g *gerrit) urlHashtags(change int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "hashtags")
+}
+
+func (g *gerrit) urlPatch(change, revision int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "patch")
+}
+
+func (g *gerrit) urlPlugins() string {
+	return g.endpoint(nil, "plugins", "")
+}
+
+func (g *gerrit) urlQuery(search string, option []string, start int) string {
+	return g.endpoint(url.Values{"q": {search}, "o": option, "n": {strconv.Itoa(start)}}, "changes", "")
+}
+
+func (g *gerrit) urlReview(change, revision int) string {
+	return g.endpoint(nil, "changes", strconv.Itoa(change), "revisions", strconv.Itoa(revision), "review")
+}
+
+func (g *gerrit) urlVersion() string {
+	return g.endpoint(nil, "config", "server", "version")
 }
 
 func (g *gerrit) get(_url string) ([]byte, error) {
@@ -346,21 +686,36 @@ func (g *gerrit) get(_url string) ([]byte, error) {
 		return nil, errors.Wrap(err, "failed to request")
 	}
 
-	if g.r.User != "" && g.r.Pass != "" {
-		req.SetBasicAuth(g.r.User, g.r.Pass)
+	rsp, err := do(g.c, req, &g.r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	return body(rsp)
+}
+
+// getReplica gets files of revision from replicas if any.
+func (g *gerrit) getReplica(_url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, _url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
 	}
 
-	rsp, err := http.DefaultClient.Do(req)
+	rsp, err := g.fetch(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to do")
+		return nil, errors.Wrap(err, "failed to fetch")
 	}
 
+	return body(rsp)
+}
+
+func body(rsp *http.Response) ([]byte, error) {
 	defer func() {
 		_ = rsp.Body.Close()
 	}()
 
 	if rsp.StatusCode != http.StatusOK {
-		return nil, errors.New("invalid status")
+		return nil, invalid(rsp)
 	}
 
 	data, err := ioutil.ReadAll(rsp.Body)
@@ -371,40 +726,69 @@ func (g *gerrit) get(_url string) ([]byte, error) {
 	return data, nil
 }
 
-func (g *gerrit) post(_url string, data map[string]interface{}) error {
-	buf, err := json.Marshal(data)
-	if err != nil {
-		return errors.Wrap(err, "failed to marshal")
+// invalid reports status of response with its body diagnosed, which drains body to reuse connection.
+func invalid(rsp *http.Response) error {
+	buf, _ := ioutil.ReadAll(rsp.Body)
+
+	if msg := diagnose(buf); msg != "" {
+		return errors.New("invalid status " + rsp.Status + ": " + msg)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, _url, bytes.NewBuffer(buf))
-	if err != nil {
-		return errors.Wrap(err, "failed to request")
+	return errors.New("invalid status " + rsp.Status)
+}
+
+func (g *gerrit) post(_url string, data interface{}) error {
+	_, err := g.send(http.MethodPost, _url, data)
+	return err
+}
+
+func (g *gerrit) put(_url string, data interface{}) error {
+	_, err := g.send(http.MethodPut, _url, data)
+	return err
+}
+
+func (g *gerrit) remove(_url string) error {
+	_, err := g.send(http.MethodDelete, _url, nil)
+	return err
+}
+
+func (g *gerrit) send(method, _url string, data interface{}) ([]byte, error) {
+	var buf []byte
+
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal")
+		}
+		buf = b
 	}
 
-	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	req, err := http.NewRequest(method, _url, bytes.NewBuffer(buf))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
 
-	if g.r.User != "" && g.r.Pass != "" {
-		req.SetBasicAuth(g.r.User, g.r.Pass)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json;charset=utf-8")
 	}
 
-	rsp, err := http.DefaultClient.Do(req)
+	rsp, err := do(g.c, req, &g.r)
 	if err != nil {
-		return errors.Wrap(err, "failed to do")
+		return nil, errors.Wrap(err, "failed to do")
 	}
 
 	defer func() {
 		_ = rsp.Body.Close()
 	}()
 
-	if rsp.StatusCode != http.StatusOK {
-		return errors.New("invalid status")
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusCreated && rsp.StatusCode != http.StatusNoContent {
+		return nil, invalid(rsp)
 	}
 
-	_, err = ioutil.ReadAll(rsp.Body)
+	ret, err := ioutil.ReadAll(rsp.Body)
 	if err != nil {
-		return errors.Wrap(err, "failed to read")
+		return nil, errors.Wrap(err, "failed to read")
 	}
 
-	return nil
+	return ret, nil
 }
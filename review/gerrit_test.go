@@ -14,21 +14,30 @@ package review
 
 import (
 	"encoding/base64"
-	"net/url"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
 	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
 )
 
 func initHandle(t *testing.T) gerrit {
 	c, err := initConfig("../tests/config.yml")
 	assert.Equal(t, nil, err)
 
-	var g gerrit
+	g := gerrit{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), s: storage.New(storage.DefaultConfig())}
 
 	for index := range c.Spec.Review {
 		if c.Spec.Review[index].Name == "gerrit" {
@@ -54,58 +63,397 @@ func TestClean(t *testing.T) {
 
 // nolint: dogsled
 func TestFetch(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
 
 	d, _ := os.Getwd()
 	root := filepath.Join(d, "gerrit-test-fetch")
 
-	_, _, _, err := h.Fetch(root, commitGerrit)
+	_, _, _, err := h.Fetch(root, "invalid")
+	assert.NotEqual(t, nil, err)
+
+	dname, rname, files, err := h.Fetch(root, commitGerrit)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join(root, strconv.Itoa(changeGerrit), commitGerrit), dname)
+	assert.Equal(t, "lintflow", rname)
+
+	sort.Strings(files)
+	assert.Equal(t, []string{"main.go" + proto.Base64Content, proto.Base64Message, "src/util.cpp" + proto.Base64Content}, files)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dname, "src", "util.cpp"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("int main() {}\n")), string(buf))
 
 	err = h.Clean(root)
 	assert.Equal(t, nil, err)
 }
 
 // nolint: dogsled
+func TestFetchPatch(t *testing.T) {
+	f := initFixture()
+	rev := f.Change.Revisions[commitGerrit]
+	rev.Files = map[string]fileInfo{commitMsg: {}}
+	f.Change.Revisions[commitGerrit] = rev
+
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "gerrit-test-fetch-patch")
+
+	_, _, files, err := h.Fetch(root, commitGerrit)
+	assert.Equal(t, nil, err)
+
+	sort.Strings(files)
+	assert.Equal(t, []string{"main.go" + proto.Base64Content, proto.Base64Message, "src/util.cpp" + proto.Base64Content}, files)
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestChange(t *testing.T) {
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	_, err := h.Change("invalid")
+	assert.NotEqual(t, nil, err)
+
+	c, err := h.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dev@example.com", c.Author)
+	assert.Equal(t, "author@example.com", c.CommitAuthor)
+	assert.Equal(t, changeGerrit, c.Number)
+	assert.Equal(t, "Add main\n", c.Message)
+	assert.Equal(t, 3, c.Lines["main.go"])
+	assert.Equal(t, (*proto.Signature)(nil), c.Signature)
+}
+
+func TestChangeSignature(t *testing.T) {
+	f := initFixture()
+	rev := f.Change.Revisions[commitGerrit]
+	rev.PushCertificate = &pushCertificateInfo{Key: gpgKeyInfo{Problems: []string{"Key is expired"}, Status: "BAD"}}
+	f.Change.Revisions[commitGerrit] = rev
+
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	c, err := h.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &proto.Signature{Problems: []string{"Key is expired"}, Status: proto.SignatureBad}, c.Signature)
+}
+
+func TestCarryRevision(t *testing.T) {
+	f := initFixture()
+	rev := f.Change.Revisions[commitGerrit]
+	rev.Kind = proto.KindTrivialRebase
+	rev.Number = revisionGerrit + 1
+	f.Change.Revisions[commitGerrit] = rev
+	f.Change.Revisions["previous"] = revisionInfo{Commit: rev.Commit, Number: revisionGerrit}
+
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	c, err := h.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proto.KindTrivialRebase, c.Kind)
+	assert.Equal(t, "previous", c.Previous)
+
+	data := []proto.Format{
+		{File: commitMsg, Line: 1, Details: "subject"},
+		{File: "main.go", Line: 2, Details: "main"},
+		{File: "old.go", Line: 1, Details: "old"},
+		{Details: "change"},
+	}
+
+	buf, err := h.Carry("previous", commitGerrit, data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []proto.Format{data[0], data[1], data[3]}, buf)
+
+	_, err = h.Carry("invalid", commitGerrit, data)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParent(t *testing.T) {
+	f := initFixture()
+	rev := f.Change.Revisions[commitGerrit]
+	rev.Files["src/util.cpp"] = fileInfo{LinesInserted: 1, Status: "A"}
+	f.Change.Revisions[commitGerrit] = rev
+	f.Parent = map[string]string{"main.go": "package main\n"}
+
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	d, err := ioutil.TempDir("", "gerrit-test-parent")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	err = h.Parent(d, commitGerrit, []string{proto.Base64Message, "main.go" + proto.Base64Content, "src/util.cpp" + proto.Base64Content})
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(d, proto.ParentDir, "main.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package main\n")), string(buf))
+
+	_, err = os.Stat(filepath.Join(d, proto.ParentDir, "src", "util.cpp"+proto.Base64Content))
+	assert.Equal(t, true, os.IsNotExist(err))
+
+	s.mu.Lock()
+	s.f.Parent = nil
+	s.mu.Unlock()
+
+	err = h.Parent(d, commitGerrit, []string{"main.go" + proto.Base64Content})
+	assert.NotEqual(t, nil, err)
+}
+
 func TestVote(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
 
-	buf := make([]proto.Format, 0)
+	h := s.gerrit(config.Review{Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}})
 
-	err := h.Vote("", buf)
+	err := h.Vote("", nil, proto.ModeFull, nil)
 	assert.NotEqual(t, nil, err)
 
-	err = h.Vote(commitGerrit, buf)
+	err = h.Vote(commitGerrit, nil, proto.ModeFull, nil)
 	assert.Equal(t, nil, err)
 
-	buf = make([]proto.Format, 1)
-	buf[0] = proto.Format{
-		Details: "Disapproved",
-		File:    "AndroidManifest.xml",
-		Line:    1,
-		Type:    proto.TypeError,
+	err = h.Vote(commitGerrit, []proto.Format{{Details: "Disapproved", File: "main.go", Line: 1, Type: proto.TypeError}}, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(s.reviews))
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, s.reviews[0].Labels)
+	assert.Equal(t, map[string]interface{}{"Code-Review": "-1"}, s.reviews[1].Labels)
+	assert.Equal(t, "Disapproved", s.reviews[1].Comments["main.go"][0].Message)
+}
+
+func TestVoteQuiet(t *testing.T) {
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review", PayloadSize: 2000}})
+
+	var buf []proto.Format
+
+	for i := 0; i < 50; i++ {
+		buf = append(buf, proto.Format{Details: strings.Repeat("x", 80), File: "main.go", Line: 1, Type: proto.TypeError})
 	}
 
-	err = h.Vote(commitGerrit, buf)
+	err := h.Vote(commitGerrit, buf, proto.ModeFull, &proto.Note{Quiet: true})
 	assert.Equal(t, nil, err)
+
+	assert.Equal(t, true, len(s.reviews) > 1)
+
+	for _, item := range s.reviews {
+		assert.Equal(t, notifyNone, item.Notify)
+	}
 }
 
-func TestGetContent(t *testing.T) {
+func TestVoteAttention(t *testing.T) {
+	f := initFixture()
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{Vote: config.Vote{Approval: "+1", Attention: true, Disapproval: "-1", Label: "Code-Review"}})
+
+	err := h.Vote(commitGerrit, []proto.Format{{Details: "Disapproved", File: "main.go", Line: 1, Type: proto.TypeError}},
+		proto.ModeComment, nil)
+	assert.Equal(t, nil, err)
+
+	err = h.Vote(commitGerrit, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(s.reviews))
+	assert.Equal(t, []attentionSetInput{{Reason: attentionBlocking, User: "1000"}}, s.reviews[0].AddToAttentionSet)
+	assert.Equal(t, []attentionSetInput{{Reason: attentionReviewed, User: attentionSelf}}, s.reviews[0].RemoveFromAttentionSet)
+	assert.Equal(t, 0, len(s.reviews[1].AddToAttentionSet))
+	assert.Equal(t, true, s.reviews[1].IgnoreAutomaticAttentionSetRules)
+
+	f.Version = "3.2.0"
+	s = newFakeGerrit(f)
+	defer s.Close()
+
+	h = s.gerrit(config.Review{Vote: config.Vote{Approval: "+1", Attention: true, Disapproval: "-1", Label: "Code-Review"}})
+
+	err = h.Vote(commitGerrit, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, s.reviews[0].IgnoreAutomaticAttentionSetRules)
+}
+
+func TestNotify(t *testing.T) {
+	var path, body string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			buf, _ := ioutil.ReadAll(r.Body)
+			path, body = r.URL.Path, string(buf)
+			return
+		}
+		_, _ = w.Write([]byte(")]}'\n" + `[{"_number":41,"current_revision":"abc","revisions":{"abc":{"_number":2}}}]`))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Url: ts.URL}}
+
+	err := g.Notify(commitGerrit, "lintflow could not run lintcpp: timeout")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/changes/41/revisions/2/review", path)
+	assert.Equal(t, `{"message":"lintflow could not run lintcpp: timeout"}`, body)
+}
+
+func TestVoteDrafts(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1 @@\n+a\n"
+
+	var deleted, drafts, review []string
+	status := http.StatusOK
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/drafts"):
+			_, _ = w.Write([]byte(")]}'\n" + `{"main.go":[{"id":"stale","line":1,"message":"text"}]}`))
+		case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/drafts/"):
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/drafts"):
+			drafts = append(drafts, string(buf))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(")]}'\n" + `{"id":"new","line":1,"message":"text"}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/review"):
+			review = append(review, string(buf))
+			w.WriteHeader(status)
+		case strings.HasSuffix(r.URL.Path, "/patch"):
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(patch))))
+		case r.URL.Path == "/changes/":
+			_, _ = w.Write([]byte(")]}'\n" + `[{"_number":41,"current_revision":"abc","revisions":{"abc":{"_number":2}}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()),
+		r: config.Review{Url: ts.URL, Vote: config.Vote{Approval: "+1", Disapproval: "-1", Draft: true, Label: "Code-Review"}}}
+
+	err := g.Vote(commitGerrit, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text"}}, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{`{"line":1,"message":"text","path":"main.go"}`}, drafts)
+	assert.Equal(t, []string{`{"drafts":"PUBLISH","labels":{"Code-Review":"-1"}}`}, review)
+	assert.Equal(t, []string{"stale"}, deleted)
+
+	deleted, status = nil, http.StatusInternalServerError
+
+	err = g.Vote(commitGerrit, []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text"}}, proto.ModeFull, nil)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, []string{"stale", "new"}, deleted)
+}
+
+func TestVoteFile(t *testing.T) {
+	patch := "diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1 @@\n+a\n"
+
+	var review []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/review"):
+			review = append(review, string(buf))
+		case strings.HasSuffix(r.URL.Path, "/patch"):
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(patch))))
+		case r.URL.Path == "/changes/":
+			_, _ = w.Write([]byte(")]}'\n" + `[{"_number":41,"current_revision":"abc","revisions":{"abc":{"_number":2}}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()),
+		r: config.Review{Url: ts.URL, Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}}}
+
+	err := g.Vote(commitGerrit, []proto.Format{
+		{File: "main.go", Line: 0, Type: proto.TypeError, Details: "text"},
+		{File: "other.go", Line: 0, Type: proto.TypeError, Details: "text"},
+		{Type: proto.TypeError, Details: "missing CODEOWNERS entry", Scope: proto.ScopeChange},
+	}, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{`{"comments":{"main.go":[{"message":"text"}]},"labels":{"Code-Review":"-1"},` +
+		`"message":"- Error: missing CODEOWNERS entry"}`}, review)
+}
+
+func TestFile(t *testing.T) {
+	h := initHandle(t)
+
+	assert.Equal(t, proto.Base64Message, h.file(commitMsg))
+	assert.Equal(t, "AndroidManifest.xml"+proto.Base64Content, h.file("AndroidManifest.xml"))
+	assert.Equal(t, "src/com/android/Foo.java"+proto.Base64Content, h.file("src/com/android/Foo.java"))
+}
+
+func TestWrite(t *testing.T) {
+	h := initHandle(t)
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "gerrit-test-write")
+
+	err := h.write(root, h.file("src/com/android/Foo.java"), "data")
+	assert.Equal(t, nil, err)
+
+	_, err = os.Stat(filepath.Join(root, "src", "com", "android", "Foo.java"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestIgnore(t *testing.T) {
 	h := initHandle(t)
 
+	i := h.ignore("", "")
+	assert.NotEqual(t, nil, i)
+	assert.Equal(t, false, i.Match("AndroidManifest.xml"))
+}
+
+func TestGetCommitContent(t *testing.T) {
+	h := initHandle(t)
+
+	_, err := h.get(h.urlCommitContent("", "", ""))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGetContent(t *testing.T) {
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
 	_, err := h.get(h.urlContent(-1, -1, ""))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlContent(changeGerrit, revisionGerrit, url.PathEscape("AndroidManifest.xml")))
+	buf, err := h.get(h.urlContent(changeGerrit, revisionGerrit, "src/util.cpp"))
 	assert.Equal(t, nil, err)
 
-	dst := make([]byte, len(buf))
-	n, _ := base64.StdEncoding.Decode(dst, buf)
-	assert.NotEqual(t, 0, n)
+	dst, err := base64.StdEncoding.DecodeString(string(buf))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "int main() {}\n", string(dst))
 }
 
 func TestGetDetail(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
 
 	_, err := h.get(h.urlDetail(-1))
 	assert.NotEqual(t, nil, err)
@@ -113,12 +461,17 @@ func TestGetDetail(t *testing.T) {
 	buf, err := h.get(h.urlDetail(changeGerrit))
 	assert.Equal(t, nil, err)
 
-	_, err = h.unmarshal(buf)
+	var c changeInfo
+	err = decode(buf, &c)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, changeGerrit, c.Number)
 }
 
 func TestGetFiles(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
 
 	_, err := h.get(h.urlFiles(-1, -1))
 	assert.NotEqual(t, nil, err)
@@ -126,58 +479,127 @@ func TestGetFiles(t *testing.T) {
 	buf, err := h.get(h.urlFiles(changeGerrit, revisionGerrit))
 	assert.Equal(t, nil, err)
 
-	_, err = h.unmarshal(buf)
+	var f map[string]fileInfo
+	err = decode(buf, &f)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, "D", f["old.go"].Status)
 }
 
 func TestGetPatch(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
 
 	_, err := h.get(h.urlPatch(-1, -1))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlPatch(changeGerrit, revisionGerrit))
+	diffs, err := h.patch(changeGerrit, revisionGerrit)
 	assert.Equal(t, nil, err)
-
-	dst := make([]byte, len(buf))
-	n, _ := base64.StdEncoding.Decode(dst, buf)
-	assert.NotEqual(t, 0, n)
+	assert.Equal(t, 2, len(diffs))
 }
 
 func TestGetQuery(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
 
-	_, err := h.get(h.urlQuery("commit:-1", []string{"CURRENT_REVISION"}, 0))
-	assert.NotEqual(t, nil, err)
+	h := s.gerrit(config.Review{User: "user", Pass: "pass"})
 
-	buf, err := h.get(h.urlQuery("commit:"+commitGerrit, []string{"CURRENT_REVISION"}, 0))
-	assert.Equal(t, nil, err)
+	_, err := h.query("commit:-1", "CURRENT_REVISION")
+	assert.NotEqual(t, nil, err)
 
-	_, err = h.unmarshalList(buf)
+	c, err := h.query("commit:"+commitGerrit, "CURRENT_REVISION")
 	assert.Equal(t, nil, err)
+	assert.Equal(t, commitGerrit, c.CurrentRevision)
 }
 
 func TestPostReview(t *testing.T) {
-	h := initHandle(t)
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
 
 	err := h.post(h.urlReview(-1, -1), nil)
 	assert.NotEqual(t, nil, err)
 
-	buf := map[string]interface{}{
-		"comments": map[string]interface{}{
-			"AndroidManifest.xml": []map[string]interface{}{
+	buf := &reviewInput{
+		Comments: map[string][]commentInput{
+			"main.go": {
 				{
-					"line":    1,
-					"message": "Commented by lintflow",
+					Line:    1,
+					Message: "Commented by lintflow",
 				},
 			},
 		},
-		"labels": map[string]interface{}{
-			"Code-Review": -1,
+		Labels: map[string]interface{}{
+			"Code-Review": "-1",
 		},
-		"message": "Voting Code-Review by lintflow",
+		Message: "Voting Code-Review by lintflow",
 	}
 
 	err = h.post(h.urlReview(changeGerrit, revisionGerrit), buf)
 	assert.Equal(t, nil, err)
+	assert.Equal(t, []reviewInput{*buf}, s.reviews)
+}
+
+func TestBody(t *testing.T) {
+	rsp := &http.Response{Body: ioutil.NopCloser(strings.NewReader("ok")), Status: "200 OK", StatusCode: http.StatusOK}
+	buf, err := body(rsp)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ok", string(buf))
+
+	rsp = &http.Response{Body: ioutil.NopCloser(strings.NewReader("<html><title>Bad Gateway</title></html>")),
+		Status: "502 Bad Gateway", StatusCode: http.StatusBadGateway}
+	_, err = body(rsp)
+	assert.Equal(t, "invalid status 502 Bad Gateway: Bad Gateway", err.Error())
+
+	rsp = &http.Response{Body: ioutil.NopCloser(strings.NewReader("")), Status: "404 Not Found",
+		StatusCode: http.StatusNotFound}
+	_, err = body(rsp)
+	assert.Equal(t, "invalid status 404 Not Found", err.Error())
+}
+
+func TestSendStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("change is closed\n"))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: ts.Client(), r: config.Review{Url: ts.URL}}
+
+	err := g.post(ts.URL+"/changes/1/revisions/2/review", &reviewInput{Message: "msg"})
+	assert.Equal(t, "invalid status 409 Conflict: change is closed", err.Error())
+}
+
+func TestEndpoint(t *testing.T) {
+	g := gerrit{r: config.Review{Host: "http://127.0.0.1/", Port: 8080}}
+	assert.Equal(t, "http://127.0.0.1:8080/changes/1/detail", g.urlDetail(1))
+
+	g = gerrit{r: config.Review{Url: "https://example.com/gerrit/", User: "user", Pass: "pass"}}
+	assert.Equal(t, "https://example.com/gerrit/a/projects/foo%2Fbar/commits/abc/files/doc%2FREADME.md/content",
+		g.urlCommitContent("foo/bar", "abc", "doc/README.md"))
+	assert.Equal(t, "https://example.com/gerrit/a/changes/?n=0&o=CURRENT_REVISION&q=commit%3Aabc",
+		g.urlQuery("commit:abc", []string{"CURRENT_REVISION"}, 0))
+	assert.Equal(t, "https://example.com/gerrit/a/changes/1/revisions/2/files/", g.urlFiles(1, 2))
+}
+
+func TestPatchFiles(t *testing.T) {
+	patch := "From 1 Mon Sep 17 00:00:00 2001\nSubject: test\n\n---\n" +
+		"diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/old.go b/old.go\ndeleted file mode 100644\nindex 1..0\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n" +
+		"diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files differ\n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(patch))))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Url: ts.URL}}
+
+	diffs, err := g.patch(1, 1)
+	assert.Equal(t, nil, err)
+
+	fs := g.patchFiles(diffs)
+	assert.Equal(t, map[string]fileInfo{commitMsg: {}, "main.go": {}}, fs)
 }
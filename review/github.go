@@ -0,0 +1,652 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/ignore"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	githubApprove        = "APPROVE"
+	githubComment        = "COMMENT"
+	githubRequestChanges = "REQUEST_CHANGES"
+)
+
+const (
+	githubAdded   = "added"
+	githubRemoved = "removed"
+)
+
+const (
+	githubCommentSize = 65536
+	githubPages       = 30
+	githubPerPage     = 100
+	githubUrl         = "https://api.github.com"
+)
+
+const (
+	githubDiff = "application/vnd.github.v3.diff"
+	githubJson = "application/vnd.github.v3+json"
+	githubRaw  = "application/vnd.github.v3.raw"
+)
+
+type github struct {
+	c *http.Client
+	p policy.Policy
+	r config.Review
+	s storage.Storage
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubRef struct {
+	Ref string `json:"ref"`
+	Sha string `json:"sha"`
+}
+
+type githubPull struct {
+	Additions int        `json:"additions"`
+	Base      githubRef  `json:"base"`
+	Deletions int        `json:"deletions"`
+	Head      githubRef  `json:"head"`
+	Number    int        `json:"number"`
+	State     string     `json:"state"`
+	User      githubUser `json:"user"`
+}
+
+type githubFile struct {
+	Changes          int    `json:"changes"`
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+}
+
+type githubCommit struct {
+	Commit struct {
+		Author struct {
+			Email string `json:"email"`
+		} `json:"author"`
+		Message string `json:"message"`
+	} `json:"commit"`
+	Sha string `json:"sha"`
+}
+
+type githubReviewComment struct {
+	Body        string     `json:"body"`
+	CommitID    string     `json:"commit_id"`
+	ID          int64      `json:"id"`
+	InReplyToID int64      `json:"in_reply_to_id"`
+	Line        int        `json:"line"`
+	Path        string     `json:"path"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	User        githubUser `json:"user"`
+}
+
+type githubDraft struct {
+	Body        string `json:"body"`
+	Line        int    `json:"line,omitempty"`
+	Path        string `json:"path"`
+	Side        string `json:"side,omitempty"`
+	SubjectType string `json:"subject_type,omitempty"`
+}
+
+type githubReview struct {
+	Body     string        `json:"body,omitempty"`
+	Comments []githubDraft `json:"comments,omitempty"`
+	CommitID string        `json:"commit_id"`
+	Event    string        `json:"event"`
+}
+
+// Carry is unsupported, since pull requests have no revisions to map findings between, and Change reports no previous
+// revision for flow to carry from.
+func (g *github) Carry(_, _ string, _ []proto.Format) ([]proto.Format, error) {
+	return nil, errors.New("unsupported carry")
+}
+
+func (g *github) Change(commit string) (proto.Change, error) {
+	p, err := g.pull(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to pull")
+	}
+
+	c, err := g.commit(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to commit")
+	}
+
+	fs, err := g.files(p.Number)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to files")
+	}
+
+	ret := proto.Change{
+		Author:       c.Commit.Author.Email,
+		Branch:       p.Base.Ref,
+		Commit:       commit,
+		CommitAuthor: c.Commit.Author.Email,
+		Deletions:    p.Deletions,
+		Insertions:   p.Additions,
+		Lines:        map[string]int{},
+		Message:      c.Commit.Message,
+		Number:       p.Number,
+		Project:      g.r.Repo,
+	}
+
+	if ret.Author == "" {
+		ret.Author = p.User.Login
+	}
+
+	for _, item := range fs {
+		ret.Lines[item.Filename] = item.Changes
+	}
+
+	return ret, nil
+}
+
+func (g *github) Clean(name string) error {
+	if err := g.s.Remove(name); err != nil {
+		return errors.Wrap(err, "failed to clean")
+	}
+
+	return nil
+}
+
+// Feedback gets replies to review comments on pull request of commit, which contain keyword.
+func (g *github) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if keyword == "" {
+		return nil, nil
+	}
+
+	p, err := g.pull(commit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pull")
+	}
+
+	comments, err := g.comments(p.Number)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to comments")
+	}
+
+	var ret []proto.Feedback
+
+	for _, item := range comments {
+		if item.InReplyToID == 0 || !strings.Contains(item.Body, keyword) {
+			continue
+		}
+		if item.CommitID != "" && item.CommitID != commit {
+			continue
+		}
+		ret = append(ret, proto.Feedback{ID: strconv.FormatInt(item.ID, 10), Author: item.User.Login, Commit: commit,
+			File: item.Path, Line: item.Line, Time: item.UpdatedAt})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+
+	return ret, nil
+}
+
+func (g *github) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
+	p, err := g.pull(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to pull")
+	}
+
+	c, err := g.commit(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to commit")
+	}
+
+	fs, err := g.files(p.Number)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to files")
+	}
+
+	base := filepath.Join(root, strconv.Itoa(p.Number), commit)
+
+	if err := g.write(base, proto.Base64Message, base64.StdEncoding.EncodeToString([]byte(c.Commit.Message))); err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to fetch")
+	}
+
+	files := []string{proto.Base64Message}
+
+	// Match ignore
+	ig := g.ignore(commit)
+
+	for _, item := range fs {
+		if item.Status == githubRemoved || ig.Match(item.Filename) {
+			continue
+		}
+		buf, err := g.get(g.urlContent(item.Filename, commit), githubRaw)
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to content")
+		}
+		if err := g.write(base, g.file(item.Filename), base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to fetch")
+		}
+		files = append(files, g.file(item.Filename))
+	}
+
+	return base, g.r.Repo, files, nil
+}
+
+// Notify posts message to pull request of commit as comment without review.
+func (g *github) Notify(commit, message string) error {
+	p, err := g.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	if err := g.post(g.endpoint(nil, "issues", strconv.Itoa(p.Number), "comments"),
+		map[string]string{"body": message}); err != nil {
+		return errors.Wrap(err, "failed to comment")
+	}
+
+	return nil
+}
+
+// Parent writes content of files in base of pull request of commit into parent directory of dir, skipping files added.
+func (g *github) Parent(dir, commit string, files []string) error {
+	p, err := g.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	fs, err := g.files(p.Number)
+	if err != nil {
+		return errors.Wrap(err, "failed to files")
+	}
+
+	info := map[string]githubFile{}
+
+	for _, item := range fs {
+		info[item.Filename] = item
+	}
+
+	for _, val := range files {
+		name := strings.TrimSuffix(val, proto.Base64Content)
+		item, ok := info[name]
+		if val == proto.Base64Message || !ok || item.Status == githubAdded {
+			continue
+		}
+		if item.PreviousFilename != "" {
+			name = item.PreviousFilename
+		}
+		buf, err := g.get(g.urlContent(name, p.Base.Sha), githubRaw)
+		if err != nil {
+			return errors.Wrap(err, "failed to content")
+		}
+		if err := g.write(filepath.Join(dir, proto.ParentDir), val, base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+// Vote posts findings as review comments on lines added by pull request of commit, and approves or requests changes
+// by policy. Findings on commit message or change, and out of diff, are listed in body of review instead.
+// nolint:gocyclo
+func (g *github) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	match := func(data proto.Format, diffs []*diff.File) bool {
+		for _, d := range diffs {
+			if d.New != data.File {
+				continue
+			}
+			if data.Line == 0 || d.Added(data.Line) {
+				return true
+			}
+		}
+		return false
+	}
+
+	size := githubCommentSize
+	if g.r.Vote.CommentSize > 0 && g.r.Vote.CommentSize < size {
+		size = g.r.Vote.CommentSize
+	}
+
+	p, err := g.pull(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to pull")
+	}
+
+	buf, err := g.get(g.endpoint(nil, "pulls", strconv.Itoa(p.Number)), githubDiff)
+	if err != nil {
+		return errors.Wrap(err, "failed to diff")
+	}
+
+	diffs, err := diff.Parse(buf)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
+	}
+
+	var remarks []remark
+
+	if g.r.Vote.Dedup.Enabled {
+		if remarks, err = g.remarks(p.Number, commit); err != nil {
+			log.Println(errors.Wrap(err, "failed to remarks"))
+		}
+	}
+
+	c := map[string][]commentInput{}
+
+	var m, change []proto.Format
+
+	for _, item := range data {
+		if item.Details == "" {
+			continue
+		}
+		m = append(m, item)
+		if item.Scope == proto.ScopeChange || item.File == proto.Base64Message || !match(item, diffs) {
+			change = append(change, item)
+			continue
+		}
+		if duplicate(&item, remarks, &g.r.Vote.Dedup) {
+			continue
+		}
+		c[item.File] = append(c[item.File], commentInput{Line: item.Line, Message: message(&item)})
+	}
+
+	var comments []githubDraft
+
+	for key, val := range c {
+		for _, item := range pack(val, &g.r.Vote) {
+			link := ""
+			if g.r.Vote.Link != "" {
+				link = detail(g.r.Vote.Link, commit, key, item.Line)
+			}
+			d := githubDraft{Body: truncate(item.Message, size, link), Line: item.Line, Path: key, Side: "RIGHT"}
+			if item.Line == 0 {
+				d.Side, d.SubjectType = "", "file"
+			}
+			comments = append(comments, d)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].Path != comments[j].Path {
+			return comments[i].Path < comments[j].Path
+		}
+		return comments[i].Line < comments[j].Line
+	})
+
+	r := githubReview{Body: truncate(changeLevel(g.r.Vote.Message, change), size, ""), Comments: comments, CommitID: commit,
+		Event: githubApprove}
+
+	if disapproving(labels(m, &g.r.Vote, g.p), &g.r.Vote) {
+		r.Event = githubRequestChanges
+	}
+
+	switch mode {
+	case proto.ModeComment:
+		r.Event = githubComment
+	case proto.ModeFreeze:
+		if r.Event == githubRequestChanges {
+			r.Event = githubComment
+		}
+	case proto.ModeFreezeVote:
+		if r.Event == githubRequestChanges {
+			r.Event = githubComment
+		}
+		r.Body, r.Comments = summary(g.r.Vote.Message, m), nil
+	case proto.ModeVote:
+		r.Body, r.Comments = summary(g.r.Vote.Message, m), nil
+	}
+
+	// Body is required unless approving
+	if r.Body == "" && r.Event != githubApprove {
+		r.Body = summary("", m)
+	}
+
+	r.Body = annotate(r.Body, note)
+
+	if err := g.post(g.endpoint(nil, "pulls", strconv.Itoa(p.Number), "reviews"), &r); err != nil {
+		return errors.Wrap(err, "failed to review")
+	}
+
+	if !security(data, &g.r.Security) {
+		return nil
+	}
+
+	if len(g.r.Security.Reviewers) != 0 {
+		if err := g.post(g.endpoint(nil, "pulls", strconv.Itoa(p.Number), "requested_reviewers"),
+			map[string][]string{"reviewers": g.r.Security.Reviewers}); err != nil {
+			return errors.Wrap(err, "failed to request reviewers")
+		}
+	}
+
+	if g.r.Security.Hashtag != "" {
+		if err := g.post(g.endpoint(nil, "issues", strconv.Itoa(p.Number), "labels"),
+			map[string][]string{"labels": {g.r.Security.Hashtag}}); err != nil {
+			return errors.Wrap(err, "failed to label")
+		}
+	}
+
+	return nil
+}
+
+// pull finds the pull request of commit, preferring open ones whose head is commit.
+func (g *github) pull(commit string) (*githubPull, error) {
+	buf, err := g.get(g.endpoint(nil, "commits", commit, "pulls"), githubJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	var pulls []githubPull
+
+	if err := json.Unmarshal(buf, &pulls); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if len(pulls) == 0 {
+		return nil, errors.New("invalid pull")
+	}
+
+	number := pulls[0].Number
+
+	for _, item := range pulls {
+		if item.Head.Sha == commit && item.State == "open" {
+			number = item.Number
+			break
+		}
+	}
+
+	// Counts of lines are in detail of pull only
+	buf, err = g.get(g.endpoint(nil, "pulls", strconv.Itoa(number)), githubJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	ret := &githubPull{}
+
+	if err := json.Unmarshal(buf, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return ret, nil
+}
+
+func (g *github) comments(number int) ([]githubReviewComment, error) {
+	var ret []githubReviewComment
+
+	for page := 1; page <= githubPages; page++ {
+		buf, err := g.get(g.endpoint(g.page(page), "pulls", strconv.Itoa(number), "comments"), githubJson)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get")
+		}
+		var comments []githubReviewComment
+		if err := json.Unmarshal(buf, &comments); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		ret = append(ret, comments...)
+		if len(comments) < githubPerPage {
+			break
+		}
+	}
+
+	return ret, nil
+}
+
+// remarks gets comments of reviewers on commit in pull request.
+func (g *github) remarks(number int, commit string) ([]remark, error) {
+	comments, err := g.comments(number)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to comments")
+	}
+
+	var ret []remark
+
+	for _, item := range comments {
+		if item.CommitID != "" && item.CommitID != commit {
+			continue
+		}
+		if human(item.User.Login, g.r.User) {
+			ret = append(ret, remark{Author: item.User.Login, File: item.Path, Line: item.Line, Message: item.Body})
+		}
+	}
+
+	return ret, nil
+}
+
+func (g *github) commit(commit string) (*githubCommit, error) {
+	buf, err := g.get(g.endpoint(nil, "commits", commit), githubJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	ret := &githubCommit{}
+
+	if err := json.Unmarshal(buf, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return ret, nil
+}
+
+// files lists files changed by pull request in pages, up to the limit of 3000 files in GitHub.
+func (g *github) files(number int) ([]githubFile, error) {
+	var ret []githubFile
+
+	for page := 1; page <= githubPages; page++ {
+		buf, err := g.get(g.endpoint(g.page(page), "pulls", strconv.Itoa(number), "files"), githubJson)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get")
+		}
+		var fs []githubFile
+		if err := json.Unmarshal(buf, &fs); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		ret = append(ret, fs...)
+		if len(fs) < githubPerPage {
+			break
+		}
+	}
+
+	return ret, nil
+}
+
+func (g *github) ignore(commit string) ignore.Ignore {
+	buf, err := g.get(g.urlContent(ignore.Name, commit), githubRaw)
+	if err != nil {
+		// Missing ignore file in commit
+		return ignore.New(nil)
+	}
+
+	return ignore.New(buf)
+}
+
+// file maps the slash-separated file name in GitHub to the slash-separated name in workspace
+func (g *github) file(name string) string {
+	return path.Join(path.Dir(name), path.Base(name)+proto.Base64Content)
+}
+
+func (g *github) write(root, file, data string) error {
+	if err := g.s.Write(filepath.Join(root, filepath.FromSlash(file)), []byte(data)); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// base returns URL of GitHub API, e.g. https://api.github.com, or https://example.com/api/v3 for GitHub Enterprise
+func (g *github) base() string {
+	if g.r.Url != "" {
+		return strings.TrimSuffix(g.r.Url, "/")
+	}
+
+	if g.r.Host != "" {
+		return strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port)
+	}
+
+	return githubUrl
+}
+
+// endpoint joins escaped path elements to base under repository, e.g. /repos/{owner}/{repo}/pulls
+func (g *github) endpoint(query url.Values, elem ...string) string {
+	u, err := url.Parse(g.base())
+	if err != nil {
+		return ""
+	}
+
+	name := []string{strings.TrimSuffix(u.Path, "/"), "repos"}
+	raw := []string{strings.TrimSuffix(u.EscapedPath(), "/"), "repos"}
+
+	for _, val := range append(strings.Split(g.r.Repo, "/"), elem...) {
+		name, raw = append(name, val), append(raw, url.PathEscape(val))
+	}
+
+	u.Path = strings.Join(name, "/")
+	u.RawPath = strings.Join(raw, "/")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (g *github) page(page int) url.Values {
+	return url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(githubPerPage)}}
+}
+
+func (g *github) urlContent(name, ref string) string {
+	return g.endpoint(url.Values{"ref": {ref}}, append([]string{"contents"}, strings.Split(name, "/")...)...)
+}
+
+func (g *github) get(_url, accept string) ([]byte, error) {
+	return request(g.c, &g.r, http.MethodGet, _url, accept, nil)
+}
+
+func (g *github) post(_url string, data interface{}) error {
+	_, err := request(g.c, &g.r, http.MethodPost, _url, githubJson, data)
+	return err
+}
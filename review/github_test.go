@@ -0,0 +1,283 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	commitGithub = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	repoGithub   = "octocat/hello"
+)
+
+// fakeGithub implements the subset of GitHub REST API used by lintflow, and records what is posted.
+type fakeGithub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	comments []string
+	labels   []string
+	reviews  []githubReview
+}
+
+func newFakeGithub() *fakeGithub {
+	s := &fakeGithub{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *fakeGithub) github(r config.Review) github {
+	r.Repo, r.Url = repoGithub, s.URL
+
+	return github{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), r: r, s: storage.New(storage.DefaultConfig())}
+}
+
+// nolint:gocyclo
+func (s *fakeGithub) serve(w http.ResponseWriter, r *http.Request) {
+	prefix := "/repos/" + repoGithub + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	content := map[string]string{
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"old/lib.go": "package old\n",
+		"src/lib.go": "package src\n",
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p := strings.TrimPrefix(r.URL.Path, prefix); {
+	case p == "commits/"+commitGithub+"/pulls":
+		s.json(w, []githubPull{{Head: githubRef{Sha: commitGithub}, Number: 7, State: "open"}})
+	case p == "commits/"+commitGithub:
+		c := githubCommit{Sha: commitGithub}
+		c.Commit.Author.Email = "dev@example.com"
+		c.Commit.Message = "Add main\n"
+		s.json(w, c)
+	case p == "pulls/7" && r.Header.Get("Accept") == githubDiff:
+		_, _ = w.Write([]byte("diff --git a/main.go b/main.go\nnew file mode 100644\nindex 0..1\n--- /dev/null\n" +
+			"+++ b/main.go\n@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n"))
+	case p == "pulls/7":
+		s.json(w, githubPull{Additions: 4, Base: githubRef{Ref: "main", Sha: "base"}, Deletions: 1,
+			Head: githubRef{Sha: commitGithub}, Number: 7, State: "open", User: githubUser{Login: "dev"}})
+	case p == "pulls/7/files":
+		s.json(w, []githubFile{
+			{Changes: 3, Filename: "main.go", Status: "added"},
+			{Changes: 1, Filename: "gone.go", Status: "removed"},
+			{Changes: 2, Filename: "src/lib.go", PreviousFilename: "old/lib.go", Status: "renamed"},
+		})
+	case p == "pulls/7/comments":
+		s.json(w, []githubReviewComment{
+			{Body: "fix it", CommitID: commitGithub, ID: 1, Line: 3, Path: "main.go"},
+			{Body: "/lintflow false-positive", CommitID: commitGithub, ID: 2, InReplyToID: 1, Line: 3, Path: "main.go",
+				User: githubUser{Login: "dev"}},
+		})
+	case p == "pulls/7/reviews":
+		var buf githubReview
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.reviews = append(s.reviews, buf)
+		w.WriteHeader(http.StatusOK)
+	case p == "issues/7/comments":
+		var buf map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.comments = append(s.comments, buf["body"])
+		w.WriteHeader(http.StatusCreated)
+	case p == "issues/7/labels":
+		var buf map[string][]string
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.labels = append(s.labels, buf["labels"]...)
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(p, "contents/"):
+		name := strings.TrimPrefix(p, "contents/")
+		if val, ok := content[name]; ok && r.Header.Get("Accept") == githubRaw {
+			_, _ = w.Write([]byte(val))
+			return
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeGithub) json(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// nolint: dogsled
+func TestGithubFetch(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "github-test-fetch")
+
+	_, _, _, err := h.Fetch(root, "invalid")
+	assert.NotEqual(t, nil, err)
+
+	dname, rname, files, err := h.Fetch(root, commitGithub)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join(root, "7", commitGithub), dname)
+	assert.Equal(t, repoGithub, rname)
+
+	sort.Strings(files)
+	assert.Equal(t, []string{"main.go" + proto.Base64Content, proto.Base64Message, "src/lib.go" + proto.Base64Content}, files)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dname, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package src\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestGithubChange(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{})
+
+	c, err := h.Change(commitGithub)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dev@example.com", c.Author)
+	assert.Equal(t, "main", c.Branch)
+	assert.Equal(t, 4, c.Insertions)
+	assert.Equal(t, "Add main\n", c.Message)
+	assert.Equal(t, 7, c.Number)
+	assert.Equal(t, repoGithub, c.Project)
+	assert.Equal(t, map[string]int{"gone.go": 1, "main.go": 3, "src/lib.go": 2}, c.Lines)
+
+	_, err = h.Carry("", commitGithub, nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGithubParent(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "github-test-parent")
+
+	err := h.Parent(root, commitGithub, []string{"main.go" + proto.Base64Content, proto.Base64Message,
+		"src/lib.go" + proto.Base64Content})
+	assert.Equal(t, nil, err)
+
+	_, err = os.Stat(filepath.Join(root, proto.ParentDir, "main.go"+proto.Base64Content))
+	assert.NotEqual(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(root, proto.ParentDir, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package old\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestGithubVote(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{Security: config.Security{Hashtag: "security"},
+		Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review", Message: "Voting by lintflow"}})
+
+	err := h.Vote(commitGithub, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, githubApprove, s.reviews[0].Event)
+	assert.Equal(t, 0, len(s.reviews[0].Comments))
+
+	data := []proto.Format{
+		{Details: "unused", File: "main.go", Line: 3, Type: proto.TypeError},
+		{Details: "out of diff", File: "src/lib.go", Line: 1, Type: proto.TypeWarn},
+		{Category: proto.CategorySecurity, Details: "too long", File: proto.Base64Message, Line: 1, Type: proto.TypeError},
+	}
+
+	err = h.Vote(commitGithub, data, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, githubRequestChanges, s.reviews[1].Event)
+	assert.Equal(t, commitGithub, s.reviews[1].CommitID)
+	assert.Equal(t, 1, len(s.reviews[1].Comments))
+	assert.Equal(t, "main.go", s.reviews[1].Comments[0].Path)
+	assert.Equal(t, 3, s.reviews[1].Comments[0].Line)
+	assert.Equal(t, true, strings.Contains(s.reviews[1].Body, "out of diff"))
+	assert.Equal(t, true, strings.Contains(s.reviews[1].Body, "too long"))
+	assert.Equal(t, []string{"security"}, s.labels)
+
+	err = h.Vote(commitGithub, data, proto.ModeComment, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, githubComment, s.reviews[2].Event)
+
+	err = h.Vote(commitGithub, data, proto.ModeVote, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(s.reviews[3].Comments))
+	assert.Equal(t, true, strings.Contains(s.reviews[3].Body, "lintflow found 3 findings"))
+}
+
+func TestGithubNotify(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{})
+
+	err := h.Notify(commitGithub, "hello")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"hello"}, s.comments)
+}
+
+func TestGithubFeedback(t *testing.T) {
+	s := newFakeGithub()
+	defer s.Close()
+
+	h := s.github(config.Review{})
+
+	buf, err := h.Feedback(commitGithub, "/lintflow false-positive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "2", buf[0].ID)
+	assert.Equal(t, "dev", buf[0].Author)
+	assert.Equal(t, "main.go", buf[0].File)
+	assert.Equal(t, 3, buf[0].Line)
+}
+
+func TestGithubEndpoint(t *testing.T) {
+	h := github{r: config.Review{Repo: repoGithub}}
+	assert.Equal(t, "https://api.github.com/repos/octocat/hello/pulls/7", h.endpoint(nil, "pulls", "7"))
+
+	h.r.Url = "https://example.com/api/v3/"
+	assert.Equal(t, "https://example.com/api/v3/repos/octocat/hello/contents/src/a%20b.go?ref=main",
+		h.urlContent("src/a b.go", "main"))
+}
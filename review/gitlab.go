@@ -0,0 +1,699 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/ignore"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	gitlabCommentSize = 1000000
+	gitlabOpened      = "opened"
+	gitlabPages       = 30
+	gitlabPerPage     = 100
+	gitlabUrl         = "https://gitlab.com"
+)
+
+type gitlab struct {
+	c *http.Client
+	p policy.Policy
+	r config.Review
+	s storage.Storage
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabRefs struct {
+	BaseSha  string `json:"base_sha"`
+	HeadSha  string `json:"head_sha"`
+	StartSha string `json:"start_sha"`
+}
+
+type gitlabMerge struct {
+	Author       gitlabUser `json:"author"`
+	DiffRefs     gitlabRefs `json:"diff_refs"`
+	Iid          int        `json:"iid"`
+	Sha          string     `json:"sha"`
+	State        string     `json:"state"`
+	TargetBranch string     `json:"target_branch"`
+}
+
+type gitlabDiff struct {
+	DeletedFile bool   `json:"deleted_file"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	NewPath     string `json:"new_path"`
+	OldPath     string `json:"old_path"`
+}
+
+type gitlabCommit struct {
+	AuthorEmail string `json:"author_email"`
+	Message     string `json:"message"`
+}
+
+type gitlabPosition struct {
+	BaseSha      string `json:"base_sha"`
+	HeadSha      string `json:"head_sha"`
+	NewLine      int    `json:"new_line,omitempty"`
+	NewPath      string `json:"new_path"`
+	OldPath      string `json:"old_path"`
+	PositionType string `json:"position_type"`
+	StartSha     string `json:"start_sha"`
+}
+
+type gitlabNote struct {
+	Author    gitlabUser      `json:"author"`
+	Body      string          `json:"body"`
+	ID        int64           `json:"id"`
+	Position  *gitlabPosition `json:"position"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type gitlabDiscussion struct {
+	Notes []gitlabNote `json:"notes"`
+}
+
+type gitlabDiscussionInput struct {
+	Body     string          `json:"body"`
+	Position *gitlabPosition `json:"position,omitempty"`
+}
+
+// Carry is unsupported, since Change reports no previous revision of merge request for flow to carry from.
+func (g *gitlab) Carry(_, _ string, _ []proto.Format) ([]proto.Format, error) {
+	return nil, errors.New("unsupported carry")
+}
+
+func (g *gitlab) Change(commit string) (proto.Change, error) {
+	m, err := g.merge(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to merge")
+	}
+
+	c, err := g.commit(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to commit")
+	}
+
+	_, diffs, err := g.diffs(m.Iid)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to diffs")
+	}
+
+	ret := proto.Change{
+		Author:       c.AuthorEmail,
+		Branch:       m.TargetBranch,
+		Commit:       commit,
+		CommitAuthor: c.AuthorEmail,
+		Message:      c.Message,
+		Number:       m.Iid,
+		Project:      g.r.Repo,
+	}
+
+	if ret.Author == "" {
+		ret.Author = m.Author.Username
+	}
+
+	ret.Lines, ret.Insertions, ret.Deletions = stats(diffs)
+	ret.Submodules = submodules(diffs)
+
+	return ret, nil
+}
+
+func (g *gitlab) Clean(name string) error {
+	if err := g.s.Remove(name); err != nil {
+		return errors.Wrap(err, "failed to clean")
+	}
+
+	return nil
+}
+
+// Feedback gets replies in discussions on merge request of commit, which contain keyword. Replies are on file and line
+// of discussions replied to.
+func (g *gitlab) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if keyword == "" {
+		return nil, nil
+	}
+
+	m, err := g.merge(commit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge")
+	}
+
+	discussions, err := g.discussions(m.Iid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discussions")
+	}
+
+	var ret []proto.Feedback
+
+	for _, item := range discussions {
+		if len(item.Notes) < 2 || item.Notes[0].Position == nil {
+			continue
+		}
+		p := item.Notes[0].Position
+		if p.HeadSha != "" && p.HeadSha != commit {
+			continue
+		}
+		for _, val := range item.Notes[1:] {
+			if !strings.Contains(val.Body, keyword) {
+				continue
+			}
+			ret = append(ret, proto.Feedback{ID: strconv.FormatInt(val.ID, 10), Author: val.Author.Username,
+				Commit: commit, File: p.NewPath, Line: p.NewLine, Time: val.UpdatedAt})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+
+	return ret, nil
+}
+
+func (g *gitlab) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
+	m, err := g.merge(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to merge")
+	}
+
+	c, err := g.commit(commit)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to commit")
+	}
+
+	fs, _, err := g.diffs(m.Iid)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to diffs")
+	}
+
+	base := filepath.Join(root, strconv.Itoa(m.Iid), commit)
+
+	if err := g.write(base, proto.Base64Message, base64.StdEncoding.EncodeToString([]byte(c.Message))); err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to fetch")
+	}
+
+	files := []string{proto.Base64Message}
+
+	// Match ignore
+	ig := g.ignore(commit)
+
+	for _, item := range fs {
+		if item.DeletedFile || ig.Match(item.NewPath) {
+			continue
+		}
+		buf, err := g.get(g.urlRaw(item.NewPath, commit))
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to raw")
+		}
+		if err := g.write(base, g.file(item.NewPath), base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return "", "", nil, errors.Wrap(err, "failed to fetch")
+		}
+		files = append(files, g.file(item.NewPath))
+	}
+
+	return base, g.r.Repo, files, nil
+}
+
+// Notify posts message to merge request of commit as note without approval.
+func (g *gitlab) Notify(commit, message string) error {
+	m, err := g.merge(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to merge")
+	}
+
+	if err := g.post(g.endpoint(nil, "merge_requests", strconv.Itoa(m.Iid), "notes"),
+		map[string]string{"body": message}); err != nil {
+		return errors.Wrap(err, "failed to note")
+	}
+
+	return nil
+}
+
+// Parent writes content of files in base of merge request of commit into parent directory of dir, skipping files added.
+func (g *gitlab) Parent(dir, commit string, files []string) error {
+	m, err := g.merge(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to merge")
+	}
+
+	fs, _, err := g.diffs(m.Iid)
+	if err != nil {
+		return errors.Wrap(err, "failed to diffs")
+	}
+
+	info := map[string]gitlabDiff{}
+
+	for _, item := range fs {
+		info[item.NewPath] = item
+	}
+
+	for _, val := range files {
+		item, ok := info[strings.TrimSuffix(val, proto.Base64Content)]
+		if val == proto.Base64Message || !ok || item.NewFile {
+			continue
+		}
+		buf, err := g.get(g.urlRaw(item.OldPath, m.DiffRefs.BaseSha))
+		if err != nil {
+			return errors.Wrap(err, "failed to raw")
+		}
+		if err := g.write(filepath.Join(dir, proto.ParentDir), val, base64.StdEncoding.EncodeToString(buf)); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+// Vote starts discussions of findings on lines added by merge request of commit, and approves or unapproves it by
+// policy. Findings on commit message or change, and out of diff, are posted in one note instead.
+// nolint:gocyclo
+func (g *gitlab) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	match := func(data proto.Format, diffs []*diff.File) *diff.File {
+		for _, d := range diffs {
+			if d.New == data.File && data.Line != 0 && d.Added(data.Line) {
+				return d
+			}
+		}
+		return nil
+	}
+
+	size := gitlabCommentSize
+	if g.r.Vote.CommentSize > 0 && g.r.Vote.CommentSize < size {
+		size = g.r.Vote.CommentSize
+	}
+
+	m, err := g.merge(commit)
+	if err != nil {
+		return errors.Wrap(err, "failed to merge")
+	}
+
+	_, diffs, err := g.diffs(m.Iid)
+	if err != nil {
+		return errors.Wrap(err, "failed to diffs")
+	}
+
+	var remarks []remark
+
+	if g.r.Vote.Dedup.Enabled {
+		if remarks, err = g.remarks(m.Iid, commit); err != nil {
+			log.Println(errors.Wrap(err, "failed to remarks"))
+		}
+	}
+
+	c := map[string][]commentInput{}
+	old := map[string]string{}
+
+	var f, change []proto.Format
+
+	for _, item := range data {
+		if item.Details == "" {
+			continue
+		}
+		f = append(f, item)
+		d := match(item, diffs)
+		if item.Scope == proto.ScopeChange || item.File == proto.Base64Message || d == nil {
+			change = append(change, item)
+			continue
+		}
+		if duplicate(&item, remarks, &g.r.Vote.Dedup) {
+			continue
+		}
+		c[item.File] = append(c[item.File], commentInput{Line: item.Line, Message: message(&item)})
+		old[item.File] = d.Old
+	}
+
+	var discussions []gitlabDiscussionInput
+
+	for key, val := range c {
+		for _, item := range pack(val, &g.r.Vote) {
+			link := ""
+			if g.r.Vote.Link != "" {
+				link = detail(g.r.Vote.Link, commit, key, item.Line)
+			}
+			p := &gitlabPosition{BaseSha: m.DiffRefs.BaseSha, HeadSha: m.DiffRefs.HeadSha, NewLine: item.Line,
+				NewPath: key, OldPath: key, PositionType: "text", StartSha: m.DiffRefs.StartSha}
+			if old[key] != "" {
+				p.OldPath = old[key]
+			}
+			discussions = append(discussions, gitlabDiscussionInput{Body: truncate(item.Message, size, link), Position: p})
+		}
+	}
+
+	sort.Slice(discussions, func(i, j int) bool {
+		if discussions[i].Position.NewPath != discussions[j].Position.NewPath {
+			return discussions[i].Position.NewPath < discussions[j].Position.NewPath
+		}
+		return discussions[i].Position.NewLine < discussions[j].Position.NewLine
+	})
+
+	body := changeLevel(g.r.Vote.Message, change)
+	if mode == proto.ModeVote || mode == proto.ModeFreezeVote {
+		discussions, body = nil, summary(g.r.Vote.Message, f)
+	}
+
+	body = annotate(body, note)
+
+	for index := range discussions {
+		if err := g.post(g.endpoint(nil, "merge_requests", strconv.Itoa(m.Iid), "discussions"),
+			&discussions[index]); err != nil {
+			return errors.Wrap(err, "failed to discuss")
+		}
+	}
+
+	if body != "" {
+		if err := g.post(g.endpoint(nil, "merge_requests", strconv.Itoa(m.Iid), "notes"),
+			map[string]string{"body": truncate(body, size, "")}); err != nil {
+			return errors.Wrap(err, "failed to note")
+		}
+	}
+
+	// Approval is kept as is in comment mode, and is never revoked in freeze mode
+	block := disapproving(labels(f, &g.r.Vote, g.p), &g.r.Vote)
+	frozen := mode == proto.ModeFreeze || mode == proto.ModeFreezeVote
+	if mode != proto.ModeComment && !(frozen && block) {
+		if err := g.approve(m.Iid, commit, !block); err != nil {
+			return errors.Wrap(err, "failed to approve")
+		}
+	}
+
+	if security(data, &g.r.Security) {
+		if g.r.Security.Hashtag != "" {
+			if err := g.put(g.endpoint(nil, "merge_requests", strconv.Itoa(m.Iid)),
+				map[string]string{"add_labels": g.r.Security.Hashtag}); err != nil {
+				return errors.Wrap(err, "failed to label")
+			}
+		}
+		if len(g.r.Security.Reviewers) != 0 {
+			log.Println("security reviewers unavailable")
+		}
+	}
+
+	return nil
+}
+
+// approve approves or unapproves merge request at commit, in which failure to unapprove merge request which is not
+// approved by the bot before, i.e., 404 or 401, is ignored.
+func (g *gitlab) approve(iid int, commit string, approval bool) error {
+	action := "unapprove"
+	if approval {
+		action = "approve"
+	}
+
+	err := g.post(g.endpoint(nil, "merge_requests", strconv.Itoa(iid), action), map[string]string{"sha": commit})
+	if err == nil {
+		return nil
+	}
+
+	if s, ok := errors.Cause(err).(*statusError); ok && !approval &&
+		(s.code == http.StatusNotFound || s.code == http.StatusUnauthorized) {
+		return nil
+	}
+
+	return errors.Wrap(err, "failed to "+action)
+}
+
+// merge finds the merge request of commit, preferring opened ones whose head is commit.
+func (g *gitlab) merge(commit string) (*gitlabMerge, error) {
+	buf, err := g.get(g.endpoint(nil, "repository", "commits", commit, "merge_requests"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	var merges []gitlabMerge
+
+	if err := json.Unmarshal(buf, &merges); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if len(merges) == 0 {
+		return nil, errors.New("invalid merge request")
+	}
+
+	iid := merges[0].Iid
+
+	for _, item := range merges {
+		if item.Sha == commit && item.State == gitlabOpened {
+			iid = item.Iid
+			break
+		}
+	}
+
+	// Refs of diff are in detail of merge request only
+	buf, err = g.get(g.endpoint(nil, "merge_requests", strconv.Itoa(iid)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	ret := &gitlabMerge{}
+
+	if err := json.Unmarshal(buf, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return ret, nil
+}
+
+func (g *gitlab) commit(commit string) (*gitlabCommit, error) {
+	buf, err := g.get(g.endpoint(nil, "repository", "commits", commit))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	ret := &gitlabCommit{}
+
+	if err := json.Unmarshal(buf, ret); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return ret, nil
+}
+
+// diffs lists diffs of files in merge request in pages, and parses them in unified format of git as well.
+func (g *gitlab) discussions(iid int) ([]gitlabDiscussion, error) {
+	var ret []gitlabDiscussion
+
+	for page := 1; page <= gitlabPages; page++ {
+		buf, err := g.get(g.endpoint(g.page(page), "merge_requests", strconv.Itoa(iid), "discussions"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get")
+		}
+		var discussions []gitlabDiscussion
+		if err := json.Unmarshal(buf, &discussions); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		ret = append(ret, discussions...)
+		if len(discussions) < gitlabPerPage {
+			break
+		}
+	}
+
+	return ret, nil
+}
+
+// remarks gets notes of reviewers in discussions on commit in merge request, on line of their first notes.
+func (g *gitlab) remarks(iid int, commit string) ([]remark, error) {
+	discussions, err := g.discussions(iid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discussions")
+	}
+
+	var ret []remark
+
+	for _, item := range discussions {
+		if len(item.Notes) == 0 || item.Notes[0].Position == nil {
+			continue
+		}
+		p := item.Notes[0].Position
+		if p.HeadSha != "" && p.HeadSha != commit {
+			continue
+		}
+		for _, val := range item.Notes {
+			if human(val.Author.Username, g.r.User) {
+				ret = append(ret, remark{Author: val.Author.Username, File: p.NewPath, Line: p.NewLine, Message: val.Body})
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (g *gitlab) diffs(iid int) ([]gitlabDiff, []*diff.File, error) {
+	var ret []gitlabDiff
+
+	for page := 1; page <= gitlabPages; page++ {
+		buf, err := g.get(g.endpoint(g.page(page), "merge_requests", strconv.Itoa(iid), "diffs"))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get")
+		}
+		var d []gitlabDiff
+		if err := json.Unmarshal(buf, &d); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		ret = append(ret, d...)
+		if len(d) < gitlabPerPage {
+			break
+		}
+	}
+
+	var patch strings.Builder
+
+	for _, item := range ret {
+		from, to := "a/"+item.OldPath, "b/"+item.NewPath
+		if item.NewFile {
+			from = diff.DevNull
+		}
+		if item.DeletedFile {
+			to = diff.DevNull
+		}
+		patch.WriteString("diff --git a/" + item.OldPath + " b/" + item.NewPath + "\n--- " + from + "\n+++ " + to + "\n")
+		patch.WriteString(item.Diff)
+		if !strings.HasSuffix(item.Diff, "\n") {
+			patch.WriteString("\n")
+		}
+	}
+
+	diffs, err := diff.Parse([]byte(patch.String()))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse")
+	}
+
+	return ret, diffs, nil
+}
+
+// stats counts lines inserted and deleted in diffs, and changed lines of each file.
+func stats(diffs []*diff.File) (lines map[string]int, insertions, deletions int) {
+	lines = map[string]int{}
+
+	for _, d := range diffs {
+		name := d.New
+		if name == "" {
+			name = d.Old
+		}
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				switch l.Type {
+				case diff.LineAdded:
+					insertions++
+				case diff.LineDeleted:
+					deletions++
+				default:
+					continue
+				}
+				lines[name]++
+			}
+		}
+	}
+
+	return lines, insertions, deletions
+}
+
+func (g *gitlab) ignore(commit string) ignore.Ignore {
+	buf, err := g.get(g.urlRaw(ignore.Name, commit))
+	if err != nil {
+		// Missing ignore file in commit
+		return ignore.New(nil)
+	}
+
+	return ignore.New(buf)
+}
+
+// file maps the slash-separated file name in GitLab to the slash-separated name in workspace
+func (g *gitlab) file(name string) string {
+	return path.Join(path.Dir(name), path.Base(name)+proto.Base64Content)
+}
+
+func (g *gitlab) write(root, file, data string) error {
+	if err := g.s.Write(filepath.Join(root, filepath.FromSlash(file)), []byte(data)); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// base returns URL of GitLab, e.g. https://gitlab.com, or https://example.com/gitlab for self-managed ones
+func (g *gitlab) base() string {
+	if g.r.Url != "" {
+		return strings.TrimSuffix(g.r.Url, "/")
+	}
+
+	if g.r.Host != "" {
+		return strings.TrimSuffix(g.r.Host, "/") + ":" + strconv.Itoa(g.r.Port)
+	}
+
+	return gitlabUrl
+}
+
+// endpoint joins escaped path elements to API of project, in which path of project is encoded as its ID, e.g.
+// /api/v4/projects/group%2Fproject/merge_requests
+func (g *gitlab) endpoint(query url.Values, elem ...string) string {
+	u, err := url.Parse(g.base())
+	if err != nil {
+		return ""
+	}
+
+	name := []string{strings.TrimSuffix(u.Path, "/"), "api", "v4", "projects"}
+	raw := []string{strings.TrimSuffix(u.EscapedPath(), "/"), "api", "v4", "projects"}
+
+	for _, val := range append([]string{g.r.Repo}, elem...) {
+		name, raw = append(name, val), append(raw, url.PathEscape(val))
+	}
+
+	u.Path = strings.Join(name, "/")
+	u.RawPath = strings.Join(raw, "/")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (g *gitlab) page(page int) url.Values {
+	return url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(gitlabPerPage)}}
+}
+
+func (g *gitlab) urlRaw(name, ref string) string {
+	return g.endpoint(url.Values{"ref": {ref}}, "repository", "files", name, "raw")
+}
+
+func (g *gitlab) get(_url string) ([]byte, error) {
+	return request(g.c, &g.r, http.MethodGet, _url, "", nil)
+}
+
+func (g *gitlab) post(_url string, data interface{}) error {
+	_, err := request(g.c, &g.r, http.MethodPost, _url, "", data)
+	return err
+}
+
+func (g *gitlab) put(_url string, data interface{}) error {
+	_, err := request(g.c, &g.r, http.MethodPut, _url, "", data)
+	return err
+}
@@ -0,0 +1,311 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	commitGitlab = "a1e8f8d745cc87e3a9248358d9352bb7f9a0aeba"
+	repoGitlab   = "group/project"
+)
+
+// fakeGitlab implements the subset of GitLab REST API used by lintflow, and records what is posted.
+type fakeGitlab struct {
+	*httptest.Server
+	mu          sync.Mutex
+	approval    int
+	approvals   []string
+	discussions []gitlabDiscussionInput
+	labels      []string
+	notes       []string
+}
+
+func newFakeGitlab() *fakeGitlab {
+	s := &fakeGitlab{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *fakeGitlab) gitlab(r config.Review) gitlab {
+	r.Repo, r.Url = repoGitlab, s.URL
+
+	return gitlab{c: http.DefaultClient, p: policy.New(policy.DefaultConfig()), r: r, s: storage.New(storage.DefaultConfig())}
+}
+
+// nolint:gocyclo
+func (s *fakeGitlab) serve(w http.ResponseWriter, r *http.Request) {
+	prefix := "/api/v4/projects/group%2Fproject/"
+	if !strings.HasPrefix(r.URL.EscapedPath(), prefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	content := map[string]string{
+		commitGitlab + "/main.go":    "package main\n\nfunc main() {}\n",
+		commitGitlab + "/src/lib.go": "package src\n",
+		"base/old/lib.go":            "package old\n",
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch p := strings.TrimPrefix(r.URL.EscapedPath(), prefix); {
+	case p == "repository/commits/"+commitGitlab+"/merge_requests":
+		s.json(w, []gitlabMerge{{Iid: 5, Sha: commitGitlab, State: gitlabOpened}})
+	case p == "repository/commits/"+commitGitlab:
+		s.json(w, gitlabCommit{AuthorEmail: "dev@example.com", Message: "Add main\n"})
+	case p == "merge_requests/5" && r.Method == http.MethodPut:
+		var buf map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.labels = append(s.labels, buf["add_labels"])
+	case p == "merge_requests/5":
+		s.json(w, gitlabMerge{Author: gitlabUser{Username: "dev"}, DiffRefs: gitlabRefs{BaseSha: "base",
+			HeadSha: commitGitlab, StartSha: "base"}, Iid: 5, Sha: commitGitlab, State: gitlabOpened, TargetBranch: "main"})
+	case p == "merge_requests/5/diffs":
+		s.json(w, []gitlabDiff{
+			{Diff: "@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n", NewFile: true, NewPath: "main.go", OldPath: "main.go"},
+			{DeletedFile: true, Diff: "@@ -1 +0,0 @@\n-package gone\n", NewPath: "gone.go", OldPath: "gone.go"},
+			{Diff: "@@ -1 +1 @@\n-package old\n+package src\n", NewPath: "src/lib.go", OldPath: "old/lib.go"},
+		})
+	case p == "merge_requests/5/discussions" && r.Method == http.MethodPost:
+		var buf gitlabDiscussionInput
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.discussions = append(s.discussions, buf)
+		w.WriteHeader(http.StatusCreated)
+	case p == "merge_requests/5/discussions":
+		position := &gitlabPosition{HeadSha: commitGitlab, NewLine: 3, NewPath: "main.go"}
+		s.json(w, []gitlabDiscussion{{Notes: []gitlabNote{
+			{Body: "unused", ID: 1, Position: position},
+			{Author: gitlabUser{Username: "dev"}, Body: "/lintflow false-positive", ID: 2, Position: position},
+		}}})
+	case p == "merge_requests/5/notes":
+		var buf map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		s.notes = append(s.notes, buf["body"])
+		w.WriteHeader(http.StatusCreated)
+	case p == "merge_requests/5/approve" || p == "merge_requests/5/unapprove":
+		s.approvals = append(s.approvals, strings.TrimPrefix(p, "merge_requests/5/"))
+		if s.approval != 0 {
+			w.WriteHeader(s.approval)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(p, "repository/files/") && strings.HasSuffix(p, "/raw"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v4/projects/group/project/repository/files/"), "/raw")
+		if val, ok := content[r.URL.Query().Get("ref")+"/"+name]; ok {
+			_, _ = w.Write([]byte(val))
+			return
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeGitlab) json(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// nolint: dogsled
+func TestGitlabFetch(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "gitlab-test-fetch")
+
+	_, _, _, err := h.Fetch(root, "invalid")
+	assert.NotEqual(t, nil, err)
+
+	dname, rname, files, err := h.Fetch(root, commitGitlab)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filepath.Join(root, "5", commitGitlab), dname)
+	assert.Equal(t, repoGitlab, rname)
+
+	sort.Strings(files)
+	assert.Equal(t, []string{"main.go" + proto.Base64Content, proto.Base64Message, "src/lib.go" + proto.Base64Content}, files)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dname, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package src\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestGitlabChange(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{})
+
+	c, err := h.Change(commitGitlab)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dev@example.com", c.Author)
+	assert.Equal(t, "main", c.Branch)
+	assert.Equal(t, 4, c.Insertions)
+	assert.Equal(t, 2, c.Deletions)
+	assert.Equal(t, "Add main\n", c.Message)
+	assert.Equal(t, 5, c.Number)
+	assert.Equal(t, repoGitlab, c.Project)
+	assert.Equal(t, map[string]int{"gone.go": 1, "main.go": 3, "src/lib.go": 2}, c.Lines)
+}
+
+func TestGitlabParent(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{})
+
+	d, _ := os.Getwd()
+	root := filepath.Join(d, "gitlab-test-parent")
+
+	err := h.Parent(root, commitGitlab, []string{"main.go" + proto.Base64Content, proto.Base64Message,
+		"src/lib.go" + proto.Base64Content})
+	assert.Equal(t, nil, err)
+
+	_, err = os.Stat(filepath.Join(root, proto.ParentDir, "main.go"+proto.Base64Content))
+	assert.NotEqual(t, nil, err)
+
+	buf, err := ioutil.ReadFile(filepath.Join(root, proto.ParentDir, "src", "lib.go"+proto.Base64Content))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("package old\n")), string(buf))
+
+	err = h.Clean(root)
+	assert.Equal(t, nil, err)
+}
+
+func TestGitlabVote(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{Security: config.Security{Hashtag: "security"},
+		Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}})
+
+	err := h.Vote(commitGitlab, nil, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"approve"}, s.approvals)
+	assert.Equal(t, 0, len(s.discussions))
+	assert.Equal(t, 0, len(s.notes))
+
+	data := []proto.Format{
+		{Details: "unused", File: "main.go", Line: 3, Type: proto.TypeError},
+		{Details: "changed", File: "src/lib.go", Line: 1, Type: proto.TypeWarn},
+		{Category: proto.CategorySecurity, Details: "too long", File: proto.Base64Message, Line: 1, Type: proto.TypeError},
+	}
+
+	err = h.Vote(commitGitlab, data, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"approve", "unapprove"}, s.approvals)
+	assert.Equal(t, 2, len(s.discussions))
+	assert.Equal(t, "main.go", s.discussions[0].Position.NewPath)
+	assert.Equal(t, 3, s.discussions[0].Position.NewLine)
+	assert.Equal(t, commitGitlab, s.discussions[0].Position.HeadSha)
+	assert.Equal(t, "old/lib.go", s.discussions[1].Position.OldPath)
+	assert.Equal(t, 1, len(s.notes))
+	assert.Equal(t, true, strings.Contains(s.notes[0], "too long"))
+	assert.Equal(t, []string{"security"}, s.labels)
+
+	err = h.Vote(commitGitlab, data, proto.ModeComment, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(s.approvals))
+
+	err = h.Vote(commitGitlab, data, proto.ModeFreeze, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(s.approvals))
+
+	err = h.Vote(commitGitlab, data, proto.ModeVote, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 6, len(s.discussions))
+	assert.Equal(t, true, strings.Contains(s.notes[len(s.notes)-1], "lintflow found 3 findings"))
+}
+
+func TestGitlabApprove(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{Vote: config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}})
+	data := []proto.Format{{Details: "unused", File: "main.go", Line: 3, Type: proto.TypeError}}
+
+	s.approval = http.StatusUnauthorized
+
+	err := h.Vote(commitGitlab, data, proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+
+	err = h.Vote(commitGitlab, nil, proto.ModeFull, nil)
+	assert.NotEqual(t, nil, err)
+
+	s.approval = http.StatusInternalServerError
+
+	err = h.Vote(commitGitlab, data, proto.ModeFull, nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGitlabNotify(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{})
+
+	err := h.Notify(commitGitlab, "hello")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"hello"}, s.notes)
+}
+
+func TestGitlabFeedback(t *testing.T) {
+	s := newFakeGitlab()
+	defer s.Close()
+
+	h := s.gitlab(config.Review{})
+
+	buf, err := h.Feedback(commitGitlab, "/lintflow false-positive")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "2", buf[0].ID)
+	assert.Equal(t, "dev", buf[0].Author)
+	assert.Equal(t, "main.go", buf[0].File)
+	assert.Equal(t, 3, buf[0].Line)
+}
+
+func TestGitlabEndpoint(t *testing.T) {
+	h := gitlab{r: config.Review{Repo: repoGitlab}}
+	assert.Equal(t, "https://gitlab.com/api/v4/projects/group%2Fproject/merge_requests/5",
+		h.endpoint(nil, "merge_requests", "5"))
+
+	h.r.Url = "https://example.com/gitlab/"
+	assert.Equal(t, "https://example.com/gitlab/api/v4/projects/group%2Fproject/repository/files/src%2Fa.go/raw?ref=main",
+		h.urlRaw("src/a.go", "main"))
+}
@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	attentionBlocking = "Blocking findings by lintflow"
+	attentionReviewed = "Reviewed by lintflow"
+	attentionSelf     = "self"
+)
+
+// labels votes on label, or on each of labels by findings of its lints independently.
+func labels(data []proto.Format, vote *config.Vote, p policy.Policy) map[string]interface{} {
+	value := func(data []proto.Format, approval, disapproval string) string {
+		if p.Approve(data) {
+			return approval
+		}
+		return disapproval
+	}
+
+	if len(vote.Labels) == 0 {
+		return map[string]interface{}{vote.Label: value(data, vote.Approval, vote.Disapproval)}
+	}
+
+	ret := map[string]interface{}{}
+
+	for _, label := range vote.Labels {
+		ret[label.Name] = value(filterLint(data, label.Lint), label.Approval, label.Disapproval)
+	}
+
+	return ret
+}
+
+// approving keeps approving votes in labels only, which withholds disapproving ones, e.g., in freeze.
+func approving(labels map[string]interface{}, vote *config.Vote) map[string]interface{} {
+	approval := map[string]interface{}{vote.Label: vote.Approval}
+	for _, label := range vote.Labels {
+		approval[label.Name] = label.Approval
+	}
+
+	ret := map[string]interface{}{}
+
+	for key, val := range labels {
+		if val == approval[key] {
+			ret[key] = val
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil
+	}
+
+	return ret
+}
+
+// disapproving reports whether any of labels is voted other than approval, i.e., findings block change.
+func disapproving(labels map[string]interface{}, vote *config.Vote) bool {
+	return len(labels) != len(approving(labels, vote))
+}
+
+// attention adds owner of change to attention set if findings block it, and removes the bot from it instead of
+// automatic rules of Gerrit, which keeps the bot from waiting on attention of humans.
+func attention(r *reviewInput, block bool, owner string, vote *config.Vote) {
+	if !vote.Attention {
+		return
+	}
+
+	if block && owner != "" {
+		r.AddToAttentionSet = []attentionSetInput{{Reason: attentionBlocking, User: owner}}
+	}
+
+	r.IgnoreAutomaticAttentionSetRules = true
+	r.RemoveFromAttentionSet = []attentionSetInput{{Reason: attentionReviewed, User: attentionSelf}}
+}
+
+// withdraw resets votes on labels.
+func withdraw(vote *config.Vote) map[string]interface{} {
+	if len(vote.Labels) == 0 {
+		return map[string]interface{}{vote.Label: 0}
+	}
+
+	ret := map[string]interface{}{}
+
+	for _, label := range vote.Labels {
+		ret[label.Name] = 0
+	}
+
+	return ret
+}
+
+func filterLint(data []proto.Format, lints []string) []proto.Format {
+	if len(lints) == 0 {
+		return data
+	}
+
+	var ret []proto.Format
+
+	for _, item := range data {
+		for _, val := range lints {
+			if item.Lint == val {
+				ret = append(ret, item)
+				break
+			}
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,74 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestLabels(t *testing.T) {
+	p := policy.New(policy.DefaultConfig())
+	data := []proto.Format{{File: "main.go", Line: 1, Type: proto.TypeError, Details: "text", Lint: "lintgo"}}
+
+	vote := config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, labels(nil, &vote, p))
+	assert.Equal(t, map[string]interface{}{"Code-Review": "-1"}, labels(data, &vote, p))
+	assert.Equal(t, map[string]interface{}{"Code-Review": 0}, withdraw(&vote))
+
+	vote.Labels = []config.Label{
+		{Approval: "+1", Disapproval: "-1", Lint: []string{"lintstyle"}, Name: "Code-Style"},
+		{Approval: "+1", Disapproval: "-2", Lint: []string{"lintgo", "lintcpp"}, Name: "Static-Analysis"},
+	}
+	assert.Equal(t, map[string]interface{}{"Code-Style": "+1", "Static-Analysis": "-2"}, labels(data, &vote, p))
+	assert.Equal(t, map[string]interface{}{"Code-Style": 0, "Static-Analysis": 0}, withdraw(&vote))
+}
+
+func TestApproving(t *testing.T) {
+	vote := config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}
+	assert.Equal(t, map[string]interface{}{"Code-Review": "+1"}, approving(map[string]interface{}{"Code-Review": "+1"}, &vote))
+	assert.Equal(t, map[string]interface{}(nil), approving(map[string]interface{}{"Code-Review": "-1"}, &vote))
+
+	vote.Labels = []config.Label{
+		{Approval: "+1", Disapproval: "-1", Name: "Code-Style"},
+		{Approval: "+1", Disapproval: "-2", Name: "Static-Analysis"},
+	}
+	assert.Equal(t, map[string]interface{}{"Code-Style": "+1"},
+		approving(map[string]interface{}{"Code-Style": "+1", "Static-Analysis": "-2"}, &vote))
+}
+
+func TestAttention(t *testing.T) {
+	vote := config.Vote{Approval: "+1", Disapproval: "-1", Label: "Code-Review"}
+	assert.Equal(t, false, disapproving(map[string]interface{}{"Code-Review": "+1"}, &vote))
+	assert.Equal(t, true, disapproving(map[string]interface{}{"Code-Review": "-1"}, &vote))
+	assert.Equal(t, false, disapproving(nil, &vote))
+
+	var r reviewInput
+	attention(&r, true, "1000", &vote)
+	assert.Equal(t, reviewInput{}, r)
+
+	vote.Attention = true
+
+	attention(&r, false, "1000", &vote)
+	assert.Equal(t, 0, len(r.AddToAttentionSet))
+	assert.Equal(t, []attentionSetInput{{Reason: attentionReviewed, User: attentionSelf}}, r.RemoveFromAttentionSet)
+	assert.Equal(t, true, r.IgnoreAutomaticAttentionSetRules)
+
+	attention(&r, true, "1000", &vote)
+	assert.Equal(t, []attentionSetInput{{Reason: attentionBlocking, User: "1000"}}, r.AddToAttentionSet)
+}
@@ -0,0 +1,74 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limit caps download rate of responses in bytes per second, shared by all requests of client.
+type limit struct {
+	at   time.Time
+	mu   sync.Mutex
+	next http.RoundTripper
+	rate int
+}
+
+type limitReader struct {
+	l *limit
+	r io.ReadCloser
+}
+
+func (l *limit) RoundTrip(req *http.Request) (*http.Response, error) {
+	rsp, err := l.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.Body = &limitReader{l: l, r: rsp.Body}
+
+	return rsp, nil
+}
+
+func (l *limit) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.at.Before(now) {
+		l.at = now
+	}
+	l.at = l.at.Add(time.Duration(n) * time.Second / time.Duration(l.rate))
+	d := l.at.Sub(now)
+	l.mu.Unlock()
+
+	time.Sleep(d)
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if len(p) > r.l.rate {
+		p = p[:r.l.rate]
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.l.wait(n)
+	}
+
+	return n, err
+}
+
+func (r *limitReader) Close() error {
+	return r.r.Close()
+}
@@ -0,0 +1,46 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("a"), 2048))
+	}))
+	defer ts.Close()
+
+	c := &http.Client{Transport: &limit{next: http.DefaultTransport, rate: 4096}}
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		rsp, err := c.Get(ts.URL)
+		assert.Equal(t, nil, err)
+		buf, _ := ioutil.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+		assert.Equal(t, 2048, len(buf))
+	}
+
+	// 6 KiB in 4 KiB/s
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(1400*time.Millisecond))
+}
@@ -0,0 +1,181 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Models of Gerrit REST API in subset used, see https://gerrit-review.googlesource.com/Documentation/rest-api.html
+
+type accountInfo struct {
+	AccountID int    `json:"_account_id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+}
+
+type changeInfo struct {
+	Branch          string                  `json:"branch"`
+	CurrentRevision string                  `json:"current_revision"`
+	Deletions       int                     `json:"deletions"`
+	Insertions      int                     `json:"insertions"`
+	Number          int                     `json:"_number"`
+	Owner           accountInfo             `json:"owner"`
+	Project         string                  `json:"project"`
+	Revisions       map[string]revisionInfo `json:"revisions"`
+	Topic           string                  `json:"topic"`
+}
+
+type commentInfo struct {
+	ID        string      `json:"id"`
+	Author    accountInfo `json:"author"`
+	CommitID  string      `json:"commit_id"`
+	InReplyTo string      `json:"in_reply_to"`
+	Line      int         `json:"line"`
+	Message   string      `json:"message"`
+	Updated   string      `json:"updated"`
+}
+
+type commitInfo struct {
+	Author  gitPersonInfo `json:"author"`
+	Message string        `json:"message"`
+	Subject string        `json:"subject"`
+}
+
+type gitPersonInfo struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type fileInfo struct {
+	LinesDeleted  int    `json:"lines_deleted"`
+	LinesInserted int    `json:"lines_inserted"`
+	Size          *int64 `json:"size"`
+	Status        string `json:"status"`
+}
+
+type revisionInfo struct {
+	Commit          commitInfo           `json:"commit"`
+	Files           map[string]fileInfo  `json:"files"`
+	Kind            string               `json:"kind"`
+	Number          int                  `json:"_number"`
+	PushCertificate *pushCertificateInfo `json:"push_certificate"`
+}
+
+// pushCertificateInfo is certificate of signed push, whose key is without status if signature is not checked.
+type pushCertificateInfo struct {
+	Certificate string     `json:"certificate"`
+	Key         gpgKeyInfo `json:"key"`
+}
+
+type gpgKeyInfo struct {
+	Problems []string `json:"problems"`
+	Status   string   `json:"status"`
+}
+
+// commentInput is on file without line if line is 0.
+type commentInput struct {
+	Line       int    `json:"line,omitempty"`
+	Message    string `json:"message"`
+	Path       string `json:"path,omitempty"`
+	RobotID    string `json:"robot_id,omitempty"`
+	RobotRunID string `json:"robot_run_id,omitempty"`
+}
+
+type hashtagsInput struct {
+	Add []string `json:"add"`
+}
+
+type reviewerInput struct {
+	Reviewer string `json:"reviewer"`
+}
+
+type attentionSetInput struct {
+	Reason string `json:"reason"`
+	User   string `json:"user"`
+}
+
+type reviewInput struct {
+	AddToAttentionSet                []attentionSetInput       `json:"add_to_attention_set,omitempty"`
+	Comments                         map[string][]commentInput `json:"comments,omitempty"`
+	Drafts                           string                    `json:"drafts,omitempty"`
+	IgnoreAutomaticAttentionSetRules bool                      `json:"ignore_automatic_attention_set_rules,omitempty"`
+	Labels                           map[string]interface{}    `json:"labels,omitempty"`
+	Message                          string                    `json:"message,omitempty"`
+	Notify                           string                    `json:"notify,omitempty"`
+	RemoveFromAttentionSet           []attentionSetInput       `json:"remove_from_attention_set,omitempty"`
+	Reviewers                        []reviewerInput           `json:"reviewers,omitempty"`
+	RobotComments                    map[string][]commentInput `json:"robot_comments,omitempty"`
+}
+
+// account returns identifier of account, which is its ID, or email if ID is absent.
+func (a *accountInfo) account() string {
+	if a.AccountID != 0 {
+		return strconv.Itoa(a.AccountID)
+	}
+
+	return a.Email
+}
+
+// revision returns commit of revision in number, which is queried with ALL_REVISIONS.
+func (c *changeInfo) revision(number int) (string, *revisionInfo) {
+	for key, val := range c.Revisions {
+		if val.Number == number {
+			return key, &val
+		}
+	}
+
+	return "", nil
+}
+
+// current returns current revision of change, which is queried with CURRENT_REVISION.
+func (c *changeInfo) current() (*revisionInfo, error) {
+	r, ok := c.Revisions[c.CurrentRevision]
+	if !ok {
+		return nil, errors.New("invalid current revision")
+	}
+
+	return &r, nil
+}
+
+// query gets the first change of search with options.
+func (g *gerrit) query(search string, option ...string) (*changeInfo, error) {
+	c, err := g.queryAll(search, option...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c) == 0 {
+		return nil, errors.New("failed to match")
+	}
+
+	return &c[0], nil
+}
+
+func (g *gerrit) queryAll(search string, option ...string) ([]changeInfo, error) {
+	buf, err := g.get(g.urlQuery(search, option, 0))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	var c []changeInfo
+
+	if err := decode(buf, &c); err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	return c, nil
+}
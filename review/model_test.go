@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestCurrent(t *testing.T) {
+	c := changeInfo{CurrentRevision: "abc", Revisions: map[string]revisionInfo{"abc": {Number: 2}}}
+
+	r, err := c.current()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, r.Number)
+
+	c.CurrentRevision = "def"
+	_, err = c.current()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "commit:abc" {
+			_, _ = w.Write([]byte(")]}'\n" + `[{"_number":41,"owner":{"email":"dev@example.com"},"revisions":{"abc":{"files":{"main.go":{}}}}}]`))
+			return
+		}
+		_, _ = w.Write([]byte(")]}'\n" + `[]`))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Url: ts.URL}}
+
+	c, err := g.query("commit:abc", "CURRENT_REVISION")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 41, c.Number)
+	assert.Equal(t, "dev@example.com", c.Owner.Email)
+	assert.Equal(t, map[string]fileInfo{"main.go": {}}, c.Revisions["abc"].Files)
+
+	_, err = g.query("commit:def")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,82 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	packFile  = "file"
+	packRange = "range"
+)
+
+const (
+	packSpan = 5
+)
+
+// pack combines comments of one file, into one comment of file or one comment per range of lines.
+func pack(data []commentInput, vote *config.Vote) []commentInput {
+	if (vote.Pack != packFile && vote.Pack != packRange) || len(data) < 2 {
+		return data
+	}
+
+	span := vote.Range
+	if span <= 0 {
+		span = packSpan
+	}
+
+	buf := make([]commentInput, len(data))
+	copy(buf, data)
+
+	sort.SliceStable(buf, func(i, j int) bool {
+		return buf[i].Line < buf[j].Line
+	})
+
+	var ret []commentInput
+	var group []commentInput
+
+	flush := func() {
+		if len(group) == 0 {
+			return
+		}
+		if len(group) == 1 {
+			ret = append(ret, group[0])
+			group = nil
+			return
+		}
+		var msg []string
+		for _, val := range group {
+			msg = append(msg, fmt.Sprintf("- Line %d: %s", val.Line, val.Message))
+		}
+		c := group[0]
+		c.Message = strings.Join(msg, "\n")
+		ret = append(ret, c)
+		group = nil
+	}
+
+	for _, val := range buf {
+		if len(group) != 0 && vote.Pack == packRange && val.Line-group[0].Line > span {
+			flush()
+		}
+		group = append(group, val)
+	}
+
+	flush()
+
+	return ret
+}
@@ -0,0 +1,43 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestPack(t *testing.T) {
+	data := []commentInput{
+		{Line: 12, Message: "c"},
+		{Line: 1, Message: "a"},
+		{Line: 3, Message: "b"},
+	}
+
+	assert.Equal(t, data, pack(data, &config.Vote{}))
+
+	buf := pack(data, &config.Vote{Pack: packFile})
+	assert.Equal(t, []commentInput{{Line: 1, Message: "- Line 1: a\n- Line 3: b\n- Line 12: c"}}, buf)
+
+	buf = pack(data, &config.Vote{Pack: packRange})
+	assert.Equal(t, []commentInput{
+		{Line: 1, Message: "- Line 1: a\n- Line 3: b"},
+		{Line: 12, Message: "c"},
+	}, buf)
+
+	buf = pack(data, &config.Vote{Pack: packRange, Range: 20})
+	assert.Equal(t, 1, len(buf))
+}
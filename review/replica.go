@@ -0,0 +1,98 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	replicaCooldown = 30 * time.Second
+)
+
+// replica tracks health of read replicas, where failed ones are skipped for cooldown.
+type replica struct {
+	down  map[string]time.Time
+	mutex sync.Mutex
+}
+
+func (r *replica) healthy(name string) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return time.Since(r.down[name]) >= replicaCooldown
+}
+
+func (r *replica) fail(name string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.down[name] = time.Now()
+}
+
+// fetch does request of files of revision on healthy replicas in order, and fails over to primary.
+// Not found on replica is not failure since replication may lag behind.
+func (g *gerrit) fetch(req *http.Request) (*http.Response, error) {
+	base := g.base()
+
+	if len(g.r.Replica) == 0 || !strings.HasPrefix(req.URL.String(), base) {
+		return do(g.c, req, &g.r)
+	}
+
+	for _, val := range g.r.Replica {
+		if !g.h.healthy(val) {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSuffix(val, "/") + strings.TrimPrefix(req.URL.String(), base))
+		if err != nil {
+			log.Println(errors.Wrap(err, "failed to parse replica "+val))
+			continue
+		}
+		r := req.Clone(req.Context())
+		r.URL, r.Host = u, ""
+		rsp, err := do(g.c, r, &g.r)
+		if err == nil && (rsp.StatusCode == http.StatusOK || rsp.StatusCode == http.StatusNotModified) {
+			return rsp, nil
+		}
+		if err == nil {
+			// Drain body to reuse connection
+			_, _ = io.Copy(ioutil.Discard, rsp.Body)
+			_ = rsp.Body.Close()
+			if rsp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			err = errors.New("invalid status " + rsp.Status)
+		}
+		log.Printf("replica %s failed over: %v", val, err)
+		g.h.fail(val)
+	}
+
+	return do(g.c, req, &g.r)
+}
@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestGetReplica(t *testing.T) {
+	var primary, replicated int
+
+	status := http.StatusOK
+
+	p := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primary++
+		_, _ = w.Write([]byte("primary"))
+	}))
+	defer p.Close()
+
+	rs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replicated++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("replica " + r.URL.Path))
+	}))
+	defer rs.Close()
+
+	g := gerrit{c: http.DefaultClient, h: &replica{down: map[string]time.Time{}},
+		r: config.Review{Url: p.URL, Replica: []string{rs.URL + "/"}}}
+
+	buf, err := g.getReplica(g.urlFiles(1, 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "replica /changes/1/revisions/1/files/", string(buf))
+
+	buf, err = g.get(g.urlFiles(1, 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "primary", string(buf))
+
+	status = http.StatusNotFound
+
+	buf, err = g.getReplica(g.urlFiles(1, 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "primary", string(buf))
+	assert.Equal(t, true, g.h.healthy(rs.URL+"/"))
+
+	status = http.StatusServiceUnavailable
+
+	buf, err = g.getReplica(g.urlFiles(1, 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "primary", string(buf))
+	assert.Equal(t, false, g.h.healthy(rs.URL+"/"))
+
+	buf, err = g.getReplica(g.urlFiles(1, 1))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "primary", string(buf))
+	assert.Equal(t, 3, replicated)
+	assert.Equal(t, 4, primary)
+}
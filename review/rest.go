@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	contentJson = "application/json;charset=utf-8"
+)
+
+// statusError is response of REST API in unexpected status, so that expected failures could be told apart.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (s *statusError) Error() string {
+	return "invalid status " + s.status
+}
+
+// request sends data in JSON if any to REST API of review, and returns body of response in success.
+func request(c *http.Client, r *config.Review, method, _url, accept string, data interface{}) ([]byte, error) {
+	var buf io.Reader
+
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal")
+		}
+		buf = bytes.NewBuffer(b)
+	}
+
+	req, err := http.NewRequest(method, _url, buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if data != nil {
+		req.Header.Set("Content-Type", contentJson)
+	}
+
+	rsp, err := do(c, req, r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusCreated && rsp.StatusCode != http.StatusNoContent {
+		// Drain body to reuse connection
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		return nil, &statusError{code: rsp.StatusCode, status: rsp.Status}
+	}
+
+	ret, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,55 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestRequest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(r.Header.Get("Accept")))
+			return
+		}
+		var buf map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&buf)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Header.Get("Content-Type") + " " + buf["body"]))
+	}))
+	defer s.Close()
+
+	r := config.Review{}
+
+	buf, err := request(http.DefaultClient, &r, http.MethodGet, s.URL, "text/plain", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "text/plain", string(buf))
+
+	buf, err = request(http.DefaultClient, &r, http.MethodPost, s.URL, "", map[string]string{"body": "hello"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, contentJson+" hello", string(buf))
+
+	_, err = request(http.DefaultClient, &r, http.MethodGet, s.URL+"/invalid", "", nil)
+	assert.NotEqual(t, nil, err)
+}
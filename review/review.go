@@ -13,25 +13,54 @@
 package review
 
 import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
 	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	dirPerm  = 0755
+	filePerm = 0600
+)
+
+const (
+	maxIdleConnsPerHost = 16
 )
 
 const (
-	reviewGerrit = "gerrit"
+	reviewBitbucket = "bitbucket"
+	reviewFake      = "fake"
+	reviewGerrit    = "gerrit"
+	reviewGithub    = "github"
+	reviewGitlab    = "gitlab"
 )
 
 type Review interface {
+	Carry(string, string, []proto.Format) ([]proto.Format, error)
+	Change(string) (proto.Change, error)
 	Clean(string) error
+	Feedback(string, string) ([]proto.Feedback, error)
 	Fetch(string, string) (string, string, []string, error)
-	Vote(string, []proto.Format) error
+	Notify(string, string) error
+	Parent(string, string, []string) error
+	Vote(string, []proto.Format, string, *proto.Note) error
 }
 
 type Config struct {
+	Client  *http.Client
 	Name    string
+	Policy  policy.Policy
+	Record  string
+	Replay  string
 	Reviews []config.Review
+	Storage storage.Storage
 }
 
 type review struct {
@@ -42,9 +71,36 @@ type review struct {
 func New(cfg *Config) Review {
 	reviews := map[string]Review{}
 
+	client := func(t config.Transport) *http.Client {
+		if cfg.Client != nil {
+			return cfg.Client
+		}
+		return newClient(cfg, t)
+	}
+
+	p := cfg.Policy
+	if p == nil {
+		p = policy.New(policy.DefaultConfig())
+	}
+
+	s := cfg.Storage
+	if s == nil {
+		s = storage.New(storage.DefaultConfig())
+	}
+
 	for index := range cfg.Reviews {
-		if cfg.Reviews[index].Name == reviewGerrit {
-			reviews[cfg.Reviews[index].Name] = &gerrit{cfg.Reviews[index]}
+		switch cfg.Reviews[index].Name {
+		case reviewBitbucket:
+			reviews[cfg.Reviews[index].Name] = &bitbucket{c: client(cfg.Reviews[index].Transport), p: p, r: cfg.Reviews[index], s: s}
+		case reviewFake:
+			reviews[cfg.Reviews[index].Name] = &fake{p: p, r: cfg.Reviews[index], s: s}
+		case reviewGerrit:
+			reviews[cfg.Reviews[index].Name] = &gerrit{c: client(cfg.Reviews[index].Transport),
+				h: &replica{down: map[string]time.Time{}}, p: p, r: cfg.Reviews[index], s: s}
+		case reviewGithub:
+			reviews[cfg.Reviews[index].Name] = &github{c: client(cfg.Reviews[index].Transport), p: p, r: cfg.Reviews[index], s: s}
+		case reviewGitlab:
+			reviews[cfg.Reviews[index].Name] = &gitlab{c: client(cfg.Reviews[index].Transport), p: p, r: cfg.Reviews[index], s: s}
 		}
 	}
 
@@ -63,6 +119,75 @@ func DefaultConfig() *Config {
 	return &Config{}
 }
 
+func newClient(cfg *Config, t config.Transport) *http.Client {
+	if cfg.Replay != "" {
+		return &http.Client{Transport: &cassette{path: cfg.Replay, replay: true}}
+	}
+
+	var tr http.RoundTripper = newTransport(t)
+
+	if t.RateLimit > 0 {
+		tr = &limit{next: tr, rate: t.RateLimit}
+	}
+
+	if cfg.Record != "" {
+		return &http.Client{Transport: &cassette{next: tr, path: cfg.Record}}
+	}
+
+	return &http.Client{Transport: tr}
+}
+
+// newTransport keeps more idle connections to review server than default, since files are fetched from one host.
+func newTransport(t config.Transport) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	if t.IdleTimeout > 0 {
+		tr.IdleConnTimeout = time.Duration(t.IdleTimeout) * time.Second
+	}
+
+	if t.MaxIdleConns > 0 {
+		tr.MaxIdleConns = t.MaxIdleConns
+	}
+
+	if t.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+
+	if t.Http2 != nil && !*t.Http2 {
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return tr
+}
+
+func (r *review) Carry(from, to string, data []proto.Format) ([]proto.Format, error) {
+	if r.hdl == nil {
+		return nil, errors.New("invalid handle")
+	}
+
+	buf, err := r.hdl.Carry(from, to, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to carry")
+	}
+
+	return buf, nil
+}
+
+func (r *review) Change(commit string) (proto.Change, error) {
+	if r.hdl == nil {
+		return proto.Change{}, errors.New("invalid handle")
+	}
+
+	c, err := r.hdl.Change(commit)
+	if err != nil {
+		return proto.Change{}, errors.Wrap(err, "failed to change")
+	}
+
+	return c, nil
+}
+
 func (r *review) Clean(name string) error {
 	if r.hdl == nil {
 		return errors.New("invalid handle")
@@ -75,6 +200,19 @@ func (r *review) Clean(name string) error {
 	return nil
 }
 
+func (r *review) Feedback(commit, keyword string) ([]proto.Feedback, error) {
+	if r.hdl == nil {
+		return nil, errors.New("invalid handle")
+	}
+
+	buf, err := r.hdl.Feedback(commit, keyword)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to feedback")
+	}
+
+	return buf, nil
+}
+
 func (r *review) Fetch(root, commit string) (dname, rname string, flist []string, emsg error) {
 	if r.hdl == nil {
 		return "", "", nil, errors.New("invalid handle")
@@ -88,12 +226,42 @@ func (r *review) Fetch(root, commit string) (dname, rname string, flist []string
 	return dir, repo, files, nil
 }
 
-func (r *review) Vote(commit string, data []proto.Format) error {
+func (r *review) Parent(dir, commit string, files []string) error {
 	if r.hdl == nil {
 		return errors.New("invalid handle")
 	}
 
-	if err := r.hdl.Vote(commit, data); err != nil {
+	if err := r.hdl.Parent(dir, commit, files); err != nil {
+		return errors.Wrap(err, "failed to parent")
+	}
+
+	return nil
+}
+
+func (r *review) Notify(commit, message string) error {
+	if r.hdl == nil {
+		return errors.New("invalid handle")
+	}
+
+	if err := r.hdl.Notify(commit, message); err != nil {
+		return errors.Wrap(err, "failed to notify")
+	}
+
+	return nil
+}
+
+// Vote votes on commit in mode of full, comment (comments without vote) or vote (vote with summary only), with note
+// of change appended to message if any.
+func (r *review) Vote(commit string, data []proto.Format, mode string, note *proto.Note) error {
+	if r.hdl == nil {
+		return errors.New("invalid handle")
+	}
+
+	if r.cfg.Policy != nil {
+		data = r.cfg.Policy.Filter(data)
+	}
+
+	if err := r.hdl.Vote(commit, data, mode, note); err != nil {
 		return errors.Wrap(err, "failed to vote")
 	}
 
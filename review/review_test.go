@@ -14,6 +14,7 @@ package review
 
 import (
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
@@ -60,6 +61,31 @@ func initConfig(name string) (*config.Config, error) {
 	return c, nil
 }
 
+func TestNewClient(t *testing.T) {
+	c := newClient(DefaultConfig(), config.Transport{})
+	assert.Equal(t, maxIdleConnsPerHost, c.Transport.(*http.Transport).MaxIdleConnsPerHost)
+
+	c = newClient(&Config{Record: "record"}, config.Transport{})
+	assert.Equal(t, false, c.Transport.(*cassette).replay)
+
+	c = newClient(&Config{Replay: "replay"}, config.Transport{})
+	assert.Equal(t, true, c.Transport.(*cassette).replay)
+}
+
+func TestNewTransport(t *testing.T) {
+	http2 := false
+
+	tr := newTransport(config.Transport{Http2: &http2, IdleTimeout: 30, MaxIdleConns: 10, MaxIdleConnsPerHost: 5})
+	assert.Equal(t, 30*time.Second, tr.IdleConnTimeout)
+	assert.Equal(t, 10, tr.MaxIdleConns)
+	assert.Equal(t, 5, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, false, tr.ForceAttemptHTTP2)
+	assert.Equal(t, 0, len(tr.TLSNextProto))
+
+	tr = newTransport(config.Transport{})
+	assert.Equal(t, true, tr.ForceAttemptHTTP2)
+}
+
 // nolint: dogsled
 func TestReview(t *testing.T) {
 	c, err := initConfig("../tests/config.yml")
@@ -80,7 +106,7 @@ func TestReview(t *testing.T) {
 
 	buf := make([]proto.Format, 0)
 
-	err = r.Vote(commitGerrit, buf)
+	err = r.Vote(commitGerrit, buf, proto.ModeFull, nil)
 	assert.Equal(t, nil, err)
 
 	buf = make([]proto.Format, 1)
@@ -91,7 +117,7 @@ func TestReview(t *testing.T) {
 		Type:    proto.TypeError,
 	}
 
-	err = r.Vote(commitGerrit, buf)
+	err = r.Vote(commitGerrit, buf, proto.ModeFull, nil)
 	assert.Equal(t, nil, err)
 
 	err = r.Clean(root)
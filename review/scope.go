@@ -0,0 +1,42 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"strings"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+// changeLevel appends findings on change to message, where patchset-level comments are unavailable.
+func changeLevel(message string, data []proto.Format) string {
+	if len(data) == 0 {
+		return message
+	}
+
+	var buf []string
+
+	for _, val := range data {
+		item := "- " + val.Type + ": " + val.Details
+		if val.Docs != "" {
+			item += " (" + val.Docs + ")"
+		}
+		buf = append(buf, item)
+	}
+
+	if message == "" {
+		return strings.Join(buf, "\n")
+	}
+
+	return message + "\n\n" + strings.Join(buf, "\n")
+}
@@ -0,0 +1,33 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestChangeLevel(t *testing.T) {
+	assert.Equal(t, "Voting", changeLevel("Voting", nil))
+
+	data := []proto.Format{{Type: proto.TypeError, Details: "missing CODEOWNERS entry", Scope: proto.ScopeChange}}
+
+	assert.Equal(t, "- Error: missing CODEOWNERS entry", changeLevel("", data))
+	assert.Equal(t, "Voting\n\n- Error: missing CODEOWNERS entry", changeLevel("Voting", data))
+
+	data[0].Docs = "https://example.com/codeowners"
+	assert.Equal(t, "- Error: missing CODEOWNERS entry (https://example.com/codeowners)", changeLevel("", data))
+}
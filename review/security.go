@@ -0,0 +1,50 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"path"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+// security reports whether any finding is in category of security, or with rule configured as security.
+func security(data []proto.Format, s *config.Security) bool {
+	if s.Hashtag == "" && len(s.Reviewers) == 0 {
+		return false
+	}
+
+	for _, item := range data {
+		if item.Category == proto.CategorySecurity {
+			return true
+		}
+		for _, val := range s.Rule {
+			if ok, _ := path.Match(val, item.Rule); ok && item.Rule != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func reviewers(s *config.Security) []reviewerInput {
+	var buf []reviewerInput
+
+	for _, val := range s.Reviewers {
+		buf = append(buf, reviewerInput{Reviewer: val})
+	}
+
+	return buf
+}
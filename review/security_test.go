@@ -0,0 +1,33 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSecurity(t *testing.T) {
+	s := &config.Security{Hashtag: "security", Reviewers: []string{"security-review"}, Rule: []string{"gosec*"}}
+
+	assert.Equal(t, false, security([]proto.Format{{Rule: "errcheck"}, {}}, s))
+	assert.Equal(t, true, security([]proto.Format{{Rule: "gosec-G101"}}, s))
+	assert.Equal(t, true, security([]proto.Format{{Category: proto.CategorySecurity}}, s))
+	assert.Equal(t, false, security([]proto.Format{{Category: proto.CategorySecurity}}, &config.Security{}))
+
+	assert.Equal(t, []reviewerInput{{Reviewer: "security-review"}}, reviewers(s))
+}
@@ -0,0 +1,336 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/policy"
+	"github.com/craftslab/lintflow/proto"
+	"github.com/craftslab/lintflow/storage"
+)
+
+const (
+	fakeVersion = "3.4.0"
+)
+
+// fixture is the state served by fake Gerrit, content of files is plain text and encoded when served.
+type fixture struct {
+	Change   changeInfo
+	Comments map[string][]commentInfo
+	Content  map[string]string
+	Parent   map[string]string
+	Patch    string
+	Plugins  []string
+	Related  []changeInfo
+	Version  string
+}
+
+// fakeGerrit implements the subset of Gerrit REST API used by lintflow, and records what is posted.
+type fakeGerrit struct {
+	*httptest.Server
+	f        fixture
+	mu       sync.Mutex
+	drafts   []commentInput
+	hashtags []hashtagsInput
+	reviews  []reviewInput
+}
+
+func initFixture() fixture {
+	return fixture{
+		Change: changeInfo{
+			Branch:          "master",
+			CurrentRevision: commitGerrit,
+			Number:          changeGerrit,
+			Owner:           accountInfo{AccountID: 1000, Email: "dev@example.com", Name: "dev"},
+			Project:         "lintflow",
+			Revisions: map[string]revisionInfo{
+				commitGerrit: {
+					Commit: commitInfo{Author: gitPersonInfo{Email: "author@example.com", Name: "author"},
+						Message: "Add main\n", Subject: "Add main"},
+					Files: map[string]fileInfo{
+						commitMsg:      {},
+						"main.go":      {LinesInserted: 3},
+						"old.go":       {LinesDeleted: 1, Status: "D"},
+						"src/util.cpp": {LinesInserted: 1},
+					},
+					Number: revisionGerrit,
+				},
+			},
+		},
+		Content: map[string]string{
+			"main.go":      "package main\n\nfunc main() {}\n",
+			"src/util.cpp": "int main() {}\n",
+		},
+		Patch: "diff --git a/main.go b/main.go\nnew file mode 100644\nindex 0..1\n--- /dev/null\n+++ b/main.go\n" +
+			"@@ -0,0 +1,3 @@\n+package main\n+\n+func main() {}\n" +
+			"diff --git a/src/util.cpp b/src/util.cpp\nnew file mode 100644\nindex 0..1\n--- /dev/null\n+++ b/src/util.cpp\n" +
+			"@@ -0,0 +1 @@\n+int main() {}\n",
+		Version: fakeVersion,
+	}
+}
+
+func newFakeGerrit(f fixture) *fakeGerrit {
+	s := &fakeGerrit{f: f}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// gerrit returns handle of review connected to fake Gerrit.
+func (s *fakeGerrit) gerrit(r config.Review) gerrit {
+	r.Url = s.URL
+
+	return gerrit{c: http.DefaultClient, h: &replica{down: map[string]time.Time{}}, p: policy.New(policy.DefaultConfig()), r: r,
+		s: storage.New(storage.DefaultConfig())}
+}
+
+// nolint:gocyclo
+func (s *fakeGerrit) serve(w http.ResponseWriter, r *http.Request) {
+	var elem []string
+
+	for _, val := range strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/") {
+		buf, err := url.PathUnescape(val)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		elem = append(elem, buf)
+	}
+
+	if len(elem) != 0 && elem[0] == "a" {
+		elem = elem[1:]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case match(elem, "config", "server", "version"):
+		s.json(w, s.f.Version)
+	case match(elem, "plugins", ""):
+		buf := map[string]interface{}{}
+		for _, val := range s.f.Plugins {
+			buf[val] = map[string]interface{}{"id": val}
+		}
+		s.json(w, buf)
+	case match(elem, "changes", ""):
+		s.query(w, r.URL.Query().Get("q"))
+	case match(elem, "changes", "*", "detail"):
+		if s.change(elem[1]) {
+			s.json(w, s.f.Change)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case match(elem, "changes", "*", "comments"):
+		if s.change(elem[1]) {
+			s.json(w, s.f.Comments)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case match(elem, "changes", "*", "hashtags") && r.Method == http.MethodPost:
+		var buf hashtagsInput
+		if s.decode(w, r, elem[1], &buf) {
+			s.hashtags = append(s.hashtags, buf)
+			s.json(w, buf.Add)
+		}
+	case match(elem, "changes", "*", "revisions", "*", "files", ""):
+		if rev, ok := s.revision(elem[1], elem[3]); ok {
+			s.json(w, rev.Files)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case match(elem, "changes", "*", "revisions", "*", "files", "*", "content"):
+		rev, ok := s.revision(elem[1], elem[3])
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if elem[5] == commitMsg {
+			s.base64(w, rev.Commit.Message)
+			return
+		}
+		if r.URL.Query().Get("parent") == "1" {
+			if buf, ok := s.f.Parent[elem[5]]; ok {
+				s.base64(w, buf)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.content(w, elem[5])
+	case match(elem, "changes", "*", "revisions", "*", "patch"):
+		if _, ok := s.revision(elem[1], elem[3]); ok {
+			s.base64(w, s.f.Patch)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case match(elem, "changes", "*", "revisions", "*", "drafts") && r.Method == http.MethodGet:
+		s.json(w, map[string][]commentInfo{})
+	case match(elem, "changes", "*", "revisions", "*", "drafts") && r.Method == http.MethodPut:
+		var buf commentInput
+		if s.decode(w, r, elem[1], &buf) {
+			s.drafts = append(s.drafts, buf)
+			s.json(w, commentInfo{ID: strconv.Itoa(len(s.drafts)), Line: buf.Line, Message: buf.Message})
+		}
+	case match(elem, "changes", "*", "revisions", "*", "review") && r.Method == http.MethodPost:
+		var buf reviewInput
+		if s.decode(w, r, elem[1], &buf) {
+			s.reviews = append(s.reviews, buf)
+			s.json(w, map[string]interface{}{"labels": buf.Labels})
+		}
+	case match(elem, "projects", "*", "commits", "*", "files", "*", "content"):
+		if elem[1] == s.f.Change.Project && elem[3] == s.f.Change.CurrentRevision {
+			s.content(w, elem[5])
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *fakeGerrit) query(w http.ResponseWriter, search string) {
+	var buf []changeInfo
+
+	if search == "commit:"+s.f.Change.CurrentRevision || search == "change:"+strconv.Itoa(s.f.Change.Number) {
+		buf = append(buf, s.f.Change)
+	}
+
+	if s.f.Change.Topic != "" && search == "topic:\""+s.f.Change.Topic+"\"" {
+		buf = append(append(buf, s.f.Change), s.f.Related...)
+	}
+
+	s.json(w, buf)
+}
+
+func (s *fakeGerrit) change(id string) bool {
+	return id == strconv.Itoa(s.f.Change.Number)
+}
+
+func (s *fakeGerrit) revision(change, revision string) (revisionInfo, bool) {
+	if !s.change(change) {
+		return revisionInfo{}, false
+	}
+
+	for key, val := range s.f.Change.Revisions {
+		if revision == key || revision == strconv.Itoa(val.Number) || (revision == "current" && key == s.f.Change.CurrentRevision) {
+			return val, true
+		}
+	}
+
+	return revisionInfo{}, false
+}
+
+func (s *fakeGerrit) content(w http.ResponseWriter, name string) {
+	buf, ok := s.f.Content[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.base64(w, buf)
+}
+
+func (s *fakeGerrit) decode(w http.ResponseWriter, r *http.Request, change string, data interface{}) bool {
+	if !s.change(change) {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	if err := json.Unmarshal(buf, data); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (s *fakeGerrit) json(w http.ResponseWriter, data interface{}) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, _ = w.Write(append(append([]byte{}, xssiPrefix...), '\n'))
+	_, _ = w.Write(buf)
+}
+
+func (s *fakeGerrit) base64(w http.ResponseWriter, data string) {
+	w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+	_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(data))))
+}
+
+// match matches elements of path against pattern, where "*" matches any non-empty element.
+func match(elem []string, pattern ...string) bool {
+	if len(elem) != len(pattern) {
+		return false
+	}
+
+	for index := range pattern {
+		if pattern[index] == "*" {
+			if elem[index] == "" {
+				return false
+			}
+			continue
+		}
+		if elem[index] != pattern[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestServer(t *testing.T) {
+	s := newFakeGerrit(initFixture())
+	defer s.Close()
+
+	h := s.gerrit(config.Review{Security: config.Security{Hashtag: "security", Rule: []string{"gosec*"}},
+		Vote: config.Vote{Approval: "+1", Disapproval: "-1", Draft: true, Label: "Code-Review"}})
+
+	v, err := h.version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, fakeVersion, v)
+
+	err = h.Vote(commitGerrit, []proto.Format{{Details: "text", File: "main.go", Line: 1, Rule: "gosec-G101", Type: proto.TypeError}},
+		proto.ModeFull, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []commentInput{{Line: 1, Message: "text", Path: "main.go"}}, s.drafts)
+	assert.Equal(t, []hashtagsInput{{Add: []string{"security"}}}, s.hashtags)
+	assert.Equal(t, "PUBLISH", s.reviews[0].Drafts)
+
+	_, err = h.get(s.URL + "/invalid")
+	assert.NotEqual(t, nil, err)
+}
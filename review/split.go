@@ -0,0 +1,131 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	payloadSize  = 1 << 20
+	reviewRetry  = 3
+	withdrawVote = "lintflow could not post all comments, and withdrew its vote"
+)
+
+// split splits comments of review into reviews within size, where the first one carries the rest of review.
+func split(review *reviewInput, size int) []*reviewInput {
+	if size <= 0 {
+		size = payloadSize
+	}
+
+	key, comments := "comments", review.Comments
+	if len(review.RobotComments) != 0 {
+		key, comments = "robot_comments", review.RobotComments
+	}
+
+	if buf, err := json.Marshal(review); err != nil || len(buf) <= size || len(comments) == 0 {
+		return []*reviewInput{review}
+	}
+
+	first := *review
+	first.Comments, first.RobotComments = nil, nil
+
+	length := func(data interface{}) int {
+		buf, _ := json.Marshal(data)
+		return len(buf)
+	}
+
+	part := func(chunk map[string][]commentInput) *reviewInput {
+		if key == "comments" {
+			return &reviewInput{Comments: chunk, Notify: first.Notify}
+		}
+		return &reviewInput{Notify: first.Notify, RobotComments: chunk}
+	}
+
+	var ret []*reviewInput
+
+	chunk, used := map[string][]commentInput{}, length(&first)+len(key)+6
+
+	files := make([]string, 0, len(comments))
+	for k := range comments {
+		files = append(files, k)
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		for _, item := range comments[file] {
+			n := length(item) + 1
+			if _, ok := chunk[file]; !ok {
+				n += length(file) + 3
+			}
+			if used+n > size && len(chunk) != 0 {
+				ret = append(ret, part(chunk))
+				chunk, used = map[string][]commentInput{}, len(key)+6
+				n = length(item) + length(file) + 4
+			}
+			chunk[file] = append(chunk[file], item)
+			used += n
+		}
+	}
+
+	if len(chunk) != 0 {
+		ret = append(ret, part(chunk))
+	}
+
+	if len(ret) == 0 {
+		return []*reviewInput{review}
+	}
+
+	comments, robots := ret[0].Comments, ret[0].RobotComments
+	*ret[0] = first
+	ret[0].Comments, ret[0].RobotComments = comments, robots
+
+	return ret
+}
+
+// review posts review in parts if too large, each retried, and withdraws vote if any of the rest fails.
+func (g *gerrit) review(change, revision int, data *reviewInput) error {
+	post := func(data *reviewInput) error {
+		var err error
+		for i := 0; i < reviewRetry; i++ {
+			if err = g.post(g.urlReview(change, revision), data); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+
+	buf := split(data, g.r.Vote.PayloadSize)
+
+	if len(buf) > 1 {
+		log.Printf("review of change %d split into %d parts", change, len(buf))
+	}
+
+	if err := post(buf[0]); err != nil {
+		return errors.Wrap(err, "failed to post")
+	}
+
+	for _, item := range buf[1:] {
+		if err := post(item); err != nil {
+			_ = post(&reviewInput{Labels: withdraw(&g.r.Vote), Message: withdrawVote})
+			return errors.Wrap(err, "failed to post rest")
+		}
+	}
+
+	return nil
+}
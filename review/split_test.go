@@ -0,0 +1,100 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func initReview(n int) *reviewInput {
+	comments := map[string][]commentInput{}
+
+	for _, file := range []string{"a.go", "b.go"} {
+		for i := 0; i < n; i++ {
+			comments[file] = append(comments[file], commentInput{Line: i + 1, Message: strings.Repeat("x", 80)})
+		}
+	}
+
+	return &reviewInput{Comments: comments, Labels: map[string]interface{}{"Code-Review": "-1"}, Message: "text"}
+}
+
+func TestSplit(t *testing.T) {
+	buf := split(initReview(5), 0)
+	assert.Equal(t, 1, len(buf))
+
+	r := initReview(50)
+	r.AddToAttentionSet = []attentionSetInput{{Reason: attentionBlocking, User: "1000"}}
+	r.RemoveFromAttentionSet = []attentionSetInput{{Reason: attentionReviewed, User: attentionSelf}}
+	r.IgnoreAutomaticAttentionSetRules = true
+
+	buf = split(r, 2000)
+	assert.Equal(t, true, len(buf) > 1)
+	assert.Equal(t, "text", buf[0].Message)
+	assert.Equal(t, r.AddToAttentionSet, buf[0].AddToAttentionSet)
+	assert.Equal(t, r.RemoveFromAttentionSet, buf[0].RemoveFromAttentionSet)
+	assert.Equal(t, true, buf[0].IgnoreAutomaticAttentionSetRules)
+
+	count := 0
+
+	for index, item := range buf {
+		b, _ := json.Marshal(item)
+		assert.Equal(t, true, len(b) <= 2000)
+		if index != 0 {
+			assert.Equal(t, &reviewInput{Comments: item.Comments, Notify: item.Notify}, item)
+		}
+		for _, val := range item.Comments {
+			count += len(val)
+		}
+	}
+
+	assert.Equal(t, 100, count)
+
+	buf = split(&reviewInput{Labels: map[string]interface{}{"Code-Review": "+1"}}, 1)
+	assert.Equal(t, 1, len(buf))
+}
+
+func TestReviewWithdraw(t *testing.T) {
+	var bodies []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(buf))
+		if len(bodies) > 1 && !strings.Contains(string(buf), "withdrew") {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Url: ts.URL, Vote: config.Vote{Label: "Code-Review", PayloadSize: 2000}}}
+
+	err := g.review(41, 2, initReview(50))
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 1+reviewRetry+1, len(bodies))
+	assert.Equal(t, true, strings.Contains(bodies[len(bodies)-1], withdrawVote))
+
+	bodies = nil
+	g.r.Vote.PayloadSize = 0
+
+	err = g.review(41, 2, initReview(50))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(bodies))
+}
@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	subprojectCommit = "Subproject commit "
+)
+
+// submodules derives updates of pointers of submodules from patch, in which lines of gitlinks are
+// "Subproject commit {sha}" in format of git.
+func submodules(diffs []*diff.File) []proto.Submodule {
+	var ret []proto.Submodule
+
+	for _, d := range diffs {
+		s := proto.Submodule{Path: d.New}
+		if s.Path == "" {
+			s.Path = d.Old
+		}
+		gitlink, other := false, false
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				if !strings.HasPrefix(l.Content, subprojectCommit) {
+					other = true
+					continue
+				}
+				gitlink = true
+				switch l.Type {
+				case diff.LineAdded:
+					s.New = strings.TrimSpace(strings.TrimPrefix(l.Content, subprojectCommit))
+				case diff.LineDeleted:
+					s.Old = strings.TrimSpace(strings.TrimPrefix(l.Content, subprojectCommit))
+				}
+			}
+		}
+		if gitlink && !other {
+			ret = append(ret, s)
+		}
+	}
+
+	return ret
+}
+
+// related queries current revisions of other changes in topic of change, e.g., of multi-repo changes.
+func (g *gerrit) related(topic string, number int) ([]proto.Related, error) {
+	c, err := g.queryAll("topic:\""+topic+"\"", "CURRENT_REVISION")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+
+	var ret []proto.Related
+
+	for _, item := range c {
+		if item.Number != number {
+			ret = append(ret, proto.Related{Commit: item.CurrentRevision, Number: item.Number, Project: item.Project})
+		}
+	}
+
+	return ret, nil
+}
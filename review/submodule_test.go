@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/diff"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSubmodules(t *testing.T) {
+	patch := "diff --git a/lib b/lib\nindex 1111111..2222222 160000\n--- a/lib\n+++ b/lib\n@@ -1 +1 @@\n" +
+		"-Subproject commit 1111111111111111111111111111111111111111\n" +
+		"+Subproject commit 2222222222222222222222222222222222222222\n" +
+		"diff --git a/ext b/ext\nnew file mode 160000\nindex 0000000..3333333\n--- /dev/null\n+++ b/ext\n@@ -0,0 +1 @@\n" +
+		"+Subproject commit 3333333333333333333333333333333333333333\n" +
+		"diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n" +
+		"-package old\n+Subproject commit 4444444444444444444444444444444444444444\n"
+
+	diffs, err := diff.Parse([]byte(patch))
+	assert.Equal(t, nil, err)
+
+	buf := submodules(diffs)
+	assert.Equal(t, []proto.Submodule{
+		{New: "2222222222222222222222222222222222222222", Old: "1111111111111111111111111111111111111111", Path: "lib"},
+		{New: "3333333333333333333333333333333333333333", Path: "ext"},
+	}, buf)
+
+	assert.Equal(t, 0, len(submodules(nil)))
+}
+
+func TestChangeTopic(t *testing.T) {
+	f := initFixture()
+	f.Change.Topic = "bump-lib"
+	f.Related = []changeInfo{{CurrentRevision: "2222222222222222222222222222222222222222", Number: 101, Project: "lib"}}
+	f.Patch += "diff --git a/lib b/lib\nindex 1111111..2222222 160000\n--- a/lib\n+++ b/lib\n@@ -1 +1 @@\n" +
+		"-Subproject commit 1111111111111111111111111111111111111111\n" +
+		"+Subproject commit 2222222222222222222222222222222222222222\n"
+
+	s := newFakeGerrit(f)
+	defer s.Close()
+
+	h := s.gerrit(config.Review{})
+
+	c, err := h.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "bump-lib", c.Topic)
+	assert.Equal(t, []proto.Related{{Commit: "2222222222222222222222222222222222222222", Number: 101, Project: "lib"}},
+		c.Related)
+	assert.Equal(t, []proto.Submodule{{New: "2222222222222222222222222222222222222222",
+		Old: "1111111111111111111111111111111111111111", Path: "lib"}}, c.Submodules)
+
+	s.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Query().Get("q"), "topic:") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.serve(w, r)
+	})
+
+	c, err = h.Change(commitGerrit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "bump-lib", c.Topic)
+	assert.Equal(t, 0, len(c.Related))
+}
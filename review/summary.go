@@ -0,0 +1,69 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+// annotate appends text of note to message of vote.
+func annotate(message string, note *proto.Note) string {
+	if note == nil || note.Text == "" {
+		return message
+	}
+
+	if message == "" {
+		return note.Text
+	}
+
+	return message + "\n\n" + note.Text
+}
+
+// summary appends counts of findings by type to message, which is voted without inline comments.
+func summary(message string, data []proto.Format) string {
+	if len(data) == 0 {
+		return message
+	}
+
+	count := map[string]int{}
+
+	for _, val := range data {
+		count[val.Type]++
+	}
+
+	var types []string
+
+	for key := range count {
+		types = append(types, key)
+	}
+
+	sort.Strings(types)
+
+	var buf []string
+
+	for _, val := range types {
+		buf = append(buf, fmt.Sprintf("%d %s", count[val], val))
+	}
+
+	ret := fmt.Sprintf("lintflow found %d findings: %s", len(data), strings.Join(buf, ", "))
+
+	if message == "" {
+		return ret
+	}
+
+	return message + "\n\n" + ret
+}
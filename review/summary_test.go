@@ -0,0 +1,36 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestSummary(t *testing.T) {
+	data := []proto.Format{{Type: proto.TypeWarn}, {Type: proto.TypeError}, {Type: proto.TypeWarn}}
+
+	assert.Equal(t, "text", summary("text", nil))
+	assert.Equal(t, "lintflow found 3 findings: 1 Error, 2 Warn", summary("", data))
+	assert.Equal(t, "text\n\nlintflow found 3 findings: 1 Error, 2 Warn", summary("text", data))
+}
+
+func TestAnnotate(t *testing.T) {
+	assert.Equal(t, "text", annotate("text", nil))
+	assert.Equal(t, "text", annotate("text", &proto.Note{}))
+	assert.Equal(t, "note", annotate("", &proto.Note{Text: "note"}))
+	assert.Equal(t, "text\n\nnote", annotate("text", &proto.Note{Text: "note"}))
+}
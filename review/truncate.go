@@ -0,0 +1,53 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	truncateMark = "\n\n... (truncated"
+)
+
+// truncate cuts message within size on boundary of lines or words, and appends link to full details if any.
+func truncate(message string, size int, link string) string {
+	if size <= 0 || len(message) <= size {
+		return message
+	}
+
+	suffix := truncateMark + ")"
+	if link != "" {
+		suffix = truncateMark + ", see " + link + ")"
+	}
+
+	if len(suffix) >= size {
+		return strings.ToValidUTF8(message[:size], "")
+	}
+
+	buf := message[:size-len(suffix)]
+
+	if i := strings.LastIndexAny(buf, "\n"); i > len(buf)/2 {
+		buf = buf[:i]
+	} else if i := strings.LastIndexAny(buf, " \t"); i > len(buf)/2 {
+		buf = buf[:i]
+	}
+
+	return strings.ToValidUTF8(buf, "") + suffix
+}
+
+// detail expands link to full details of finding with placeholders {commit}, {file} and {line}.
+func detail(link, commit, file string, line interface{}) string {
+	return strings.NewReplacer("{commit}", commit, "{file}", file, "{line}", fmt.Sprint(line)).Replace(link)
+}
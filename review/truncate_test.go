@@ -0,0 +1,41 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "text", truncate("text", 0, ""))
+	assert.Equal(t, "text", truncate("text", 10, ""))
+
+	buf := truncate(strings.Repeat("line\n", 20), 50, "")
+	assert.Equal(t, true, len(buf) <= 50)
+	assert.Equal(t, true, strings.HasPrefix(buf, "line\nline\n"))
+	assert.Equal(t, true, strings.HasSuffix(buf, "line"+truncateMark+")"))
+
+	buf = truncate(strings.Repeat("word ", 40), 100, "https://example.com/41")
+	assert.Equal(t, true, len(buf) <= 100)
+	assert.Equal(t, true, strings.HasSuffix(buf, "word"+truncateMark+", see https://example.com/41)"))
+
+	assert.Equal(t, "text", truncate("text text", 4, "https://example.com/41"))
+	assert.Equal(t, "", truncate("中文", 2, ""))
+}
+
+func TestLink(t *testing.T) {
+	assert.Equal(t, "https://example.com/abc/main.go#3", detail("https://example.com/{commit}/{file}#{line}", "abc", "main.go", 3))
+}
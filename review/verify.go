@@ -0,0 +1,67 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"encoding/base64"
+	"log"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	verifyRetry = 3
+)
+
+// verify checks size of content in base64 against size of file reported by Gerrit, if any.
+func verify(data []byte, info *fileInfo) error {
+	if info == nil || info.Size == nil {
+		return nil
+	}
+
+	size := *info.Size
+
+	buf, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return errors.Wrap(err, "failed to decode")
+	}
+
+	if len(buf) != int(size) {
+		return errors.New("mismatched size " + strconv.Itoa(len(buf)) + " of " + strconv.Itoa(int(size)))
+	}
+
+	return nil
+}
+
+// verified gets content verified against info of file, and retries bypassing cache on mismatch, e.g., corrupted download.
+func (g *gerrit) verified(_url, key string, info *fileInfo) ([]byte, error) {
+	var err error
+
+	for i := 0; i < verifyRetry; i++ {
+		var buf []byte
+		if buf, err = g.content(_url, key); err != nil {
+			return nil, errors.Wrap(err, "failed to content")
+		}
+		if err = verify(buf, info); err == nil {
+			return buf, nil
+		}
+		log.Printf("content of %s retried: %v", key, err)
+		if g.r.Cache.Path != "" {
+			c := cache{path: g.r.Cache.Path}
+			c.remove(key)
+		}
+	}
+
+	return nil, errors.Wrap(err, "failed to verify")
+}
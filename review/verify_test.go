@@ -0,0 +1,67 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestVerify(t *testing.T) {
+	assert.Equal(t, nil, verify([]byte("Y29udGVudA=="), nil))
+	assert.Equal(t, nil, verify([]byte("Y29udGVudA=="), &fileInfo{}))
+	assert.Equal(t, nil, verify([]byte("Y29udGVudA=="), &fileInfo{Size: size(7)}))
+	assert.NotEqual(t, nil, verify([]byte("Y29udGVu"), &fileInfo{Size: size(7)}))
+	assert.NotEqual(t, nil, verify([]byte("invalid"), &fileInfo{Size: size(7)}))
+}
+
+func TestVerified(t *testing.T) {
+	d, err := ioutil.TempDir("", "verify")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	count := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			_, _ = w.Write([]byte("Y29udGVu"))
+			return
+		}
+		_, _ = w.Write([]byte("Y29udGVudA=="))
+	}))
+	defer ts.Close()
+
+	g := gerrit{c: http.DefaultClient, r: config.Review{Cache: config.Cache{Path: d}}}
+
+	buf, err := g.verified(ts.URL, "1/abc/main.go", &fileInfo{Size: size(7)})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Y29udGVudA==", string(buf))
+	assert.Equal(t, 2, count)
+
+	_, err = g.verified(ts.URL, "1/abc/main.go", &fileInfo{Size: size(8)})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 1+verifyRetry, count)
+}
+
+func size(n int64) *int64 {
+	return &n
+}
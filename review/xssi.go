@@ -0,0 +1,67 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	htmlSnippet = 200
+)
+
+var (
+	// Magic prefix of Gerrit against XSSI, which proxies may strip
+	xssiPrefix = []byte(")]}'")
+
+	htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlTag   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// decode unmarshals JSON response of Gerrit into v, stripping magic prefix only if present,
+// and reports HTML error pages, e.g., of proxies, with their titles.
+func decode(data []byte, v interface{}) error {
+	buf := bytes.TrimSpace(bytes.TrimPrefix(data, xssiPrefix))
+
+	if len(buf) == 0 {
+		return errors.New("empty response")
+	}
+
+	if buf[0] == '<' {
+		return errors.New("html response: " + diagnose(buf))
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return nil
+}
+
+func diagnose(data []byte) string {
+	if m := htmlTitle.FindSubmatch(data); m != nil {
+		return strings.Join(strings.Fields(string(m[1])), " ")
+	}
+
+	buf := strings.Join(strings.Fields(htmlTag.ReplaceAllString(string(data), " ")), " ")
+	if len(buf) > htmlSnippet {
+		buf = buf[:htmlSnippet] + "..."
+	}
+
+	return buf
+}
@@ -0,0 +1,43 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	var v map[string]interface{}
+
+	assert.Equal(t, nil, decode([]byte(")]}'\n{\"_number\":1}"), &v))
+	assert.Equal(t, float64(1), v["_number"])
+
+	assert.Equal(t, nil, decode([]byte("{\"_number\":2}"), &v))
+	assert.Equal(t, float64(2), v["_number"])
+
+	var s string
+
+	assert.Equal(t, nil, decode([]byte(")]}'\n\"3.2.0\""), &s))
+	assert.Equal(t, "3.2.0", s)
+
+	assert.NotEqual(t, nil, decode([]byte(")]}'\n"), &v))
+	assert.NotEqual(t, nil, decode([]byte("Not found"), &v))
+
+	err := decode([]byte("<html><head><title>502 Bad\n Gateway</title></head><body>nginx</body></html>"), &v)
+	assert.Equal(t, "html response: 502 Bad Gateway", err.Error())
+
+	err = decode([]byte("<html><body><h1>Service Unavailable</h1></body></html>"), &v)
+	assert.Equal(t, "html response: Service Unavailable", err.Error())
+}
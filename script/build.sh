@@ -9,7 +9,9 @@ target="lintflow"
 go env -w GOPROXY=https://goproxy.cn,direct
 
 GIN_MODE=release CGO_ENABLED=0 GOARCH=amd64 GOOS=linux go build -ldflags "$ldflags" -o bin/$target main.go
+GIN_MODE=release CGO_ENABLED=0 GOARCH=arm64 GOOS=linux go build -ldflags "$ldflags" -o bin/$target-arm64 main.go
 GIN_MODE=release CGO_ENABLED=0 GOARCH=amd64 GOOS=windows go build -ldflags "$ldflags" -o bin/$target.exe main.go
 
 upx bin/$target
+upx bin/$target-arm64
 upx bin/$target.exe
@@ -0,0 +1,174 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	BadgeErrors = "errors"
+	BadgeStatus = "status"
+)
+
+const (
+	badgeExt   = ".svg"
+	charWidth  = 7
+	colorGreen = "#4c1"
+	colorGrey  = "#9f9f9f"
+	colorRed   = "#e05d44"
+	padWidth   = 10
+	unknown    = "unknown"
+)
+
+const badgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">
+<title>%[3]s: %[4]s</title>
+<rect width="%[2]d" height="20" fill="#555"/>
+<rect x="%[2]d" width="%[5]d" height="20" fill="%[6]s"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="14">%[3]s</text>
+<text x="%[8]d" y="14">%[4]s</text>
+</g>
+</svg>
+`
+
+// badge serves SVG badge of project in history, i.e., /api/v1/badges/{project}/{kind}.svg, in which kind is errors
+// in the latest successful run with trend against the former one, or status of the latest run.
+func (s *server) badge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.cfg.History == nil {
+		http.Error(w, "invalid history", http.StatusNotFound)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, RouteBadges+"/")
+	project, kind := path.Dir(name), strings.TrimSuffix(path.Base(name), badgeExt)
+
+	if project == "." || (kind != BadgeErrors && kind != BadgeStatus) {
+		http.Error(w, "invalid badge", http.StatusNotFound)
+		return
+	}
+
+	runs, err := s.cfg.History.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	runs = projectRuns(runs, project)
+
+	var value, color string
+
+	if kind == BadgeErrors {
+		value, color = errorsBadge(runs)
+	} else {
+		value, color = statusBadge(runs)
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "image/svg+xml;charset=utf-8")
+	_, _ = w.Write(renderBadge(kind, value, color))
+}
+
+// projectRuns returns runs of project in order of start.
+func projectRuns(runs []proto.Run, project string) []proto.Run {
+	var ret []proto.Run
+
+	for _, val := range runs {
+		if val.Repo == project {
+			ret = append(ret, val)
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Start.Before(ret[j].Start)
+	})
+
+	return ret
+}
+
+func errorsBadge(runs []proto.Run) (value, color string) {
+	var count []int
+
+	for _, val := range runs {
+		if val.Status != proto.StatusSuccess {
+			continue
+		}
+		n := 0
+		for _, f := range val.Findings {
+			if f.Type == proto.TypeError {
+				n++
+			}
+		}
+		count = append(count, n)
+	}
+
+	if len(count) == 0 {
+		return unknown, colorGrey
+	}
+
+	last := count[len(count)-1]
+	value = strconv.Itoa(last)
+
+	if len(count) > 1 {
+		if prev := count[len(count)-2]; last > prev {
+			value += " ↑"
+		} else if last < prev {
+			value += " ↓"
+		}
+	}
+
+	if last == 0 {
+		return value, colorGreen
+	}
+
+	return value, colorRed
+}
+
+func statusBadge(runs []proto.Run) (value, color string) {
+	if len(runs) == 0 {
+		return unknown, colorGrey
+	}
+
+	value = runs[len(runs)-1].Status
+
+	switch value {
+	case proto.StatusSuccess:
+		return value, colorGreen
+	case proto.StatusFailed:
+		return value, colorRed
+	default:
+		return value, colorGrey
+	}
+}
+
+func renderBadge(label, value, color string) []byte {
+	lw := utf8.RuneCountInString(label)*charWidth + padWidth
+	vw := utf8.RuneCountInString(value)*charWidth + padWidth
+
+	return []byte(fmt.Sprintf(badgeTemplate, lw+vw, lw, html.EscapeString(label), html.EscapeString(value), vw, color,
+		lw/2, lw+vw/2))
+}
@@ -0,0 +1,87 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+type historyTest struct {
+	runs []proto.Run
+}
+
+func (h *historyTest) Feedback() ([]proto.Feedback, error) {
+	return nil, nil
+}
+
+func (h *historyTest) List() ([]proto.Run, error) {
+	return h.runs, nil
+}
+
+func (h *historyTest) Put(run proto.Run) error {
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+func (h *historyTest) PutFeedback(proto.Feedback) error {
+	return nil
+}
+
+func TestBadge(t *testing.T) {
+	s := initServer()
+
+	get := func(name string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteBadges+"/"+name, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("foo/errors.svg").Code)
+
+	start := time.Unix(0, 0)
+	errs := []proto.Format{{Type: proto.TypeError}, {Type: proto.TypeWarn}}
+
+	s.cfg.History = &historyTest{runs: []proto.Run{
+		{Repo: "platform/foo", Start: start.Add(2 * time.Hour), Status: proto.StatusFailed},
+		{Repo: "platform/foo", Start: start.Add(time.Hour), Status: proto.StatusSuccess, Findings: errs[:1]},
+		{Repo: "platform/foo", Start: start, Status: proto.StatusSuccess, Findings: append(errs, errs...)},
+		{Repo: "bar", Start: start, Status: proto.StatusSuccess},
+	}}
+
+	rec := get("platform/foo/errors.svg")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/svg+xml;charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), "<title>errors: 1 ↓</title>"))
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), colorRed))
+
+	rec = get("platform/foo/status.svg")
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), "<title>status: failed</title>"))
+
+	rec = get("bar/errors.svg")
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), "<title>errors: 0</title>"))
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), colorGreen))
+
+	rec = get("baz/status.svg")
+	assert.Equal(t, true, strings.Contains(rec.Body.String(), "<title>status: unknown</title>"))
+
+	assert.Equal(t, http.StatusNotFound, get("foo/invalid.svg").Code)
+	assert.Equal(t, http.StatusNotFound, get("errors.svg").Code)
+}
@@ -0,0 +1,253 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/craftslab/lintflow/flow"
+)
+
+const (
+	commandPrefix  = "/lintflow"
+	commandRecheck = "recheck"
+	commandRun     = "run"
+)
+
+const (
+	eventCommentAdded = "comment-added"
+	// Seconds to retry events on followers, i.e. default ttl of leader within which leader is elected
+	eventRetry = 15
+)
+
+// event is the subset of Gerrit event, e.g., from stream-events or webhooks plugin.
+type event struct {
+	Author struct {
+		Email string `json:"email"`
+	} `json:"author"`
+	Change struct {
+		Number int `json:"number"`
+	} `json:"change"`
+	Comment        string `json:"comment"`
+	EventCreatedOn int64  `json:"eventCreatedOn"`
+	PatchSet       struct {
+		Revision string `json:"revision"`
+	} `json:"patchSet"`
+	Type string `json:"type"`
+}
+
+// key identifies event among redeliveries, e.g., retries of webhooks.
+func (e *event) key() string {
+	h := sha256.Sum256([]byte(strings.Join([]string{e.Type, strconv.FormatInt(e.EventCreatedOn, 10),
+		strconv.Itoa(e.Change.Number), e.PatchSet.Revision, e.Author.Email, e.Comment}, "\x00")))
+
+	return hex.EncodeToString(h[:])
+}
+
+// events triggers runs by commands in comments, e.g., /lintflow recheck or /lintflow run security-profile,
+// which are allowed for members of groups in command config. Events are deduplicated and kept in event log if enabled.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Events are consumed by leader only, and retried by senders on followers, e.g. behind load balancer
+	if !s.leading() {
+		retry := s.cfg.Config.Spec.Leader.Ttl
+		if retry <= 0 {
+			retry = eventRetry
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		http.Error(w, "not leader", http.StatusServiceUnavailable)
+		return
+	}
+
+	var e event
+
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil || json.Unmarshal(buf, &e) != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	var offset int64
+
+	if s.cfg.EventLog != nil {
+		var ok bool
+		offset, ok, err = s.cfg.EventLog.Append(e.key(), buf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			log.Printf("event %s of change %d deduplicated", e.Type, e.Change.Number)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	status, job, msg := s.dispatch(&e)
+
+	var ret Job
+	if job != nil {
+		ret = *job
+	}
+
+	s.consume(offset, status, job)
+
+	switch status {
+	case http.StatusOK, http.StatusAccepted:
+		s.reply(w, status, &ret)
+	case http.StatusNoContent:
+		w.WriteHeader(status)
+	default:
+		http.Error(w, msg, status)
+	}
+}
+
+// dispatch queues job by command in event, and returns status in HTTP along with job or message of error.
+func (s *server) dispatch(e *event) (int, *Job, string) {
+	// Other commands, e.g., /lintflow false-positive, are not for runs
+	args := command(e.Comment)
+	if e.Type != eventCommentAdded || len(args) == 0 || (args[0] != commandRecheck && args[0] != commandRun) {
+		return http.StatusNoContent, nil, ""
+	}
+
+	if e.PatchSet.Revision == "" {
+		return http.StatusBadRequest, nil, "invalid event"
+	}
+
+	if !s.allowed(e.Author.Email) {
+		log.Printf("command %v of %s denied", args, e.Author.Email)
+		return http.StatusForbidden, nil, "invalid permission"
+	}
+
+	profile := ""
+
+	switch {
+	case args[0] == commandRecheck && len(args) == 1:
+	case args[0] == commandRun && len(args) == 2:
+		if _, ok := s.cfg.Config.Spec.Command.Profile[args[1]]; !ok {
+			return http.StatusBadRequest, nil, "invalid profile"
+		}
+		profile = args[1]
+	default:
+		return http.StatusBadRequest, nil, "invalid command"
+	}
+
+	change := ""
+	if e.Change.Number > 0 {
+		change = strconv.Itoa(e.Change.Number)
+	}
+
+	job, ok := s.queue(change, e.PatchSet.Revision, flow.SourceCommand, profile)
+	if job == nil {
+		return http.StatusTooManyRequests, nil, "queue full"
+	}
+
+	if !ok {
+		return http.StatusOK, job, ""
+	}
+
+	return http.StatusAccepted, job, ""
+}
+
+// consume runs job queued for event at offset, which is done once job ends. Event rejected for now is removed from
+// event log for redelivery, and others are done at once.
+func (s *server) consume(offset int64, status int, job *Job) {
+	if status == http.StatusAccepted {
+		job.offset = offset
+		go s.routine(job)
+		return
+	}
+
+	if s.cfg.EventLog == nil || offset == 0 {
+		return
+	}
+
+	var err error
+
+	if status == http.StatusTooManyRequests {
+		err = s.cfg.EventLog.Remove(offset)
+	} else {
+		err = s.cfg.EventLog.Done(offset)
+	}
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// resume dispatches events pending in event log, e.g., whose jobs were lost on restart.
+func (s *server) resume() {
+	buf, err := s.cfg.EventLog.Pending()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for _, val := range buf {
+		var e event
+		if err := json.Unmarshal(val.Data, &e); err != nil {
+			s.consume(val.Offset, http.StatusBadRequest, nil)
+			continue
+		}
+		log.Printf("event %s of change %d resumed at offset %d", e.Type, e.Change.Number, val.Offset)
+		status, job, _ := s.dispatch(&e)
+		s.consume(val.Offset, status, job)
+	}
+}
+
+func (s *server) allowed(email string) bool {
+	if email == "" {
+		return false
+	}
+
+	for _, group := range s.cfg.Config.Spec.Command.Group {
+		for _, val := range s.cfg.Config.Spec.Group[group] {
+			if strings.EqualFold(val, email) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// command returns arguments of the first line with command prefix in comment, e.g., "Patch Set 2:\n\n/lintflow recheck".
+func command(comment string) []string {
+	for _, line := range strings.Split(comment, "\n") {
+		buf := strings.Fields(line)
+		if len(buf) > 1 && buf[0] == commandPrefix {
+			return buf[1:]
+		}
+	}
+
+	return nil
+}
+
+func active(commit, profile string) string {
+	if profile == "" {
+		return commit
+	}
+
+	return commit + " " + profile
+}
@@ -0,0 +1,145 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/eventlog"
+	"github.com/craftslab/lintflow/flow"
+	"github.com/craftslab/lintflow/leader"
+)
+
+func TestEvents(t *testing.T) {
+	s := initServer()
+	s.cfg.Config.Spec.Command = config.Command{Group: []string{"core"},
+		Profile: map[string][]string{"security-profile": {"lintsecurity"}}}
+	s.cfg.Config.Spec.Group = map[string][]string{"core": {"dev@example.com"}}
+
+	post := func(email, comment string) (int, Job) {
+		var job Job
+		buf, _ := json.Marshal(map[string]interface{}{
+			"type":     eventCommentAdded,
+			"author":   map[string]string{"email": email},
+			"change":   map[string]int{"number": 41},
+			"comment":  comment,
+			"patchSet": map[string]string{"revision": "foo"},
+		})
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteEvents, strings.NewReader(string(buf))))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return rec.Code, job
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteEvents, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	code, _ := post("dev@example.com", "Patch Set 1:\n\nLooks good")
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, _ = post("dev@example.com", "Patch Set 1:\n\n/lintflow false-positive")
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, _ = post("other@example.com", "Patch Set 1:\n\n/lintflow recheck")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = post("dev@example.com", "Patch Set 1:\n\n/lintflow run invalid")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, job := post("dev@example.com", "Patch Set 1:\n\n/lintflow recheck")
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "41", job.Change)
+	assert.Equal(t, "foo", job.Commit)
+	assert.Equal(t, flow.SourceCommand, job.Source)
+
+	code, job = post("dev@example.com", "Patch Set 1:\n\n/lintflow run security-profile")
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "security-profile", job.Profile)
+
+	s.cfg.Leader = leader.New(&leader.Config{Leader: config.Leader{Kind: leader.KindRedis}})
+
+	code, _ = post("dev@example.com", "Patch Set 2:\n\n/lintflow recheck")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestEventsLog(t *testing.T) {
+	d, err := ioutil.TempDir("", "server")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	e := eventlog.New(&eventlog.Config{Path: filepath.Join(d, "events.jsonl")})
+
+	s := initServer()
+	s.cfg.Config.Spec.Command = config.Command{Group: []string{"core"}}
+	s.cfg.Config.Spec.Group = map[string][]string{"core": {"dev@example.com"}}
+	s.cfg.EventLog = e
+
+	body := `{"type":"comment-added","author":{"email":"dev@example.com"},"change":{"number":41},` +
+		`"comment":"/lintflow recheck","eventCreatedOn":1614585600,"patchSet":{"revision":"foo"}}`
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteEvents, strings.NewReader(body)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post(body))
+	assert.Equal(t, http.StatusNoContent, post(body))
+
+	assert.Eventually(t, func() bool {
+		buf, _ := e.Pending()
+		return len(buf) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusNoContent, post(strings.Replace(body, "recheck", "false-positive", 1)))
+
+	buf, err := e.Pending()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+
+	_, ok, err := e.Append("bar", []byte(strings.Replace(body, "foo", "bar", 1)))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	s.resume()
+
+	assert.Eventually(t, func() bool {
+		buf, _ := e.Pending()
+		return len(buf) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	s.mutex.Lock()
+	assert.Equal(t, 2, len(s.order))
+	assert.Equal(t, "bar", s.jobs[s.order[1]].Commit)
+	s.mutex.Unlock()
+}
+
+func TestCommand(t *testing.T) {
+	assert.Equal(t, []string(nil), command("Patch Set 1: Code-Review+1"))
+	assert.Equal(t, []string(nil), command("/lintflow"))
+	assert.Equal(t, []string{"recheck"}, command("Patch Set 2:\n\n/lintflow recheck\n"))
+	assert.Equal(t, []string{"run", "security-profile"}, command("Patch Set 2:\n\n  /lintflow run security-profile"))
+}
@@ -0,0 +1,153 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	requeueSuffix = "/requeue"
+)
+
+// deadJobs lists jobs dead-lettered after retries, in order of their deaths.
+func (s *server) deadJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mutex.Lock()
+	ret := make([]Job, 0, len(s.dead))
+	for _, val := range s.dead {
+		ret = append(ret, *val)
+	}
+	s.mutex.Unlock()
+
+	s.reply(w, http.StatusOK, ret)
+}
+
+// requeue removes dead job from queue of dead letters and queues its commit again, e.g., after outage of workers is
+// fixed, which is /api/v1/dead/{id}/requeue.
+func (s *server) requeue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, RouteDead+"/")
+	if !strings.HasSuffix(id, requeueSuffix) {
+		http.Error(w, "invalid request", http.StatusNotFound)
+		return
+	}
+
+	dead := s.undead(strings.TrimSuffix(id, requeueSuffix))
+	if dead == nil {
+		http.Error(w, "invalid job", http.StatusNotFound)
+		return
+	}
+
+	job, ok := s.queue(dead.Change, dead.Commit, dead.Source, dead.Profile)
+	if job == nil {
+		s.mutex.Lock()
+		s.dead = append(s.dead, dead)
+		s.mutex.Unlock()
+		http.Error(w, "queue full", http.StatusTooManyRequests)
+		return
+	}
+
+	ret := *job
+
+	if !ok {
+		s.reply(w, http.StatusOK, &ret)
+		return
+	}
+
+	go s.routine(job)
+
+	s.reply(w, http.StatusAccepted, &ret)
+}
+
+func (s *server) undead(id string) *Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for index, val := range s.dead {
+		if val.ID == id {
+			s.dead = append(s.dead[:index], s.dead[index+1:]...)
+			return val
+		}
+	}
+
+	return nil
+}
+
+// Dead lists dead jobs of the server serving on addr.
+func Dead(addr string) ([]Job, error) {
+	var ret []Job
+
+	if err := call(http.MethodGet, addr, RouteDead, &ret); err != nil {
+		return nil, errors.Wrap(err, "failed to call")
+	}
+
+	return ret, nil
+}
+
+// Requeue queues dead job of id again in the server serving on addr, and returns the new job.
+func Requeue(addr, id string) (*Job, error) {
+	var ret Job
+
+	if err := call(http.MethodPost, addr, RouteDead+"/"+id+requeueSuffix, &ret); err != nil {
+		return nil, errors.Wrap(err, "failed to call")
+	}
+
+	return &ret, nil
+}
+
+func call(method, addr, route string, data interface{}) error {
+	req, err := http.NewRequest(method, base(addr)+route, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to request")
+	}
+
+	client := http.Client{Timeout: probeTimeout}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	buf, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read")
+	}
+
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusAccepted {
+		return errors.New("invalid status " + strings.TrimSpace(string(buf)))
+	}
+
+	if err := json.Unmarshal(buf, data); err != nil {
+		return errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return nil
+}
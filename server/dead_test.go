@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDead(t *testing.T) {
+	s := initServer()
+	s.backoff = time.Millisecond
+	s.cfg.Config.Spec.Queue.Retry = 1
+
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns,
+		strings.NewReader(`{"commit":"invalid","source":"webhook"}`)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var job Job
+	_ = json.Unmarshal(rec.Body.Bytes(), &job)
+
+	var dead []Job
+
+	assert.Eventually(t, func() bool {
+		dead, _ = Dead(addr)
+		return len(dead) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, job.ID, dead[0].ID)
+	assert.Equal(t, StatusDead, dead[0].Status)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Equal(t, "invalid commit", dead[0].Error)
+
+	ret, err := Requeue(addr, job.ID)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, job.ID, ret.ID)
+	assert.Equal(t, "webhook", ret.Source)
+
+	_, err = Requeue(addr, job.ID)
+	assert.NotEqual(t, nil, err)
+
+	assert.Eventually(t, func() bool {
+		dead, _ = Dead(addr)
+		return len(dead) == 1 && dead[0].ID == ret.ID
+	}, time.Second, 10*time.Millisecond)
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteDead+"/"+ret.ID, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteDead, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
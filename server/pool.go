@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+)
+
+// pool limits running jobs of trigger sources sharing it, i.e., the configured source or the default one of the others,
+// and jobs queued for it, in which zero is unlimited.
+type pool struct {
+	queued int
+	size   int
+	slots  chan struct{}
+}
+
+func newPool(concurrency, size int) *pool {
+	p := &pool{size: size}
+
+	if concurrency > 0 {
+		p.slots = make(chan struct{}, concurrency)
+	}
+
+	return p
+}
+
+func (p *pool) acquire(ctx context.Context) error {
+	if p.slots == nil {
+		return nil
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *pool) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+func (p *pool) full() bool {
+	return p.size > 0 && p.queued >= p.size
+}
+
+// pool returns pool of source, which is called with mutex held.
+func (s *server) pool(source string) *pool {
+	name, concurrency, size := "", s.cfg.Config.Spec.Queue.Concurrency, s.cfg.Config.Spec.Queue.Size
+
+	for _, val := range s.cfg.Config.Spec.Queue.Source {
+		if val.Name == source {
+			name, concurrency, size = val.Name, val.Concurrency, val.Size
+			break
+		}
+	}
+
+	p, ok := s.pools[name]
+	if !ok {
+		p = newPool(concurrency, size)
+		s.pools[name] = p
+	}
+
+	return p
+}
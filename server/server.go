@@ -0,0 +1,599 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/eventlog"
+	"github.com/craftslab/lintflow/feedback"
+	"github.com/craftslab/lintflow/flow"
+	"github.com/craftslab/lintflow/history"
+	"github.com/craftslab/lintflow/leader"
+	"github.com/craftslab/lintflow/lint"
+	"github.com/craftslab/lintflow/metrics"
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	RouteBadges  = "/api/v1/badges"
+	RouteDead    = "/api/v1/dead"
+	RouteEvents  = "/api/v1/events"
+	RouteHealth  = "/healthz"
+	RouteMetrics = "/metrics"
+	RouteReady   = "/readyz"
+	RouteRuns    = "/api/v1/runs"
+)
+
+const (
+	StatusCanceled = "canceled"
+	StatusDead     = "dead"
+	StatusFailed   = "failed"
+	StatusQueued   = "queued"
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+)
+
+const (
+	backoff      = 30
+	dialTimeout  = 3 * time.Second
+	idLength     = 8
+	interval     = 60
+	jobsLimit    = 1000
+	pollInterval = 600
+	probeTimeout = 5 * time.Second
+	shutdownWait = 10 * time.Second
+)
+
+type Server interface {
+	Run(context.Context) error
+}
+
+type Config struct {
+	Addr     string
+	Config   config.Config
+	EventLog eventlog.EventLog
+	Feedback feedback.Feedback
+	Flow     flow.Flow
+	History  history.History
+	Leader   leader.Leader
+	Metrics  metrics.Metrics
+}
+
+type Job struct {
+	ID       string         `json:"id"`
+	Change   string         `json:"change,omitempty"`
+	Commit   string         `json:"commit"`
+	Profile  string         `json:"profile,omitempty"`
+	Source   string         `json:"source,omitempty"`
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Attempts int            `json:"attempts,omitempty"`
+	Findings []proto.Format `json:"findings,omitempty"`
+
+	cancel context.CancelFunc
+	ctx    context.Context
+	offset int64
+	pool   *pool
+}
+
+type server struct {
+	active  map[string]string
+	backoff time.Duration
+	cfg     *Config
+	dead    []*Job
+	jobs    map[string]*Job
+	mutex   sync.Mutex
+	order   []string
+	pools   map[string]*pool
+}
+
+func New(_ context.Context, cfg *Config) Server {
+	b := cfg.Config.Spec.Queue.Backoff
+	if b <= 0 {
+		b = backoff
+	}
+
+	return &server{
+		active:  map[string]string{},
+		backoff: time.Duration(b) * time.Second,
+		cfg:     cfg,
+		jobs:    map[string]*Job{},
+		pools:   map[string]*pool{},
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Addr: ":8080",
+	}
+}
+
+// Probe checks health of the server serving on addr, which is used by container health checks.
+func Probe(addr string) error {
+	client := http.Client{Timeout: probeTimeout}
+
+	rsp, err := client.Get(base(addr) + RouteHealth)
+	if err != nil {
+		return errors.Wrap(err, "failed to get")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("invalid status")
+	}
+
+	return nil
+}
+
+// base returns URL of the local instance if host is omitted in addr.
+func base(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+
+	return "http://" + addr
+}
+
+func (s *server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:    s.cfg.Addr,
+		Handler: s.handler(),
+	}
+
+	ch := make(chan error, 1)
+
+	go func() {
+		ch <- srv.ListenAndServe()
+	}()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		if s.cfg.Leader == nil {
+			return
+		}
+		if err := s.cfg.Leader.Run(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if s.cfg.EventLog != nil {
+		s.resume()
+	}
+
+	if s.cfg.Feedback != nil {
+		go s.poll(ctx)
+	}
+
+	if s.cfg.Metrics != nil {
+		go s.refresh(ctx)
+	}
+
+	select {
+	case err := <-ch:
+		return errors.Wrap(err, "failed to listen")
+	case <-ctx.Done():
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), shutdownWait)
+	defer cancel()
+
+	if err := srv.Shutdown(c); err != nil {
+		return errors.Wrap(err, "failed to shutdown")
+	}
+
+	<-done
+
+	return nil
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(RouteBadges+"/", s.badge)
+	mux.HandleFunc(RouteDead, s.deadJobs)
+	mux.HandleFunc(RouteDead+"/", s.requeue)
+	mux.HandleFunc(RouteEvents, s.events)
+	mux.HandleFunc(RouteHealth, s.health)
+	mux.HandleFunc(RouteMetrics, s.metrics)
+	mux.HandleFunc(RouteReady, s.ready)
+	mux.HandleFunc(RouteRuns, s.runs)
+	mux.HandleFunc(RouteRuns+"/", s.job)
+
+	return mux
+}
+
+func (s *server) health(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (s *server) metrics(w http.ResponseWriter, _ *http.Request) {
+	if s.cfg.Metrics == nil {
+		http.Error(w, "invalid metrics", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := s.cfg.Metrics.Write(w); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *server) ready(w http.ResponseWriter, _ *http.Request) {
+	if err := s.check(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (s *server) runs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Change string `json:"change"`
+		Commit string `json:"commit"`
+		Source string `json:"source"`
+	}
+
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil || json.Unmarshal(buf, &req) != nil || req.Commit == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Source == "" {
+		req.Source = flow.SourceApi
+	}
+
+	job, ok := s.queue(req.Change, req.Commit, req.Source, "")
+	if job == nil {
+		http.Error(w, "queue full", http.StatusTooManyRequests)
+		return
+	}
+
+	ret := *job
+
+	if !ok {
+		s.reply(w, http.StatusOK, &ret)
+		return
+	}
+
+	go s.routine(job)
+
+	s.reply(w, http.StatusAccepted, &ret)
+}
+
+func (s *server) job(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mutex.Lock()
+	job, ok := s.jobs[strings.TrimPrefix(r.URL.Path, RouteRuns+"/")]
+	if ok {
+		buf := *job
+		job = &buf
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		http.Error(w, "invalid job", http.StatusNotFound)
+		return
+	}
+
+	s.reply(w, http.StatusOK, job)
+}
+
+func (s *server) reply(w http.ResponseWriter, status int, data interface{}) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf)
+}
+
+// queue queues job of commit with optional profile of lints, or coalesces it into the queued or running one of the same
+// commit and profile, e.g., on retries. Active jobs of former commits in the same change are canceled if superseding
+// is enabled, or queued ones not yet run if debouncing is enabled. Job is nil if pool of source is full.
+func (s *server) queue(change, commit, source, profile string) (*Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if id, ok := s.active[active(commit, profile)]; ok {
+		if job, ok := s.jobs[id]; ok {
+			buf := *job
+			return &buf, false
+		}
+	}
+
+	p := s.pool(source)
+	if p.full() {
+		return nil, false
+	}
+
+	if change != "" && s.cfg.Config.Spec.Queue.Supersede {
+		for _, id := range s.active {
+			if job, ok := s.jobs[id]; ok && job.Change == change {
+				log.Printf("job %s of commit %s superseded by commit %s", job.ID, job.Commit, commit)
+				job.cancel()
+			}
+		}
+	} else if change != "" && s.cfg.Config.Spec.Queue.Debounce > 0 {
+		for _, id := range s.active {
+			if job, ok := s.jobs[id]; ok && job.Change == change && job.Status == StatusQueued && job.Attempts == 0 {
+				log.Printf("job %s of commit %s debounced by commit %s", job.ID, job.Commit, commit)
+				job.cancel()
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if profile != "" {
+		ctx = lint.WithLints(ctx, s.cfg.Config.Spec.Command.Profile[profile])
+	}
+
+	job := &Job{
+		ID:      s.id(),
+		Change:  change,
+		Commit:  commit,
+		Profile: profile,
+		Source:  source,
+		Status:  StatusQueued,
+		cancel:  cancel,
+		ctx:     flow.WithSource(ctx, source),
+		pool:    p,
+	}
+
+	p.queued++
+
+	s.active[active(commit, profile)] = job.ID
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+
+	for len(s.order) > jobsLimit {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	return job, true
+}
+
+// routine runs job once a slot in its pool is free, in which job is queued till then. Failed job is retried after
+// backoff if retries are enabled, and dead-lettered once they are exhausted.
+func (s *server) routine(job *Job) {
+	defer s.done(job.offset)
+	defer job.cancel()
+
+	for {
+		buf, err := s.attempt(job)
+		if err == nil {
+			s.update(job, StatusSuccess, buf, nil)
+			return
+		}
+
+		log.Println(err)
+
+		if job.ctx.Err() != nil {
+			s.update(job, StatusCanceled, nil, err)
+			return
+		}
+
+		if s.cfg.Config.Spec.Queue.Retry <= 0 {
+			s.update(job, StatusFailed, nil, err)
+			return
+		}
+
+		if job.Attempts > s.cfg.Config.Spec.Queue.Retry {
+			log.Printf("job %s of commit %s dead-lettered after %d attempts", job.ID, job.Commit, job.Attempts)
+			s.update(job, StatusDead, nil, err)
+			return
+		}
+
+		s.update(job, StatusQueued, nil, err)
+
+		select {
+		case <-time.After(s.backoff * time.Duration(job.Attempts)):
+		case <-job.ctx.Done():
+			s.update(job, StatusCanceled, nil, job.ctx.Err())
+			return
+		}
+
+		s.mutex.Lock()
+		job.pool.queued++
+		s.mutex.Unlock()
+	}
+}
+
+// done marks event at offset as done in event log, whose job ended.
+func (s *server) done(offset int64) {
+	if s.cfg.EventLog == nil || offset == 0 {
+		return
+	}
+
+	if err := s.cfg.EventLog.Done(offset); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *server) attempt(job *Job) ([]proto.Format, error) {
+	err := s.debounce(job)
+	if err == nil {
+		err = job.pool.acquire(job.ctx)
+	}
+
+	s.mutex.Lock()
+	job.pool.queued--
+	s.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer job.pool.release()
+
+	s.update(job, StatusRunning, nil, nil)
+
+	return s.cfg.Flow.RunContext(job.ctx, job.Commit)
+}
+
+// debounce holds the first attempt of job in change for quiet period of debouncing, in which job is canceled if
+// another patchset of change is triggered, so that only the latest one of burst uploads is linted. Jobs triggered by
+// commands are not held.
+func (s *server) debounce(job *Job) error {
+	d := s.cfg.Config.Spec.Queue.Debounce
+	if d <= 0 || job.Change == "" || job.Attempts != 0 || job.Source == flow.SourceCommand {
+		return nil
+	}
+
+	select {
+	case <-time.After(time.Duration(d) * time.Second):
+		return nil
+	case <-job.ctx.Done():
+		return errors.Wrap(job.ctx.Err(), "failed to debounce")
+	}
+}
+
+func (s *server) update(job *Job, status string, data []proto.Format, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job.Status = status
+	job.Findings = data
+
+	if status == StatusRunning {
+		job.Attempts++
+	}
+
+	if status == StatusDead {
+		s.dead = append(s.dead, job)
+		if len(s.dead) > jobsLimit {
+			s.dead = s.dead[1:]
+		}
+	}
+
+	if status != StatusQueued && status != StatusRunning {
+		delete(s.active, active(job.Commit, job.Profile))
+	}
+
+	if err != nil {
+		job.Error = err.Error()
+	}
+}
+
+func (s *server) poll(ctx context.Context) {
+	t := s.cfg.Config.Spec.Feedback.Interval
+	if t <= 0 {
+		t = pollInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(t) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if s.leading() {
+			if err := s.cfg.Feedback.Poll(); err != nil {
+				log.Println(err)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *server) refresh(ctx context.Context) {
+	t := s.cfg.Config.Spec.Metrics.Interval
+	if t <= 0 {
+		t = interval
+	}
+
+	ticker := time.NewTicker(time.Duration(t) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.cfg.Metrics.Refresh(); err != nil {
+			log.Println(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// leading reports whether the instance consumes events, i.e., it is elected as leader or leader is not configured.
+func (s *server) leading() bool {
+	return s.cfg.Leader == nil || s.cfg.Leader.Leading()
+}
+
+func (s *server) check() error {
+	if err := flow.Check("", &s.cfg.Config.Spec.Workspace); err != nil {
+		return errors.Wrap(err, "failed to check workspace")
+	}
+
+	for _, val := range s.cfg.Config.Spec.Lint {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(val.Host, strconv.Itoa(val.Port)), dialTimeout)
+		if err != nil {
+			return errors.Wrap(err, "failed to dial "+val.Name)
+		}
+		_ = conn.Close()
+	}
+
+	return nil
+}
+
+func (s *server) id() string {
+	buf := make([]byte, idLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,325 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+type flowTest struct{}
+
+func (f *flowTest) Flush() {}
+
+func (f *flowTest) Run(commit string) ([]proto.Format, error) {
+	return f.RunContext(context.Background(), commit)
+}
+
+func (f *flowTest) RunContext(_ context.Context, commit string) ([]proto.Format, error) {
+	if commit == "invalid" {
+		return nil, errors.New("invalid commit")
+	}
+
+	return []proto.Format{{File: "name", Line: 1, Type: proto.TypeError, Details: "text"}}, nil
+}
+
+func initServer() *server {
+	cfg := DefaultConfig()
+	cfg.Flow = &flowTest{}
+
+	return New(context.Background(), cfg).(*server)
+}
+
+func TestHealth(t *testing.T) {
+	s := initServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteHealth, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+type metricsTest struct{}
+
+func (m *metricsTest) Refresh() error {
+	return nil
+}
+
+func (m *metricsTest) Write(w io.Writer) error {
+	_, err := io.WriteString(w, "lintflow_runs_total{status=\"success\"} 1\n")
+	return err
+}
+
+func TestMetrics(t *testing.T) {
+	s := initServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteMetrics, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	s.cfg.Metrics = &metricsTest{}
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteMetrics, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "lintflow_runs_total")
+}
+
+func TestReady(t *testing.T) {
+	s := initServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteReady, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	s.cfg.Config.Spec.Lint = []config.Lint{{Name: "lintinvalid", Host: "127.0.0.1", Port: 1}}
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteReady, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestRuns(t *testing.T) {
+	s := initServer()
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader("{}")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader(`{"commit":"foo"}`)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var job Job
+	err := json.Unmarshal(rec.Body.Bytes(), &job)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "foo", job.Commit)
+
+	assert.Eventually(t, func() bool {
+		rec = httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/"+job.ID, nil))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job.Status == StatusSuccess
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, len(job.Findings))
+
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/invalid", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+type flowBlock struct {
+	ch chan struct{}
+}
+
+func (f *flowBlock) Flush() {}
+
+func (f *flowBlock) Run(commit string) ([]proto.Format, error) {
+	return f.RunContext(context.Background(), commit)
+}
+
+func (f *flowBlock) RunContext(ctx context.Context, _ string) ([]proto.Format, error) {
+	select {
+	case <-f.ch:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRunsCoalesce(t *testing.T) {
+	f := &flowBlock{ch: make(chan struct{})}
+
+	s := initServer()
+	s.cfg.Flow = f
+
+	post := func() (int, Job) {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader(`{"commit":"foo"}`)))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return rec.Code, job
+	}
+
+	code, a := post()
+	assert.Equal(t, http.StatusAccepted, code)
+
+	code, b := post()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, a.ID, b.ID)
+
+	close(f.ch)
+
+	assert.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/"+a.ID, nil))
+		_ = json.Unmarshal(rec.Body.Bytes(), &a)
+		return a.Status == StatusSuccess
+	}, time.Second, 10*time.Millisecond)
+
+	code, b = post()
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.NotEqual(t, a.ID, b.ID)
+}
+
+func TestRunsSupersede(t *testing.T) {
+	f := &flowBlock{ch: make(chan struct{})}
+	defer close(f.ch)
+
+	s := initServer()
+	s.cfg.Config.Spec.Queue.Supersede = true
+	s.cfg.Flow = f
+
+	post := func(body string) Job {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader(body)))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job
+	}
+
+	get := func(id string) Job {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/"+id, nil))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job
+	}
+
+	a := post(`{"change":"41","commit":"foo"}`)
+	b := post(`{"change":"42","commit":"bar"}`)
+	c := post(`{"change":"41","commit":"baz"}`)
+
+	assert.Eventually(t, func() bool {
+		return get(a.ID).Status == StatusCanceled
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NotEqual(t, StatusCanceled, get(b.ID).Status)
+	assert.NotEqual(t, StatusCanceled, get(c.ID).Status)
+}
+
+func TestRunsDebounce(t *testing.T) {
+	s := initServer()
+	s.cfg.Config.Spec.Queue.Debounce = 1
+
+	post := func(body string) Job {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader(body)))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job
+	}
+
+	get := func(id string) Job {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/"+id, nil))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job
+	}
+
+	a := post(`{"change":"41","commit":"foo"}`)
+	b := post(`{"commit":"bar"}`)
+	c := post(`{"change":"41","commit":"baz"}`)
+
+	assert.Eventually(t, func() bool {
+		return get(a.ID).Status == StatusCanceled
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return get(b.ID).Status == StatusSuccess
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, StatusQueued, get(c.ID).Status)
+
+	assert.Eventually(t, func() bool {
+		return get(c.ID).Status == StatusSuccess
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestRunsPool(t *testing.T) {
+	f := &flowBlock{ch: make(chan struct{})}
+
+	s := initServer()
+	s.cfg.Config.Spec.Queue.Source = []config.QueueSource{{Concurrency: 1, Name: "backfill", Size: 1}}
+	s.cfg.Flow = f
+
+	post := func(body string) (int, Job) {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RouteRuns, strings.NewReader(body)))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return rec.Code, job
+	}
+
+	get := func(id string) Job {
+		var job Job
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RouteRuns+"/"+id, nil))
+		_ = json.Unmarshal(rec.Body.Bytes(), &job)
+		return job
+	}
+
+	_, a := post(`{"commit":"foo","source":"backfill"}`)
+	assert.Eventually(t, func() bool {
+		return get(a.ID).Status == StatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	code, b := post(`{"commit":"bar","source":"backfill"}`)
+	assert.Equal(t, http.StatusAccepted, code)
+
+	code, _ = post(`{"commit":"baz","source":"backfill"}`)
+	assert.Equal(t, http.StatusTooManyRequests, code)
+
+	_, c := post(`{"commit":"baz","source":"webhook"}`)
+	assert.Eventually(t, func() bool {
+		return get(c.ID).Status == StatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, StatusQueued, get(b.ID).Status)
+
+	close(f.ch)
+
+	assert.Eventually(t, func() bool {
+		return get(b.ID).Status == StatusSuccess
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestProbe(t *testing.T) {
+	s := initServer()
+
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	err := Probe(strings.TrimPrefix(ts.URL, "http://"))
+	assert.Equal(t, nil, err)
+
+	err = Probe("127.0.0.1:1")
+	assert.NotEqual(t, nil, err)
+}
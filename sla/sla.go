@@ -0,0 +1,172 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sla
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+type Sla interface {
+	Report([]proto.Run, time.Time) []Entry
+}
+
+type Config struct {
+	Rules []config.SlaRule
+}
+
+// Entry is finding open in the latest patchset of change, for patchsets in row and age since first seen in them.
+type Entry struct {
+	Age       time.Duration `json:"age"`
+	Breach    bool          `json:"breach"`
+	Change    int           `json:"change"`
+	Commit    string        `json:"commit"`
+	Finding   proto.Format  `json:"finding"`
+	Patchsets int           `json:"patchsets"`
+	Repo      string        `json:"repo"`
+}
+
+type sla struct {
+	cfg *Config
+}
+
+// patchset is the latest successful run of commit, ordered by the first run of commit in change.
+type patchset struct {
+	first time.Time
+	run   proto.Run
+}
+
+func New(cfg *Config) Sla {
+	return &sla{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Report reports findings open in the latest patchsets of changes in runs at now, in order of change and finding.
+func (s *sla) Report(runs []proto.Run, now time.Time) []Entry {
+	var ret []Entry
+
+	changes := s.changes(runs)
+
+	for _, c := range sortChanges(changes) {
+		buf := changes[c]
+		latest := buf[len(buf)-1].run
+		for _, val := range latest.Findings {
+			e := Entry{Change: c, Commit: latest.Commit, Finding: val, Repo: latest.Repo}
+			k := key(val)
+			for index := len(buf) - 1; index >= 0 && has(buf[index].run.Findings, k); index-- {
+				e.Age, e.Patchsets = now.Sub(buf[index].first), e.Patchsets+1
+			}
+			e.Breach = s.breach(&e)
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+// changes groups runs of changes into patchsets, skipping runs failed, skipped or without change.
+func (s *sla) changes(runs []proto.Run) map[int][]patchset {
+	first := map[string]time.Time{}
+	latest := map[string]proto.Run{}
+
+	for _, val := range runs {
+		if val.Change == 0 {
+			continue
+		}
+		if t, ok := first[val.Commit]; !ok || val.Start.Before(t) {
+			first[val.Commit] = val.Start
+		}
+		if val.Status != proto.StatusSuccess || val.Mode == proto.ModeSkip {
+			continue
+		}
+		if r, ok := latest[val.Commit]; !ok || val.Start.After(r.Start) {
+			latest[val.Commit] = val
+		}
+	}
+
+	ret := map[int][]patchset{}
+
+	for key, val := range latest {
+		ret[val.Change] = append(ret[val.Change], patchset{first: first[key], run: val})
+	}
+
+	for _, val := range ret {
+		sort.Slice(val, func(i, j int) bool {
+			return val[i].first.Before(val[j].first)
+		})
+	}
+
+	return ret
+}
+
+// breach reports whether entry is open beyond the first rule matched by type of finding.
+func (s *sla) breach(e *Entry) bool {
+	for _, val := range s.cfg.Rules {
+		if val.Type != "" && val.Type != e.Finding.Type {
+			continue
+		}
+		return (val.Patchsets > 0 && e.Patchsets > val.Patchsets) ||
+			(val.Age > 0 && e.Age > time.Duration(val.Age)*time.Second)
+	}
+
+	return false
+}
+
+// Breaches filters entries breaching rules.
+func Breaches(data []Entry) []Entry {
+	var ret []Entry
+
+	for _, val := range data {
+		if val.Breach {
+			ret = append(ret, val)
+		}
+	}
+
+	return ret
+}
+
+// key identifies finding across patchsets regardless of line, which is shifted by changes around.
+func key(data proto.Format) string {
+	return strings.Join([]string{data.File, data.Lint, data.Rule, data.Type, data.Details}, "\x00")
+}
+
+func has(data []proto.Format, k string) bool {
+	for _, val := range data {
+		if key(val) == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortChanges(data map[int][]patchset) []int {
+	var buf []int
+
+	for k := range data {
+		buf = append(buf, k)
+	}
+
+	sort.Ints(buf)
+
+	return buf
+}
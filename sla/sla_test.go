@@ -0,0 +1,58 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+	"github.com/craftslab/lintflow/proto"
+)
+
+func TestReport(t *testing.T) {
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	start := now.Add(-24 * time.Hour)
+
+	err := proto.Format{File: "main.go", Line: 3, Type: "Error", Details: "error"}
+	warn := proto.Format{File: "main.go", Line: 5, Type: "Warn", Details: "unused"}
+	moved := err
+	moved.Line = 8
+
+	runs := []proto.Run{
+		{Change: 1, Commit: "a", Findings: []proto.Format{err, warn}, Start: start, Status: proto.StatusSuccess},
+		{Change: 1, Commit: "b", Findings: []proto.Format{err}, Start: start.Add(time.Hour), Status: proto.StatusSuccess},
+		{Change: 1, Commit: "c", Start: start.Add(2 * time.Hour), Status: proto.StatusFailed},
+		{Change: 1, Commit: "c", Findings: []proto.Format{moved, warn}, Start: start.Add(3 * time.Hour),
+			Status: proto.StatusSuccess},
+		{Change: 2, Commit: "d", Findings: []proto.Format{err}, Start: start, Status: proto.StatusSuccess},
+		{Change: 2, Commit: "e", Start: start.Add(time.Hour), Mode: proto.ModeSkip, Status: proto.StatusSuccess},
+		{Commit: "f", Findings: []proto.Format{err}, Start: start, Status: proto.StatusSuccess},
+	}
+
+	s := New(&Config{Rules: []config.SlaRule{{Patchsets: 2, Type: "Error"}, {Age: 3600}}})
+
+	buf := s.Report(runs, now)
+	assert.Equal(t, []Entry{
+		{Age: 24 * time.Hour, Breach: true, Change: 1, Commit: "c", Finding: moved, Patchsets: 3},
+		{Age: 22 * time.Hour, Breach: true, Change: 1, Commit: "c", Finding: warn, Patchsets: 1},
+		{Age: 24 * time.Hour, Change: 2, Commit: "d", Finding: err, Patchsets: 1},
+	}, buf)
+
+	assert.Equal(t, []Entry{buf[0], buf[1]}, Breaches(buf))
+
+	s = New(DefaultConfig())
+	assert.Equal(t, 0, len(Breaches(s.Report(runs, now))))
+}
@@ -0,0 +1,122 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dirPerm  = 0755
+	filePerm = 0600
+)
+
+// local keeps workspaces on disk of node, including tmpfs mounted, e.g., /dev/shm.
+type local struct{}
+
+// List returns files whose paths start with prefix, under directory of prefix.
+func (l *local) List(prefix string) ([]Object, error) {
+	prefix = filepath.FromSlash(prefix)
+
+	var ret []Object
+
+	err := filepath.Walk(filepath.Dir(prefix), func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasPrefix(name, prefix) {
+			ret = append(ret, Object{Modified: info.ModTime(), Name: filepath.ToSlash(name)})
+		}
+		return nil
+	})
+
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to walk")
+	}
+
+	return ret, nil
+}
+
+func (l *local) Read(name string) ([]byte, error) {
+	buf, err := ioutil.ReadFile(filepath.FromSlash(name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	return buf, nil
+}
+
+func (l *local) Remove(name string) error {
+	if err := os.RemoveAll(filepath.FromSlash(name)); err != nil {
+		return errors.Wrap(err, "failed to remove")
+	}
+
+	return nil
+}
+
+func (l *local) Write(name string, data []byte) error {
+	return WriteFile(filepath.FromSlash(name), data, filePerm)
+}
+
+// WriteFile writes data to temp file synced in the same directory, and renames it to name atomically,
+// so that no truncated file is left, e.g., on full disk.
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(name)
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return errors.Wrap(err, "failed to mkdir")
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(name)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create")
+	}
+
+	tmp := f.Name()
+
+	helper := func() error {
+		if _, err := f.Write(data); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+		if err := f.Sync(); err != nil {
+			return errors.Wrap(err, "failed to sync")
+		}
+		if err := f.Chmod(perm); err != nil {
+			return errors.Wrap(err, "failed to chmod")
+		}
+		return nil
+	}
+
+	if err := helper(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "failed to close")
+	}
+
+	if err := os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "failed to rename")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	d, err := ioutil.TempDir("", "storage")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	l := local{}
+	root := filepath.Join(d, "lintflow-id")
+
+	err = l.Write(filepath.Join(root, "src", "main.go.base64"), []byte("text"))
+	assert.Equal(t, nil, err)
+
+	buf, err := l.Read(filepath.Join(root, "src", "main.go.base64"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "text", string(buf))
+
+	objects, err := l.List(filepath.Join(d, "lintflow-"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(objects))
+	assert.Equal(t, filepath.ToSlash(filepath.Join(root, "src", "main.go.base64")), objects[0].Name)
+
+	err = l.Remove(root)
+	assert.Equal(t, nil, err)
+
+	objects, err = l.List(filepath.Join(d, "invalid", "lintflow-"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(objects))
+
+	_, err = l.Read(filepath.Join(root, "src", "main.go.base64"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestWriteFile(t *testing.T) {
+	d, err := ioutil.TempDir("", "atomic")
+	assert.Equal(t, nil, err)
+
+	defer func() { _ = os.RemoveAll(d) }()
+
+	name := filepath.Join(d, "dir", "main.go.base64")
+
+	err = WriteFile(name, []byte("text"), filePerm)
+	assert.Equal(t, nil, err)
+
+	err = WriteFile(name, []byte("content"), filePerm)
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(name)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "content", string(buf))
+
+	files, _ := ioutil.ReadDir(filepath.Join(d, "dir"))
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, os.FileMode(filePerm), files[0].Mode().Perm())
+
+	err = WriteFile(filepath.Join(name, "invalid"), []byte("text"), filePerm)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,307 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsRegion   = "auto"
+	s3Region    = "us-east-1"
+)
+
+const (
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	signDate      = "20060102"
+	signTime      = "20060102T150405Z"
+	signHeaders   = "host;x-amz-content-sha256;x-amz-date"
+)
+
+// s3 keeps workspaces in bucket by path-style requests signed in AWS Signature Version 4,
+// which are also served by interoperability of GCS with HMAC keys.
+type s3 struct {
+	c        *http.Client
+	bucket   string
+	endpoint string
+	key      string
+	region   string
+	secret   string
+}
+
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func newGcs(client *http.Client, cfg *config.Storage) Storage {
+	s := newS3(client, cfg).(*s3)
+
+	if cfg.Endpoint == "" {
+		s.endpoint = gcsEndpoint
+	}
+
+	if cfg.Region == "" {
+		s.region = gcsRegion
+	}
+
+	return s
+}
+
+func newS3(client *http.Client, cfg *config.Storage) Storage {
+	s := &s3{
+		c:        client,
+		bucket:   cfg.Bucket,
+		endpoint: strings.TrimSuffix(cfg.Endpoint, "/"),
+		key:      cfg.Key,
+		region:   cfg.Region,
+		secret:   cfg.Secret,
+	}
+
+	if s.region == "" {
+		s.region = s3Region
+	}
+
+	if s.endpoint == "" {
+		s.endpoint = "https://s3." + s.region + ".amazonaws.com"
+	}
+
+	return s
+}
+
+// List returns objects whose names start with prefix.
+func (s *s3) List(prefix string) ([]Object, error) {
+	ret, err := s.list(s.object(prefix))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list")
+	}
+
+	return ret, nil
+}
+
+func (s *s3) Read(name string) ([]byte, error) {
+	rsp, err := s.do(http.MethodGet, s.object(name), nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		return nil, errors.New("invalid status " + rsp.Status)
+	}
+
+	buf, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	return buf, nil
+}
+
+// Remove deletes object of name and objects under it as directory.
+func (s *s3) Remove(name string) error {
+	objects, err := s.list(s.object(name) + "/")
+	if err != nil {
+		return errors.Wrap(err, "failed to list")
+	}
+
+	var keys []string
+
+	for _, val := range objects {
+		keys = append(keys, val.Name)
+	}
+
+	for _, val := range append(keys, s.object(name)) {
+		rsp, err := s.do(http.MethodDelete, val, nil, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to do")
+		}
+		_, _ = io.Copy(ioutil.Discard, rsp.Body)
+		_ = rsp.Body.Close()
+		if rsp.StatusCode != http.StatusNoContent && rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusNotFound {
+			return errors.New("invalid status " + rsp.Status)
+		}
+	}
+
+	return nil
+}
+
+func (s *s3) Write(name string, data []byte) error {
+	rsp, err := s.do(http.MethodPut, s.object(name), nil, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to do")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	_, _ = io.Copy(ioutil.Discard, rsp.Body)
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("invalid status " + rsp.Status)
+	}
+
+	return nil
+}
+
+func (s *s3) list(prefix string) ([]Object, error) {
+	var ret []Object
+
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+
+	for {
+		rsp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to do")
+		}
+		buf, err := ioutil.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+		if rsp.StatusCode != http.StatusOK {
+			return nil, errors.New("invalid status " + rsp.Status)
+		}
+		var r listResult
+		if err := xml.Unmarshal(buf, &r); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		for _, val := range r.Contents {
+			ret = append(ret, Object{Modified: val.LastModified, Name: val.Key})
+		}
+		if !r.IsTruncated || r.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", r.NextContinuationToken)
+	}
+
+	return ret, nil
+}
+
+// object maps name in workspace to key of object, e.g., /root/lintflow-id/main.go.base64 to root/lintflow-id/main.go.base64
+func (s *s3) object(name string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+}
+
+func (s *s3) do(method, key string, query url.Values, data []byte) (*http.Response, error) {
+	u := s.endpoint + "/" + escape(s.bucket, false)
+	if key != "" {
+		u += "/" + escape(key, false)
+	}
+
+	if len(query) != 0 {
+		u += "?" + canonicalQuery(query)
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request")
+	}
+
+	s.sign(req, data, time.Now().UTC())
+
+	return s.c.Do(req)
+}
+
+// sign signs request in AWS Signature Version 4 with headers of host, content hash and date.
+func (s *s3) sign(req *http.Request, data []byte, t time.Time) {
+	hash := sha256.Sum256(data)
+	payload := hex.EncodeToString(hash[:])
+
+	req.Header.Set("x-amz-content-sha256", payload)
+	req.Header.Set("x-amz-date", t.Format(signTime))
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		"host:" + req.URL.Host + "\n" + "x-amz-content-sha256:" + payload + "\n" + "x-amz-date:" + t.Format(signTime) + "\n",
+		signHeaders,
+		payload,
+	}, "\n")
+
+	scope := t.Format(signDate) + "/" + s.region + "/s3/aws4_request"
+	hash = sha256.Sum256([]byte(canonical))
+	message := signAlgorithm + "\n" + t.Format(signTime) + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := sum([]byte("AWS4"+s.secret), t.Format(signDate))
+	key = sum(key, s.region)
+	key = sum(key, "s3")
+	key = sum(key, "aws4_request")
+
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+s.key+"/"+scope+", SignedHeaders="+signHeaders+
+		", Signature="+hex.EncodeToString(sum(key, message)))
+}
+
+func sum(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	var buf []string
+
+	for key, val := range query {
+		for _, v := range val {
+			buf = append(buf, escape(key, true)+"="+escape(v, true))
+		}
+	}
+
+	sort.Strings(buf)
+
+	return strings.Join(buf, "&")
+}
+
+// escape encodes all but unreserved characters of RFC 3986, and slash unless in query.
+func escape(data string, query bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(data) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' || (c == '/' && !query) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+
+	return b.String()
+}
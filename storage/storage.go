@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+const (
+	KindGcs   = "gcs"
+	KindLocal = "local"
+	KindS3    = "s3"
+	KindTmpfs = "tmpfs"
+)
+
+const (
+	// TmpfsRoot is default root of workspaces on tmpfs.
+	TmpfsRoot = "/dev/shm"
+)
+
+// Storage keeps fetched content of workspaces, in which names are slash or OS separated paths.
+type Storage interface {
+	List(string) ([]Object, error)
+	Read(string) ([]byte, error)
+	Remove(string) error
+	Write(string, []byte) error
+}
+
+// Object is file in storage, in which name is slash separated path.
+type Object struct {
+	Modified time.Time
+	Name     string
+}
+
+type Config struct {
+	Client  *http.Client
+	Storage config.Storage
+}
+
+func New(cfg *Config) Storage {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	switch cfg.Storage.Kind {
+	case KindGcs:
+		return newGcs(client, &cfg.Storage)
+	case KindS3:
+		return newS3(client, &cfg.Storage)
+	default:
+		return &local{}
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Remote reports whether workspaces are kept off disk of node, in which root is prefix of names instead of directory.
+func Remote(cfg *config.Storage) bool {
+	return cfg.Kind == KindGcs || cfg.Kind == KindS3
+}
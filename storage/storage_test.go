@@ -0,0 +1,45 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/craftslab/lintflow/config"
+)
+
+func TestNew(t *testing.T) {
+	_, ok := New(DefaultConfig()).(*local)
+	assert.Equal(t, true, ok)
+
+	_, ok = New(&Config{Storage: config.Storage{Kind: KindTmpfs}}).(*local)
+	assert.Equal(t, true, ok)
+
+	s, ok := New(&Config{Storage: config.Storage{Bucket: "lintflow", Kind: KindS3}}).(*s3)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "https://s3.us-east-1.amazonaws.com", s.endpoint)
+
+	s, ok = New(&Config{Storage: config.Storage{Bucket: "lintflow", Kind: KindGcs}}).(*s3)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, gcsEndpoint, s.endpoint)
+	assert.Equal(t, gcsRegion, s.region)
+}
+
+func TestRemote(t *testing.T) {
+	assert.Equal(t, false, Remote(&config.Storage{}))
+	assert.Equal(t, false, Remote(&config.Storage{Kind: KindTmpfs}))
+	assert.Equal(t, true, Remote(&config.Storage{Kind: KindGcs}))
+	assert.Equal(t, true, Remote(&config.Storage{Kind: KindS3}))
+}
@@ -0,0 +1,112 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	timeout = 3 * time.Second
+)
+
+type Telemetry interface {
+	Report(proto.Run) error
+}
+
+type Config struct {
+	Lints   []string
+	Url     string
+	Version string
+}
+
+// Usage is the anonymous report, which never carries hosts, users, repos, commits, files or details.
+type Usage struct {
+	Version  string         `json:"version"`
+	OS       string         `json:"os"`
+	Arch     string         `json:"arch"`
+	Linters  []string       `json:"linters"`
+	Duration int64          `json:"duration"`
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Findings map[string]int `json:"findings"`
+}
+
+type telemetry struct {
+	cfg *Config
+}
+
+func New(cfg *Config) Telemetry {
+	return &telemetry{
+		cfg: cfg,
+	}
+}
+
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+func (t *telemetry) Report(run proto.Run) error {
+	if t.cfg.Url == "" {
+		return errors.New("invalid url")
+	}
+
+	buf, err := json.Marshal(t.usage(&run))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	client := http.Client{Timeout: timeout}
+
+	rsp, err := client.Post(t.cfg.Url, "application/json;charset=utf-8", bytes.NewReader(buf))
+	if err != nil {
+		return errors.Wrap(err, "failed to post")
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode/100 != 2 {
+		return errors.New("invalid status")
+	}
+
+	return nil
+}
+
+func (t *telemetry) usage(run *proto.Run) *Usage {
+	u := &Usage{
+		Version:  t.cfg.Version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Linters:  t.cfg.Lints,
+		Duration: run.Duration.Milliseconds(),
+		Status:   run.Status,
+		Error:    run.Stage,
+		Findings: map[string]int{},
+	}
+
+	for _, val := range run.Findings {
+		u.Findings[val.Type]++
+	}
+
+	return u
+}
@@ -0,0 +1,158 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"html/template"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/craftslab/lintflow/proto"
+)
+
+const (
+	changeFile = "(change)"
+)
+
+// htmlTemplate is self-contained, i.e., styles and scripts are inline without external assets.
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>lintflow report</title>
+<style>
+body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;margin:2em;color:#24292e}
+h1{font-size:1.5em}h2{font-size:1.2em;margin-top:2em}
+table{border-collapse:collapse;width:100%}
+th,td{border-bottom:1px solid #e1e4e8;padding:4px 8px;text-align:left;vertical-align:top}
+th{background:#f6f8fa}
+.Error{color:#cb2431;font-weight:bold}.Warn{color:#b08800;font-weight:bold}.Info{color:#0366d6}
+.summary span{margin-right:2em}
+.filter{margin:1em 0}.filter input{width:30em}
+pre{margin:0;font-family:SFMono-Regular,Consolas,monospace;white-space:pre-wrap}
+.line{background:#fffbdd;border-left:4px solid #b08800;padding:2px 8px}
+.line.Error{background:#ffeef0;border-color:#cb2431}.line.Info{background:#f1f8ff;border-color:#0366d6}
+.note{padding:2px 8px 8px 2.5em;color:#586069}
+</style>
+</head>
+<body>
+<h1>lintflow report</h1>
+<p>Generated at {{.Time}}</p>
+<p class="summary">{{range .Summary}}<span class="{{.Type}}">{{.Type}}: {{.Count}}</span>{{end}}<span>Total: {{len .Rows}}</span></p>
+<div class="filter">
+<input id="text" type="search" placeholder="Filter by file, lint, rule or details" oninput="filter()">
+<select id="type" onchange="filter()"><option value="">All types</option>{{range .Summary}}<option>{{.Type}}</option>{{end}}</select>
+</div>
+<table id="findings">
+<thead><tr><th>File</th><th>Line</th><th>Type</th><th>Lint</th><th>Rule</th><th>Details</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr data-type="{{.Type}}"><td><a href="#file-{{.Index}}">{{.File}}</a></td><td>{{if .Line}}{{.Line}}{{end}}</td><td class="{{.Type}}">{{.Type}}</td><td>{{.Lint}}</td><td>{{.Rule}}</td><td>{{.Details}}</td></tr>
+{{end}}</tbody>
+</table>
+{{range .Files}}<h2 id="file-{{.Index}}">{{.Name}}</h2>
+{{range .Findings}}<div class="line {{.Type}}"><pre>{{if .Line}}{{.Line}}: {{end}}{{.Snippet}}</pre></div>
+<div class="note"><span class="{{.Type}}">{{.Type}}</span> {{.Details}} <em>{{.Lint}}{{if .Rule}}/{{.Rule}}{{end}}</em></div>
+{{end}}{{end}}
+<script>
+function filter() {
+  var text = document.getElementById("text").value.toLowerCase();
+  var type = document.getElementById("type").value;
+  var rows = document.querySelectorAll("#findings tbody tr");
+  for (var i = 0; i < rows.length; i++) {
+    var row = rows[i];
+    var ok = (type === "" || row.dataset.type === type) && row.textContent.toLowerCase().indexOf(text) >= 0;
+    row.style.display = ok ? "" : "none";
+  }
+}
+</script>
+</body>
+</html>
+`))
+
+type htmlReport struct {
+	Files   []htmlFile
+	Rows    []htmlRow
+	Summary []htmlCount
+	Time    string
+}
+
+type htmlFile struct {
+	Findings []htmlRow
+	Index    int
+	Name     string
+}
+
+type htmlRow struct {
+	proto.Format
+	Index   int
+	Snippet string
+}
+
+type htmlCount struct {
+	Count int
+	Type  string
+}
+
+func (w *writer) writeHtml(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if err := htmlTemplate.Execute(f, w.html()); err != nil {
+		return errors.Wrap(err, "failed to execute")
+	}
+
+	return nil
+}
+
+func (w *writer) html() *htmlReport {
+	data := append([]proto.Format{}, w.data...)
+	sort.SliceStable(data, func(i, j int) bool {
+		if data[i].File != data[j].File {
+			return data[i].File < data[j].File
+		}
+		return data[i].Line < data[j].Line
+	})
+
+	ret := &htmlReport{Time: time.Now().Local().Format(time.RFC3339)}
+	count := map[string]int{}
+
+	for _, val := range data {
+		file := val.File
+		if file == "" {
+			file = changeFile
+		}
+		if len(ret.Files) == 0 || ret.Files[len(ret.Files)-1].Name != file {
+			ret.Files = append(ret.Files, htmlFile{Index: len(ret.Files), Name: file})
+		}
+		f := &ret.Files[len(ret.Files)-1]
+		row := htmlRow{Format: val, Index: f.Index, Snippet: w.cfg.Snippets[val.File][val.Line]}
+		row.File = file
+		f.Findings = append(f.Findings, row)
+		ret.Rows = append(ret.Rows, row)
+		count[val.Type]++
+	}
+
+	for _, val := range []string{proto.TypeError, proto.TypeWarn, proto.TypeInfo} {
+		ret.Summary = append(ret.Summary, htmlCount{Count: count[val], Type: val})
+	}
+
+	return ret
+}
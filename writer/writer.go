@@ -25,6 +25,7 @@ import (
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
 	"github.com/pkg/errors"
 
+	"github.com/craftslab/lintflow/codeclimate"
 	"github.com/craftslab/lintflow/proto"
 )
 
@@ -43,6 +44,8 @@ type Writer interface {
 }
 
 type Config struct {
+	// Snippets are lines with findings by file and line, which are highlighted in HTML.
+	Snippets map[string]map[int]string
 }
 
 type writer struct {
@@ -64,7 +67,11 @@ func (w *writer) Run(name string, data []proto.Format) error {
 	var err error
 	w.data = data
 
-	if strings.HasSuffix(name, ".json") {
+	if strings.HasSuffix(name, codeclimate.Ext) {
+		err = w.writeCodeClimate(name)
+	} else if strings.HasSuffix(name, ".html") {
+		err = w.writeHtml(name)
+	} else if strings.HasSuffix(name, ".json") {
 		err = w.writeJson(name)
 	} else if strings.HasSuffix(name, ".txt") {
 		err = w.writeTxt(name)
@@ -77,6 +84,19 @@ func (w *writer) Run(name string, data []proto.Format) error {
 	return err
 }
 
+func (w *writer) writeCodeClimate(name string) error {
+	b, err := codeclimate.Marshal(w.data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if err := ioutil.WriteFile(name, b, perm); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
 func (w *writer) writeJson(name string) error {
 	buf := make(map[string][]proto.Format)
 	buf[sheetName] = w.data
@@ -100,7 +120,7 @@ func (w *writer) writeTxt(name string) error {
 		var h []string
 		r := reflect.TypeOf(proto.Format{})
 		for i := 0; i < r.NumField(); i++ {
-			h = append(h, r.Field(i).Tag.Get("json"))
+			h = append(h, strings.Split(r.Field(i).Tag.Get("json"), sep)[0])
 		}
 		buf = append(buf, strings.Join(h, sep))
 
@@ -149,7 +169,7 @@ func (w *writer) writeXlsx(name string) error {
 
 		r := reflect.TypeOf(proto.Format{})
 		for i := 0; i < r.NumField(); i++ {
-			head = append(head, strings.ToUpper(r.Field(i).Tag.Get("json")))
+			head = append(head, strings.ToUpper(strings.Split(r.Field(i).Tag.Get("json"), sep)[0]))
 		}
 
 		for _, val := range w.data {
@@ -196,16 +216,17 @@ func (w *writer) writeXlsx(name string) error {
 	}
 
 	head, data := helper()
+	col := string(rune('A' + len(head) - 1))
 
 	style := `{"alignment":{"horizontal":"center","vertical":"center"},"font":{"bold":true}}`
-	if err := write("1", "D", style, &head); err != nil {
+	if err := write("1", col, style, &head); err != nil {
 		return errors.Wrap(err, "failed to write head")
 	}
 
 	style = `{"alignment":{"horizontal":"center","vertical":"center"},"font":{"bold":false}}`
 	offset := 2
 	for index := range data {
-		if err := write(strconv.Itoa(index+offset), "D", style, &data[index]); err != nil {
+		if err := write(strconv.Itoa(index+offset), col, style, &data[index]); err != nil {
 			return errors.Wrap(err, "failed to write data")
 		}
 	}
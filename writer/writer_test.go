@@ -13,11 +13,14 @@
 package writer
 
 import (
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/craftslab/lintflow/codeclimate"
 	"github.com/craftslab/lintflow/proto"
 )
 
@@ -47,6 +50,45 @@ func TestWriterJson(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestWriteCodeClimate(t *testing.T) {
+	name := "output" + codeclimate.Ext
+
+	w := New(DefaultConfig())
+
+	err := w.Run(name, []proto.Format{fileContent})
+	defer func(name string) { _ = os.Remove(name) }(name)
+
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(name)
+	assert.Equal(t, nil, err)
+
+	data, err := codeclimate.Unmarshal(buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(data))
+}
+
+func TestWriteHtml(t *testing.T) {
+	name := "output.html"
+
+	cfg := DefaultConfig()
+	cfg.Snippets = map[string]map[int]string{"name": {1: "<tag>"}}
+
+	w := New(cfg)
+
+	err := w.Run(name, []proto.Format{fileContent, {Type: proto.TypeInfo, Details: "change"}})
+	defer func(name string) { _ = os.Remove(name) }(name)
+
+	assert.Equal(t, nil, err)
+
+	buf, err := ioutil.ReadFile(name)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, true, strings.Contains(string(buf), `<div class="line Error"><pre>1: &lt;tag&gt;</pre></div>`))
+	assert.Equal(t, true, strings.Contains(string(buf), `<a href="#file-0">(change)</a>`))
+	assert.Equal(t, true, strings.Contains(string(buf), `<span class="Error">Error: 1</span>`))
+}
+
 func TestWriteTxt(t *testing.T) {
 	name := "output.txt"
 